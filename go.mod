@@ -4,6 +4,7 @@ go 1.23
 
 require (
 	github.com/chzyer/readline v1.5.1
+	github.com/hexops/gotextdiff v1.0.3
 	github.com/marcuscaisey/go-sumtype v0.0.0-20241208122212-4c96c503b8ce
 	github.com/mattn/go-runewidth v0.0.15
 	golang.org/x/term v0.18.0
@@ -18,7 +19,6 @@ require (
 // Test-only
 require (
 	github.com/google/go-cmp v0.6.0
-	github.com/hexops/gotextdiff v1.0.3
 )
 
 require (
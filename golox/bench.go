@@ -0,0 +1,189 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/marcuscaisey/lox/lox/ast"
+	"github.com/marcuscaisey/lox/lox/output"
+	"github.com/marcuscaisey/lox/lox/parser"
+)
+
+// benchResult is the result of running a program several times with a backend.
+type benchResult struct {
+	backend string
+	runs    int
+	total   time.Duration
+	mean    time.Duration
+	stddev  time.Duration
+	min     time.Duration
+	max     time.Duration
+}
+
+// runBench implements the bench subcommand, which runs a script several times with one or both backends and prints how
+// long the runs took.
+func runBench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	runs := flags.Int("n", 10, "Number of times to run the script")
+	compare := flags.Bool("compare", false, fmt.Sprintf("Run the script with both the %s and %s backends and compare them", backendTree, backendVM))
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: golox [options] bench [bench options] script\n")
+		fmt.Fprintf(flags.Output(), "\n")
+		fmt.Fprintf(flags.Output(), "Runs the script several times with the backend selected by -backend and prints how long the runs took.\n")
+		fmt.Fprintf(flags.Output(), "The output of the script is discarded.\n")
+		fmt.Fprintf(flags.Output(), "\n")
+		fmt.Fprintf(flags.Output(), "Bench options:\n")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *runs < 1 {
+		fmt.Fprintf(flags.Output(), "error: -n must be positive\n\n")
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if *compare && *tailCalls {
+		fmt.Fprintf(flags.Output(), "error: -compare can't be used with -tail-calls, which only the %s backend supports\n\n", backendTree)
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	program, err := parser.Parse(f)
+	if err != nil {
+		return err
+	}
+
+	backends := []string{*backend}
+	if *compare {
+		backends = []string{backendTree, backendVM}
+	}
+	results := make([]benchResult, len(backends))
+	for i, backend := range backends {
+		results[i], err = bench(program, backend, *runs)
+		if err != nil {
+			return err
+		}
+	}
+
+	printBenchResults(results)
+	return nil
+}
+
+// bench runs a program with a backend the given number of times and returns how long the runs took. Each run uses a
+// new runtime, so that no state is shared between them.
+func bench(program ast.Program, backend string, runs int) (benchResult, error) {
+	// The first run isn't timed. It reports any warnings or errors before the timed runs start and warms up the Go
+	// runtime.
+	if err := interpret(newBackendRuntime(backend, false, io.Discard, printWarnings), program); err != nil {
+		return benchResult{}, err
+	}
+
+	durations := make([]time.Duration, runs)
+	for i := range durations {
+		runtime := newBackendRuntime(backend, false, io.Discard, nil)
+		start := time.Now()
+		err := interpret(runtime, program)
+		durations[i] = time.Since(start)
+		if err != nil {
+			return benchResult{}, err
+		}
+	}
+	return newBenchResult(backend, durations), nil
+}
+
+func newBenchResult(backend string, durations []time.Duration) benchResult {
+	result := benchResult{
+		backend: backend,
+		runs:    len(durations),
+		min:     durations[0],
+		max:     durations[0],
+	}
+	for _, d := range durations {
+		result.total += d
+		result.min = min(result.min, d)
+		result.max = max(result.max, d)
+	}
+	result.mean = result.total / time.Duration(len(durations))
+	if len(durations) > 1 {
+		var sumSquares float64
+		for _, d := range durations {
+			diff := float64(d - result.mean)
+			sumSquares += diff * diff
+		}
+		result.stddev = time.Duration(math.Sqrt(sumSquares / float64(len(durations)-1)))
+	}
+	return result
+}
+
+// printBenchResults prints the results of running a program with each backend.
+//
+// In the pretty format, the results are printed as a table. If there are two results, then the ratio between their mean
+// durations is printed after it:
+//
+//	backend  runs  total     mean     stddev  min      max
+//	tree     10    3.291s    329.1ms  4.1ms   324.5ms  337.6ms
+//	vm       10    612.87ms  61.28ms  1.2ms   60.01ms  63.93ms
+//
+//	vm is 5.37x faster than tree
+//
+// In the JSON format, each result is printed as an object on its own line, with durations in seconds:
+//
+//	{"backend":"vm","max":0.06393,"mean":0.06128,"min":0.06001,"runs":10,"stddev":0.0012,"total":0.61287}
+func printBenchResults(results []benchResult) {
+	if outFormat == output.JSON {
+		for _, result := range results {
+			output.PrintJSON(os.Stdout, map[string]any{
+				"backend": result.backend,
+				"runs":    result.runs,
+				"total":   result.total.Seconds(),
+				"mean":    result.mean.Seconds(),
+				"stddev":  result.stddev.Seconds(),
+				"min":     result.min.Seconds(),
+				"max":     result.max.Seconds(),
+			})
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "backend\truns\ttotal\tmean\tstddev\tmin\tmax")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", r.backend, r.runs, roundDuration(r.total), roundDuration(r.mean),
+			roundDuration(r.stddev), roundDuration(r.min), roundDuration(r.max))
+	}
+	w.Flush()
+
+	if len(results) == 2 {
+		fast, slow := results[0], results[1]
+		if slow.mean < fast.mean {
+			fast, slow = slow, fast
+		}
+		fmt.Printf("\n%s is %.2fx faster than %s\n", fast.backend, float64(slow.mean)/float64(fast.mean), slow.backend)
+	}
+}
+
+// roundDuration rounds d to a precision which is suitable for printing it.
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
@@ -1,4 +1,5 @@
-package interpreter
+// Package callstack implements the call stack which is used to print the stack trace of a Lox runtime error.
+package callstack
 
 import (
 	"bytes"
@@ -13,7 +14,8 @@ import (
 	"github.com/marcuscaisey/lox/lox/token"
 )
 
-type callStack struct {
+// Stack is a stack of Lox function calls.
+type Stack struct {
 	frames      *stack.Stack[*stackFrame]
 	calledFuncs *stack.Stack[string]
 }
@@ -24,8 +26,9 @@ type stackFrame struct {
 	Location token.Position
 }
 
-func newCallStack() *callStack {
-	callStack := &callStack{
+// New returns an empty call stack.
+func New() *Stack {
+	callStack := &Stack{
 		frames:      stack.New[*stackFrame](),
 		calledFuncs: stack.New[string](),
 	}
@@ -33,7 +36,9 @@ func newCallStack() *callStack {
 	return callStack
 }
 
-func (cs *callStack) Push(function string, location token.Position) {
+// Push pushes a call of the named function from the given location. The location of a runtime error is pushed with an
+// empty function name.
+func (cs *Stack) Push(function string, location token.Position) {
 	cs.frames.Push(&stackFrame{
 		Function: cs.calledFuncs.Peek(),
 		Location: location,
@@ -41,22 +46,41 @@ func (cs *callStack) Push(function string, location token.Position) {
 	cs.calledFuncs.Push(function)
 }
 
-func (cs *callStack) Pop() {
+// Pop pops the most recent call.
+func (cs *Stack) Pop() {
 	cs.frames.Pop()
 	cs.calledFuncs.Pop()
 }
 
-func (cs *callStack) Len() int {
+// Replace replaces the function of the most recent call with the named function, keeping the location of the call. This
+// is used when the function makes a tail call, which takes its place.
+func (cs *Stack) Replace(function string) {
+	cs.calledFuncs.Pop()
+	cs.calledFuncs.Push(function)
+}
+
+// Truncate pops the most recent calls until there are n calls on the stack.
+func (cs *Stack) Truncate(n int) {
+	for cs.Len() > n {
+		cs.Pop()
+	}
+}
+
+// Len returns the number of calls on the stack.
+func (cs *Stack) Len() int {
 	return cs.frames.Len()
 }
 
-func (cs *callStack) Clear() {
+// Clear removes all calls from the stack.
+func (cs *Stack) Clear() {
 	cs.frames.Clear()
 	cs.calledFuncs.Clear()
 	cs.calledFuncs.Push("")
 }
 
-func (cs *callStack) StackTrace() string {
+// StackTrace formats the calls on the stack as a stack trace, most recent call first. Consecutive identical calls, such
+// as those made by a recursive function, are only printed once followed by the number of times that they're repeated.
+func (cs *Stack) StackTrace() string {
 	var b strings.Builder
 	ansi.Fprintln(&b, "${BOLD}Stack Trace (most recent call first):${RESET_BOLD}")
 	locations := make([]string, cs.Len())
@@ -80,6 +104,19 @@ func (cs *callStack) StackTrace() string {
 		location := runewidth.FillRight(locations[i], locationWidth)
 		function := runewidth.FillRight(functions[i], functionWidth)
 		fmt.Fprint(&b, "  ", location, " ", function, " ", lines[i])
+		repeats := 0
+		for i > 0 && locations[i-1] == locations[i] && functions[i-1] == functions[i] {
+			repeats++
+			i--
+		}
+		if repeats > 0 {
+			times := "times"
+			if repeats == 1 {
+				times = "time"
+			}
+			fmt.Fprintln(&b)
+			ansi.Fprintf(&b, "  ${FAINT}... repeated %d more %s${RESET_BOLD}", repeats, times)
+		}
 		if i > 0 {
 			fmt.Fprintln(&b)
 		}
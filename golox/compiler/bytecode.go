@@ -0,0 +1,220 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/marcuscaisey/lox/lox/ast"
+)
+
+// Op is the operation performed by an [Instruction].
+// The stack effect of each operation is described by its comment, with the top of the stack on the right.
+type Op uint8
+
+const (
+	// OpConstant pushes Constants[Arg].
+	OpConstant Op = iota
+	// OpNil pushes nil.
+	OpNil
+	// OpTrue pushes true.
+	OpTrue
+	// OpFalse pushes false.
+	OpFalse
+	// OpUndefined pushes the value of a variable which has been declared but not defined.
+	OpUndefined
+	// OpPop pops the top value.
+	OpPop
+	// OpPopN pops the top Arg values.
+	OpPopN
+	// OpCopy pushes a copy of the value Arg values below the top value: [value x1 ... xArg] → [value x1 ... xArg value].
+	OpCopy
+	// OpSwap swaps the top two values: [a b] → [b a].
+	OpSwap
+
+	// OpGetLocal pushes the value of the local variable in slot Arg of the current call frame.
+	OpGetLocal
+	// OpSetLocal sets the local variable in slot Arg of the current call frame to the top value without popping it.
+	OpSetLocal
+	// OpGetUpvalue pushes the value of upvalue Arg of the current closure.
+	OpGetUpvalue
+	// OpSetUpvalue sets upvalue Arg of the current closure to the top value without popping it.
+	OpSetUpvalue
+	// OpDeclareGlobal declares global variable Arg without defining it.
+	OpDeclareGlobal
+	// OpDefineGlobal declares global variable Arg and defines it with the popped value.
+	OpDefineGlobal
+	// OpGetGlobal pushes the value of global variable Arg.
+	OpGetGlobal
+	// OpSetGlobal sets global variable Arg to the top value without popping it.
+	OpSetGlobal
+
+	// OpGetProperty replaces an object with the value of its property named by Constants[Arg].
+	OpGetProperty
+	// OpSetProperty sets the property named by Constants[Arg] of an object to a value: [object value] → [value].
+	OpSetProperty
+	// OpGetSuper replaces the current instance with its method or property named by Constants[Arg], looked up from
+	// the superclass of the class that the current closure was declared in.
+	OpGetSuper
+
+	// OpEqual and the other binary operations replace the top two values with the result of applying the operation
+	// to them: [left right] → [result].
+	OpEqual
+	OpNotEqual
+	OpLess
+	OpLessEqual
+	OpGreater
+	OpGreaterEqual
+	OpAdd
+	OpSubtract
+	OpMultiply
+	OpDivide
+	OpIntegerDivide
+	OpModulo
+	// OpConcat replaces the top Arg values with the concatenation of their string representations:
+	// [value1 ... valueN] → [result].
+	OpConcat
+	// OpNot replaces the top value with its logical negation.
+	OpNot
+	// OpNegate replaces the top value with its arithmetic negation.
+	OpNegate
+	// OpIncrement replaces the top value with the result of adding Arg to it. Node is the [ast.IncrementExpr] which it
+	// was compiled from.
+	OpIncrement
+
+	// OpPrint pops and prints the top value.
+	OpPrint
+
+	// OpJump jumps to instruction Arg. If it jumps backwards, then Node is the loop statement which it's part of.
+	OpJump
+	// OpJumpIfFalse jumps to instruction Arg if the top value is falsy without popping it.
+	OpJumpIfFalse
+	// OpJumpIfTrue jumps to instruction Arg if the top value is truthy without popping it.
+	OpJumpIfTrue
+	// OpIterator replaces the top value with an iterator over its elements. Node is the expression which produced the
+	// value.
+	OpIterator
+	// OpIterNext pushes the next element of the iterator on top of the stack or jumps to instruction Arg if it has no
+	// more elements.
+	OpIterNext
+
+	// OpTry starts a try statement whose handler is at instruction Arg. If a runtime error occurs before the matching
+	// OpEndTry, then the stack is unwound to its height when OpTry was executed, the error is pushed, and execution
+	// jumps to the handler.
+	OpTry
+	// OpEndTry ends the innermost try statement.
+	OpEndTry
+	// OpThrow pops a value and throws it as a runtime error. Node is the [ast.ThrowStmt] which it was compiled from.
+	OpThrow
+
+	// OpCall calls a callee with Arg arguments: [callee arg1 ... argN] → [result].
+	OpCall
+	// OpClosure pushes a closure of the function Constants[Arg], capturing the variables described by its Upvalues.
+	OpClosure
+	// OpCloseUpvalues closes the upvalues which capture the local variables in slot Arg of the current call frame
+	// and above.
+	OpCloseUpvalues
+	// OpReturn returns from the current function with the popped value.
+	OpReturn
+
+	// OpImport pushes the module imported by Node, which is an [ast.ImportStmt], executing it first if it hasn't been
+	// imported before.
+	OpImport
+
+	// OpClass pushes a new class declared by Node, which is an [ast.ClassDecl]. If the class has a superclass, then
+	// it's popped first.
+	OpClass
+	// OpMethod pops a closure and adds it to the class on top of the stack as the method declared by Node, which is
+	// an [ast.MethodDecl].
+	OpMethod
+)
+
+var opNames = [...]string{
+	OpConstant:      "OpConstant",
+	OpNil:           "OpNil",
+	OpTrue:          "OpTrue",
+	OpFalse:         "OpFalse",
+	OpUndefined:     "OpUndefined",
+	OpPop:           "OpPop",
+	OpPopN:          "OpPopN",
+	OpCopy:          "OpCopy",
+	OpSwap:          "OpSwap",
+	OpGetLocal:      "OpGetLocal",
+	OpSetLocal:      "OpSetLocal",
+	OpGetUpvalue:    "OpGetUpvalue",
+	OpSetUpvalue:    "OpSetUpvalue",
+	OpDeclareGlobal: "OpDeclareGlobal",
+	OpDefineGlobal:  "OpDefineGlobal",
+	OpGetGlobal:     "OpGetGlobal",
+	OpSetGlobal:     "OpSetGlobal",
+	OpGetProperty:   "OpGetProperty",
+	OpSetProperty:   "OpSetProperty",
+	OpGetSuper:      "OpGetSuper",
+	OpEqual:         "OpEqual",
+	OpNotEqual:      "OpNotEqual",
+	OpLess:          "OpLess",
+	OpLessEqual:     "OpLessEqual",
+	OpGreater:       "OpGreater",
+	OpGreaterEqual:  "OpGreaterEqual",
+	OpAdd:           "OpAdd",
+	OpSubtract:      "OpSubtract",
+	OpMultiply:      "OpMultiply",
+	OpDivide:        "OpDivide",
+	OpIntegerDivide: "OpIntegerDivide",
+	OpModulo:        "OpModulo",
+	OpConcat:        "OpConcat",
+	OpNot:           "OpNot",
+	OpNegate:        "OpNegate",
+	OpIncrement:     "OpIncrement",
+	OpPrint:         "OpPrint",
+	OpJump:          "OpJump",
+	OpJumpIfFalse:   "OpJumpIfFalse",
+	OpJumpIfTrue:    "OpJumpIfTrue",
+	OpIterator:      "OpIterator",
+	OpIterNext:      "OpIterNext",
+	OpTry:           "OpTry",
+	OpEndTry:        "OpEndTry",
+	OpThrow:         "OpThrow",
+	OpCall:          "OpCall",
+	OpClosure:       "OpClosure",
+	OpCloseUpvalues: "OpCloseUpvalues",
+	OpReturn:        "OpReturn",
+	OpImport:        "OpImport",
+	OpClass:         "OpClass",
+	OpMethod:        "OpMethod",
+}
+
+func (o Op) String() string {
+	if int(o) < len(opNames) {
+		return opNames[o]
+	}
+	return fmt.Sprintf("Op(%d)", o)
+}
+
+// Instruction is a single bytecode instruction.
+type Instruction struct {
+	Op  Op
+	Arg int
+	// Node is the node that the instruction was compiled from. It's used to report runtime errors and is nil if the
+	// instruction can't fail.
+	Node ast.Node
+}
+
+// Function is a compiled Lox function.
+type Function struct {
+	// Name is the name which the function is referred to by in stack traces and error messages. It's empty for the
+	// top-level code of a program.
+	Name   string
+	Params []string
+	Code   []Instruction
+	// Constants contains the float64, string, and *Function values referred to by the function's instructions.
+	Constants []any
+	Upvalues  []Upvalue
+}
+
+// Upvalue describes a variable captured by a closure.
+type Upvalue struct {
+	// IsLocal reports whether the variable is a local variable of the enclosing function. Otherwise, it's one of the
+	// enclosing function's upvalues.
+	IsLocal bool
+	// Index is the slot of the local variable or the index of the upvalue in the enclosing function.
+	Index int
+}
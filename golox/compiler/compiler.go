@@ -0,0 +1,830 @@
+// Package compiler implements a compiler of Lox programs to the bytecode executed by package vm.
+package compiler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/marcuscaisey/lox/lox"
+	"github.com/marcuscaisey/lox/lox/ast"
+	"github.com/marcuscaisey/lox/lox/token"
+)
+
+// Compiler compiles Lox programs to bytecode.
+type Compiler struct {
+	globalIndexesByName map[string]int
+	globalNames         []string
+
+	replMode bool
+}
+
+// Option can be passed to New to configure the compiler.
+type Option func(*Compiler)
+
+// WithREPLMode configures the compiler to compile programs for a REPL.
+// In REPL mode, the result of expression statements is printed.
+func WithREPLMode() Option {
+	return func(c *Compiler) {
+		c.replMode = true
+	}
+}
+
+// New constructs a new Compiler with the given options.
+// The built-in functions are assigned the first global variable indexes, in the order of [lox.AllBuiltins].
+func New(opts ...Option) *Compiler {
+	c := &Compiler{
+		globalIndexesByName: map[string]int{},
+	}
+	for _, name := range lox.AllBuiltins {
+		c.globalIndex(name)
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Compile compiles a program into a function which executes its top-level code.
+// The program must have been checked with [analysis.ResolveIdents] and [analysis.CheckSemantics] without any errors
+// being reported.
+// Compile can be called multiple times with different programs and global variables are shared between them.
+func (c *Compiler) Compile(program ast.Program) *Function {
+	fc := newFunCompiler(c, nil, "", funTypeScript)
+	for _, stmt := range program.Stmts {
+		fc.compileStmt(stmt)
+	}
+	fc.emitReturn()
+	return fc.fun
+}
+
+// GlobalNames returns the names of the global variables which have been assigned an index, indexed by it.
+func (c *Compiler) GlobalNames() []string {
+	return c.globalNames
+}
+
+func (c *Compiler) globalIndex(name string) int {
+	if index, ok := c.globalIndexesByName[name]; ok {
+		return index
+	}
+	index := len(c.globalNames)
+	c.globalIndexesByName[name] = index
+	c.globalNames = append(c.globalNames, name)
+	return index
+}
+
+type funType int
+
+const (
+	funTypeScript funType = iota
+	funTypeFunction
+	funTypeMethod
+	funTypeConstructor
+)
+
+// local is a local variable which is stored in a stack slot of the call frame of the function declaring it.
+type local struct {
+	name     string
+	depth    int
+	captured bool
+}
+
+// loop holds the jumps out of a loop body which are patched once the loop has been compiled.
+type loop struct {
+	stmt           ast.Stmt
+	depth          int // Scope depth outside of the loop body
+	tries          int // Number of try statements that the loop is inside of
+	continueTarget int // Instruction which continue jumps to or -1 if it's not known yet
+	breakJumps     []int
+	continueJumps  []int
+}
+
+// funCompiler compiles a single function.
+type funCompiler struct {
+	compiler  *Compiler
+	enclosing *funCompiler // nil for top-level code
+	fun       *Function
+	typ       funType
+	locals    []local
+	depth     int
+	loops     []*loop
+	// tries contains the try statements whose body or catch clause is being compiled, innermost last. Their handlers
+	// have to be ended and their finally clauses executed when a break, continue, or return exits them.
+	tries []ast.TryStmt
+}
+
+func newFunCompiler(compiler *Compiler, enclosing *funCompiler, name string, typ funType) *funCompiler {
+	fc := &funCompiler{
+		compiler:  compiler,
+		enclosing: enclosing,
+		fun:       &Function{Name: name},
+		typ:       typ,
+	}
+	// Slot 0 holds the callee or the current instance in methods.
+	slot0Name := ""
+	if typ == funTypeMethod || typ == funTypeConstructor {
+		slot0Name = token.CurrentInstanceIdent
+	}
+	fc.locals = append(fc.locals, local{name: slot0Name})
+	return fc
+}
+
+func (fc *funCompiler) emit(op Op, arg int, node ast.Node) int {
+	fc.fun.Code = append(fc.fun.Code, Instruction{Op: op, Arg: arg, Node: node})
+	return len(fc.fun.Code) - 1
+}
+
+func (fc *funCompiler) emitConstant(value any) {
+	fc.emit(OpConstant, fc.addConstant(value), nil)
+}
+
+func (fc *funCompiler) addConstant(value any) int {
+	fc.fun.Constants = append(fc.fun.Constants, value)
+	return len(fc.fun.Constants) - 1
+}
+
+// emitJump emits a jump whose target is patched later with patchJump.
+func (fc *funCompiler) emitJump(op Op) int {
+	return fc.emit(op, -1, nil)
+}
+
+// patchJump sets the target of a jump to the next instruction.
+func (fc *funCompiler) patchJump(jump int) {
+	fc.fun.Code[jump].Arg = len(fc.fun.Code)
+}
+
+func (fc *funCompiler) emitReturn() {
+	fc.emitDefaultReturnValue()
+	fc.emit(OpReturn, 0, nil)
+}
+
+// emitDefaultReturnValue emits the instruction to push the value returned by a return statement without a value.
+func (fc *funCompiler) emitDefaultReturnValue() {
+	if fc.typ == funTypeConstructor {
+		fc.emit(OpGetLocal, 0, nil)
+	} else {
+		fc.emit(OpNil, 0, nil)
+	}
+}
+
+func (fc *funCompiler) beginScope() {
+	fc.depth++
+}
+
+func (fc *funCompiler) endScope() {
+	fc.depth--
+	fc.emitPopLocals(fc.depth)
+	n := 0
+	for n < len(fc.locals) && fc.locals[len(fc.locals)-1-n].depth > fc.depth {
+		n++
+	}
+	fc.locals = fc.locals[:len(fc.locals)-n]
+}
+
+// abandonScope ends a scope whose end is never reached because it's exited by a return or throw, so its local
+// variables don't need to be popped.
+func (fc *funCompiler) abandonScope() {
+	fc.depth--
+	n := 0
+	for n < len(fc.locals) && fc.locals[len(fc.locals)-1-n].depth > fc.depth {
+		n++
+	}
+	fc.locals = fc.locals[:len(fc.locals)-n]
+}
+
+// emitPopLocals emits the instructions to pop the local variables declared deeper than the given scope depth, closing
+// any upvalues which capture them.
+func (fc *funCompiler) emitPopLocals(depth int) {
+	n := 0
+	captured := false
+	for i := len(fc.locals) - 1; i >= 0 && fc.locals[i].depth > depth; i-- {
+		n++
+		captured = captured || fc.locals[i].captured
+	}
+	if n == 0 {
+		return
+	}
+	if captured {
+		fc.emit(OpCloseUpvalues, len(fc.locals)-n, nil)
+	}
+	if n == 1 {
+		fc.emit(OpPop, 0, nil)
+	} else {
+		fc.emit(OpPopN, n, nil)
+	}
+}
+
+func (fc *funCompiler) addLocal(name string) {
+	fc.locals = append(fc.locals, local{name: name, depth: fc.depth})
+}
+
+func (fc *funCompiler) resolveLocal(name string) (int, bool) {
+	for i := len(fc.locals) - 1; i >= 0; i-- {
+		if fc.locals[i].name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (fc *funCompiler) resolveUpvalue(name string) (int, bool) {
+	if fc.enclosing == nil {
+		return 0, false
+	}
+	if slot, ok := fc.enclosing.resolveLocal(name); ok {
+		fc.enclosing.locals[slot].captured = true
+		return fc.addUpvalue(true, slot), true
+	}
+	if index, ok := fc.enclosing.resolveUpvalue(name); ok {
+		return fc.addUpvalue(false, index), true
+	}
+	return 0, false
+}
+
+func (fc *funCompiler) addUpvalue(isLocal bool, index int) int {
+	upvalue := Upvalue{IsLocal: isLocal, Index: index}
+	for i, existing := range fc.fun.Upvalues {
+		if existing == upvalue {
+			return i
+		}
+	}
+	fc.fun.Upvalues = append(fc.fun.Upvalues, upvalue)
+	return len(fc.fun.Upvalues) - 1
+}
+
+// emitGet emits the instruction to push the value of the variable referred to by ident.
+func (fc *funCompiler) emitGet(ident ast.Ident) {
+	name := ident.Token.Lexeme
+	if slot, ok := fc.resolveLocal(name); ok {
+		fc.emit(OpGetLocal, slot, ident)
+	} else if index, ok := fc.resolveUpvalue(name); ok {
+		fc.emit(OpGetUpvalue, index, ident)
+	} else {
+		fc.emit(OpGetGlobal, fc.compiler.globalIndex(name), ident)
+	}
+}
+
+// emitGetThis emits the instruction to push the current instance. tok is the token which refers to it.
+func (fc *funCompiler) emitGetThis(tok token.Token) {
+	tok.Lexeme = token.CurrentInstanceIdent
+	fc.emitGet(ast.Ident{Token: tok})
+}
+
+// emitSet emits the instruction to assign the top value to the variable referred to by ident.
+func (fc *funCompiler) emitSet(ident ast.Ident) {
+	name := ident.Token.Lexeme
+	if slot, ok := fc.resolveLocal(name); ok {
+		fc.emit(OpSetLocal, slot, ident)
+	} else if index, ok := fc.resolveUpvalue(name); ok {
+		fc.emit(OpSetUpvalue, index, ident)
+	} else {
+		fc.emit(OpSetGlobal, fc.compiler.globalIndex(name), ident)
+	}
+}
+
+// defineVariable defines a variable with the value on top of the stack. Global variables are popped whereas local
+// variables remain in their stack slot.
+func (fc *funCompiler) defineVariable(ident ast.Ident) {
+	if fc.depth == 0 {
+		fc.emit(OpDefineGlobal, fc.compiler.globalIndex(ident.Token.Lexeme), ident)
+	} else {
+		fc.addLocal(ident.Token.Lexeme)
+	}
+}
+
+func (fc *funCompiler) compileStmt(stmt ast.Stmt) {
+	switch stmt := stmt.(type) {
+	case ast.ImportStmt:
+		fc.emit(OpImport, 0, stmt)
+		fc.defineVariable(stmt.Name)
+	case ast.VarDecl:
+		fc.compileVarDecl(stmt)
+	case ast.FunDecl:
+		fc.compileFunDecl(stmt)
+	case ast.ClassDecl:
+		fc.compileClassDecl(stmt)
+	case ast.ExprStmt:
+		fc.compileExprStmt(stmt)
+	case ast.PrintStmt:
+		fc.compileExpr(stmt.Expr)
+		fc.emit(OpPrint, 0, nil)
+	case ast.BlockStmt:
+		fc.beginScope()
+		fc.compileStmts(stmt.Stmts)
+		fc.endScope()
+	case ast.IfStmt:
+		fc.compileIfStmt(stmt)
+	case ast.WhileStmt:
+		fc.compileWhileStmt(stmt)
+	case ast.ForStmt:
+		fc.compileForStmt(stmt)
+	case ast.ForInStmt:
+		fc.compileForInStmt(stmt)
+	case ast.BreakStmt:
+		fc.compileBreakStmt()
+	case ast.ContinueStmt:
+		fc.compileContinueStmt()
+	case ast.ReturnStmt:
+		fc.compileReturnStmt(stmt)
+	case ast.TryStmt:
+		fc.compileTryStmt(stmt)
+	case ast.ThrowStmt:
+		fc.compileExpr(stmt.Value)
+		fc.emit(OpThrow, 0, stmt)
+	case ast.CommentStmt, ast.InlineCommentStmt, ast.IllegalStmt, ast.MethodDecl:
+		panic(fmt.Sprintf("unexpected statement type: %T", stmt))
+	}
+}
+
+func (fc *funCompiler) compileStmts(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		fc.compileStmt(stmt)
+	}
+}
+
+func (fc *funCompiler) compileVarDecl(decl ast.VarDecl) {
+	if decl.Name.Token.Lexeme == token.PlaceholderIdent {
+		if decl.Initialiser != nil {
+			fc.compileExpr(decl.Initialiser)
+			fc.emit(OpPop, 0, nil)
+		}
+		return
+	}
+	if decl.Initialiser != nil {
+		fc.compileExpr(decl.Initialiser)
+		fc.defineVariable(decl.Name)
+	} else if fc.depth == 0 {
+		fc.emit(OpDeclareGlobal, fc.compiler.globalIndex(decl.Name.Token.Lexeme), decl.Name)
+	} else {
+		fc.emit(OpUndefined, 0, nil)
+		fc.addLocal(decl.Name.Token.Lexeme)
+	}
+}
+
+func (fc *funCompiler) compileFunDecl(decl ast.FunDecl) {
+	if decl.Name.Token.Lexeme == token.PlaceholderIdent {
+		return
+	}
+	if fc.depth == 0 {
+		fc.compileFunction(decl.Name.Token.Lexeme, decl.Function, funTypeFunction)
+		fc.defineVariable(decl.Name)
+	} else {
+		// The local is added before the function is compiled so that the function can refer to itself.
+		fc.addLocal(decl.Name.Token.Lexeme)
+		fc.compileFunction(decl.Name.Token.Lexeme, decl.Function, funTypeFunction)
+	}
+}
+
+// compileFunction compiles a function and emits the instruction to push a closure of it.
+func (fc *funCompiler) compileFunction(name string, fun ast.Function, typ funType) {
+	child := newFunCompiler(fc.compiler, fc, name, typ)
+	child.beginScope()
+	child.fun.Params = make([]string, len(fun.Params))
+	for i, param := range fun.Params {
+		child.fun.Params[i] = param.Token.Lexeme
+		child.addLocal(param.Token.Lexeme)
+	}
+	child.compileStmts(fun.Body.Stmts)
+	child.emitReturn()
+	fc.emit(OpClosure, fc.addConstant(child.fun), nil)
+}
+
+func (fc *funCompiler) compileClassDecl(decl ast.ClassDecl) {
+	name := decl.Name.Token.Lexeme
+	if name == token.PlaceholderIdent {
+		return
+	}
+	if decl.Superclass != nil {
+		fc.compileExpr(decl.Superclass)
+	}
+	fc.emit(OpClass, 0, decl)
+	if fc.depth > 0 {
+		// The local is added before the methods are compiled so that they can refer to the class.
+		fc.addLocal(name)
+	}
+	for _, method := range decl.Methods() {
+		methodName := name + "." + method.Name.Token.Lexeme
+		switch {
+		case method.HasModifier(token.Get):
+			methodName = "get " + methodName
+		case method.HasModifier(token.Set):
+			methodName = "set " + methodName
+		}
+		typ := funTypeMethod
+		if method.IsConstructor() {
+			typ = funTypeConstructor
+		}
+		fc.compileFunction(methodName, method.Function, typ)
+		fc.emit(OpMethod, 0, method)
+	}
+	if fc.depth == 0 {
+		fc.defineVariable(decl.Name)
+	}
+}
+
+func (fc *funCompiler) compileExprStmt(stmt ast.ExprStmt) {
+	fc.compileExpr(stmt.Expr)
+	if fc.compiler.replMode {
+		fc.emit(OpPrint, 0, nil)
+	} else {
+		fc.emit(OpPop, 0, nil)
+	}
+}
+
+func (fc *funCompiler) compileIfStmt(stmt ast.IfStmt) {
+	fc.compileExpr(stmt.Condition)
+	elseJump := fc.emitJump(OpJumpIfFalse)
+	fc.emit(OpPop, 0, nil)
+	fc.compileStmt(stmt.Then)
+	endJump := fc.emitJump(OpJump)
+	fc.patchJump(elseJump)
+	fc.emit(OpPop, 0, nil)
+	if stmt.Else != nil {
+		fc.compileStmt(stmt.Else)
+	}
+	fc.patchJump(endJump)
+}
+
+func (fc *funCompiler) compileWhileStmt(stmt ast.WhileStmt) {
+	start := len(fc.fun.Code)
+	fc.compileExpr(stmt.Condition)
+	exitJump := fc.emitJump(OpJumpIfFalse)
+	fc.emit(OpPop, 0, nil)
+	l := fc.beginLoop(stmt, start)
+	fc.compileStmt(stmt.Body)
+	fc.emit(OpJump, start, stmt)
+	fc.patchJump(exitJump)
+	fc.emit(OpPop, 0, nil)
+	fc.endLoop(l)
+}
+
+func (fc *funCompiler) compileForStmt(stmt ast.ForStmt) {
+	fc.beginScope()
+	loopVarSlot := -1
+	if stmt.Initialise != nil {
+		fc.compileStmt(stmt.Initialise)
+		if decl, ok := stmt.Initialise.(ast.VarDecl); ok && decl.Name.Token.Lexeme != token.PlaceholderIdent {
+			loopVarSlot = len(fc.locals) - 1
+		}
+	}
+	start := len(fc.fun.Code)
+	exitJump := -1
+	if stmt.Condition != nil {
+		fc.compileExpr(stmt.Condition)
+		exitJump = fc.emitJump(OpJumpIfFalse)
+		fc.emit(OpPop, 0, nil)
+	}
+	l := fc.beginLoop(stmt, -1)
+	fc.compileStmt(stmt.Body)
+	for _, jump := range l.continueJumps {
+		fc.patchJump(jump)
+	}
+	if loopVarSlot != -1 {
+		// Each iteration gets its own binding of the loop variable, so closures created in the body capture the value
+		// from their iteration. Closing the upvalues which capture it means the next iteration starts with a new one.
+		fc.emit(OpCloseUpvalues, loopVarSlot, nil)
+	}
+	if stmt.Update != nil {
+		fc.compileExpr(stmt.Update)
+		fc.emit(OpPop, 0, nil)
+	}
+	fc.emit(OpJump, start, stmt)
+	if exitJump != -1 {
+		fc.patchJump(exitJump)
+		fc.emit(OpPop, 0, nil)
+	}
+	fc.endLoop(l)
+	fc.endScope()
+}
+
+func (fc *funCompiler) compileForInStmt(stmt ast.ForInStmt) {
+	fc.beginScope()
+	fc.compileExpr(stmt.Iterable)
+	fc.emit(OpIterator, 0, stmt.Iterable)
+	// The iterator is stored in a local variable which can't be referred to by name.
+	fc.addLocal("")
+	start := len(fc.fun.Code)
+	exitJump := fc.emitJump(OpIterNext)
+	l := fc.beginLoop(stmt, start)
+	// The loop variable is declared in its own scope which is exited at the end of each iteration, so each iteration
+	// gets its own binding of it.
+	fc.beginScope()
+	fc.addLocal(stmt.Name.Token.Lexeme)
+	fc.compileStmt(stmt.Body)
+	fc.endScope()
+	fc.emit(OpJump, start, stmt)
+	fc.patchJump(exitJump)
+	fc.endLoop(l)
+	fc.endScope()
+}
+
+// beginLoop starts a loop whose body is about to be compiled. continueTarget is the instruction that continue
+// statements jump to or -1 if it's not known until the body has been compiled.
+func (fc *funCompiler) beginLoop(stmt ast.Stmt, continueTarget int) *loop {
+	l := &loop{stmt: stmt, depth: fc.depth, tries: len(fc.tries), continueTarget: continueTarget}
+	fc.loops = append(fc.loops, l)
+	return l
+}
+
+// endLoop ends a loop, patching its break statements to jump to the next instruction.
+func (fc *funCompiler) endLoop(l *loop) {
+	for _, jump := range l.breakJumps {
+		fc.patchJump(jump)
+	}
+	fc.loops = fc.loops[:len(fc.loops)-1]
+}
+
+func (fc *funCompiler) compileBreakStmt() {
+	l := fc.loops[len(fc.loops)-1]
+	fc.emitExitTries(l.tries)
+	fc.emitPopLocals(l.depth)
+	l.breakJumps = append(l.breakJumps, fc.emitJump(OpJump))
+}
+
+func (fc *funCompiler) compileContinueStmt() {
+	l := fc.loops[len(fc.loops)-1]
+	fc.emitExitTries(l.tries)
+	fc.emitPopLocals(l.depth)
+	if l.continueTarget != -1 {
+		fc.emit(OpJump, l.continueTarget, l.stmt)
+	} else {
+		l.continueJumps = append(l.continueJumps, fc.emitJump(OpJump))
+	}
+}
+
+func (fc *funCompiler) compileReturnStmt(stmt ast.ReturnStmt) {
+	if stmt.Value == nil {
+		fc.emitDefaultReturnValue()
+	} else {
+		fc.compileExpr(stmt.Value)
+	}
+	if len(fc.tries) == 0 {
+		fc.emit(OpReturn, 0, nil)
+		return
+	}
+	// The return value is stored in a local variable which can't be referred to by name while the finally clauses of
+	// the try statements being exited are executed.
+	fc.beginScope()
+	fc.addLocal("")
+	fc.emitExitTries(0)
+	fc.emit(OpReturn, 0, nil)
+	fc.abandonScope()
+}
+
+func (fc *funCompiler) compileTryStmt(stmt ast.TryStmt) {
+	var endJumps []int
+	handler := fc.emitJump(OpTry)
+	fc.compileTryBlock(stmt, stmt.Body)
+	if stmt.HasFinally() {
+		fc.compileStmt(stmt.FinallyBody)
+	}
+	endJumps = append(endJumps, fc.emitJump(OpJump))
+
+	// The handler is executed with the error on top of the stack.
+	fc.patchJump(handler)
+	if stmt.HasCatch() {
+		fc.beginScope()
+		errSlot := len(fc.locals)
+		fc.addLocal(stmt.CatchName.Token.Lexeme)
+		if stmt.HasFinally() {
+			catchHandler := fc.emitJump(OpTry)
+			fc.compileTryBlock(stmt, stmt.CatchBody)
+			errCaptured := fc.locals[errSlot].captured
+			fc.endScope()
+			fc.compileStmt(stmt.FinallyBody)
+			endJumps = append(endJumps, fc.emitJump(OpJump))
+
+			// The error thrown by the catch clause is on top of the one that it caught, which is discarded.
+			fc.patchJump(catchHandler)
+			if errCaptured {
+				fc.emit(OpCloseUpvalues, errSlot, nil)
+			}
+			fc.emit(OpSwap, 0, nil)
+			fc.emit(OpPop, 0, nil)
+		} else {
+			fc.compileStmt(stmt.CatchBody)
+			fc.endScope()
+		}
+	}
+	if stmt.HasFinally() {
+		// The error is stored in a local variable which can't be referred to by name while the finally clause is
+		// executed and then thrown again.
+		fc.beginScope()
+		fc.addLocal("")
+		fc.compileStmt(stmt.FinallyBody)
+		fc.emit(OpThrow, 0, nil)
+		fc.abandonScope()
+	}
+
+	for _, jump := range endJumps {
+		fc.patchJump(jump)
+	}
+}
+
+// compileTryBlock compiles the body or catch clause of a try statement, which is protected by the handler started by
+// the preceding OpTry.
+func (fc *funCompiler) compileTryBlock(stmt ast.TryStmt, block ast.BlockStmt) {
+	fc.tries = append(fc.tries, stmt)
+	fc.compileStmt(block)
+	fc.tries = fc.tries[:len(fc.tries)-1]
+	fc.emit(OpEndTry, 0, nil)
+}
+
+// emitExitTries emits the instructions to exit the try statements in fc.tries[from:], innermost first, ending their
+// handlers and executing their finally clauses.
+func (fc *funCompiler) emitExitTries(from int) {
+	tries := fc.tries
+	defer func() { fc.tries = tries }()
+	for i := len(tries) - 1; i >= from; i-- {
+		fc.emit(OpEndTry, 0, nil)
+		if tries[i].HasFinally() {
+			// A break, continue, or return in the finally clause only exits the try statements outside of this one.
+			fc.tries = tries[:i]
+			fc.compileStmt(tries[i].FinallyBody)
+		}
+	}
+}
+
+func (fc *funCompiler) compileExpr(expr ast.Expr) {
+	switch expr := expr.(type) {
+	case ast.FunExpr:
+		fc.compileFunction("(anonymous)", expr.Function, funTypeFunction)
+	case ast.GroupExpr:
+		fc.compileExpr(expr.Expr)
+	case ast.LiteralExpr:
+		fc.compileLiteralExpr(expr)
+	case ast.InterpolatedStringExpr:
+		fc.compileInterpolatedStringExpr(expr)
+	case ast.IdentExpr:
+		fc.emitGet(expr.Ident)
+	case ast.ThisExpr:
+		fc.emitGetThis(expr.This)
+	case ast.SuperExpr:
+		fc.emitGetThis(expr.Super)
+		fc.emit(OpGetSuper, fc.addConstant(expr.Method.Token.Lexeme), expr)
+	case ast.CallExpr:
+		fc.compileExpr(expr.Callee)
+		for _, arg := range expr.Args {
+			fc.compileExpr(arg)
+		}
+		fc.emit(OpCall, len(expr.Args), expr)
+	case ast.GetExpr:
+		fc.compileExpr(expr.Object)
+		fc.emit(OpGetProperty, fc.addConstant(expr.Name.Token.Lexeme), expr)
+	case ast.UnaryExpr:
+		fc.compileUnaryExpr(expr)
+	case ast.IncrementExpr:
+		fc.compileIncrementExpr(expr)
+	case ast.BinaryExpr:
+		fc.compileBinaryExpr(expr)
+	case ast.TernaryExpr:
+		fc.compileTernaryExpr(expr)
+	case ast.AssignmentExpr:
+		fc.compileExpr(expr.Right)
+		if expr.Left.Token.Lexeme != token.PlaceholderIdent {
+			fc.emitSet(expr.Left)
+		}
+	case ast.SetExpr:
+		fc.compileExpr(expr.Object)
+		fc.compileExpr(expr.Value)
+		fc.emit(OpSetProperty, fc.addConstant(expr.Name.Token.Lexeme), expr)
+	default:
+		panic(fmt.Sprintf("unexpected expression type: %T", expr))
+	}
+}
+
+func (fc *funCompiler) compileInterpolatedStringExpr(expr ast.InterpolatedStringExpr) {
+	parts := expr.StringParts()
+	n := 0
+	for i, interpolated := range expr.Exprs {
+		if parts[i] != "" {
+			fc.emitConstant(parts[i])
+			n++
+		}
+		fc.compileExpr(interpolated)
+		n++
+	}
+	if tail := parts[len(parts)-1]; tail != "" {
+		fc.emitConstant(tail)
+		n++
+	}
+	fc.emit(OpConcat, n, nil)
+}
+
+func (fc *funCompiler) compileLiteralExpr(expr ast.LiteralExpr) {
+	switch tok := expr.Value; tok.Type {
+	case token.Number:
+		value, err := strconv.ParseFloat(tok.Lexeme, 64)
+		if err != nil {
+			panic(fmt.Sprintf("unexpected error parsing number literal: %s", err))
+		}
+		fc.emitConstant(value)
+	case token.String:
+		fc.emitConstant(tok.Lexeme[1 : len(tok.Lexeme)-1]) // Remove surrounding quotes
+	case token.True:
+		fc.emit(OpTrue, 0, nil)
+	case token.False:
+		fc.emit(OpFalse, 0, nil)
+	case token.Nil:
+		fc.emit(OpNil, 0, nil)
+	default:
+		panic(fmt.Sprintf("unexpected literal type: %s", tok.Type))
+	}
+}
+
+func (fc *funCompiler) compileUnaryExpr(expr ast.UnaryExpr) {
+	fc.compileExpr(expr.Right)
+	switch expr.Op.Type {
+	case token.Bang:
+		fc.emit(OpNot, 0, nil)
+	case token.Minus:
+		fc.emit(OpNegate, 0, expr)
+	default:
+		panic(fmt.Sprintf("unexpected unary operator: %s", expr.Op.Type))
+	}
+}
+
+func (fc *funCompiler) compileIncrementExpr(expr ast.IncrementExpr) {
+	delta := 1
+	if expr.Op.Type == token.MinusMinus {
+		delta = -1
+	}
+	switch operand := expr.Operand.(type) {
+	case ast.IdentExpr:
+		fc.emitGet(operand.Ident)
+		if expr.Postfix {
+			// Keep a copy of the old value as the result: [old] → [old old].
+			fc.emit(OpCopy, 0, nil)
+		}
+		fc.emit(OpIncrement, delta, expr)
+		fc.emitSet(operand.Ident)
+	case ast.GetExpr:
+		name := fc.addConstant(operand.Name.Token.Lexeme)
+		fc.compileExpr(operand.Object)
+		fc.emit(OpCopy, 0, nil)
+		fc.emit(OpGetProperty, name, operand)
+		if expr.Postfix {
+			// Keep a copy of the old value as the result: [object old] → [old object old].
+			fc.emit(OpSwap, 0, nil)
+			fc.emit(OpCopy, 1, nil)
+		}
+		fc.emit(OpIncrement, delta, expr)
+		fc.emit(OpSetProperty, name, ast.SetExpr{Object: operand.Object, Name: operand.Name, Value: expr})
+	default:
+		panic(fmt.Sprintf("unexpected increment operand type: %T", operand))
+	}
+	if expr.Postfix {
+		fc.emit(OpPop, 0, nil)
+	}
+}
+
+var binaryOps = map[token.Type]Op{
+	token.EqualEqual:   OpEqual,
+	token.BangEqual:    OpNotEqual,
+	token.Less:         OpLess,
+	token.LessEqual:    OpLessEqual,
+	token.Greater:      OpGreater,
+	token.GreaterEqual: OpGreaterEqual,
+	token.Plus:         OpAdd,
+	token.Minus:        OpSubtract,
+	token.Asterisk:     OpMultiply,
+	token.Slash:        OpDivide,
+	token.TildeSlash:   OpIntegerDivide,
+	token.Percent:      OpModulo,
+}
+
+func (fc *funCompiler) compileBinaryExpr(expr ast.BinaryExpr) {
+	fc.compileExpr(expr.Left)
+	switch expr.Op.Type {
+	case token.Or, token.And:
+		jumpOp := OpJumpIfTrue
+		if expr.Op.Type == token.And {
+			jumpOp = OpJumpIfFalse
+		}
+		endJump := fc.emitJump(jumpOp)
+		fc.emit(OpPop, 0, nil)
+		fc.compileExpr(expr.Right)
+		fc.patchJump(endJump)
+	case token.Comma:
+		fc.emit(OpPop, 0, nil)
+		fc.compileExpr(expr.Right)
+	default:
+		op, ok := binaryOps[expr.Op.Type]
+		if !ok {
+			panic(fmt.Sprintf("unexpected binary operator: %s", expr.Op.Type))
+		}
+		fc.compileExpr(expr.Right)
+		fc.emit(op, 0, expr)
+	}
+}
+
+func (fc *funCompiler) compileTernaryExpr(expr ast.TernaryExpr) {
+	fc.compileExpr(expr.Condition)
+	elseJump := fc.emitJump(OpJumpIfFalse)
+	fc.emit(OpPop, 0, nil)
+	fc.compileExpr(expr.Then)
+	endJump := fc.emitJump(OpJump)
+	fc.patchJump(elseJump)
+	fc.emit(OpPop, 0, nil)
+	fc.compileExpr(expr.Else)
+	fc.patchJump(endJump)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/marcuscaisey/lox/lox"
+)
+
+// runExplain implements the explain subcommand, which prints the explanation of an error code, or lists all of the
+// error codes if none is given.
+func runExplain(args []string) {
+	flags := flag.NewFlagSet("explain", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: golox explain [code]\n")
+		fmt.Fprintf(flags.Output(), "\n")
+		fmt.Fprintf(flags.Output(), "Prints an extended description of the errors which have the code, with an example of code which causes them\n")
+		fmt.Fprintf(flags.Output(), "and how to fix it. If no code is given, then all of the error codes are listed.\n")
+	}
+	_ = flags.Parse(args)
+	if flags.NArg() > 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if flags.NArg() == 0 {
+		for _, code := range lox.ErrorCodes() {
+			fmt.Println(code)
+		}
+		return
+	}
+
+	code := lox.ErrorCode(flags.Arg(0))
+	explanation, ok := code.Explanation()
+	if !ok {
+		fmt.Fprintf(flags.Output(), "error: unknown error code %s, run golox explain to list them\n", code)
+		os.Exit(2)
+	}
+	fmt.Print(explanation)
+}
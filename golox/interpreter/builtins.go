@@ -1,14 +1,15 @@
 package interpreter
 
 import (
-	"time"
+	"fmt"
 
+	"github.com/marcuscaisey/lox/golox/lib"
 	"github.com/marcuscaisey/lox/lox"
 )
 
 var builtins = map[string]loxObject{
 	lox.BuiltinClock: newBuiltinLoxFunction(lox.BuiltinClock, nil, func([]loxObject) loxObject {
-		return loxNumber(time.Now().UnixNano()) / loxNumber(time.Second)
+		return loxNumber(lib.Clock())
 	}),
 	lox.BuiltinType: newBuiltinLoxFunction(lox.BuiltinType, []string{"object"}, func(args []loxObject) loxObject {
 		return loxString(args[0].Type())
@@ -16,4 +17,142 @@ var builtins = map[string]loxObject{
 	lox.BuiltinError: newBuiltinLoxFunction(lox.BuiltinError, []string{"msg"}, func(args []loxObject) loxObject {
 		return errorMsg(args[0].String())
 	}),
+	lox.BuiltinLen: newBuiltinLoxFunction(lox.BuiltinLen, []string{"s"}, func(args []loxObject) loxObject {
+		s, errMsg := stringArg(lox.BuiltinLen, "s", args[0])
+		if errMsg != nil {
+			return errMsg
+		}
+		return loxNumber(lib.Len(string(s)))
+	}),
+	lox.BuiltinSubstring: newBuiltinLoxFunction(lox.BuiltinSubstring, []string{"s", "start", "end"}, func(args []loxObject) loxObject {
+		s, errMsg := stringArg(lox.BuiltinSubstring, "s", args[0])
+		if errMsg != nil {
+			return errMsg
+		}
+		start, errMsg := integerArg(lox.BuiltinSubstring, "start", args[1])
+		if errMsg != nil {
+			return errMsg
+		}
+		end, errMsg := integerArg(lox.BuiltinSubstring, "end", args[2])
+		if errMsg != nil {
+			return errMsg
+		}
+		return stringResult(lib.Substring(string(s), start, end))
+	}),
+	lox.BuiltinIndexOf: newBuiltinLoxFunction(lox.BuiltinIndexOf, []string{"s", "substr"}, func(args []loxObject) loxObject {
+		s, errMsg := stringArg(lox.BuiltinIndexOf, "s", args[0])
+		if errMsg != nil {
+			return errMsg
+		}
+		substr, errMsg := stringArg(lox.BuiltinIndexOf, "substr", args[1])
+		if errMsg != nil {
+			return errMsg
+		}
+		return loxNumber(lib.IndexOf(string(s), string(substr)))
+	}),
+	lox.BuiltinCharAt: newBuiltinLoxFunction(lox.BuiltinCharAt, []string{"s", "index"}, func(args []loxObject) loxObject {
+		s, errMsg := stringArg(lox.BuiltinCharAt, "s", args[0])
+		if errMsg != nil {
+			return errMsg
+		}
+		index, errMsg := integerArg(lox.BuiltinCharAt, "index", args[1])
+		if errMsg != nil {
+			return errMsg
+		}
+		return stringResult(lib.CharAt(string(s), index))
+	}),
+	lox.BuiltinToUpper: stringFunction(lox.BuiltinToUpper, lib.ToUpper),
+	lox.BuiltinToLower: stringFunction(lox.BuiltinToLower, lib.ToLower),
+	lox.BuiltinTrim:    stringFunction(lox.BuiltinTrim, lib.Trim),
+	lox.BuiltinMath:    newBuiltinLoxModule(lox.BuiltinMath, mathMembers()),
+}
+
+// mathMembers returns the members of the built-in math module.
+func mathMembers() map[string]loxObject {
+	members := map[string]loxObject{
+		"random": newBuiltinLoxFunction(lib.MathMemberName("random"), nil, func([]loxObject) loxObject {
+			return loxNumber(lib.Random())
+		}),
+	}
+	for name, constant := range lib.MathConstants {
+		members[name] = loxNumber(constant)
+	}
+	for name, f := range lib.UnaryMathFunctions {
+		fun := lib.MathMemberName(name)
+		members[name] = newBuiltinLoxFunction(fun, []string{"x"}, func(args []loxObject) loxObject {
+			x, errMsg := numberArg(fun, "x", args[0])
+			if errMsg != nil {
+				return errMsg
+			}
+			return loxNumber(f(float64(x)))
+		})
+	}
+	for name, f := range lib.BinaryMathFunctions {
+		fun := lib.MathMemberName(name)
+		members[name] = newBuiltinLoxFunction(fun, f.Params[:], func(args []loxObject) loxObject {
+			a, errMsg := numberArg(fun, f.Params[0], args[0])
+			if errMsg != nil {
+				return errMsg
+			}
+			b, errMsg := numberArg(fun, f.Params[1], args[1])
+			if errMsg != nil {
+				return errMsg
+			}
+			return loxNumber(f.Func(float64(a), float64(b)))
+		})
+	}
+	return members
+}
+
+// stringFunction returns a built-in function which applies f to its string argument s.
+func stringFunction(name string, f func(string) string) loxObject {
+	return newBuiltinLoxFunction(name, []string{"s"}, func(args []loxObject) loxObject {
+		s, errMsg := stringArg(name, "s", args[0])
+		if errMsg != nil {
+			return errMsg
+		}
+		return loxString(f(string(s)))
+	})
+}
+
+// stringResult returns s, or an error message if err is not nil.
+func stringResult(s string, err error) loxObject {
+	if err != nil {
+		return errorMsg(err.Error())
+	}
+	return loxString(s)
+}
+
+// stringArg returns arg if it's a string. Otherwise, it returns an error message describing the invalid argument param
+// of the built-in function fun.
+func stringArg(fun string, param string, arg loxObject) (loxString, loxObject) {
+	s, ok := arg.(loxString)
+	if !ok {
+		return "", errorMsg(fmt.Sprintf("%s() expects %s to be a %m but got %m", fun, param, loxTypeString, arg.Type()))
+	}
+	return s, nil
+}
+
+// numberArg returns arg if it's a number. Otherwise, it returns an error message describing the invalid argument param
+// of the built-in function fun.
+func numberArg(fun string, param string, arg loxObject) (loxNumber, loxObject) {
+	n, ok := arg.(loxNumber)
+	if !ok {
+		return 0, errorMsg(fmt.Sprintf("%s() expects %s to be a %m but got %m", fun, param, loxTypeNumber, arg.Type()))
+	}
+	return n, nil
+}
+
+// integerArg returns arg as an int if it's an integer number. Otherwise, it returns an error message describing the
+// invalid argument param of the built-in function fun.
+func integerArg(fun string, param string, arg loxObject) (int, loxObject) {
+	n, errMsg := numberArg(fun, param, arg)
+	if errMsg != nil {
+		return 0, errMsg
+	}
+	i, ok := lib.Integer(float64(n))
+	if !ok {
+		return 0, errorMsg(fmt.Sprintf("%s() expects %s to be an integer but got %s", fun, param, n))
+	}
+	return i, nil
 }
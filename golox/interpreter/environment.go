@@ -2,8 +2,10 @@ package interpreter
 
 import (
 	"fmt"
+	"maps"
 
 	"github.com/marcuscaisey/lox/lox"
+	"github.com/marcuscaisey/lox/lox/analysis"
 	"github.com/marcuscaisey/lox/lox/ast"
 )
 
@@ -46,7 +48,7 @@ func (e *globalEnvironment) Declare(ident ast.Ident) environment {
 		e.values[ident.Token.Lexeme] = nil
 		return e
 	} else {
-		panic(lox.NewErrorf(ident, "%s has already been declared", ident.Token.Lexeme))
+		panic(lox.NewCodedErrorf(lox.ErrorCodeRedeclared, ident, "%s has already been declared", ident.Token.Lexeme))
 	}
 }
 
@@ -69,7 +71,7 @@ func (e *globalEnvironment) Assign(ident ast.Ident, value loxObject) {
 	if _, ok := e.values[ident.Token.Lexeme]; ok {
 		e.values[ident.Token.Lexeme] = value
 	} else {
-		panic(lox.NewErrorf(ident, "%s has not been declared", ident.Token.Lexeme))
+		panic(e.notDeclaredError(ident))
 	}
 }
 
@@ -78,13 +80,22 @@ func (e *globalEnvironment) Get(ident ast.Ident) loxObject {
 		if value != nil {
 			return value
 		} else {
-			panic(lox.NewErrorf(ident, "%s has not been defined", ident.Token.Lexeme))
+			panic(lox.NewCodedErrorf(lox.ErrorCodeUndefined, ident, "%s has not been defined", ident.Token.Lexeme))
 		}
 	} else {
-		panic(lox.NewErrorf(ident, "%s has not been declared", ident.Token.Lexeme))
+		panic(e.notDeclaredError(ident))
 	}
 }
 
+// notDeclaredError returns the error for an identifier which hasn't been declared, suggesting a declared global which
+// is spelt similarly to it if there is one.
+func (e *globalEnvironment) notDeclaredError(ident ast.Ident) error {
+	if similar := analysis.SimilarName(ident.Token.Lexeme, maps.Keys(e.values)); similar != "" {
+		return lox.NewCodedErrorf(lox.ErrorCodeUndeclared, ident, "%s has not been declared, did you mean %s?", ident.Token.Lexeme, similar)
+	}
+	return lox.NewCodedErrorf(lox.ErrorCodeUndeclared, ident, "%s has not been declared", ident.Token.Lexeme)
+}
+
 // localEnvironment is the environment for a local scope.
 type localEnvironment struct {
 	parent environment
@@ -100,6 +111,12 @@ func newLocalEnvironment(parent environment, name string, value loxObject) *loca
 	}
 }
 
+// Rebind returns a copy of the environment with a new binding of its identifier to the same value. Assigning to the
+// identifier in the copy doesn't affect closures which captured the original.
+func (e *localEnvironment) Rebind() *localEnvironment {
+	return newLocalEnvironment(e.parent, e.name, e.value)
+}
+
 func (e *localEnvironment) Child() environment {
 	return e
 }
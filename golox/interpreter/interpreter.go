@@ -2,22 +2,54 @@
 package interpreter
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/marcuscaisey/lox/golox/callstack"
+	"github.com/marcuscaisey/lox/golox/module"
 	"github.com/marcuscaisey/lox/lox"
 	"github.com/marcuscaisey/lox/lox/analysis"
 	"github.com/marcuscaisey/lox/lox/ast"
 	"github.com/marcuscaisey/lox/lox/token"
 )
 
+// defaultMaxCallDepth is the maximum depth of nested Lox function calls if WithMaxCallDepth isn't passed to New.
+const defaultMaxCallDepth = 1000
+
+// MaxCallDepthLimit is the largest depth which can be passed to [WithMaxCallDepth]. Each Lox function call is evaluated
+// by several nested Go calls, so a program could overflow the Go stack before exceeding a deeper limit.
+const MaxCallDepthLimit = 10000
+
 // Interpreter is the interpreter for the language.
 type Interpreter struct {
 	globals   environment
-	callStack *callStack
+	callStack *callstack.Stack
+	modules   *module.Loader[*loxModule]
+
+	// ctx is the context passed to InterpretContext, or nil if a program isn't being interpreted.
+	ctx context.Context
+
+	// steps is the number of steps taken by the program being interpreted.
+	steps int
+
+	// tries is the number of try statements which are being executed by the current function call.
+	tries int
 
-	replMode bool
+	replMode         bool
+	warningHandler   func(lox.Errors)
+	maxCallDepth     int
+	maxSteps         int
+	stdout           io.Writer
+	nativeFunctions  []nativeFunction
+	disabledBuiltins []string
+	tailCalls        bool
+	noShadowingCheck bool
 }
 
 // Option can be passed to New to configure the interpreter.
@@ -31,26 +63,131 @@ func WithREPLMode() Option {
 	}
 }
 
+// WithWarningHandler configures the interpreter to call handler with the warnings found in a program before executing
+// it. Warnings don't prevent a program from being executed.
+func WithWarningHandler(handler func(warnings lox.Errors)) Option {
+	return func(i *Interpreter) {
+		i.warningHandler = handler
+	}
+}
+
+// WithMaxCallDepth configures the maximum depth of nested Lox function calls. Exceeding it results in a stack overflow
+// error instead of the interpreter running out of stack. Calls to built-in functions don't count towards the depth.
+// Depths above [MaxCallDepthLimit] are reduced to it.
+func WithMaxCallDepth(depth int) Option {
+	return func(i *Interpreter) {
+		i.maxCallDepth = min(depth, MaxCallDepthLimit)
+	}
+}
+
+// WithMaxSteps configures the maximum number of steps that a program can take each time that it's interpreted, where a
+// step is a function call or a loop iteration. Exceeding it results in an error which the program can't catch. This
+// guards against programs which never finish. By default, there's no limit.
+func WithMaxSteps(steps int) Option {
+	return func(i *Interpreter) {
+		i.maxSteps = steps
+	}
+}
+
+// WithStdout configures the writer which print statements write to. By default, they write to [os.Stdout].
+func WithStdout(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.stdout = w
+	}
+}
+
+// WithNativeFunction adds a built-in function with the given name and parameters which is implemented by body. It can
+// be called by programs and the modules which they import in the same way as the built-in functions of the language.
+// If a built-in function of the language has the same name, then it's replaced, which can be used to intercept calls to
+// it.
+func WithNativeFunction(name string, params []string, body NativeFunction) Option {
+	return func(i *Interpreter) {
+		i.nativeFunctions = append(i.nativeFunctions, nativeFunction{name: name, params: params, body: body})
+	}
+}
+
+// WithoutBuiltin removes the built-in function or module of the language with the given name, such as [lox.BuiltinClock],
+// so that programs can't use it. Using it is reported as using an undeclared identifier.
+func WithoutBuiltin(name string) Option {
+	return func(i *Interpreter) {
+		i.disabledBuiltins = append(i.disabledBuiltins, name)
+	}
+}
+
+// WithTailCalls enables tail call optimisation. A call to a Lox function which is returned from another Lox function
+// replaces the call to the returning function instead of being nested inside it, so that tail recursive functions don't
+// exceed the maximum call depth. The replaced calls don't appear in stack traces. Unbounded tail recursion never
+// finishes instead of resulting in a stack overflow error.
+func WithTailCalls() Option {
+	return func(i *Interpreter) {
+		i.tailCalls = true
+	}
+}
+
+// WithoutShadowingCheck disables the warning about local declarations which shadow one declared in an enclosing scope,
+// both in programs and the modules which they import.
+func WithoutShadowingCheck() Option {
+	return func(i *Interpreter) {
+		i.noShadowingCheck = true
+	}
+}
+
 // New constructs a new Interpreter with the given options.
 func New(opts ...Option) *Interpreter {
-	var globals environment = newGlobalEnvironment()
-	for name, builtin := range builtins {
-		globals = globals.Define(name, builtin)
-	}
 	interpreter := &Interpreter{
-		globals:   globals,
-		callStack: newCallStack(),
+		callStack:    callstack.New(),
+		maxCallDepth: defaultMaxCallDepth,
+		stdout:       os.Stdout,
 	}
 	for _, opt := range opts {
 		opt(interpreter)
 	}
+	interpreter.globals = interpreter.newGlobals()
+	interpreter.modules = module.NewLoader[*loxModule](interpreter.warningHandler, interpreter.resolveOptions()...)
 	return interpreter
 }
 
+// newGlobals returns a global environment containing the enabled built-ins and the native functions.
+func (i *Interpreter) newGlobals() *globalEnvironment {
+	objects := maps.Clone(builtins)
+	for _, name := range i.disabledBuiltins {
+		delete(objects, name)
+	}
+	for _, f := range i.nativeFunctions {
+		objects[f.name] = f.loxFunction()
+	}
+	globals := newGlobalEnvironment()
+	for name, object := range objects {
+		globals.Define(name, object)
+	}
+	return globals
+}
+
+// resolveOptions returns the options which are passed to [analysis.ResolveIdents] for programs and the modules which
+// they import. They declare the native functions, remove the disabled built-ins, and disable the configured checks.
+func (i *Interpreter) resolveOptions() []analysis.ResolveIdentsOption {
+	names := make([]string, len(i.nativeFunctions))
+	for j, f := range i.nativeFunctions {
+		names[j] = f.name
+	}
+	opts := []analysis.ResolveIdentsOption{analysis.WithoutBuiltins(i.disabledBuiltins...), analysis.WithBuiltins(names...)}
+	if i.noShadowingCheck {
+		opts = append(opts, analysis.WithoutShadowingCheck())
+	}
+	return opts
+}
+
 // Interpret interprets a program and returns an error if one occurred.
 // Interpret can be called multiple times with different ASTs and the state will be maintained between calls.
 func (i *Interpreter) Interpret(program ast.Program) error {
-	var opts []analysis.ResolveIdentsOption
+	return i.InterpretContext(context.Background(), program)
+}
+
+// InterpretContext is like Interpret but stops interpreting the program once ctx is done. This is checked before each
+// statement is executed. The error returned reports where execution stopped and wraps ctx.Err(). The program can't catch
+// this error.
+func (i *Interpreter) InterpretContext(ctx context.Context, program ast.Program) error {
+	opts := i.resolveOptions()
 	if i.replMode {
 		opts = append(opts, analysis.WithREPLMode())
 	}
@@ -59,6 +196,14 @@ func (i *Interpreter) Interpret(program ast.Program) error {
 	if err := errs.Err(); err != nil {
 		return err
 	}
+	if len(errs) > 0 && i.warningHandler != nil {
+		// Err only returns nil if there are no errors, so everything remaining is a warning.
+		errs.Sort()
+		i.warningHandler(errs)
+	}
+	i.ctx = ctx
+	i.steps = 0
+	defer func() { i.ctx = nil }()
 	return i.interpretProgram(program)
 }
 
@@ -66,12 +211,19 @@ func (i *Interpreter) interpretProgram(node ast.Program) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			if loxErr, ok := r.(*lox.Error); ok {
-				err = loxErr
-				if i.callStack.Len() > 0 {
-					i.callStack.Push("", loxErr.Start)
-					err = fmt.Errorf("%w\n\n%s", err, i.callStack.StackTrace())
-					i.callStack.Clear()
-				}
+				err = i.stackTraceError(loxErr)
+				i.callStack.Clear()
+			} else if thrownErr, ok := r.(*loxError); ok {
+				// The error was caught and then thrown again, so it already has the stack trace from where it occurred.
+				err = thrownErr.err
+				i.callStack.Clear()
+			} else if loxErrs, ok := r.(lox.Errors); ok {
+				// An imported module contained errors, so it was never executed.
+				err = loxErrs
+				i.callStack.Clear()
+			} else if interrupted, ok := r.(interruption); ok {
+				err = &interruptedError{err: i.stackTraceError(interrupted.err), cause: interrupted.cause}
+				i.callStack.Clear()
 			} else {
 				panic(r)
 			}
@@ -83,6 +235,16 @@ func (i *Interpreter) interpretProgram(node ast.Program) (err error) {
 	return nil
 }
 
+// stackTraceError returns err with the stack trace of the calls which led to it appended, if there are any.
+func (i *Interpreter) stackTraceError(err *lox.Error) error {
+	if i.callStack.Len() == 0 {
+		return err
+	}
+	i.callStack.Push("", err.Start)
+	defer i.callStack.Pop()
+	return fmt.Errorf("%w\n\n%s", err, i.callStack.StackTrace())
+}
+
 //gosumtype:decl stmtResult
 type stmtResult interface {
 	isStmtResult()
@@ -93,15 +255,67 @@ type (
 	stmtResultBreak    struct{ stmtResult }
 	stmtResultContinue struct{ stmtResult }
 	stmtResultReturn   struct {
-		Value loxObject
+		Value    loxObject
+		TailCall *tailCall // Set instead of Value if the return value is a call which hasn't been made yet
 		stmtResult
 	}
 )
 
+// tailCall is a call to a Lox function in tail position which is made by the function returning it, so that it can
+// reuse the returning function's place on the call stack.
+type tailCall struct {
+	rang     token.Range
+	function *loxFunction
+	args     []loxObject
+}
+
+// interruption is panicked with when execution is stopped early, either because the context passed to InterpretContext
+// is done or because the step limit has been exceeded. It's a separate type from the runtime errors so that it can't be
+// caught by a try statement.
+type interruption struct {
+	err   *lox.Error // Reports where execution stopped
+	cause error      // The context's error if it's done, otherwise nil
+}
+
+// interruptedError is returned when execution is stopped early. It wraps both the runtime error which reports where
+// execution stopped and the context's error if it's done.
+type interruptedError struct {
+	err   error
+	cause error
+}
+
+func (e *interruptedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *interruptedError) Unwrap() []error {
+	if e.cause == nil {
+		return []error{e.err}
+	}
+	return []error{e.err, e.cause}
+}
+
+// step counts a function call or loop iteration towards the step limit. rang is the call or loop.
+func (i *Interpreter) step(rang token.Range) {
+	if i.maxSteps == 0 {
+		return
+	}
+	i.steps++
+	if i.steps > i.maxSteps {
+		msg := fmt.Sprintf("step limit of %d exceeded", i.maxSteps)
+		panic(interruption{err: &lox.Error{Msg: msg, Start: rang.Start(), End: rang.End()}})
+	}
+}
+
 func (i *Interpreter) execStmt(env environment, stmt ast.Stmt) (stmtResult, environment) {
+	if err := i.ctx.Err(); err != nil {
+		panic(interruption{err: &lox.Error{Msg: "execution cancelled", Start: stmt.Start(), End: stmt.End()}, cause: err})
+	}
 	var result stmtResult = stmtResultNone{}
 	newEnv := env
 	switch stmt := stmt.(type) {
+	case ast.ImportStmt:
+		newEnv = i.execImportStmt(env, stmt)
 	case ast.VarDecl:
 		newEnv = i.execVarDecl(env, stmt)
 	case ast.FunDecl:
@@ -120,18 +334,42 @@ func (i *Interpreter) execStmt(env environment, stmt ast.Stmt) (stmtResult, envi
 		result = i.execWhileStmt(env, stmt)
 	case ast.ForStmt:
 		result = i.execForStmt(env, stmt)
+	case ast.ForInStmt:
+		result = i.execForInStmt(env, stmt)
 	case ast.BreakStmt:
 		result = i.execBreakStmt()
 	case ast.ContinueStmt:
 		result = i.execContinueStmt()
 	case ast.ReturnStmt:
 		result = i.execReturnStmt(env, stmt)
+	case ast.TryStmt:
+		result = i.execTryStmt(env, stmt)
+	case ast.ThrowStmt:
+		i.execThrowStmt(env, stmt)
 	case ast.CommentStmt, ast.InlineCommentStmt, ast.IllegalStmt, ast.MethodDecl:
 		panic(fmt.Sprintf("unexpected statement type: %T", stmt))
 	}
 	return result, newEnv
 }
 
+func (i *Interpreter) execImportStmt(env environment, stmt ast.ImportStmt) environment {
+	mod, err := i.modules.Import(stmt, func(program ast.Program) *loxModule {
+		globals := i.newGlobals()
+		i.callStack.Push(filepath.Base(module.Path(stmt)), stmt.Start())
+		for _, stmt := range program.Stmts {
+			i.execStmt(globals, stmt)
+		}
+		i.callStack.Pop()
+		return &loxModule{name: stmt.Name.Token.Lexeme, globals: globals}
+	})
+	if err != nil {
+		panic(err)
+	}
+	newEnv := env.Declare(stmt.Name)
+	newEnv.Assign(stmt.Name, mod)
+	return newEnv
+}
+
 func (i *Interpreter) execVarDecl(env environment, stmt ast.VarDecl) environment {
 	var value loxObject
 	if stmt.Initialiser != nil {
@@ -160,21 +398,29 @@ func (i *Interpreter) execClassDecl(env environment, stmt ast.ClassDecl) environ
 	if stmt.Name.Token.Lexeme == token.PlaceholderIdent {
 		return env
 	}
+	var superclass *loxClass
+	if stmt.Superclass != nil {
+		object := i.evalExpr(env, stmt.Superclass)
+		var ok bool
+		if superclass, ok = object.(*loxClass); !ok {
+			panic(lox.NewCodedErrorf(lox.ErrorCodeNotAClass, stmt.Superclass, "%m object is not a class", object.Type()))
+		}
+	}
 	newEnv := env.Declare(stmt.Name)
-	newEnv.Assign(stmt.Name, newLoxClass(stmt.Name.Token.Lexeme, stmt.Methods(), newEnv))
+	newEnv.Assign(stmt.Name, newLoxClass(stmt.Name.Token.Lexeme, stmt.Methods(), newEnv, superclass))
 	return newEnv
 }
 
 func (i *Interpreter) execExprStmt(env environment, stmt ast.ExprStmt) {
 	value := i.evalExpr(env, stmt.Expr)
 	if i.replMode {
-		fmt.Println(value.String())
+		fmt.Fprintln(i.stdout, value.String())
 	}
 }
 
 func (i *Interpreter) execPrintStmt(env environment, stmt ast.PrintStmt) {
 	value := i.evalExpr(env, stmt.Expr)
-	fmt.Println(value.String())
+	fmt.Fprintln(i.stdout, value.String())
 }
 
 func (i *Interpreter) execBlockStmt(env environment, stmt ast.BlockStmt) stmtResult {
@@ -215,6 +461,7 @@ func (i *Interpreter) execWhileStmt(env environment, stmt ast.WhileStmt) stmtRes
 			return result
 		case stmtResultContinue, stmtResultNone:
 		}
+		i.step(stmt)
 	}
 	return stmtResultNone{}
 }
@@ -224,6 +471,10 @@ func (i *Interpreter) execForStmt(env environment, stmt ast.ForStmt) stmtResult
 	if stmt.Initialise != nil {
 		_, childEnv = i.execStmt(childEnv, stmt.Initialise)
 	}
+	// If a variable is declared in the initialise section, then each iteration gets its own binding of it, so that
+	// closures created in different iterations don't share the variable.
+	loopVarEnv, hasLoopVar := childEnv.(*localEnvironment)
+	hasLoopVar = hasLoopVar && isVarDecl(stmt.Initialise)
 	for stmt.Condition == nil || isTruthy(i.evalExpr(childEnv, stmt.Condition)) {
 		switch result, _ := i.execStmt(childEnv, stmt.Body); result.(type) {
 		case stmtResultBreak:
@@ -232,6 +483,12 @@ func (i *Interpreter) execForStmt(env environment, stmt ast.ForStmt) stmtResult
 			return result
 		case stmtResultContinue, stmtResultNone:
 		}
+		i.step(stmt)
+		if hasLoopVar {
+			// The next binding starts with the value at the end of this iteration, before it's updated.
+			loopVarEnv = loopVarEnv.Rebind()
+			childEnv = loopVarEnv
+		}
 		if stmt.Update != nil {
 			i.evalExpr(childEnv, stmt.Update)
 		}
@@ -239,6 +496,38 @@ func (i *Interpreter) execForStmt(env environment, stmt ast.ForStmt) stmtResult
 	return stmtResultNone{}
 }
 
+func (i *Interpreter) execForInStmt(env environment, stmt ast.ForInStmt) stmtResult {
+	value := i.evalExpr(env, stmt.Iterable)
+	iterable, ok := value.(loxIterable)
+	if !ok {
+		panic(lox.NewCodedErrorf(lox.ErrorCodeNotIterable, stmt.Iterable, "iteration is not valid for %m object", value.Type()))
+	}
+	for element := range iterable.Elements() {
+		// Each iteration gets its own binding of the loop variable, so that closures created in different iterations
+		// don't share the variable.
+		childEnv := env
+		if stmt.Name.Token.Lexeme != token.PlaceholderIdent {
+			childEnv = env.Child().Declare(stmt.Name)
+			childEnv.Assign(stmt.Name, element)
+		}
+		switch result, _ := i.execStmt(childEnv, stmt.Body); result.(type) {
+		case stmtResultBreak:
+			return stmtResultNone{}
+		case stmtResultReturn:
+			return result
+		case stmtResultContinue, stmtResultNone:
+		}
+		i.step(stmt)
+	}
+	return stmtResultNone{}
+}
+
+// isVarDecl reports whether a statement declares a variable which isn't the placeholder identifier.
+func isVarDecl(stmt ast.Stmt) bool {
+	varDecl, ok := stmt.(ast.VarDecl)
+	return ok && varDecl.Name.Token.Lexeme != token.PlaceholderIdent
+}
+
 func (i *Interpreter) execBreakStmt() stmtResultBreak {
 	return stmtResultBreak{}
 }
@@ -248,6 +537,15 @@ func (i *Interpreter) execContinueStmt() stmtResultContinue {
 }
 
 func (i *Interpreter) execReturnStmt(env environment, stmt ast.ReturnStmt) stmtResultReturn {
+	// A call can't be made in place of the current one from inside a try statement, since the statement has to handle
+	// any error which the call results in.
+	if callExpr, ok := stmt.Value.(ast.CallExpr); ok && i.tailCalls && i.tries == 0 {
+		callable, args := i.evalCallee(env, callExpr)
+		if f, ok := callable.(*loxFunction); ok && !f.typ.IsBuiltin() {
+			return stmtResultReturn{TailCall: &tailCall{rang: callExpr, function: f, args: args}}
+		}
+		return stmtResultReturn{Value: i.callChecked(callExpr, callable, args)}
+	}
 	var value loxObject = loxNil{}
 	if stmt.Value != nil {
 		value = i.evalExpr(env, stmt.Value)
@@ -255,6 +553,64 @@ func (i *Interpreter) execReturnStmt(env environment, stmt ast.ReturnStmt) stmtR
 	return stmtResultReturn{Value: value}
 }
 
+func (i *Interpreter) execTryStmt(env environment, stmt ast.TryStmt) stmtResult {
+	i.tries++
+	defer func() { i.tries-- }()
+	result, err := i.catch(func() stmtResult {
+		return i.execBlockStmt(env, stmt.Body)
+	})
+	if err != nil && stmt.HasCatch() {
+		result, err = i.catch(func() stmtResult {
+			catchEnv := env
+			if stmt.CatchName.Token.Lexeme != token.PlaceholderIdent {
+				catchEnv = env.Child().Declare(stmt.CatchName)
+				catchEnv.Assign(stmt.CatchName, err)
+			}
+			return i.execBlockStmt(catchEnv, stmt.CatchBody)
+		})
+	}
+	if stmt.HasFinally() {
+		// If the finally clause returns, breaks, or continues, then it overrides the result of the rest of the
+		// statement, including any error which wasn't caught.
+		finallyResult := i.execBlockStmt(env, stmt.FinallyBody)
+		if _, ok := finallyResult.(stmtResultNone); !ok {
+			return finallyResult
+		}
+	}
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// catch calls f and returns its result. If a runtime error occurs in f, then it's returned instead.
+func (i *Interpreter) catch(f func() stmtResult) (result stmtResult, caught *loxError) {
+	callDepth := i.callStack.Len()
+	defer func() {
+		if r := recover(); r != nil {
+			switch r := r.(type) {
+			case *lox.Error:
+				caught = &loxError{message: r.Msg, err: i.stackTraceError(r)}
+			case *loxError:
+				caught = r
+			default:
+				panic(r)
+			}
+			// The calls which were being made when the error occurred were never popped.
+			i.callStack.Truncate(callDepth)
+		}
+	}()
+	return f(), nil
+}
+
+func (i *Interpreter) execThrowStmt(env environment, stmt ast.ThrowStmt) {
+	value := i.evalExpr(env, stmt.Value)
+	if err, ok := value.(*loxError); ok {
+		panic(err)
+	}
+	panic(lox.NewCodedError(lox.ErrorCodeUncaughtError, stmt, value.String()))
+}
+
 func (i *Interpreter) evalExpr(env environment, expr ast.Expr) loxObject {
 	switch expr := expr.(type) {
 	case ast.FunExpr:
@@ -263,16 +619,22 @@ func (i *Interpreter) evalExpr(env environment, expr ast.Expr) loxObject {
 		return i.evalGroupExpr(env, expr)
 	case ast.LiteralExpr:
 		return i.evalLiteralExpr(expr)
+	case ast.InterpolatedStringExpr:
+		return i.evalInterpolatedStringExpr(env, expr)
 	case ast.IdentExpr:
 		return i.evalIdentExpr(env, expr)
 	case ast.ThisExpr:
 		return i.evalThisExpr(env, expr)
+	case ast.SuperExpr:
+		return i.evalSuperExpr(env, expr)
 	case ast.CallExpr:
 		return i.evalCallExpr(env, expr)
 	case ast.GetExpr:
 		return i.evalGetExpr(env, expr)
 	case ast.UnaryExpr:
 		return i.evalUnaryExpr(env, expr)
+	case ast.IncrementExpr:
+		return i.evalIncrementExpr(env, expr)
 	case ast.BinaryExpr:
 		return i.evalBinaryExpr(env, expr)
 	case ast.TernaryExpr:
@@ -312,6 +674,17 @@ func (i *Interpreter) evalLiteralExpr(expr ast.LiteralExpr) loxObject {
 	}
 }
 
+func (i *Interpreter) evalInterpolatedStringExpr(env environment, expr ast.InterpolatedStringExpr) loxObject {
+	var b strings.Builder
+	parts := expr.StringParts()
+	for j, interpolated := range expr.Exprs {
+		b.WriteString(parts[j])
+		b.WriteString(i.evalExpr(env, interpolated).String())
+	}
+	b.WriteString(parts[len(parts)-1])
+	return loxString(b.String())
+}
+
 func (i *Interpreter) evalIdentExpr(env environment, expr ast.IdentExpr) loxObject {
 	return env.Get(expr.Ident)
 }
@@ -320,7 +693,26 @@ func (i *Interpreter) evalThisExpr(env environment, expr ast.ThisExpr) loxObject
 	return env.Get(ast.Ident{Token: expr.This})
 }
 
+func (i *Interpreter) evalSuperExpr(env environment, expr ast.SuperExpr) loxObject {
+	superclass := env.Get(ast.Ident{Token: expr.Super}).(*loxClass)
+	instance := env.Get(ast.Ident{Token: token.Token{Lexeme: token.CurrentInstanceIdent}}).(*loxInstance)
+	name := expr.Method.Token.Lexeme
+	if property, ok := superclass.GetProperty(name); ok {
+		return property.Get(i, instance, expr.Method)
+	}
+	if method, ok := superclass.GetMethod(name); ok {
+		return method.Bind(instance)
+	}
+	panic(lox.NewCodedErrorf(lox.ErrorCodeNoProperty, expr.Method, "superclass %m has no property %s", loxType(superclass.Name), name))
+}
+
 func (i *Interpreter) evalCallExpr(env environment, expr ast.CallExpr) loxObject {
+	callable, args := i.evalCallee(env, expr)
+	return i.callChecked(expr, callable, args)
+}
+
+// evalCallee evaluates the callee and arguments of a call and checks that the callee can be called with them.
+func (i *Interpreter) evalCallee(env environment, expr ast.CallExpr) (loxCallable, []loxObject) {
 	callee := i.evalExpr(env, expr.Callee)
 	args := make([]loxObject, len(expr.Args))
 	for j, arg := range expr.Args {
@@ -329,7 +721,7 @@ func (i *Interpreter) evalCallExpr(env environment, expr ast.CallExpr) loxObject
 
 	callable, ok := callee.(loxCallable)
 	if !ok {
-		panic(lox.NewErrorf(expr.Callee, "%m object is not callable", callee.Type()))
+		panic(lox.NewCodedErrorf(lox.ErrorCodeNotCallable, expr.Callee, "%m object is not callable", callee.Type()))
 	}
 
 	params := callable.Params()
@@ -350,36 +742,54 @@ func (i *Interpreter) evalCallExpr(env environment, expr ast.CallExpr) loxObject
 		default:
 			missingArgsStr = strings.Join(missingArgs[:len(missingArgs)-1], ", ") + ", and " + missingArgs[len(missingArgs)-1]
 		}
-		panic(lox.NewErrorf(
+		panic(lox.NewCodedErrorf(lox.ErrorCodeWrongArgumentCount,
 			expr,
 			"%s() missing %d argument%s: %s", callable.CallableName(), arity-len(args), argumentSuffix, missingArgsStr,
 		))
 	case len(args) > arity:
-		panic(lox.NewErrorf(
+		panic(lox.NewCodedErrorf(lox.ErrorCodeWrongArgumentCount,
 			expr.Args[arity:],
 			"%s() accepts %d arguments but %d were given", callable.CallableName(), arity, len(args),
 		))
 	}
 
-	result := i.call(expr.Start(), callable, args)
+	return callable, args
+}
+
+// callChecked calls callable with args and converts an error message returned by a built-in function into a runtime
+// error.
+func (i *Interpreter) callChecked(expr ast.CallExpr, callable loxCallable, args []loxObject) loxObject {
+	result := i.call(expr, callable, args)
 	if errorMsg, ok := result.(errorMsg); ok {
-		panic(lox.NewError(expr, string(errorMsg)))
+		panic(lox.NewCodedError(lox.ErrorCodeBuiltinError, expr, string(errorMsg)))
 	}
 	return result
 }
 
-func (i *Interpreter) call(location token.Position, callable loxCallable, args []loxObject) loxObject {
-	i.callStack.Push(callable.CallableName(), location)
+func (i *Interpreter) call(rang token.Range, callable loxCallable, args []loxObject) loxObject {
+	// Built-in functions don't call back into Lox code, so they can never be the cause of unbounded recursion. Lox code
+	// is never being executed while a built-in function is, so the call stack only contains Lox function calls here.
+	if f, ok := callable.(*loxFunction); !(ok && f.typ.IsBuiltin()) && i.callStack.Len() >= i.maxCallDepth {
+		panic(lox.NewCodedErrorf(lox.ErrorCodeStackOverflow, rang, "stack overflow: maximum call depth %d exceeded", i.maxCallDepth))
+	}
+	i.step(rang)
+	i.callStack.Push(callable.CallableName(), rang.Start())
 	result := callable.Call(i, args)
 	i.callStack.Pop()
 	return result
 }
 
+// replaceCall replaces the most recent call on the call stack with a call in tail position which was returned from it.
+func (i *Interpreter) replaceCall(call *tailCall) {
+	i.step(call.rang)
+	i.callStack.Replace(call.function.CallableName())
+}
+
 func (i *Interpreter) evalGetExpr(env environment, expr ast.GetExpr) loxObject {
 	object := i.evalExpr(env, expr.Object)
 	getter, ok := object.(loxGetter)
 	if !ok {
-		panic(lox.NewErrorf(expr, "property access is not valid for %m object", object.Type()))
+		panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidPropertyAccess, expr, "property access is not valid for %m object", object.Type()))
 	}
 	return getter.Get(i, expr.Name)
 }
@@ -396,7 +806,54 @@ func (i *Interpreter) evalUnaryExpr(env environment, expr ast.UnaryExpr) loxObje
 			return result
 		}
 	}
-	panic(lox.NewErrorf(expr.Op, "%m operator cannot be used with type %m", expr.Op.Type, right.Type()))
+	if _, ok := right.(loxNil); ok {
+		panic(lox.NewCodedErrorf(lox.ErrorCodeNilOperand, expr.Right, "operand of %m operator is nil", expr.Op.Type))
+	}
+	panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidOperand, expr.Op, "%m operator cannot be used with type %m", expr.Op.Type, right.Type()))
+}
+
+func (i *Interpreter) evalIncrementExpr(env environment, expr ast.IncrementExpr) loxObject {
+	var oldValue, newValue loxObject
+	switch operand := expr.Operand.(type) {
+	case ast.IdentExpr:
+		oldValue = i.evalIdentExpr(env, operand)
+		newValue = increment(expr, oldValue)
+		env.Assign(operand.Ident, newValue)
+	case ast.GetExpr:
+		object := i.evalExpr(env, operand.Object)
+		getter, ok := object.(loxGetter)
+		if !ok {
+			panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidPropertyAccess, operand, "property access is not valid for %m object", object.Type()))
+		}
+		oldValue = getter.Get(i, operand.Name)
+		newValue = increment(expr, oldValue)
+		setter, ok := object.(loxSetter)
+		if !ok {
+			panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidPropertyAccess, expr, "property assignment is not valid for %m object", object.Type()))
+		}
+		setter.Set(i, operand.Name, newValue)
+	default:
+		panic("unreachable")
+	}
+	if expr.Postfix {
+		return oldValue
+	}
+	return newValue
+}
+
+// increment returns the result of incrementing or decrementing value by 1, depending on the operator of expr.
+func increment(expr ast.IncrementExpr, value loxObject) loxNumber {
+	switch value := value.(type) {
+	case loxNumber:
+		if expr.Op.Type == token.PlusPlus {
+			return value + 1
+		}
+		return value - 1
+	case loxNil:
+		panic(lox.NewCodedErrorf(lox.ErrorCodeNilOperand, expr.Operand, "operand of %m operator is nil", expr.Op.Type))
+	default:
+		panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidOperand, expr.Op, "%m operator cannot be used with type %m", expr.Op.Type, value.Type()))
+	}
 }
 
 func (i *Interpreter) evalBinaryExpr(env environment, expr ast.BinaryExpr) loxObject {
@@ -440,7 +897,15 @@ func (i *Interpreter) evalBinaryExpr(env environment, expr ast.BinaryExpr) loxOb
 				return result
 			}
 		}
-		panic(lox.NewErrorf(expr.Op, "%m operator cannot be used with types %m and %m", expr.Op.Type, left.Type(), right.Type()))
+		// A nil operand is usually caused by a missing value rather than a value of the wrong type, so it's reported
+		// separately.
+		if _, ok := left.(loxNil); ok {
+			panic(lox.NewCodedErrorf(lox.ErrorCodeNilOperand, expr.Left, "left operand of %m operator is nil", expr.Op.Type))
+		}
+		if _, ok := right.(loxNil); ok {
+			panic(lox.NewCodedErrorf(lox.ErrorCodeNilOperand, expr.Right, "right operand of %m operator is nil", expr.Op.Type))
+		}
+		panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidOperand, expr.Op, "%m operator cannot be used with types %m and %m", expr.Op.Type, left.Type(), right.Type()))
 	}
 }
 
@@ -464,7 +929,7 @@ func (i *Interpreter) evalSetExpr(env environment, expr ast.SetExpr) loxObject {
 	object := i.evalExpr(env, expr.Object)
 	setter, ok := object.(loxSetter)
 	if !ok {
-		panic(lox.NewErrorf(expr, "property assignment is not valid for %m object", object.Type()))
+		panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidPropertyAccess, expr, "property assignment is not valid for %m object", object.Type()))
 	}
 	value := i.evalExpr(env, expr.Value)
 	setter.Set(i, expr.Name, value)
@@ -0,0 +1,275 @@
+package interpreter_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcuscaisey/lox/golox/interpreter"
+	"github.com/marcuscaisey/lox/lox"
+	"github.com/marcuscaisey/lox/lox/parser"
+)
+
+func Example() {
+	program, err := parser.Parse(strings.NewReader(`
+var total = 0;
+for (var i = 1; i <= 3; i++) {
+    total = total + square(i);
+}
+print greet("Lox") + " ${total}";
+`))
+	if err != nil {
+		panic(err)
+	}
+
+	i := interpreter.New(
+		interpreter.WithStdout(os.Stdout),
+		interpreter.WithNativeFunction("square", []string{"x"}, func(args []any) (any, error) {
+			x, ok := args[0].(float64)
+			if !ok {
+				return nil, errors.New("x must be a number")
+			}
+			return x * x, nil
+		}),
+		interpreter.WithNativeFunction("greet", []string{"name"}, func(args []any) (any, error) {
+			return fmt.Sprintf("Hello, %s!", args[0]), nil
+		}),
+	)
+	if err := i.Interpret(program); err != nil {
+		panic(err)
+	}
+	// Output: Hello, Lox! 14
+}
+
+func TestNativeFunctionError(t *testing.T) {
+	program, err := parser.Parse(strings.NewReader(`square("a");`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := interpreter.New(interpreter.WithNativeFunction("square", []string{"x"}, func(args []any) (any, error) {
+		return nil, errors.New("x must be a number")
+	}))
+
+	err = i.Interpret(program)
+
+	if want := "square(): x must be a number"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Interpret() = %v, want error containing %q", err, want)
+	}
+}
+
+func TestNativeFunctionArity(t *testing.T) {
+	program, err := parser.Parse(strings.NewReader(`square(1, 2);`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	called := false
+	i := interpreter.New(interpreter.WithNativeFunction("square", []string{"x"}, func(args []any) (any, error) {
+		called = true
+		return nil, nil
+	}))
+
+	err = i.Interpret(program)
+
+	if want := "square() accepts 1 arguments but 2 were given"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Interpret() = %v, want error containing %q", err, want)
+	}
+	if called {
+		t.Error("native function was called with the wrong number of arguments")
+	}
+}
+
+func TestWithoutBuiltin(t *testing.T) {
+	program, err := parser.Parse(strings.NewReader(`print clock();`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := interpreter.New(interpreter.WithoutBuiltin(lox.BuiltinClock))
+
+	err = i.Interpret(program)
+
+	if want := "clock has not been declared"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Interpret() = %v, want error containing %q", err, want)
+	}
+}
+
+func TestNativeFunctionReplacesBuiltin(t *testing.T) {
+	program, err := parser.Parse(strings.NewReader(`print clock();`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout strings.Builder
+	i := interpreter.New(
+		interpreter.WithStdout(&stdout),
+		interpreter.WithNativeFunction(lox.BuiltinClock, nil, func([]any) (any, error) {
+			return 42, nil
+		}),
+	)
+
+	if err := i.Interpret(program); err != nil {
+		t.Fatalf("Interpret() returned error: %s", err)
+	}
+
+	if got, want := stdout.String(), "42\n"; got != want {
+		t.Errorf("Interpret() printed %q, want %q", got, want)
+	}
+}
+
+func TestInterpretContextCancelled(t *testing.T) {
+	program, err := parser.Parse(strings.NewReader(`
+try {
+    while (true) {}
+} catch (_) {
+    print "caught";
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout strings.Builder
+	i := interpreter.New(interpreter.WithStdout(&stdout))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = i.InterpretContext(ctx, program)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("InterpretContext() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if want := "execution cancelled"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("InterpretContext() = %v, want error containing %q", err, want)
+	}
+	if stdout.Len() > 0 {
+		t.Errorf("InterpretContext() printed %q, want nothing", stdout.String())
+	}
+}
+
+func TestTailCalls(t *testing.T) {
+	const countDown = `
+fun countDown(n) {
+    if (n == 0) {
+        return "done";
+    }
+    return countDown(n - 1);
+}
+print countDown(10000);
+`
+	const mutualRecursion = `
+fun isEven(n) {
+    if (n == 0) {
+        return true;
+    }
+    return isOdd(n - 1);
+}
+fun isOdd(n) {
+    if (n == 0) {
+        return false;
+    }
+    return isEven(n - 1);
+}
+print isEven(10001);
+`
+	const countDownInTry = `
+fun countDown(n) {
+    if (n == 0) {
+        return "done";
+    }
+    try {
+        return countDown(n - 1);
+    } catch (e) {
+        throw e;
+    }
+}
+print countDown(10000);
+`
+	const errorAfterTailCall = `
+fun a() { return b(); }
+fun b() { return nil + 1; }
+fun c() { a(); }
+c();
+`
+	const stackOverflow = "stack overflow: maximum call depth 1000 exceeded"
+	withTailCalls := []interpreter.Option{interpreter.WithTailCalls()}
+
+	tests := []struct {
+		name       string
+		program    string
+		opts       []interpreter.Option
+		wantStdout string
+		wantErr    string
+	}{
+		{name: "Recursion", program: countDown, opts: withTailCalls, wantStdout: "done\n"},
+		{name: "MutualRecursion", program: mutualRecursion, opts: withTailCalls, wantStdout: "false\n"},
+		// The call to a is replaced by the call to b, so the stack trace points to where c called a.
+		{name: "StackTrace", program: errorAfterTailCall, opts: withTailCalls, wantErr: "4:11 in c"},
+		{name: "InsideTry", program: countDownInTry, opts: withTailCalls, wantErr: stackOverflow},
+		{name: "Disabled", program: countDown, wantErr: stackOverflow},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			program, err := parser.Parse(strings.NewReader(test.program))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var stdout strings.Builder
+			i := interpreter.New(append(test.opts, interpreter.WithStdout(&stdout))...)
+
+			err = i.Interpret(program)
+
+			if test.wantErr == "" && err != nil {
+				t.Fatalf("Interpret() returned error: %s", err)
+			}
+			if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Errorf("Interpret() = %v, want error containing %q", err, test.wantErr)
+			}
+			if got := stdout.String(); got != test.wantStdout {
+				t.Errorf("Interpret() printed %q, want %q", got, test.wantStdout)
+			}
+		})
+	}
+}
+
+func TestMaxCallDepthLimit(t *testing.T) {
+	program, err := parser.Parse(strings.NewReader("fun f() { return 1 + f(); }\nf();"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := interpreter.New(interpreter.WithMaxCallDepth(100000000))
+
+	err = i.Interpret(program)
+
+	want := fmt.Sprintf("stack overflow: maximum call depth %d exceeded", interpreter.MaxCallDepthLimit)
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Interpret() = %v, want error containing %q", err, want)
+	}
+}
+
+func TestTailCallsDontChangeOutput(t *testing.T) {
+	const path = "../../test/testdata/functions/tail_recursion.lox"
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	program, err := parser.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	interpret := func(opts ...interpreter.Option) string {
+		var stdout strings.Builder
+		if err := interpreter.New(append(opts, interpreter.WithStdout(&stdout))...).Interpret(program); err != nil {
+			t.Fatalf("Interpret() returned error: %s", err)
+		}
+		return stdout.String()
+	}
+
+	want := interpret()
+	got := interpret(interpreter.WithTailCalls())
+
+	if got != want {
+		t.Errorf("Interpret() with tail calls printed %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package interpreter
+
+import (
+	"fmt"
+)
+
+// NativeFunction is the body of a built-in function which is implemented in Go. It's called with the arguments of each
+// call and returns the result.
+//
+// Values are converted between Lox and Go as follows:
+//   - nil is nil
+//   - a boolean is a bool
+//   - a number is a float64
+//   - a string is a string
+//
+// Any other Lox value is passed as a [fmt.Stringer] which formats it as it would be printed. The result can also be an
+// int, which is converted to a number. If an error is returned, then a runtime error with its message occurs.
+type NativeFunction func(args []any) (any, error)
+
+type nativeFunction struct {
+	name   string
+	params []string
+	body   NativeFunction
+}
+
+func (f nativeFunction) loxFunction() *loxFunction {
+	return newBuiltinLoxFunction(f.name, f.params, func(args []loxObject) loxObject {
+		goArgs := make([]any, len(args))
+		for i, arg := range args {
+			goArgs[i] = goValue(arg)
+		}
+		result, err := f.body(goArgs)
+		if err != nil {
+			return errorMsg(fmt.Sprintf("%s(): %s", f.name, err))
+		}
+		object, ok := loxValue(result)
+		if !ok {
+			return errorMsg(fmt.Sprintf("%s() returned unsupported Go value of type %T", f.name, result))
+		}
+		return object
+	})
+}
+
+func goValue(object loxObject) any {
+	switch object := object.(type) {
+	case loxNil:
+		return nil
+	case loxBool:
+		return bool(object)
+	case loxNumber:
+		return float64(object)
+	case loxString:
+		return string(object)
+	default:
+		return object
+	}
+}
+
+func loxValue(value any) (loxObject, bool) {
+	switch value := value.(type) {
+	case nil:
+		return loxNil{}, true
+	case bool:
+		return loxBool(value), true
+	case float64:
+		return loxNumber(value), true
+	case int:
+		return loxNumber(value), true
+	case string:
+		return loxString(value), true
+	case loxObject:
+		// A value which was passed in as an argument.
+		return value, true
+	default:
+		return nil, false
+	}
+}
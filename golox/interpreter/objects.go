@@ -2,6 +2,7 @@ package interpreter
 
 import (
 	"fmt"
+	"iter"
 	"math"
 	"strconv"
 	"strings"
@@ -20,6 +21,8 @@ const (
 	loxTypeBool     loxType = "bool"
 	loxTypeNil      loxType = "nil"
 	loxTypeFunction loxType = "function"
+	loxTypeModule   loxType = "module"
+	loxTypeError    loxType = "error"
 )
 
 // Format implements fmt.Formatter. All verbs have the default behaviour, except for 'm' (message) which formats the
@@ -68,6 +71,11 @@ type loxSetter interface {
 	Set(interpreter *Interpreter, name ast.Ident, value loxObject)
 }
 
+type loxIterable interface {
+	// Elements returns an iterator over the elements of the object which are iterated over by a for-in loop.
+	Elements() iter.Seq[loxObject]
+}
+
 type loxNumber float64
 
 var (
@@ -77,8 +85,18 @@ var (
 	_ loxTruther       = loxNumber(0)
 )
 
+// String formats the number in its shortest decimal representation. Negative zero is formatted as 0 and the special
+// values are formatted as Inf, -Inf, and NaN.
 func (n loxNumber) String() string {
-	return strconv.FormatFloat(float64(n), 'f', -1, 64)
+	switch {
+	case n == 0:
+		// This also matches negative zero.
+		return "0"
+	case math.IsInf(float64(n), 1):
+		return "Inf"
+	default:
+		return strconv.FormatFloat(float64(n), 'f', -1, 64)
+	}
 }
 
 func (n loxNumber) Type() loxType {
@@ -104,12 +122,17 @@ func (n loxNumber) BinaryOp(op token.Token, right loxObject) loxObject {
 			return n * right
 		case token.Slash:
 			if right == 0 {
-				panic(lox.NewError(op, "cannot divide by 0"))
+				panic(lox.NewCodedError(lox.ErrorCodeDivisionByZero, op, "cannot divide by 0"))
 			}
 			return n / right
+		case token.TildeSlash:
+			if right == 0 {
+				panic(lox.NewCodedError(lox.ErrorCodeDivisionByZero, op, "cannot divide by 0"))
+			}
+			return loxNumber(math.Trunc(float64(n / right)))
 		case token.Percent:
 			if right == 0 {
-				panic(lox.NewError(op, "cannot modulo by 0"))
+				panic(lox.NewCodedError(lox.ErrorCodeDivisionByZero, op, "cannot modulo by 0"))
 			}
 			return loxNumber(math.Mod(float64(n), float64(right)))
 		case token.Plus:
@@ -143,10 +166,10 @@ func (n loxNumber) BinaryOp(op token.Token, right loxObject) loxObject {
 
 func numberTimesString(n loxNumber, op token.Token, s loxString) loxString {
 	if math.Floor(float64(n)) != float64(n) {
-		panic(lox.NewErrorf(op, "cannot multiply %m by non-integer %m", loxTypeString, loxTypeNumber))
+		panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidOperand, op, "cannot multiply %m by non-integer %m", loxTypeString, loxTypeNumber))
 	}
 	if n < 0 {
-		panic(lox.NewErrorf(op, "cannot multiply %m by negative %m", loxTypeString, loxTypeNumber))
+		panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidOperand, op, "cannot multiply %m by negative %m", loxTypeString, loxTypeNumber))
 	}
 	return loxString(strings.Repeat(string(s), int(n)))
 }
@@ -157,6 +180,7 @@ var (
 	_ loxObject        = loxString("")
 	_ loxBinaryOperand = loxString("")
 	_ loxTruther       = loxString("")
+	_ loxIterable      = loxString("")
 )
 
 func (s loxString) String() string {
@@ -171,6 +195,17 @@ func (s loxString) IsTruthy() loxBool {
 	return s != ""
 }
 
+// Elements returns an iterator over the characters of the string.
+func (s loxString) Elements() iter.Seq[loxObject] {
+	return func(yield func(loxObject) bool) {
+		for _, r := range s {
+			if !yield(loxString(r)) {
+				return
+			}
+		}
+	}
+}
+
 func (s loxString) BinaryOp(op token.Token, right loxObject) loxObject {
 	switch right := right.(type) {
 	case loxString:
@@ -341,18 +376,31 @@ func (f *loxFunction) Call(interpreter *Interpreter, args []loxObject) loxObject
 		return f.nativeBody(args)
 	}
 
-	childEnv := f.closure.Child()
-	for i, param := range f.params {
-		childEnv = childEnv.Define(param, args[i])
-	}
-	result := interpreter.executeBlock(childEnv, f.body)
-	if f.typ.IsConstructor() {
-		return f.closure.Get(ast.Ident{Token: token.Token{Lexeme: token.CurrentInstanceIdent}})
-	}
-	if r, ok := result.(stmtResultReturn); ok {
-		return r.Value
+	// The try statements being executed by the caller don't enclose the body of this function.
+	tries := interpreter.tries
+	interpreter.tries = 0
+	defer func() { interpreter.tries = tries }()
+
+	for {
+		childEnv := f.closure.Child()
+		for i, param := range f.params {
+			childEnv = childEnv.Define(param, args[i])
+		}
+		result := interpreter.executeBlock(childEnv, f.body)
+		if f.typ.IsConstructor() {
+			return f.closure.Get(ast.Ident{Token: token.Token{Lexeme: token.CurrentInstanceIdent}})
+		}
+		r, ok := result.(stmtResultReturn)
+		if !ok {
+			return loxNil{}
+		}
+		if r.TailCall == nil {
+			return r.Value
+		}
+		// Make the call in tail position in place of this one, instead of nesting it inside this one.
+		interpreter.replaceCall(r.TailCall)
+		f, args = r.TailCall.function, r.TailCall.args
 	}
-	return loxNil{}
 }
 
 func (f *loxFunction) Bind(instance *loxInstance) *loxFunction {
@@ -372,24 +420,26 @@ func newProperty(getter, setter *loxFunction) *property {
 }
 
 func (p *property) Get(interpreter *Interpreter, instance *loxInstance, name ast.Ident) loxObject {
-	return interpreter.call(name.Start(), p.getter.Bind(instance), nil)
+	return interpreter.call(name, p.getter.Bind(instance), nil)
 }
 
 func (p *property) Set(interpreter *Interpreter, instance *loxInstance, name ast.Ident, value loxObject) {
 	if p.setter == nil {
-		panic(lox.NewErrorf(name, "property '%s' of %m object is read-only", name.Token.Lexeme, instance.Type()))
+		panic(lox.NewCodedErrorf(lox.ErrorCodeReadOnlyProperty, name, "property '%s' of %m object is read-only", name.Token.Lexeme, instance.Type()))
 	}
-	interpreter.call(name.Start(), p.setter.Bind(instance), []loxObject{value})
+	interpreter.call(name, p.setter.Bind(instance), []loxObject{value})
 }
 
 type loxClass struct {
 	*loxInstance     // instance of the metaclass or nil for the metaclass itself
 	Name             string
+	superclass       *loxClass // nil if the class doesn't have a superclass
 	methodsByName    map[string]*loxFunction
 	propertiesByName map[string]*property
 }
 
-func newLoxClass(name string, methods []ast.MethodDecl, env environment) *loxClass {
+// newLoxClass creates a class with the given methods. superclass is nil if the class doesn't have a superclass.
+func newLoxClass(name string, methods []ast.MethodDecl, env environment, superclass *loxClass) *loxClass {
 	instanceMethods := make([]ast.MethodDecl, 0, len(methods))
 	staticMethods := make([]ast.MethodDecl, 0, len(methods))
 	for _, decl := range methods {
@@ -399,12 +449,20 @@ func newLoxClass(name string, methods []ast.MethodDecl, env environment) *loxCla
 			instanceMethods = append(instanceMethods, decl)
 		}
 	}
-	metaclass := newLoxClassWithMetaclass(name, staticMethods, env, nil)
+	// Static methods are inherited from the superclass's metaclass.
+	var metaSuperclass *loxClass
+	if superclass != nil {
+		metaSuperclass = superclass.loxInstance.class
+	}
+	metaclass := newLoxClassWithMetaclass(name, staticMethods, env, nil, metaSuperclass)
 	metaclass.Name = fmt.Sprintf("%s class", name)
-	return newLoxClassWithMetaclass(name, instanceMethods, env, metaclass)
+	return newLoxClassWithMetaclass(name, instanceMethods, env, metaclass, superclass)
 }
 
-func newLoxClassWithMetaclass(name string, methods []ast.MethodDecl, env environment, metaclass *loxClass) *loxClass {
+func newLoxClassWithMetaclass(name string, methods []ast.MethodDecl, env environment, metaclass *loxClass, superclass *loxClass) *loxClass {
+	if superclass != nil {
+		env = env.Child().Define(token.SuperclassIdent, superclass)
+	}
 	methodsByName := make(map[string]*loxFunction, len(methods))
 	gettersByName := make(map[string]*loxFunction, len(methods))
 	settersByName := make(map[string]*loxFunction, len(methods))
@@ -430,6 +488,7 @@ func newLoxClassWithMetaclass(name string, methods []ast.MethodDecl, env environ
 	}
 	class := &loxClass{
 		Name:             name,
+		superclass:       superclass,
 		methodsByName:    methodsByName,
 		propertiesByName: propertiesByName,
 	}
@@ -472,14 +531,32 @@ func (c *loxClass) Call(interpreter *Interpreter, args []loxObject) loxObject {
 	return instance
 }
 
+// GetMethod returns the method with the given name, looking it up through the superclass chain. A property with the
+// same name declared lower in the chain overrides the method.
 func (c *loxClass) GetMethod(name string) (*loxFunction, bool) {
-	method, ok := c.methodsByName[name]
-	return method, ok
+	for class := c; class != nil; class = class.superclass {
+		if method, ok := class.methodsByName[name]; ok {
+			return method, true
+		}
+		if _, ok := class.propertiesByName[name]; ok {
+			return nil, false
+		}
+	}
+	return nil, false
 }
 
+// GetProperty returns the property with the given name, looking it up through the superclass chain. A method with the
+// same name declared lower in the chain overrides the property.
 func (c *loxClass) GetProperty(name string) (*property, bool) {
-	property, ok := c.propertiesByName[name]
-	return property, ok
+	for class := c; class != nil; class = class.superclass {
+		if property, ok := class.propertiesByName[name]; ok {
+			return property, true
+		}
+		if _, ok := class.methodsByName[name]; ok {
+			return nil, false
+		}
+	}
+	return nil, false
 }
 
 type loxInstance struct {
@@ -521,7 +598,7 @@ func (i *loxInstance) Get(interpreter *Interpreter, name ast.Ident) loxObject {
 		return method.Bind(i)
 	}
 
-	panic(lox.NewErrorf(name, "%m object has no property %s", i.Type(), name.Token.Lexeme))
+	panic(lox.NewCodedErrorf(lox.ErrorCodeNoProperty, name, "%m object has no property %s", i.Type(), name.Token.Lexeme))
 }
 
 func (i *loxInstance) Set(interpreter *Interpreter, name ast.Ident, value loxObject) {
@@ -533,6 +610,74 @@ func (i *loxInstance) Set(interpreter *Interpreter, name ast.Ident, value loxObj
 	i.fieldValuesByName[name.Token.Lexeme] = value
 }
 
+// loxModule is a module which has been imported or is built-in. Its properties are the global variables declared by the
+// module.
+type loxModule struct {
+	name    string
+	globals *globalEnvironment
+}
+
+var (
+	_ loxObject = &loxModule{}
+	_ loxGetter = &loxModule{}
+)
+
+// newBuiltinLoxModule returns a built-in module whose properties are the given members.
+func newBuiltinLoxModule(name string, members map[string]loxObject) *loxModule {
+	globals := newGlobalEnvironment()
+	for memberName, member := range members {
+		globals.Define(memberName, member)
+	}
+	return &loxModule{name: name, globals: globals}
+}
+
+func (m *loxModule) String() string {
+	return fmt.Sprintf("[module %s]", m.name)
+}
+
+func (m *loxModule) Type() loxType {
+	return loxTypeModule
+}
+
+func (m *loxModule) Get(interpreter *Interpreter, name ast.Ident) loxObject {
+	if _, ok := builtins[name.Token.Lexeme]; !ok {
+		if value, ok := m.globals.values[name.Token.Lexeme]; ok {
+			if value == nil {
+				panic(lox.NewCodedErrorf(lox.ErrorCodeUndefined, name, "%s has not been defined", name.Token.Lexeme))
+			}
+			return value
+		}
+	}
+	panic(lox.NewCodedErrorf(lox.ErrorCodeNoProperty, name, "module %s has no property %s", m.name, name.Token.Lexeme))
+}
+
+// loxError is a runtime error which has been caught by a try statement.
+type loxError struct {
+	message string
+	// err is the error with the stack trace from where it occurred, which is reported if it isn't caught again.
+	err error
+}
+
+var (
+	_ loxObject = &loxError{}
+	_ loxGetter = &loxError{}
+)
+
+func (e *loxError) String() string {
+	return fmt.Sprintf("[error: %s]", e.message)
+}
+
+func (e *loxError) Type() loxType {
+	return loxTypeError
+}
+
+func (e *loxError) Get(_ *Interpreter, name ast.Ident) loxObject {
+	if name.Token.Lexeme == "message" {
+		return loxString(e.message)
+	}
+	panic(lox.NewCodedErrorf(lox.ErrorCodeNoProperty, name, "%m object has no property %s", e.Type(), name.Token.Lexeme))
+}
+
 // errorMsg is a special object which is returned by the built-in error function. It will be caught by the interpreter
 // and converted into a runtime error.
 type errorMsg string
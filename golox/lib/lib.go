@@ -0,0 +1,115 @@
+// Package lib implements the built-in functions and modules of Lox which are shared by the backends. They operate on Go
+// strings and numbers, so each backend only has to check the types of the arguments and convert its values to and from
+// them.
+package lib
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/marcuscaisey/lox/lox"
+)
+
+// Clock returns the number of seconds since the Unix epoch.
+func Clock() float64 {
+	return float64(time.Now().UnixNano()) / float64(time.Second)
+}
+
+// Integer returns n as an int if it's an integer.
+func Integer(n float64) (int, bool) {
+	if math.IsInf(n, 0) || n != math.Trunc(n) {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// Len returns the number of characters in s.
+func Len(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+// Substring returns the characters of s from start up to but not including end. An error is returned if the range is out
+// of bounds.
+func Substring(s string, start int, end int) (string, error) {
+	runes := []rune(s)
+	if start < 0 || end < start || end > len(runes) {
+		return "", fmt.Errorf("%s() range %d to %d is out of range for string of length %d", lox.BuiltinSubstring, start, end, len(runes))
+	}
+	return string(runes[start:end]), nil
+}
+
+// IndexOf returns the index of the character which the first instance of substr in s starts at, or -1 if substr isn't
+// present in s.
+func IndexOf(s string, substr string) int {
+	i := strings.Index(s, substr)
+	if i == -1 {
+		return -1
+	}
+	// strings.Index returns a byte index but strings are indexed by character.
+	return utf8.RuneCountInString(s[:i])
+}
+
+// CharAt returns the character of s at index. An error is returned if the index is out of bounds.
+func CharAt(s string, index int) (string, error) {
+	runes := []rune(s)
+	if index < 0 || index >= len(runes) {
+		return "", fmt.Errorf("%s() index %d is out of range for string of length %d", lox.BuiltinCharAt, index, len(runes))
+	}
+	return string(runes[index]), nil
+}
+
+// ToUpper returns s with all of its characters mapped to upper case.
+func ToUpper(s string) string {
+	return strings.ToUpper(s)
+}
+
+// ToLower returns s with all of its characters mapped to lower case.
+func ToLower(s string) string {
+	return strings.ToLower(s)
+}
+
+// Trim returns s with its leading and trailing whitespace removed.
+func Trim(s string) string {
+	return strings.TrimSpace(s)
+}
+
+// MathConstants contains the constants of the math module.
+var MathConstants = map[string]float64{
+	"PI": math.Pi,
+	"E":  math.E,
+}
+
+// UnaryMathFunctions contains the functions of the math module which take a single number, named x.
+var UnaryMathFunctions = map[string]func(x float64) float64{
+	"floor": math.Floor,
+	"ceil":  math.Ceil,
+	"abs":   math.Abs,
+	"sqrt":  math.Sqrt,
+}
+
+// BinaryMathFunction is a function of the math module which takes two numbers.
+type BinaryMathFunction struct {
+	Params [2]string
+	Func   func(float64, float64) float64
+}
+
+// BinaryMathFunctions contains the functions of the math module which take two numbers.
+var BinaryMathFunctions = map[string]BinaryMathFunction{
+	"pow": {Params: [2]string{"x", "y"}, Func: math.Pow},
+	"min": {Params: [2]string{"a", "b"}, Func: math.Min},
+	"max": {Params: [2]string{"a", "b"}, Func: math.Max},
+}
+
+// Random returns a random number in the half-open interval [0, 1).
+func Random() float64 {
+	return rand.Float64()
+}
+
+// MathMemberName returns the qualified name of a member of the math module, as it's reported in errors.
+func MathMemberName(name string) string {
+	return lox.BuiltinMath + "." + name
+}
@@ -0,0 +1,85 @@
+package lib
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSubstring(t *testing.T) {
+	tests := []struct {
+		name       string
+		s          string
+		start, end int
+		want       string
+		wantErr    string
+	}{
+		{name: "ASCII", s: "hello", start: 1, end: 3, want: "el"},
+		{name: "MultiByteCharacters", s: "héllo 世界", start: 1, end: 7, want: "éllo 世"},
+		{name: "Empty", s: "hello", start: 2, end: 2, want: ""},
+		{name: "NegativeStart", s: "hello", start: -1, end: 2, wantErr: "substring() range -1 to 2 is out of range for string of length 5"},
+		{name: "EndBeforeStart", s: "hello", start: 3, end: 2, wantErr: "substring() range 3 to 2 is out of range for string of length 5"},
+		{name: "EndAfterLength", s: "世界", start: 0, end: 3, wantErr: "substring() range 0 to 3 is out of range for string of length 2"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Substring(test.s, test.start, test.end)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("Substring(%q, %d, %d) returned error %v, want %q", test.s, test.start, test.end, err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Substring(%q, %d, %d) returned error: %s", test.s, test.start, test.end, err)
+			}
+			if got != test.want {
+				t.Errorf("Substring(%q, %d, %d) = %q, want %q", test.s, test.start, test.end, got, test.want)
+			}
+		})
+	}
+}
+
+func TestCharAt(t *testing.T) {
+	if got, err := CharAt("héllo", 1); err != nil || got != "é" {
+		t.Errorf(`CharAt("héllo", 1) = %q, %v, want "é", nil`, got, err)
+	}
+	wantErr := "charAt() index 5 is out of range for string of length 5"
+	if _, err := CharAt("héllo", 5); err == nil || err.Error() != wantErr {
+		t.Errorf(`CharAt("héllo", 5) returned error %v, want %q`, err, wantErr)
+	}
+}
+
+func TestIndexOf(t *testing.T) {
+	tests := []struct {
+		s, substr string
+		want      int
+	}{
+		{s: "hello", substr: "llo", want: 2},
+		{s: "héllo 世界", substr: "界", want: 7},
+		{s: "hello", substr: "x", want: -1},
+	}
+	for _, test := range tests {
+		if got := IndexOf(test.s, test.substr); got != test.want {
+			t.Errorf("IndexOf(%q, %q) = %d, want %d", test.s, test.substr, got, test.want)
+		}
+	}
+}
+
+func TestInteger(t *testing.T) {
+	tests := []struct {
+		n      float64
+		want   int
+		wantOK bool
+	}{
+		{n: 3, want: 3, wantOK: true},
+		{n: -2, want: -2, wantOK: true},
+		{n: 1.5},
+		{n: math.Inf(1)},
+		{n: math.NaN()},
+	}
+	for _, test := range tests {
+		if got, ok := Integer(test.n); got != test.want || ok != test.wantOK {
+			t.Errorf("Integer(%v) = %d, %t, want %d, %t", test.n, got, ok, test.want, test.wantOK)
+		}
+	}
+}
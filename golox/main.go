@@ -2,73 +2,240 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"path"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/chzyer/readline"
 
 	"github.com/marcuscaisey/lox/golox/interpreter"
+	"github.com/marcuscaisey/lox/golox/vm"
+	"github.com/marcuscaisey/lox/lox"
+	"github.com/marcuscaisey/lox/lox/analysis"
 	"github.com/marcuscaisey/lox/lox/ast"
+	"github.com/marcuscaisey/lox/lox/output"
 	"github.com/marcuscaisey/lox/lox/parser"
+	"github.com/marcuscaisey/lox/lox/token"
 )
 
 var (
-	cmd      = flag.String("c", "", "Program passed in as string")
-	printAST = flag.Bool("p", false, "Print the AST only")
+	cmd           = flag.String("c", "", "Program passed in as string")
+	printAST      = flag.Bool("p", false, "Print the AST only")
+	astFormat     = flag.String("ast-format", string(ast.PrintFormatSExpr), fmt.Sprintf("Format which -p prints the AST in (%s)", joinPrintFormats(", ")))
+	printResolved = flag.Bool("r", false, "Print what each identifier resolves to only")
+	printTokens   = flag.Bool("t", false, "Print the tokens only")
+	backend       = flag.String("backend", backendTree, fmt.Sprintf("Backend which executes the program (%s or %s)", backendTree, backendVM))
+	timeout       = flag.Duration("timeout", 0, "Maximum time that the program can run for, such as 5s (default no limit)")
+	maxCallDepth  = flag.Int("max-call-depth", 1000, "Maximum depth of nested function calls before a stack overflow error")
+	tailCalls     = flag.Bool("tail-calls", false, "Optimise tail calls so that tail recursive functions don't overflow the stack (tree backend only)")
+	noShadowWarns = flag.Bool("no-shadow-warnings", false, "Don't warn about local declarations which shadow one in an enclosing scope")
+	outFlags      = output.RegisterFlags(flag.CommandLine)
 )
 
+const (
+	backendTree = "tree"
+	backendVM   = "vm"
+)
+
+var outFormat output.Format
+
+// sarifLog collects the errors reported during the run if the SARIF format was selected, otherwise it's nil.
+var sarifLog *output.SARIFLog
+
+// runtime executes Lox programs. It's implemented by each backend.
+type runtime interface {
+	InterpretContext(ctx context.Context, program ast.Program) error
+}
+
 // nolint:revive
 func Usage() {
 	fmt.Fprintf(flag.CommandLine.Output(), "Usage: golox [options] [script]\n")
+	fmt.Fprintf(flag.CommandLine.Output(), "       golox [options] bench [bench options] script\n")
+	fmt.Fprintf(flag.CommandLine.Output(), "       golox explain [code]\n")
 	fmt.Fprintf(flag.CommandLine.Output(), "\n")
 	fmt.Fprintf(flag.CommandLine.Output(), "Options:\n")
 	flag.PrintDefaults()
 }
 
 func main() {
-	log.SetFlags(0)
-
 	flag.Usage = Usage
+	outFlags.RegisterSARIFFlag(flag.CommandLine)
 	flag.Parse()
 
-	if *cmd != "" {
-		if err := run(strings.NewReader(*cmd), interpreter.New()); err != nil {
-			log.Fatal(err)
-		}
-		return
+	var err error
+	outFormat, err = outFlags.Format()
+	if err != nil {
+		fmt.Fprintf(flag.CommandLine.Output(), "error: %s\n\n", err)
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *backend != backendTree && *backend != backendVM {
+		fmt.Fprintf(flag.CommandLine.Output(), "error: -backend must be %s or %s\n\n", backendTree, backendVM)
+		flag.Usage()
+		os.Exit(2)
 	}
 
-	switch len(flag.Args()) {
-	case 0:
-		if err := runREPL(); err != nil {
-			log.Fatal(err)
-		}
-	case 1:
-		if err := runFile(flag.Arg(0)); err != nil {
-			log.Fatal(err)
-		}
+	if *tailCalls && *backend != backendTree {
+		fmt.Fprintf(flag.CommandLine.Output(), "error: -tail-calls is only supported by the %s backend\n\n", backendTree)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *maxCallDepth < 1 {
+		fmt.Fprintf(flag.CommandLine.Output(), "error: -max-call-depth must be positive\n\n")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *backend == backendTree && *maxCallDepth > interpreter.MaxCallDepthLimit {
+		fmt.Fprintf(flag.CommandLine.Output(), "error: -max-call-depth must be at most %d with the %s backend\n\n", interpreter.MaxCallDepthLimit, backendTree)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if !slices.Contains(ast.PrintFormats(), ast.PrintFormat(*astFormat)) {
+		fmt.Fprintf(flag.CommandLine.Output(), "error: -ast-format must be one of: %s\n\n", joinPrintFormats(", "))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if outFormat == output.SARIF {
+		sarifLog = output.NewSARIFLog(filepath.Base(os.Args[0]))
+	}
+
+	switch {
+	case flag.Arg(0) == "bench":
+		err = runBench(flag.Args()[1:])
+	case flag.Arg(0) == "explain":
+		runExplain(flag.Args()[1:])
+	case *cmd != "":
+		err = run(strings.NewReader(*cmd), newRuntime(false))
+	case len(flag.Args()) == 0:
+		err = runREPL()
+	case len(flag.Args()) == 1:
+		err = runFile(flag.Arg(0))
 	default:
 		flag.Usage()
 		os.Exit(2)
 	}
+	if err != nil {
+		exitWithErr(err)
+	}
+	printSARIFLog()
 }
 
-func run(r io.Reader, interpreter *interpreter.Interpreter) error {
+func joinPrintFormats(sep string) string {
+	formats := make([]string, len(ast.PrintFormats()))
+	for i, format := range ast.PrintFormats() {
+		formats[i] = string(format)
+	}
+	return strings.Join(formats, sep)
+}
+
+func exitWithErr(err error) {
+	printError(err)
+	printSARIFLog()
+	os.Exit(1)
+}
+
+func printWarnings(warnings lox.Errors) {
+	printError(warnings)
+}
+
+// printError prints an error to stderr in the selected output format. In the SARIF format, it's added to the log which
+// is printed once golox has finished instead.
+func printError(err error) {
+	if sarifLog != nil {
+		sarifLog.Add(err)
+		return
+	}
+	output.PrintError(os.Stderr, outFormat, err)
+}
+
+// printSARIFLog prints the errors reported during the run to stderr as a single SARIF log if the SARIF format was
+// selected.
+func printSARIFLog() {
+	if sarifLog != nil {
+		sarifLog.Print(os.Stderr)
+	}
+}
+
+// newRuntime returns the runtime of the backend selected by the -backend flag.
+func newRuntime(replMode bool) runtime {
+	return newBackendRuntime(*backend, replMode, os.Stdout, printWarnings)
+}
+
+// newBackendRuntime returns the runtime of the given backend. Programs print to stdout and warnings are passed to
+// warningHandler, if it's not nil.
+func newBackendRuntime(backend string, replMode bool, stdout io.Writer, warningHandler func(lox.Errors)) runtime {
+	if backend == backendVM {
+		opts := []vm.Option{vm.WithWarningHandler(warningHandler), vm.WithMaxCallDepth(*maxCallDepth), vm.WithStdout(stdout)}
+		if replMode {
+			opts = append(opts, vm.WithREPLMode())
+		}
+		if *noShadowWarns {
+			opts = append(opts, vm.WithoutShadowingCheck())
+		}
+		return vm.New(opts...)
+	}
+	opts := []interpreter.Option{
+		interpreter.WithWarningHandler(warningHandler),
+		interpreter.WithMaxCallDepth(*maxCallDepth),
+		interpreter.WithStdout(stdout),
+	}
+	if replMode {
+		opts = append(opts, interpreter.WithREPLMode())
+	}
+	if *tailCalls {
+		opts = append(opts, interpreter.WithTailCalls())
+	}
+	if *noShadowWarns {
+		opts = append(opts, interpreter.WithoutShadowingCheck())
+	}
+	return interpreter.New(opts...)
+}
+
+func run(r io.Reader, runtime runtime) error {
+	if *printTokens {
+		return printTokenStream(os.Stdout, outFormat, r)
+	}
 	root, err := parser.Parse(r)
 	if *printAST {
-		ast.Print(root)
+		if printErr := ast.Fprint(os.Stdout, root, ast.PrintFormat(*astFormat)); printErr != nil {
+			return printErr
+		}
 		return err
 	}
 	if err != nil {
 		return err
 	}
-	return interpreter.Interpret(root)
+	if *printResolved {
+		var opts []analysis.ResolveIdentsOption
+		if *noShadowWarns {
+			opts = append(opts, analysis.WithoutShadowingCheck())
+		}
+		identDecls, errs := analysis.ResolveIdents(root, opts...)
+		printResolvedIdents(os.Stdout, outFormat, root, identDecls)
+		return errs.Err()
+	}
+	return interpret(runtime, root)
+}
+
+// interpret executes a program with runtime, stopping it if it runs for longer than the -timeout flag.
+func interpret(runtime runtime, program ast.Program) error {
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	return runtime.InterpretContext(ctx, program)
 }
 
 func runREPL() error {
@@ -91,11 +258,16 @@ func runREPL() error {
 
 	fmt.Fprintln(os.Stderr, "Welcome to the Lox REPL. Press Ctrl-D to exit.")
 
-	interpreter := interpreter.New(interpreter.WithREPLMode())
+	runtime := newRuntime(true)
+	// input contains the lines which have been read since the last complete input was run.
+	var input strings.Builder
 	for {
 		line, err := rl.Readline()
 		if err != nil {
 			if errors.Is(err, readline.ErrInterrupt) {
+				// Discard any incomplete input.
+				input.Reset()
+				rl.SetPrompt(cfg.Prompt)
 				continue
 			}
 			if errors.Is(err, io.EOF) {
@@ -103,19 +275,46 @@ func runREPL() error {
 			}
 			panic(fmt.Sprintf("unexpected error from readline: %s", err))
 		}
-		if err := run(strings.NewReader(line), interpreter); err != nil {
-			fmt.Fprintln(os.Stderr, err)
+		input.WriteString(line)
+		input.WriteString("\n")
+		if hasUnclosedBrackets(input.String()) {
+			rl.SetPrompt("... ")
+			continue
+		}
+		rl.SetPrompt(cfg.Prompt)
+		if err := run(strings.NewReader(input.String()), runtime); err != nil {
+			printError(err)
 		}
+		input.Reset()
 	}
 
 	return nil
 }
 
+// hasUnclosedBrackets reports whether src contains a parenthesis, brace, or string interpolation which hasn't been
+// closed, meaning that it continues onto the next line.
+func hasUnclosedBrackets(src string) bool {
+	l, err := parser.NewLexer(strings.NewReader(src))
+	if err != nil {
+		return false
+	}
+	depth := 0
+	for tok := range l.Tokens() {
+		switch tok.Type {
+		case token.LeftParen, token.LeftBrace, token.StringHead:
+			depth++
+		case token.RightParen, token.RightBrace, token.StringTail:
+			depth--
+		}
+	}
+	return depth > 0
+}
+
 func runFile(name string) error {
 	f, err := os.Open(name)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
-	return run(f, interpreter.New())
+	return run(f, newRuntime(false))
 }
@@ -0,0 +1,127 @@
+// Package module implements the loading of the Lox modules which are imported by programs.
+package module
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/marcuscaisey/lox/lox"
+	"github.com/marcuscaisey/lox/lox/analysis"
+	"github.com/marcuscaisey/lox/lox/ast"
+	"github.com/marcuscaisey/lox/lox/parser"
+)
+
+// Loader loads the modules imported by a program. Each module is only executed the first time that it's imported and
+// later imports of it return the same module.
+type Loader[T any] struct {
+	modules map[string]T
+	// importing contains the absolute paths of the modules which are being imported, outermost first.
+	importing []string
+
+	warningHandler func(lox.Errors)
+	resolveOpts    []analysis.ResolveIdentsOption
+}
+
+// NewLoader returns a loader which calls warningHandler with the warnings found in each module before it's executed.
+// warningHandler may be nil. resolveOpts are passed to [analysis.ResolveIdents] when each module is checked for errors.
+func NewLoader[T any](warningHandler func(lox.Errors), resolveOpts ...analysis.ResolveIdentsOption) *Loader[T] {
+	return &Loader[T]{
+		modules:        map[string]T{},
+		warningHandler: warningHandler,
+		resolveOpts:    resolveOpts,
+	}
+}
+
+// Import returns the module imported by stmt. If the module hasn't been imported before, then it's read, checked for
+// errors, and passed to exec which executes it and returns the resulting module.
+//
+// If the module can't be read, then a [*lox.Error] is returned. If it contains errors, then they're returned as a
+// [lox.Errors]. If it's already being imported, then an import cycle [*lox.Error] is returned.
+func (l *Loader[T]) Import(stmt ast.ImportStmt, exec func(program ast.Program) T) (T, error) {
+	var zero T
+	name := Path(stmt)
+	path, err := filepath.Abs(name)
+	if err != nil {
+		return zero, lox.NewCodedErrorf(lox.ErrorCodeImportFailed, stmt.Path, "cannot import %s: %s", stmt.Path.Lexeme, err)
+	}
+	if module, ok := l.modules[path]; ok {
+		return module, nil
+	}
+
+	if len(l.importing) == 0 {
+		// The program which started importing is the root of any import cycle.
+		if importer := stmt.Import.StartPos.File; importer != nil && importer.Name != "" {
+			if importerPath, err := filepath.Abs(importer.Name); err == nil {
+				l.importing = append(l.importing, importerPath)
+				defer func() { l.importing = l.importing[:0] }()
+			}
+		}
+	}
+	if i := slices.Index(l.importing, path); i != -1 {
+		cycle := make([]string, 0, len(l.importing)-i+1)
+		for _, importingPath := range l.importing[i:] {
+			cycle = append(cycle, filepath.Base(importingPath))
+		}
+		cycle = append(cycle, filepath.Base(path))
+		return zero, lox.NewCodedErrorf(lox.ErrorCodeImportCycle, stmt.Path, "import cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	program, err := l.load(name, stmt)
+	if err != nil {
+		return zero, err
+	}
+
+	l.importing = append(l.importing, path)
+	defer func() { l.importing = l.importing[:len(l.importing)-1] }()
+	module := exec(program)
+	l.modules[path] = module
+	return module, nil
+}
+
+// load reads, parses, and analyses the module at path, which is imported by stmt.
+func (l *Loader[T]) load(path string, stmt ast.ImportStmt) (ast.Program, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ast.Program{}, lox.NewCodedErrorf(lox.ErrorCodeImportFailed, stmt.Path, "cannot import %s: file does not exist", stmt.Path.Lexeme)
+		}
+		return ast.Program{}, lox.NewCodedErrorf(lox.ErrorCodeImportFailed, stmt.Path, "cannot import %s: %s", stmt.Path.Lexeme, err)
+	}
+	defer f.Close()
+
+	program, err := parser.Parse(f)
+	if err != nil {
+		var loxErrs lox.Errors
+		if errors.As(err, &loxErrs) {
+			return ast.Program{}, loxErrs
+		}
+		return ast.Program{}, lox.NewCodedErrorf(lox.ErrorCodeImportFailed, stmt.Path, "cannot import %s: %s", stmt.Path.Lexeme, err)
+	}
+	_, errs := analysis.ResolveIdents(program, append([]analysis.ResolveIdentsOption{analysis.WithModuleMode()}, l.resolveOpts...)...)
+	errs = append(errs, analysis.CheckSemantics(program)...)
+	if err := errs.Err(); err != nil {
+		return ast.Program{}, err
+	}
+	if len(errs) > 0 && l.warningHandler != nil {
+		// Err only returns nil if there are no errors, so everything remaining is a warning.
+		errs.Sort()
+		l.warningHandler(errs)
+	}
+	return program, nil
+}
+
+// Path returns the path of the file imported by stmt. A relative path is resolved relative to the directory of the file
+// containing stmt, or the current directory if stmt wasn't read from a file.
+func Path(stmt ast.ImportStmt) string {
+	path := stmt.Path.Lexeme[1 : len(stmt.Path.Lexeme)-1] // Remove surrounding quotes
+	if filepath.IsAbs(path) {
+		return path
+	}
+	if file := stmt.Import.StartPos.File; file != nil && file.Name != "" {
+		return filepath.Join(filepath.Dir(file.Name), path)
+	}
+	return path
+}
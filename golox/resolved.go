@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/marcuscaisey/lox/lox/ast"
+	"github.com/marcuscaisey/lox/lox/output"
+	"github.com/marcuscaisey/lox/lox/token"
+)
+
+// printResolvedIdents prints to w what each identifier in a program which refers to a variable resolves to, in the
+// order that they appear. An identifier can be a declaration, a reference to a declaration, a reference to a built-in, or
+// unresolved.
+//
+// In the pretty format, each identifier is printed on its own line:
+//
+//	test.lox:1:5: a -> declaration
+//	test.lox:3:7: a -> test.lox:1:5
+//	test.lox:4:7: clock -> built-in
+//	test.lox:5:7: b -> unresolved
+//
+// In the JSON format, each identifier is printed as an object on its own line:
+//
+//	{"declaration":{"column":5,"file":"test.lox","line":1},"kind":"reference","name":"a","start":{"column":7,"file":"test.lox","line":3}}
+func printResolvedIdents(w io.Writer, format output.Format, program ast.Program, identDecls map[ast.Ident]ast.Ident) {
+	for _, ident := range variableIdents(program) {
+		decl, ok := identDecls[ident]
+		var kind string
+		switch {
+		case !ok:
+			kind = "unresolved"
+		case decl == ident:
+			kind = "declaration"
+		case decl.Token.StartPos.File == nil:
+			// Built-ins aren't declared in the source code.
+			kind = "built-in"
+		default:
+			kind = "reference"
+		}
+
+		switch format {
+		case output.Pretty, output.SARIF:
+			resolution := kind
+			if kind == "reference" {
+				resolution = decl.Start().String()
+			}
+			fmt.Fprintf(w, "%s: %s -> %s\n", ident.Start(), ident.Token.Lexeme, resolution)
+		case output.JSON:
+			obj := map[string]any{
+				"name":  ident.Token.Lexeme,
+				"start": jsonPosition(ident.Start()),
+				"kind":  kind,
+			}
+			if kind == "reference" {
+				obj["declaration"] = jsonPosition(decl.Start())
+			}
+			output.PrintJSON(w, obj)
+		}
+	}
+}
+
+// variableIdents returns the identifiers in a program which refer to variables, in the order that they appear.
+// Property and method names are excluded since they aren't resolved.
+func variableIdents(program ast.Program) []ast.Ident {
+	var idents []ast.Ident
+	var visit func(ast.Node) bool
+	visit = func(node ast.Node) bool {
+		switch node := node.(type) {
+		case ast.Ident:
+			if node.Token.Lexeme != token.PlaceholderIdent {
+				idents = append(idents, node)
+			}
+		case ast.MethodDecl:
+			ast.Walk(node.Function, visit)
+			return false
+		case ast.GetExpr:
+			ast.Walk(node.Object, visit)
+			return false
+		case ast.SetExpr:
+			ast.Walk(node.Object, visit)
+			ast.Walk(node.Value, visit)
+			return false
+		}
+		return true
+	}
+	ast.Walk(program, visit)
+	return idents
+}
+
+// jsonPosition returns a JSON representation of a position which matches the one used for diagnostics.
+func jsonPosition(pos token.Position) map[string]any {
+	obj := map[string]any{"line": pos.Line, "column": pos.ColumnUTF16() + 1}
+	if pos.File.Name != "" {
+		obj["file"] = pos.File.Name
+	}
+	return obj
+}
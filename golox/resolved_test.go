@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/marcuscaisey/lox/lox/analysis"
+	"github.com/marcuscaisey/lox/lox/output"
+	"github.com/marcuscaisey/lox/lox/parser"
+)
+
+var update = flag.Bool("update", false, "updates the golden files")
+
+func TestPrintResolvedIdents(t *testing.T) {
+	tests := []struct {
+		name   string
+		format output.Format
+		golden string
+	}{
+		{name: "Pretty", format: output.Pretty, golden: "resolved.golden"},
+		{name: "JSON", format: output.JSON, golden: "resolved.json.golden"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", "resolved.lox"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			program, err := parser.Parse(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The errors are printed separately by run so they're not part of the golden output.
+			identDecls, _ := analysis.ResolveIdents(program)
+
+			var got bytes.Buffer
+			printResolvedIdents(&got, test.format, program, identDecls)
+
+			checkGolden(t, test.golden, got.Bytes())
+		})
+	}
+}
+
+// checkGolden reports an error if got doesn't match the contents of the named golden file in testdata. If the -update
+// flag is set, then the golden file is updated to match got instead.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	goldenPath := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(goldenPath, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("incorrect output (-want +got):\n%s", diff)
+	}
+}
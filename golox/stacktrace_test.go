@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marcuscaisey/lox/lox/ansi"
+	"github.com/marcuscaisey/lox/lox/parser"
+)
+
+func TestStackOverflowStackTrace(t *testing.T) {
+	// Both backends should print the same stack trace.
+	tests := []struct {
+		name    string
+		backend string
+	}{
+		{name: "Tree", backend: backendTree},
+		{name: "VM", backend: backendVM},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", "stack_overflow.lox"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			program, err := parser.Parse(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			runtime := newBackendRuntime(test.backend, false, io.Discard, nil)
+
+			err = runtime.InterpretContext(context.Background(), program)
+			if err == nil {
+				t.Fatal("InterpretContext() returned no error, want stack overflow error")
+			}
+
+			checkGolden(t, "stack_overflow.golden", []byte(ansi.Strip(err.Error())+"\n"))
+		})
+	}
+}
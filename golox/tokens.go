@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/marcuscaisey/lox/lox/output"
+	"github.com/marcuscaisey/lox/lox/parser"
+)
+
+// printTokenStream prints to w the tokens lexed from the source code read from r, including comments and the final
+// EOF token. Any syntax errors encountered while lexing are returned after all of the tokens have been printed.
+//
+// In the pretty format, each token is printed on its own line with its range, type, and lexeme:
+//
+//	test.lox:1:1-1:4: Var "var"
+//	test.lox:1:5-1:6: Ident "x"
+//
+// In the JSON format, each token is printed as an object on its own line:
+//
+//	{"end":{"column":4,"file":"test.lox","line":1},"lexeme":"var","start":{"column":1,"file":"test.lox","line":1},"type":"Var"}
+func printTokenStream(w io.Writer, format output.Format, r io.Reader) error {
+	l, err := parser.NewLexer(r, parser.WithCommentTokens())
+	if err != nil {
+		return err
+	}
+	for tok := range l.Tokens() {
+		switch format {
+		case output.Pretty, output.SARIF:
+			fmt.Fprintf(w, "%s-%d:%d: %s %q\n", tok.StartPos, tok.EndPos.Line, tok.EndPos.ColumnWidth(), tok.Type, tok.Lexeme)
+		case output.JSON:
+			output.PrintJSON(w, map[string]any{
+				"type":   tok.Type.String(),
+				"lexeme": tok.Lexeme,
+				"start":  jsonPosition(tok.StartPos),
+				"end":    jsonPosition(tok.EndPos),
+			})
+		}
+	}
+	return l.Err()
+}
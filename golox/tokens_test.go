@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marcuscaisey/lox/lox/output"
+)
+
+func TestPrintTokenStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		format output.Format
+		golden string
+	}{
+		{name: "Pretty", format: output.Pretty, golden: "tokens.golden"},
+		{name: "JSON", format: output.JSON, golden: "tokens.json.golden"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", "tokens.lox"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			var got bytes.Buffer
+			if err := printTokenStream(&got, test.format, f); err != nil {
+				t.Fatalf("printTokenStream() returned error: %s", err)
+			}
+
+			checkGolden(t, test.golden, got.Bytes())
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/marcuscaisey/lox/golox/lib"
+	"github.com/marcuscaisey/lox/lox"
+)
+
+var builtins = map[string]value{
+	lox.BuiltinClock: &builtin{name: lox.BuiltinClock, fun: func([]value) value {
+		return number(lib.Clock())
+	}},
+	lox.BuiltinType: &builtin{name: lox.BuiltinType, params: []string{"object"}, fun: func(args []value) value {
+		return str(args[0].Type())
+	}},
+	lox.BuiltinError: &builtin{name: lox.BuiltinError, params: []string{"msg"}, fun: func(args []value) value {
+		return errorMsg(args[0].String())
+	}},
+	lox.BuiltinLen: &builtin{name: lox.BuiltinLen, params: []string{"s"}, fun: func(args []value) value {
+		s, errMsg := stringArg(lox.BuiltinLen, "s", args[0])
+		if errMsg != nil {
+			return errMsg
+		}
+		return number(lib.Len(string(s)))
+	}},
+	lox.BuiltinSubstring: &builtin{name: lox.BuiltinSubstring, params: []string{"s", "start", "end"}, fun: func(args []value) value {
+		s, errMsg := stringArg(lox.BuiltinSubstring, "s", args[0])
+		if errMsg != nil {
+			return errMsg
+		}
+		start, errMsg := integerArg(lox.BuiltinSubstring, "start", args[1])
+		if errMsg != nil {
+			return errMsg
+		}
+		end, errMsg := integerArg(lox.BuiltinSubstring, "end", args[2])
+		if errMsg != nil {
+			return errMsg
+		}
+		return stringResult(lib.Substring(string(s), start, end))
+	}},
+	lox.BuiltinIndexOf: &builtin{name: lox.BuiltinIndexOf, params: []string{"s", "substr"}, fun: func(args []value) value {
+		s, errMsg := stringArg(lox.BuiltinIndexOf, "s", args[0])
+		if errMsg != nil {
+			return errMsg
+		}
+		substr, errMsg := stringArg(lox.BuiltinIndexOf, "substr", args[1])
+		if errMsg != nil {
+			return errMsg
+		}
+		return number(lib.IndexOf(string(s), string(substr)))
+	}},
+	lox.BuiltinCharAt: &builtin{name: lox.BuiltinCharAt, params: []string{"s", "index"}, fun: func(args []value) value {
+		s, errMsg := stringArg(lox.BuiltinCharAt, "s", args[0])
+		if errMsg != nil {
+			return errMsg
+		}
+		index, errMsg := integerArg(lox.BuiltinCharAt, "index", args[1])
+		if errMsg != nil {
+			return errMsg
+		}
+		return stringResult(lib.CharAt(string(s), index))
+	}},
+	lox.BuiltinToUpper: stringFunction(lox.BuiltinToUpper, lib.ToUpper),
+	lox.BuiltinToLower: stringFunction(lox.BuiltinToLower, lib.ToLower),
+	lox.BuiltinTrim:    stringFunction(lox.BuiltinTrim, lib.Trim),
+	lox.BuiltinMath:    &builtinModule{name: lox.BuiltinMath, members: mathMembers()},
+}
+
+// mathMembers returns the members of the built-in math module.
+func mathMembers() map[string]value {
+	members := map[string]value{
+		"random": &builtin{name: lib.MathMemberName("random"), fun: func([]value) value {
+			return number(lib.Random())
+		}},
+	}
+	for name, constant := range lib.MathConstants {
+		members[name] = number(constant)
+	}
+	for name, f := range lib.UnaryMathFunctions {
+		fun := lib.MathMemberName(name)
+		members[name] = &builtin{name: fun, params: []string{"x"}, fun: func(args []value) value {
+			x, errMsg := numberArg(fun, "x", args[0])
+			if errMsg != nil {
+				return errMsg
+			}
+			return number(f(float64(x)))
+		}}
+	}
+	for name, f := range lib.BinaryMathFunctions {
+		fun := lib.MathMemberName(name)
+		members[name] = &builtin{name: fun, params: f.Params[:], fun: func(args []value) value {
+			a, errMsg := numberArg(fun, f.Params[0], args[0])
+			if errMsg != nil {
+				return errMsg
+			}
+			b, errMsg := numberArg(fun, f.Params[1], args[1])
+			if errMsg != nil {
+				return errMsg
+			}
+			return number(f.Func(float64(a), float64(b)))
+		}}
+	}
+	return members
+}
+
+// stringFunction returns a built-in function which applies f to its string argument s.
+func stringFunction(name string, f func(string) string) value {
+	return &builtin{name: name, params: []string{"s"}, fun: func(args []value) value {
+		s, errMsg := stringArg(name, "s", args[0])
+		if errMsg != nil {
+			return errMsg
+		}
+		return str(f(string(s)))
+	}}
+}
+
+// stringResult returns s, or an error message if err is not nil.
+func stringResult(s string, err error) value {
+	if err != nil {
+		return errorMsg(err.Error())
+	}
+	return str(s)
+}
+
+// stringArg returns arg if it's a string. Otherwise, it returns an error message describing the invalid argument param
+// of the built-in function fun.
+func stringArg(fun string, param string, arg value) (str, value) {
+	s, ok := arg.(str)
+	if !ok {
+		return "", errorMsg(fmt.Sprintf("%s() expects %s to be a %m but got %m", fun, param, valueTypeString, arg.Type()))
+	}
+	return s, nil
+}
+
+// numberArg returns arg if it's a number. Otherwise, it returns an error message describing the invalid argument param
+// of the built-in function fun.
+func numberArg(fun string, param string, arg value) (number, value) {
+	n, ok := arg.(number)
+	if !ok {
+		return 0, errorMsg(fmt.Sprintf("%s() expects %s to be a %m but got %m", fun, param, valueTypeNumber, arg.Type()))
+	}
+	return n, nil
+}
+
+// integerArg returns arg as an int if it's an integer number. Otherwise, it returns an error message describing the
+// invalid argument param of the built-in function fun.
+func integerArg(fun string, param string, arg value) (int, value) {
+	n, errMsg := numberArg(fun, param, arg)
+	if errMsg != nil {
+		return 0, errMsg
+	}
+	i, ok := lib.Integer(float64(n))
+	if !ok {
+		return 0, errorMsg(fmt.Sprintf("%s() expects %s to be an integer but got %s", fun, param, n))
+	}
+	return i, nil
+}
@@ -0,0 +1,341 @@
+package vm
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/marcuscaisey/lox/golox/compiler"
+	"github.com/marcuscaisey/lox/lox"
+	"github.com/marcuscaisey/lox/lox/ast"
+	"github.com/marcuscaisey/lox/lox/token"
+)
+
+// valueType is the string representation of a Lox value's type.
+type valueType string
+
+const (
+	valueTypeNumber   valueType = "number"
+	valueTypeString   valueType = "string"
+	valueTypeBool     valueType = "bool"
+	valueTypeNil      valueType = "nil"
+	valueTypeFunction valueType = "function"
+	valueTypeModule   valueType = "module"
+	valueTypeError    valueType = "error"
+)
+
+// Format implements fmt.Formatter. All verbs have the default behaviour, except for 'm' (message) which formats the
+// type for use in an error message.
+func (t valueType) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'm':
+		fmt.Fprintf(f, "'%s'", t)
+	default:
+		fmt.Fprintf(f, fmt.FormatString(f, verb), string(t))
+	}
+}
+
+// value is a Lox value. A nil value is the value of a variable which has been declared but not defined.
+type value interface {
+	String() string
+	Type() valueType
+}
+
+type number float64
+
+// String formats the number in its shortest decimal representation. Negative zero is formatted as 0 and the special
+// values are formatted as Inf, -Inf, and NaN.
+func (n number) String() string {
+	switch {
+	case n == 0:
+		// This also matches negative zero.
+		return "0"
+	case math.IsInf(float64(n), 1):
+		return "Inf"
+	default:
+		return strconv.FormatFloat(float64(n), 'f', -1, 64)
+	}
+}
+
+func (n number) Type() valueType { return valueTypeNumber }
+
+type str string
+
+func (s str) String() string  { return string(s) }
+func (s str) Type() valueType { return valueTypeString }
+
+type boolean bool
+
+func (b boolean) String() string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+func (b boolean) Type() valueType { return valueTypeBool }
+
+type nilValue struct{}
+
+func (nilValue) String() string  { return "nil" }
+func (nilValue) Type() valueType { return valueTypeNil }
+
+func isTruthy(v value) bool {
+	switch v := v.(type) {
+	case number:
+		return v != 0
+	case str:
+		return v != ""
+	case boolean:
+		return bool(v)
+	case nilValue:
+		return false
+	default:
+		return true
+	}
+}
+
+// upvalue is a variable captured by a closure. It refers to a stack slot while the variable is still on the stack
+// (open) and holds the variable's value once it's been popped (closed).
+type upvalue struct {
+	slot   int
+	open   bool
+	closed value
+}
+
+type closure struct {
+	fun      *compiler.Function
+	upvalues []*upvalue
+	class    *class       // Class that the method was declared in, used to look up super, or nil if not a method
+	module   *moduleValue // Module that the function was declared in, whose globals it refers to
+}
+
+func (c *closure) String() string {
+	return fmt.Sprintf("[function %s]", c.fun.Name)
+}
+
+func (c *closure) Type() valueType { return valueTypeFunction }
+
+type boundMethod struct {
+	receiver *instance
+	method   *closure
+}
+
+func (m *boundMethod) String() string {
+	return fmt.Sprintf("[bound method %s]", m.method.fun.Name)
+}
+
+func (m *boundMethod) Type() valueType { return valueTypeFunction }
+
+type builtin struct {
+	name   string
+	params []string
+	fun    func(args []value) value
+}
+
+func (b *builtin) String() string {
+	return fmt.Sprintf("[builtin function %s]", b.name)
+}
+
+func (b *builtin) Type() valueType { return valueTypeFunction }
+
+type property struct {
+	getter *closure
+	setter *closure // nil if the property is read-only
+}
+
+type class struct {
+	*instance        // instance of the metaclass or nil for the metaclass itself
+	name             string
+	superclass       *class // nil if the class doesn't have a superclass
+	methodsByName    map[string]*closure
+	propertiesByName map[string]*property
+}
+
+// newClass creates a class without any methods. superclass is nil if the class doesn't have a superclass.
+func newClass(name string, superclass *class) *class {
+	// Static methods are inherited from the superclass's metaclass.
+	var metaSuperclass *class
+	if superclass != nil {
+		metaSuperclass = superclass.instance.class
+	}
+	metaclass := newClassWithMetaclass(fmt.Sprintf("%s class", name), nil, metaSuperclass)
+	return newClassWithMetaclass(name, metaclass, superclass)
+}
+
+func newClassWithMetaclass(name string, metaclass *class, superclass *class) *class {
+	c := &class{
+		name:             name,
+		superclass:       superclass,
+		methodsByName:    map[string]*closure{},
+		propertiesByName: map[string]*property{},
+	}
+	if metaclass != nil {
+		c.instance = newInstance(metaclass)
+	}
+	return c
+}
+
+func (c *class) String() string {
+	return fmt.Sprintf("[class %s]", c.name)
+}
+
+// callableName returns the name of the class when called, which is the name of its constructor if it has one.
+func (c *class) callableName() string {
+	if init, ok := c.getMethod(token.ConstructorIdent); ok {
+		return init.fun.Name
+	}
+	return c.name
+}
+
+// getMethod returns the method with the given name, looking it up through the superclass chain. A property with the
+// same name declared lower in the chain overrides the method.
+func (c *class) getMethod(name string) (*closure, bool) {
+	for class := c; class != nil; class = class.superclass {
+		if method, ok := class.methodsByName[name]; ok {
+			return method, true
+		}
+		if _, ok := class.propertiesByName[name]; ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// getProperty returns the property with the given name, looking it up through the superclass chain. A method with
+// the same name declared lower in the chain overrides the property.
+func (c *class) getProperty(name string) (*property, bool) {
+	for class := c; class != nil; class = class.superclass {
+		if property, ok := class.propertiesByName[name]; ok {
+			return property, true
+		}
+		if _, ok := class.methodsByName[name]; ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+type instance struct {
+	class             *class
+	fieldValuesByName map[string]value
+}
+
+func newInstance(class *class) *instance {
+	return &instance{
+		class:             class,
+		fieldValuesByName: map[string]value{},
+	}
+}
+
+func (i *instance) String() string {
+	return fmt.Sprintf("[%s object]", i.class.name)
+}
+
+func (i *instance) Type() valueType {
+	return valueType(i.class.name)
+}
+
+// moduleValue is a program or a module which it imports. Each module is compiled separately and has its own global
+// variables.
+type moduleValue struct {
+	name     string // Empty for the program which is being executed
+	compiler *compiler.Compiler
+	globals  []global
+}
+
+// newModuleValue returns a module whose programs are compiled by c.
+func newModuleValue(name string, c *compiler.Compiler) *moduleValue {
+	m := &moduleValue{name: name, compiler: c}
+	for _, name := range c.GlobalNames() {
+		m.globals = append(m.globals, global{declared: true, value: builtins[name]})
+	}
+	return m
+}
+
+func (m *moduleValue) String() string {
+	return fmt.Sprintf("[module %s]", m.name)
+}
+
+func (m *moduleValue) Type() valueType { return valueTypeModule }
+
+// compile compiles a program and allocates the global variables which it refers to.
+func (m *moduleValue) compile(program ast.Program) *compiler.Function {
+	fun := m.compiler.Compile(program)
+	for len(m.globals) < len(m.compiler.GlobalNames()) {
+		m.globals = append(m.globals, global{})
+	}
+	return fun
+}
+
+// global returns the global variable declared by the module with the given name. Built-ins are excluded.
+func (m *moduleValue) global(name string) (global, bool) {
+	index := slices.Index(m.compiler.GlobalNames(), name)
+	if index < len(lox.AllBuiltins) || !m.globals[index].declared {
+		return global{}, false
+	}
+	return m.globals[index], true
+}
+
+// builtinModule is a module which is built-in to the language. Its properties are its members.
+type builtinModule struct {
+	name    string
+	members map[string]value
+}
+
+func (m *builtinModule) String() string {
+	return fmt.Sprintf("[module %s]", m.name)
+}
+
+func (m *builtinModule) Type() valueType { return valueTypeModule }
+
+// iterator iterates over the elements of a value in a for-in loop. It's stored in a local variable which can't be
+// referred to by name, so it's never visible to programs.
+type iterator struct {
+	elements []value
+	next     int // Index of the next element
+}
+
+func (it *iterator) String() string  { return "[iterator]" }
+func (it *iterator) Type() valueType { return "iterator" }
+
+// iterableElements returns the elements of v which are iterated over by a for-in loop and reports whether v is
+// iterable. The elements of a string are its characters.
+func iterableElements(v value) ([]value, bool) {
+	switch v := v.(type) {
+	case str:
+		var elements []value
+		for _, r := range v {
+			elements = append(elements, str(r))
+		}
+		return elements, true
+	default:
+		return nil, false
+	}
+}
+
+// errorValue is a runtime error which has been caught by a try statement.
+type errorValue struct {
+	message string
+	// err is the error with the stack trace from where it occurred, which is reported if it isn't caught again.
+	err error
+}
+
+func (e *errorValue) String() string {
+	return fmt.Sprintf("[error: %s]", e.message)
+}
+
+func (e *errorValue) Type() valueType { return valueTypeError }
+
+// errorMsg is a special value which is returned by the built-in error function. It will be caught by the VM and
+// converted into a runtime error.
+type errorMsg string
+
+func (errorMsg) String() string {
+	panic("errorMsg is not a real value")
+}
+
+func (errorMsg) Type() valueType {
+	panic("errorMsg is not a real value")
+}
@@ -0,0 +1,933 @@
+// Package vm implements a virtual machine which executes Lox programs compiled to bytecode by package compiler.
+package vm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/marcuscaisey/lox/golox/callstack"
+	"github.com/marcuscaisey/lox/golox/compiler"
+	"github.com/marcuscaisey/lox/golox/module"
+	"github.com/marcuscaisey/lox/lox"
+	"github.com/marcuscaisey/lox/lox/analysis"
+	"github.com/marcuscaisey/lox/lox/ast"
+	"github.com/marcuscaisey/lox/lox/token"
+)
+
+// defaultMaxCallDepth is the maximum depth of nested Lox function calls if WithMaxCallDepth isn't passed to New.
+const defaultMaxCallDepth = 1000
+
+// VM is a virtual machine which executes Lox programs.
+type VM struct {
+	main         *moduleValue
+	modules      *module.Loader[*moduleValue]
+	stack        []value
+	frames       []frame
+	openUpvalues []*upvalue // Sorted by slot
+	handlers     []handler  // Innermost last
+	out          *bufio.Writer
+
+	// ctx is the context passed to InterpretContext, or nil if a program isn't being executed.
+	ctx context.Context
+	// steps is the number of steps taken by the program being executed.
+	steps int
+
+	replMode       bool
+	warningHandler func(lox.Errors)
+	maxCallDepth   int
+	maxSteps       int
+	resolveOpts    []analysis.ResolveIdentsOption // Passed to analysis.ResolveIdents for programs and modules
+}
+
+type global struct {
+	declared bool
+	value    value // nil if the global has been declared but not defined
+}
+
+// frame is the call frame of a function which is being executed.
+type frame struct {
+	closure *closure
+	ip      int
+	base    int         // Stack index of the frame's slot 0
+	call    token.Range // Where the function was called from
+	result  value       // Value returned from the frame instead of the function's return value or nil
+}
+
+// handler is the handler of a try statement which is being executed.
+type handler struct {
+	frame    int // Index of the frame executing the try statement
+	stackLen int // Height of the stack when the try statement began
+	ip       int // Instruction which handles an error
+}
+
+// Option can be passed to New to configure the VM.
+type Option func(*VM)
+
+// WithREPLMode configures the VM to run in REPL mode.
+// In REPL mode, the VM prints the result of expression statements.
+func WithREPLMode() Option {
+	return func(vm *VM) {
+		vm.replMode = true
+	}
+}
+
+// WithWarningHandler configures the VM to call handler with the warnings found in a program before executing it.
+// Warnings don't prevent a program from being executed.
+func WithWarningHandler(handler func(warnings lox.Errors)) Option {
+	return func(vm *VM) {
+		vm.warningHandler = handler
+	}
+}
+
+// WithMaxCallDepth configures the maximum depth of nested Lox function calls. Exceeding it results in a stack overflow
+// error. Calls to built-in functions don't count towards the depth.
+func WithMaxCallDepth(depth int) Option {
+	return func(vm *VM) {
+		vm.maxCallDepth = depth
+	}
+}
+
+// WithMaxSteps configures the maximum number of steps that a program can take each time that it's executed, where a
+// step is a function call or a loop iteration. Exceeding it results in an error which the program can't catch. This
+// guards against programs which never finish. By default, there's no limit.
+func WithMaxSteps(steps int) Option {
+	return func(vm *VM) {
+		vm.maxSteps = steps
+	}
+}
+
+// WithStdout configures the writer which print statements write to. By default, they write to [os.Stdout].
+func WithStdout(w io.Writer) Option {
+	return func(vm *VM) {
+		vm.out = bufio.NewWriter(w)
+	}
+}
+
+// WithoutShadowingCheck disables the warning about local declarations which shadow one declared in an enclosing scope,
+// both in programs and the modules which they import.
+func WithoutShadowingCheck() Option {
+	return func(vm *VM) {
+		vm.resolveOpts = append(vm.resolveOpts, analysis.WithoutShadowingCheck())
+	}
+}
+
+// New constructs a new VM with the given options.
+func New(opts ...Option) *VM {
+	vm := &VM{
+		out:          bufio.NewWriter(os.Stdout),
+		maxCallDepth: defaultMaxCallDepth,
+	}
+	for _, opt := range opts {
+		opt(vm)
+	}
+	var compilerOpts []compiler.Option
+	if vm.replMode {
+		compilerOpts = append(compilerOpts, compiler.WithREPLMode())
+	}
+	vm.main = newModuleValue("", compiler.New(compilerOpts...))
+	vm.modules = module.NewLoader[*moduleValue](vm.warningHandler, vm.resolveOpts...)
+	return vm
+}
+
+// Interpret compiles and executes a program and returns an error if one occurred.
+// Interpret can be called multiple times with different ASTs and the state will be maintained between calls.
+func (vm *VM) Interpret(program ast.Program) error {
+	return vm.InterpretContext(context.Background(), program)
+}
+
+// InterpretContext is like Interpret but stops executing the program once ctx is done. This is checked before each
+// function call and loop iteration. The error returned reports where execution stopped and wraps ctx.Err(). The program
+// can't catch this error.
+func (vm *VM) InterpretContext(ctx context.Context, program ast.Program) error {
+	opts := slices.Clone(vm.resolveOpts)
+	if vm.replMode {
+		opts = append(opts, analysis.WithREPLMode())
+	}
+	_, errs := analysis.ResolveIdents(program, opts...)
+	errs = append(errs, analysis.CheckSemantics(program)...)
+	if err := errs.Err(); err != nil {
+		return err
+	}
+	if len(errs) > 0 && vm.warningHandler != nil {
+		// Err only returns nil if there are no errors, so everything remaining is a warning.
+		errs.Sort()
+		vm.warningHandler(errs)
+	}
+	vm.ctx = ctx
+	vm.steps = 0
+	defer func() { vm.ctx = nil }()
+	return vm.run(vm.main.compile(program))
+}
+
+func (vm *VM) run(fun *compiler.Function) (err error) {
+	defer vm.out.Flush()
+	defer func() {
+		if r := recover(); r != nil {
+			if loxErr, ok := r.(*lox.Error); ok {
+				err = vm.stackTraceError(loxErr)
+				vm.reset()
+			} else if thrownErr, ok := r.(*errorValue); ok {
+				// The error was caught and then thrown again, so it already has the stack trace from where it occurred.
+				err = thrownErr.err
+				vm.reset()
+			} else if loxErrs, ok := r.(lox.Errors); ok {
+				// An imported module contained errors, so it was never executed.
+				err = loxErrs
+				vm.reset()
+			} else if interrupted, ok := r.(interruption); ok {
+				err = &interruptedError{err: vm.stackTraceError(interrupted.err), cause: interrupted.cause}
+				vm.reset()
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	script := &closure{fun: fun, module: vm.main}
+	vm.push(script)
+	vm.frames = append(vm.frames, frame{closure: script})
+	vm.execute(0)
+	return nil
+}
+
+// interruption is panicked with when execution is stopped early, either because the context passed to InterpretContext
+// is done or because the step limit has been exceeded. It's a separate type from the runtime errors so that it can't be
+// caught by a try statement.
+type interruption struct {
+	err   *lox.Error // Reports where execution stopped
+	cause error      // The context's error if it's done, otherwise nil
+}
+
+// interruptedError is returned when execution is stopped early. It wraps both the runtime error which reports where
+// execution stopped and the context's error if it's done.
+type interruptedError struct {
+	err   error
+	cause error
+}
+
+func (e *interruptedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *interruptedError) Unwrap() []error {
+	if e.cause == nil {
+		return []error{e.err}
+	}
+	return []error{e.err, e.cause}
+}
+
+// step counts a function call or loop iteration towards the step limit and checks whether the context passed to
+// InterpretContext is done. node is the call or loop.
+func (vm *VM) step(node ast.Node) {
+	if err := vm.ctx.Err(); err != nil {
+		panic(interruption{err: &lox.Error{Msg: "execution cancelled", Start: node.Start(), End: node.End()}, cause: err})
+	}
+	if vm.maxSteps == 0 {
+		return
+	}
+	vm.steps++
+	if vm.steps > vm.maxSteps {
+		msg := fmt.Sprintf("step limit of %d exceeded", vm.maxSteps)
+		panic(interruption{err: &lox.Error{Msg: msg, Start: node.Start(), End: node.End()}})
+	}
+}
+
+// stackTraceError returns err with the stack trace of the calls which led to it appended, if there are any.
+func (vm *VM) stackTraceError(err *lox.Error) error {
+	if len(vm.frames) <= 1 {
+		return err
+	}
+	callStack := callstack.New()
+	for _, frame := range vm.frames[1:] {
+		callStack.Push(frame.closure.fun.Name, frame.call.Start())
+	}
+	callStack.Push("", err.Start)
+	return fmt.Errorf("%w\n\n%s", err, callStack.StackTrace())
+}
+
+// reset clears the state left behind by a runtime error.
+func (vm *VM) reset() {
+	vm.stack = vm.stack[:0]
+	vm.frames = vm.frames[:0]
+	vm.openUpvalues = vm.openUpvalues[:0]
+	vm.handlers = vm.handlers[:0]
+}
+
+func (vm *VM) push(v value) {
+	vm.stack = append(vm.stack, v)
+}
+
+func (vm *VM) pop() value {
+	v := vm.stack[len(vm.stack)-1]
+	vm.stack = vm.stack[:len(vm.stack)-1]
+	return v
+}
+
+func (vm *VM) peek() value {
+	return vm.stack[len(vm.stack)-1]
+}
+
+// execute executes instructions until the function of the current frame returns, leaving depth frames on the frame
+// stack. The function's return value isn't pushed. A runtime error which occurs inside a try statement executed by one
+// of the frames above depth is handled by it.
+func (vm *VM) execute(depth int) {
+	for !vm.executeUntilCaught(depth) {
+	}
+}
+
+// executeUntilCaught is like execute but stops after a runtime error has been handled by a try statement, so that
+// execution can resume from its handler. It reports whether the function of the current frame returned.
+func (vm *VM) executeUntilCaught(depth int) (returned bool) {
+	defer func() {
+		if r := recover(); r != nil && !vm.catch(r, depth) {
+			panic(r)
+		}
+	}()
+	vm.executeInstructions(depth)
+	return true
+}
+
+// catch handles a value recovered from a panic with the innermost try statement if it's a runtime error and the
+// statement is being executed by one of the frames above depth. It reports whether the value was handled.
+func (vm *VM) catch(r any, depth int) bool {
+	if len(vm.handlers) == 0 || vm.handlers[len(vm.handlers)-1].frame < depth {
+		return false
+	}
+	var caught *errorValue
+	switch r := r.(type) {
+	case *lox.Error:
+		caught = &errorValue{message: r.Msg, err: vm.stackTraceError(r)}
+	case *errorValue:
+		caught = r
+	default:
+		return false
+	}
+	h := vm.handlers[len(vm.handlers)-1]
+	vm.handlers = vm.handlers[:len(vm.handlers)-1]
+	vm.closeUpvalues(h.stackLen)
+	vm.stack = vm.stack[:h.stackLen]
+	vm.frames = vm.frames[:h.frame+1]
+	vm.frames[h.frame].ip = h.ip
+	vm.push(caught)
+	return true
+}
+
+// executeInstructions executes instructions like execute but doesn't handle runtime errors.
+func (vm *VM) executeInstructions(depth int) {
+	fr := &vm.frames[len(vm.frames)-1]
+	code := fr.closure.fun.Code
+	for {
+		ins := &code[fr.ip]
+		fr.ip++
+		switch ins.Op {
+		case compiler.OpConstant:
+			switch c := fr.closure.fun.Constants[ins.Arg].(type) {
+			case float64:
+				vm.push(number(c))
+			case string:
+				vm.push(str(c))
+			default:
+				panic(fmt.Sprintf("unexpected constant type: %T", c))
+			}
+		case compiler.OpNil:
+			vm.push(nilValue{})
+		case compiler.OpTrue:
+			vm.push(boolean(true))
+		case compiler.OpFalse:
+			vm.push(boolean(false))
+		case compiler.OpUndefined:
+			vm.push(nil)
+		case compiler.OpPop:
+			vm.pop()
+		case compiler.OpPopN:
+			vm.stack = vm.stack[:len(vm.stack)-ins.Arg]
+		case compiler.OpCopy:
+			vm.push(vm.stack[len(vm.stack)-1-ins.Arg])
+		case compiler.OpSwap:
+			top := len(vm.stack) - 1
+			vm.stack[top-1], vm.stack[top] = vm.stack[top], vm.stack[top-1]
+
+		case compiler.OpGetLocal:
+			v := vm.stack[fr.base+ins.Arg]
+			if v == nil {
+				panic(notDefinedError(ins.Node))
+			}
+			vm.push(v)
+		case compiler.OpSetLocal:
+			vm.stack[fr.base+ins.Arg] = vm.peek()
+		case compiler.OpGetUpvalue:
+			v := vm.upvalueValue(fr.closure.upvalues[ins.Arg])
+			if v == nil {
+				panic(notDefinedError(ins.Node))
+			}
+			vm.push(v)
+		case compiler.OpSetUpvalue:
+			if u := fr.closure.upvalues[ins.Arg]; u.open {
+				vm.stack[u.slot] = vm.peek()
+			} else {
+				u.closed = vm.peek()
+			}
+		case compiler.OpDeclareGlobal:
+			declareGlobal(fr.closure.module, ins.Arg, ins.Node, nil)
+		case compiler.OpDefineGlobal:
+			declareGlobal(fr.closure.module, ins.Arg, ins.Node, vm.pop())
+		case compiler.OpGetGlobal:
+			g := fr.closure.module.globals[ins.Arg]
+			if !g.declared {
+				panic(notDeclaredError(fr.closure.module, ins.Node))
+			}
+			if g.value == nil {
+				panic(notDefinedError(ins.Node))
+			}
+			vm.push(g.value)
+		case compiler.OpSetGlobal:
+			g := &fr.closure.module.globals[ins.Arg]
+			if !g.declared {
+				panic(notDeclaredError(fr.closure.module, ins.Node))
+			}
+			g.value = vm.peek()
+
+		case compiler.OpGetProperty:
+			vm.getProperty(fr.closure.fun.Constants[ins.Arg].(string), ins.Node.(ast.GetExpr))
+		case compiler.OpSetProperty:
+			vm.setProperty(fr.closure.fun.Constants[ins.Arg].(string), ins.Node.(ast.SetExpr))
+		case compiler.OpGetSuper:
+			vm.getSuper(fr.closure.class.superclass, fr.closure.fun.Constants[ins.Arg].(string), ins.Node.(ast.SuperExpr))
+
+		case compiler.OpEqual:
+			right := vm.pop()
+			vm.stack[len(vm.stack)-1] = boolean(vm.peek() == right)
+		case compiler.OpNotEqual:
+			right := vm.pop()
+			vm.stack[len(vm.stack)-1] = boolean(vm.peek() != right)
+		case compiler.OpLess, compiler.OpLessEqual, compiler.OpGreater, compiler.OpGreaterEqual,
+			compiler.OpAdd, compiler.OpSubtract, compiler.OpMultiply, compiler.OpDivide, compiler.OpIntegerDivide, compiler.OpModulo:
+			right := vm.pop()
+			vm.stack[len(vm.stack)-1] = binaryOp(ins.Op, vm.peek(), right, ins.Node)
+		case compiler.OpConcat:
+			var b strings.Builder
+			for _, v := range vm.stack[len(vm.stack)-ins.Arg:] {
+				b.WriteString(v.String())
+			}
+			vm.stack = vm.stack[:len(vm.stack)-ins.Arg]
+			vm.push(str(b.String()))
+		case compiler.OpNot:
+			vm.stack[len(vm.stack)-1] = boolean(!isTruthy(vm.peek()))
+		case compiler.OpNegate:
+			vm.stack[len(vm.stack)-1] = negate(vm.peek(), ins.Node)
+		case compiler.OpIncrement:
+			vm.stack[len(vm.stack)-1] = increment(vm.peek(), ins.Arg, ins.Node)
+
+		case compiler.OpPrint:
+			fmt.Fprintln(vm.out, vm.pop().String())
+
+		case compiler.OpJump:
+			if ins.Arg < fr.ip {
+				vm.step(ins.Node)
+			}
+			fr.ip = ins.Arg
+		case compiler.OpJumpIfFalse:
+			if !isTruthy(vm.peek()) {
+				fr.ip = ins.Arg
+			}
+		case compiler.OpJumpIfTrue:
+			if isTruthy(vm.peek()) {
+				fr.ip = ins.Arg
+			}
+		case compiler.OpIterator:
+			elements, ok := iterableElements(vm.peek())
+			if !ok {
+				panic(lox.NewCodedErrorf(lox.ErrorCodeNotIterable, ins.Node, "iteration is not valid for %m object", vm.peek().Type()))
+			}
+			vm.stack[len(vm.stack)-1] = &iterator{elements: elements}
+		case compiler.OpIterNext:
+			it := vm.peek().(*iterator)
+			if it.next == len(it.elements) {
+				fr.ip = ins.Arg
+			} else {
+				vm.push(it.elements[it.next])
+				it.next++
+			}
+
+		case compiler.OpTry:
+			vm.handlers = append(vm.handlers, handler{frame: len(vm.frames) - 1, stackLen: len(vm.stack), ip: ins.Arg})
+		case compiler.OpEndTry:
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+		case compiler.OpThrow:
+			v := vm.pop()
+			if caught, ok := v.(*errorValue); ok {
+				panic(caught)
+			}
+			panic(lox.NewCodedError(lox.ErrorCodeUncaughtError, ins.Node, v.String()))
+
+		case compiler.OpCall:
+			vm.step(ins.Node)
+			vm.call(ins.Arg, ins.Node)
+		case compiler.OpClosure:
+			vm.pushClosure(fr, fr.closure.fun.Constants[ins.Arg].(*compiler.Function))
+		case compiler.OpCloseUpvalues:
+			vm.closeUpvalues(fr.base + ins.Arg)
+		case compiler.OpReturn:
+			result := vm.pop()
+			vm.closeUpvalues(fr.base)
+			// Returning from inside a try statement ends it.
+			for len(vm.handlers) > 0 && vm.handlers[len(vm.handlers)-1].frame == len(vm.frames)-1 {
+				vm.handlers = vm.handlers[:len(vm.handlers)-1]
+			}
+			if fr.result != nil {
+				result = fr.result
+			}
+			vm.stack = vm.stack[:fr.base]
+			vm.frames = vm.frames[:len(vm.frames)-1]
+			if len(vm.frames) == depth {
+				return
+			}
+			vm.push(result)
+
+		case compiler.OpImport:
+			vm.importModule(ins.Node.(ast.ImportStmt))
+
+		case compiler.OpClass:
+			vm.pushClass(ins.Node.(ast.ClassDecl))
+		case compiler.OpMethod:
+			vm.addMethod(ins.Node.(ast.MethodDecl))
+
+		default:
+			panic(fmt.Sprintf("unexpected op: %s", ins.Op))
+		}
+
+		// The frame changes when a function is called or returns.
+		if fr != &vm.frames[len(vm.frames)-1] {
+			fr = &vm.frames[len(vm.frames)-1]
+			code = fr.closure.fun.Code
+		}
+	}
+}
+
+// notDeclaredError returns the error for an identifier which hasn't been declared in m, suggesting a declared global
+// which is spelt similarly to it if there is one.
+func notDeclaredError(m *moduleValue, node ast.Node) error {
+	ident := node.(ast.Ident)
+	declaredNames := func(yield func(string) bool) {
+		for i, name := range m.compiler.GlobalNames() {
+			if m.globals[i].declared && !yield(name) {
+				return
+			}
+		}
+	}
+	if similar := analysis.SimilarName(ident.Token.Lexeme, declaredNames); similar != "" {
+		return lox.NewCodedErrorf(lox.ErrorCodeUndeclared, ident, "%s has not been declared, did you mean %s?", ident.Token.Lexeme, similar)
+	}
+	return lox.NewCodedErrorf(lox.ErrorCodeUndeclared, ident, "%s has not been declared", ident.Token.Lexeme)
+}
+
+func notDefinedError(node ast.Node) error {
+	ident := node.(ast.Ident)
+	return lox.NewCodedErrorf(lox.ErrorCodeUndefined, ident, "%s has not been defined", ident.Token.Lexeme)
+}
+
+func declareGlobal(m *moduleValue, index int, node ast.Node, v value) {
+	g := &m.globals[index]
+	if g.declared {
+		ident := node.(ast.Ident)
+		panic(lox.NewCodedErrorf(lox.ErrorCodeRedeclared, ident, "%s has already been declared", ident.Token.Lexeme))
+	}
+	g.declared = true
+	g.value = v
+}
+
+// importModule pushes the module imported by stmt, executing it first if it hasn't been imported before.
+func (vm *VM) importModule(stmt ast.ImportStmt) {
+	m, err := vm.modules.Import(stmt, func(program ast.Program) *moduleValue {
+		m := newModuleValue(stmt.Name.Token.Lexeme, compiler.New())
+		fun := m.compile(program)
+		fun.Name = filepath.Base(module.Path(stmt))
+		script := &closure{fun: fun, module: m}
+		vm.push(script)
+		vm.pushFrame(script, 0, stmt)
+		vm.execute(len(vm.frames) - 1)
+		return m
+	})
+	if err != nil {
+		panic(err)
+	}
+	vm.push(m)
+}
+
+func (vm *VM) upvalueValue(u *upvalue) value {
+	if u.open {
+		return vm.stack[u.slot]
+	}
+	return u.closed
+}
+
+func (vm *VM) pushClosure(fr *frame, fun *compiler.Function) {
+	c := &closure{fun: fun, upvalues: make([]*upvalue, len(fun.Upvalues)), module: fr.closure.module}
+	vm.push(c)
+	for i, u := range fun.Upvalues {
+		if u.IsLocal {
+			c.upvalues[i] = vm.captureUpvalue(fr.base + u.Index)
+		} else {
+			c.upvalues[i] = fr.closure.upvalues[u.Index]
+		}
+	}
+}
+
+func (vm *VM) captureUpvalue(slot int) *upvalue {
+	i := len(vm.openUpvalues) - 1
+	for ; i >= 0 && vm.openUpvalues[i].slot >= slot; i-- {
+		if vm.openUpvalues[i].slot == slot {
+			return vm.openUpvalues[i]
+		}
+	}
+	u := &upvalue{slot: slot, open: true}
+	vm.openUpvalues = append(vm.openUpvalues, nil)
+	copy(vm.openUpvalues[i+2:], vm.openUpvalues[i+1:])
+	vm.openUpvalues[i+1] = u
+	return u
+}
+
+// closeUpvalues closes the open upvalues which refer to the given stack slot and above.
+func (vm *VM) closeUpvalues(slot int) {
+	i := len(vm.openUpvalues)
+	for i > 0 && vm.openUpvalues[i-1].slot >= slot {
+		u := vm.openUpvalues[i-1]
+		u.closed = vm.stack[u.slot]
+		u.open = false
+		i--
+	}
+	vm.openUpvalues = vm.openUpvalues[:i]
+}
+
+func (vm *VM) pushClass(decl ast.ClassDecl) {
+	var superclass *class
+	if decl.Superclass != nil {
+		v := vm.pop()
+		var ok bool
+		if superclass, ok = v.(*class); !ok {
+			panic(lox.NewCodedErrorf(lox.ErrorCodeNotAClass, decl.Superclass, "%m object is not a class", v.Type()))
+		}
+	}
+	vm.push(newClass(decl.Name.Token.Lexeme, superclass))
+}
+
+func (vm *VM) addMethod(decl ast.MethodDecl) {
+	method := vm.pop().(*closure)
+	class := vm.peek().(*class)
+	if decl.HasModifier(token.Static) {
+		class = class.instance.class
+	}
+	method.class = class
+	name := decl.Name.Token.Lexeme
+	switch {
+	case decl.HasModifier(token.Get), decl.HasModifier(token.Set):
+		p, ok := class.propertiesByName[name]
+		if !ok {
+			p = &property{}
+			class.propertiesByName[name] = p
+		}
+		if decl.HasModifier(token.Get) {
+			p.getter = method
+		} else {
+			p.setter = method
+		}
+	default:
+		class.methodsByName[name] = method
+	}
+}
+
+// call calls the callee below the top argc values on the stack. node is the [ast.CallExpr] that the call was compiled
+// from, which is only converted when an error is reported.
+func (vm *VM) call(argc int, node ast.Node) {
+	calleeIndex := len(vm.stack) - 1 - argc
+	switch callee := vm.stack[calleeIndex].(type) {
+	case *closure:
+		checkArity(callee.fun.Name, callee.fun.Params, argc, node)
+		vm.pushFrame(callee, argc, node)
+	case *boundMethod:
+		checkArity(callee.method.fun.Name, callee.method.fun.Params, argc, node)
+		vm.stack[calleeIndex] = callee.receiver
+		vm.pushFrame(callee.method, argc, node)
+	case *class:
+		init, hasInit := callee.getMethod(token.ConstructorIdent)
+		var params []string
+		if hasInit {
+			params = init.fun.Params
+		}
+		checkArity(callee.callableName(), params, argc, node)
+		vm.checkCallDepth(node)
+		vm.stack[calleeIndex] = newInstance(callee)
+		if hasInit {
+			vm.pushFrame(init, argc, node)
+		}
+	case *builtin:
+		checkArity(callee.name, callee.params, argc, node)
+		result := callee.fun(vm.stack[calleeIndex+1:])
+		if errorMsg, ok := result.(errorMsg); ok {
+			panic(lox.NewCodedError(lox.ErrorCodeBuiltinError, node, string(errorMsg)))
+		}
+		vm.stack = vm.stack[:calleeIndex]
+		vm.push(result)
+	default:
+		panic(lox.NewCodedErrorf(lox.ErrorCodeNotCallable, node.(ast.CallExpr).Callee, "%m object is not callable", callee.Type()))
+	}
+}
+
+func checkArity(name string, params []string, argc int, node ast.Node) {
+	arity := len(params)
+	if argc == arity {
+		return
+	}
+	expr := node.(ast.CallExpr)
+	switch {
+	case argc < arity:
+		argumentSuffix := ""
+		if arity-argc > 1 {
+			argumentSuffix = "s"
+		}
+		missingArgs := params[argc:]
+		var missingArgsStr string
+		switch len(missingArgs) {
+		case 1:
+			missingArgsStr = missingArgs[0]
+		case 2:
+			missingArgsStr = missingArgs[0] + " and " + missingArgs[1]
+		default:
+			missingArgsStr = strings.Join(missingArgs[:len(missingArgs)-1], ", ") + ", and " + missingArgs[len(missingArgs)-1]
+		}
+		panic(lox.NewCodedErrorf(lox.ErrorCodeWrongArgumentCount, expr, "%s() missing %d argument%s: %s", name, arity-argc, argumentSuffix, missingArgsStr))
+	case argc > arity:
+		panic(lox.NewCodedErrorf(lox.ErrorCodeWrongArgumentCount, expr.Args[arity:], "%s() accepts %d arguments but %d were given", name, arity, argc))
+	}
+}
+
+func (vm *VM) checkCallDepth(rang token.Range) {
+	// The frame of the top-level code doesn't count towards the depth.
+	if len(vm.frames)-1 >= vm.maxCallDepth {
+		panic(lox.NewCodedErrorf(lox.ErrorCodeStackOverflow, rang, "stack overflow: maximum call depth %d exceeded", vm.maxCallDepth))
+	}
+}
+
+// pushFrame calls a closure whose arguments are on top of the stack, preceded by the callee or receiver.
+func (vm *VM) pushFrame(c *closure, argc int, rang token.Range) {
+	vm.checkCallDepth(rang)
+	vm.frames = append(vm.frames, frame{
+		closure: c,
+		base:    len(vm.stack) - 1 - argc,
+		call:    rang,
+	})
+}
+
+func receiverInstance(v value) (*instance, bool) {
+	switch v := v.(type) {
+	case *instance:
+		return v, true
+	case *class:
+		return v.instance, true
+	default:
+		return nil, false
+	}
+}
+
+func (vm *VM) getProperty(name string, expr ast.GetExpr) {
+	if e, ok := vm.peek().(*errorValue); ok {
+		if name != "message" {
+			panic(lox.NewCodedErrorf(lox.ErrorCodeNoProperty, expr.Name, "%m object has no property %s", e.Type(), name))
+		}
+		vm.stack[len(vm.stack)-1] = str(e.message)
+		return
+	}
+	if m, ok := vm.peek().(*moduleValue); ok {
+		g, ok := m.global(name)
+		if !ok {
+			panic(lox.NewCodedErrorf(lox.ErrorCodeNoProperty, expr.Name, "module %s has no property %s", m.name, name))
+		}
+		if g.value == nil {
+			panic(lox.NewCodedErrorf(lox.ErrorCodeUndefined, expr.Name, "%s has not been defined", name))
+		}
+		vm.stack[len(vm.stack)-1] = g.value
+		return
+	}
+	if m, ok := vm.peek().(*builtinModule); ok {
+		v, ok := m.members[name]
+		if !ok {
+			panic(lox.NewCodedErrorf(lox.ErrorCodeNoProperty, expr.Name, "module %s has no property %s", m.name, name))
+		}
+		vm.stack[len(vm.stack)-1] = v
+		return
+	}
+	inst, ok := receiverInstance(vm.peek())
+	if !ok {
+		panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidPropertyAccess, expr, "property access is not valid for %m object", vm.peek().Type()))
+	}
+	top := len(vm.stack) - 1
+	if property, ok := inst.class.getProperty(name); ok {
+		vm.stack[top] = inst
+		vm.pushFrame(property.getter, 0, expr.Name)
+		return
+	}
+	if v, ok := inst.fieldValuesByName[name]; ok {
+		vm.stack[top] = v
+		return
+	}
+	if method, ok := inst.class.getMethod(name); ok {
+		vm.stack[top] = &boundMethod{receiver: inst, method: method}
+		return
+	}
+	panic(lox.NewCodedErrorf(lox.ErrorCodeNoProperty, expr.Name, "%m object has no property %s", inst.Type(), name))
+}
+
+func (vm *VM) setProperty(name string, expr ast.SetExpr) {
+	v := vm.peek()
+	objectIndex := len(vm.stack) - 2
+	inst, ok := receiverInstance(vm.stack[objectIndex])
+	if !ok {
+		panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidPropertyAccess, expr, "property assignment is not valid for %m object", vm.stack[objectIndex].Type()))
+	}
+	if property, ok := inst.class.getProperty(name); ok {
+		if property.setter == nil {
+			panic(lox.NewCodedErrorf(lox.ErrorCodeReadOnlyProperty, expr.Name, "property '%s' of %m object is read-only", name, inst.Type()))
+		}
+		vm.stack[objectIndex] = inst
+		vm.pushFrame(property.setter, 1, expr.Name)
+		// The result of an assignment is the assigned value, whatever the setter returns.
+		vm.frames[len(vm.frames)-1].result = v
+		return
+	}
+	inst.fieldValuesByName[name] = v
+	vm.stack[objectIndex] = v
+	vm.pop()
+}
+
+func (vm *VM) getSuper(superclass *class, name string, expr ast.SuperExpr) {
+	inst := vm.peek().(*instance)
+	if property, ok := superclass.getProperty(name); ok {
+		vm.pushFrame(property.getter, 0, expr.Method)
+		return
+	}
+	if method, ok := superclass.getMethod(name); ok {
+		vm.stack[len(vm.stack)-1] = &boundMethod{receiver: inst, method: method}
+		return
+	}
+	panic(lox.NewCodedErrorf(lox.ErrorCodeNoProperty, expr.Method, "superclass %m has no property %s", valueType(superclass.name), name))
+}
+
+func negate(v value, node ast.Node) value {
+	if n, ok := v.(number); ok {
+		return -n
+	}
+	expr := node.(ast.UnaryExpr)
+	switch v.(type) {
+	case nilValue:
+		panic(lox.NewCodedErrorf(lox.ErrorCodeNilOperand, expr.Right, "operand of %m operator is nil", expr.Op.Type))
+	default:
+		panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidOperand, expr.Op, "%m operator cannot be used with type %m", expr.Op.Type, v.Type()))
+	}
+}
+
+func increment(v value, delta int, node ast.Node) value {
+	if n, ok := v.(number); ok {
+		return n + number(delta)
+	}
+	expr := node.(ast.IncrementExpr)
+	switch v.(type) {
+	case nilValue:
+		panic(lox.NewCodedErrorf(lox.ErrorCodeNilOperand, expr.Operand, "operand of %m operator is nil", expr.Op.Type))
+	default:
+		panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidOperand, expr.Op, "%m operator cannot be used with type %m", expr.Op.Type, v.Type()))
+	}
+}
+
+// binaryOp applies a binary operation to its operands. node is only converted to the [ast.BinaryExpr] that it was
+// compiled from when an error is reported, since this is on the hot path.
+func binaryOp(op compiler.Op, left, right value, node ast.Node) value {
+	switch left := left.(type) {
+	case number:
+		switch right := right.(type) {
+		case number:
+			switch op {
+			case compiler.OpMultiply:
+				return left * right
+			case compiler.OpDivide:
+				if right == 0 {
+					panic(lox.NewCodedError(lox.ErrorCodeDivisionByZero, node.(ast.BinaryExpr).Op, "cannot divide by 0"))
+				}
+				return left / right
+			case compiler.OpIntegerDivide:
+				if right == 0 {
+					panic(lox.NewCodedError(lox.ErrorCodeDivisionByZero, node.(ast.BinaryExpr).Op, "cannot divide by 0"))
+				}
+				return number(math.Trunc(float64(left / right)))
+			case compiler.OpModulo:
+				if right == 0 {
+					panic(lox.NewCodedError(lox.ErrorCodeDivisionByZero, node.(ast.BinaryExpr).Op, "cannot modulo by 0"))
+				}
+				return number(math.Mod(float64(left), float64(right)))
+			case compiler.OpAdd:
+				return left + right
+			case compiler.OpSubtract:
+				return left - right
+			case compiler.OpLess:
+				return boolean(left < right)
+			case compiler.OpLessEqual:
+				return boolean(left <= right)
+			case compiler.OpGreater:
+				return boolean(left > right)
+			case compiler.OpGreaterEqual:
+				return boolean(left >= right)
+			default:
+			}
+		case str:
+			if op == compiler.OpMultiply {
+				return numberTimesString(left, node.(ast.BinaryExpr).Op, right)
+			}
+		}
+	case str:
+		switch right := right.(type) {
+		case str:
+			switch op {
+			case compiler.OpAdd:
+				return left + right
+			case compiler.OpLess:
+				return boolean(left < right)
+			case compiler.OpLessEqual:
+				return boolean(left <= right)
+			case compiler.OpGreater:
+				return boolean(left > right)
+			case compiler.OpGreaterEqual:
+				return boolean(left >= right)
+			default:
+			}
+		case number:
+			if op == compiler.OpMultiply {
+				return numberTimesString(right, node.(ast.BinaryExpr).Op, left)
+			}
+		}
+	}
+	expr := node.(ast.BinaryExpr)
+	// A nil operand is usually caused by a missing value rather than a value of the wrong type, so it's reported
+	// separately.
+	if _, ok := left.(nilValue); ok {
+		panic(lox.NewCodedErrorf(lox.ErrorCodeNilOperand, expr.Left, "left operand of %m operator is nil", expr.Op.Type))
+	}
+	if _, ok := right.(nilValue); ok {
+		panic(lox.NewCodedErrorf(lox.ErrorCodeNilOperand, expr.Right, "right operand of %m operator is nil", expr.Op.Type))
+	}
+	panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidOperand, expr.Op, "%m operator cannot be used with types %m and %m", expr.Op.Type, left.Type(), right.Type()))
+}
+
+func numberTimesString(n number, op token.Token, s str) str {
+	if math.Floor(float64(n)) != float64(n) {
+		panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidOperand, op, "cannot multiply %m by non-integer %m", valueTypeString, valueTypeNumber))
+	}
+	if n < 0 {
+		panic(lox.NewCodedErrorf(lox.ErrorCodeInvalidOperand, op, "cannot multiply %m by negative %m", valueTypeString, valueTypeNumber))
+	}
+	return str(strings.Repeat(string(s), int(n)))
+}
@@ -0,0 +1,205 @@
+package vm_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcuscaisey/lox/golox/interpreter"
+	"github.com/marcuscaisey/lox/golox/vm"
+	"github.com/marcuscaisey/lox/lox"
+	"github.com/marcuscaisey/lox/lox/ast"
+	"github.com/marcuscaisey/lox/lox/parser"
+)
+
+const benchmarkProgram = `
+fun fib(n) {
+    if (n < 2) {
+        return n;
+    }
+    return fib(n - 1) + fib(n - 2);
+}
+
+class Point {
+    init(x, y) {
+        this.x = x;
+        this.y = y;
+    }
+
+    add(other) {
+        return Point(this.x + other.x, this.y + other.y);
+    }
+}
+
+fib(20);
+
+var p = Point(0, 0);
+for (var i = 0; i < 10000; i = i + 1) {
+    p = p.add(Point(i, -i));
+}
+`
+
+// BenchmarkInterpret compares the VM against the tree-walking interpreter.
+func BenchmarkInterpret(b *testing.B) {
+	program, err := parser.Parse(strings.NewReader(benchmarkProgram))
+	if err != nil {
+		b.Fatal(err)
+	}
+	backends := []struct {
+		name string
+		new  func() interface{ Interpret(ast.Program) error }
+	}{
+		{name: "Tree", new: func() interface{ Interpret(ast.Program) error } { return interpreter.New() }},
+		{name: "VM", new: func() interface{ Interpret(ast.Program) error } { return vm.New() }},
+	}
+	for _, backend := range backends {
+		b.Run(backend.name, func(b *testing.B) {
+			for range b.N {
+				if err := backend.new().Interpret(program); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestInterpretContextCancelled(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{name: "WhileLoop", src: "while (true) {}"},
+		{name: "ForLoopWithContinue", src: "for (;;) { continue; }"},
+		{name: "CaughtInsideTry", src: "try { while (true) {} } catch (_) { print 1; }"},
+		{name: "LoopInFunction", src: "fun spin() { while (true) {} }\nspin();"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			program, err := parser.Parse(strings.NewReader(test.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			err = vm.New().InterpretContext(ctx, program)
+
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("InterpretContext() = %v, want %v", err, context.DeadlineExceeded)
+			}
+			if want := "execution cancelled"; err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("InterpretContext() = %v, want error containing %q", err, want)
+			}
+		})
+	}
+}
+
+func TestMaxSteps(t *testing.T) {
+	program, err := parser.Parse(strings.NewReader(`fun count(n) {
+    var total = 0;
+    for (var i = 0; i < n; i++) {
+        total = total + i;
+    }
+    return total;
+}
+var total = count(3);
+try {
+    while (true) {}
+} catch (_) {
+    print "caught";
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Calling count and its three loop iterations take the first four steps, so the while loop exceeds the limit on
+	// its seventh iteration.
+	const maxSteps = 10
+	backends := []struct {
+		name string
+		new  func() interface{ Interpret(ast.Program) error }
+	}{
+		{name: "Tree", new: func() interface{ Interpret(ast.Program) error } {
+			return interpreter.New(interpreter.WithMaxSteps(maxSteps))
+		}},
+		{name: "VM", new: func() interface{ Interpret(ast.Program) error } { return vm.New(vm.WithMaxSteps(maxSteps)) }},
+	}
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			err := backend.new().Interpret(program)
+
+			if want := "10:5: error: step limit of 10 exceeded"; err == nil || !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("Interpret() = %v, want error starting with %q", err, want)
+			}
+		})
+	}
+}
+
+func TestWithoutShadowingCheck(t *testing.T) {
+	dir := t.TempDir()
+	const lib = `fun value() {
+    var x = 1;
+    var y = 0;
+    {
+        var x = 2;
+        y = x;
+    }
+    return x + y;
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "lib.lox"), []byte(lib), 0644); err != nil {
+		t.Fatal(err)
+	}
+	const main = `import "lib.lox";
+var x = 1;
+var y = 0;
+{
+    var x = lib.value();
+    y = x;
+}
+y = x + y;
+`
+	mainPath := filepath.Join(dir, "main.lox")
+	if err := os.WriteFile(mainPath, []byte(main), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(mainPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	program, err := parser.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var warnings lox.Errors
+	handleWarnings := func(errs lox.Errors) { warnings = append(warnings, errs...) }
+	backends := []struct {
+		name string
+		new  func() interface{ Interpret(ast.Program) error }
+	}{
+		{name: "Tree", new: func() interface{ Interpret(ast.Program) error } {
+			return interpreter.New(interpreter.WithWarningHandler(handleWarnings), interpreter.WithoutShadowingCheck())
+		}},
+		{name: "VM", new: func() interface{ Interpret(ast.Program) error } {
+			return vm.New(vm.WithWarningHandler(handleWarnings), vm.WithoutShadowingCheck())
+		}},
+	}
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			warnings = nil
+
+			if err := backend.new().Interpret(program); err != nil {
+				t.Fatalf("Interpret() returned error: %s", err)
+			}
+
+			if len(warnings) > 0 {
+				t.Errorf("Interpret() reported warnings %q, want none", warnings)
+			}
+		})
+	}
+}
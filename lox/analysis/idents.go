@@ -1,7 +1,10 @@
 package analysis
 
 import (
+	"fmt"
 	"iter"
+	"slices"
+	"strings"
 
 	"github.com/marcuscaisey/lox/lox"
 	"github.com/marcuscaisey/lox/lox/ast"
@@ -13,7 +16,8 @@ import (
 type ResolveIdentsOption func(*identResolver)
 
 // WithREPLMode configures identifiers to be resolved in REPL mode.
-// In REPL mode, the following identifier checks are disabled:
+// In REPL mode, the following checks are disabled:
+//   - expression statement results are used
 //   - declared and never used
 //   - declared more than once in the same scope
 //   - used before they are declared
@@ -23,6 +27,68 @@ func WithREPLMode() ResolveIdentsOption {
 	}
 }
 
+// WithoutUnusedCheck disables the check that identifiers are not declared and never used.
+func WithoutUnusedCheck() ResolveIdentsOption {
+	return func(i *identResolver) {
+		i.unusedCheckDisabled = true
+	}
+}
+
+// WithModuleMode configures identifiers to be resolved as those of a module which is imported by another program.
+// The global declarations of a module can be accessed by the program which imports it, so they aren't reported as
+// unused.
+func WithModuleMode() ResolveIdentsOption {
+	return func(i *identResolver) {
+		i.moduleMode = true
+	}
+}
+
+// WithBuiltinShadowingCheck enables the check that identifiers declared outside of the global scope don't shadow a
+// built-in. The errors reported by this check have the code [lox.ErrorCodeShadowedBuiltin].
+func WithBuiltinShadowingCheck() ResolveIdentsOption {
+	return func(i *identResolver) {
+		i.builtinShadowingCheckEnabled = true
+	}
+}
+
+// WithUnusedResultCheck enables the check that the results of expression statements which have no side effects are
+// used. The warnings reported by this check have the code [lox.ErrorCodeUnusedResult].
+func WithUnusedResultCheck() ResolveIdentsOption {
+	return func(i *identResolver) {
+		i.unusedResultCheckEnabled = true
+	}
+}
+
+// WithoutShadowingCheck disables the check that identifiers declared in a local scope don't shadow one declared in an
+// enclosing scope.
+func WithoutShadowingCheck() ResolveIdentsOption {
+	return func(i *identResolver) {
+		i.shadowingCheckDisabled = true
+	}
+}
+
+// WithBuiltins declares additional built-ins in the global scope, such as functions provided by a program which
+// embeds an interpreter. They're treated the same as the built-ins of the language.
+func WithBuiltins(names ...string) ResolveIdentsOption {
+	return func(i *identResolver) {
+		for _, name := range names {
+			if !slices.Contains(i.builtins, name) {
+				i.builtins = append(i.builtins, name)
+			}
+		}
+	}
+}
+
+// WithoutBuiltins removes built-ins from the global scope, such as those which a program embedding an interpreter has
+// disabled. Using one of them is reported as using an undeclared identifier.
+func WithoutBuiltins(names ...string) ResolveIdentsOption {
+	return func(i *identResolver) {
+		i.builtins = slices.DeleteFunc(i.builtins, func(builtin string) bool {
+			return slices.Contains(names, builtin)
+		})
+	}
+}
+
 // ResolveIdents resolves the identifiers in a program to their declarations.
 // It returns a map from identifiers to the identifier which declares them. If an error is returned then a possibly
 // incomplete map will still be returned along with it.
@@ -43,12 +109,17 @@ func WithREPLMode() ResolveIdentsOption {
 //	}
 //
 // This function also checks that identifiers are not:
-//   - declared and never used
+//   - declared and never used (reported as a warning with the code [lox.ErrorCodeUnusedDeclaration])
 //   - declared more than once in the same scope
+//   - declared in a local scope with the same name as an identifier in an enclosing scope (reported as a warning with
+//     the code [lox.ErrorCodeShadowedDeclaration])
 //   - used before they are declared (best effort for globals)
-//   - used and not declared (best effort for globals)
+//   - used and not declared (best effort for globals, reported with the code [lox.ErrorCodeUndeclared])
 //   - used before they are defined (best effort for globals)
 //
+// If enabled with [WithUnusedResultCheck], it also checks that the results of expression statements which have no side
+// effects are used (reported as a warning with the code [lox.ErrorCodeUnusedResult]).
+//
 // Some checks are best effort for global identifiers as it's not always possible to (easily) determine how they're used
 // without running the program. For example, in the following example, whether the program is valid depends on whether
 // the global variable x is defined before printX is called.
@@ -72,11 +143,21 @@ type identResolver struct {
 	forwardDeclaredGlobals map[string]bool
 	inFun                  bool
 	funScopeLevel          int
+	// initialisingLocals contains the names of the local variables whose initialisers are being resolved.
+	initialisingLocals []string
+	// paramsOf is the name of the function whose parameters are being declared, if any.
+	paramsOf string
 
 	identDecls map[ast.Ident]ast.Ident
 	errs       lox.Errors
 
-	replMode bool
+	builtins                     []string
+	replMode                     bool
+	moduleMode                   bool
+	unusedCheckDisabled          bool
+	builtinShadowingCheckEnabled bool
+	unusedResultCheckEnabled     bool
+	shadowingCheckDisabled       bool
 }
 
 func newIdentResolver(program ast.Program, opts ...ResolveIdentsOption) *identResolver {
@@ -85,6 +166,7 @@ func newIdentResolver(program ast.Program, opts ...ResolveIdentsOption) *identRe
 		scopes:                 stack.New[scope](),
 		forwardDeclaredGlobals: map[string]bool{},
 		identDecls:             map[ast.Ident]ast.Ident{},
+		builtins:               slices.Clone(lox.AllBuiltins),
 	}
 	for _, opt := range opts {
 		opt(r)
@@ -120,6 +202,8 @@ func (r *identResolver) readGlobalIdents(program ast.Program) map[string]ast.Ide
 			ident = stmt.Name
 		case ast.VarDecl:
 			ident = stmt.Name
+		case ast.ImportStmt:
+			ident = stmt.Name
 		default:
 			continue
 		}
@@ -129,7 +213,7 @@ func (r *identResolver) readGlobalIdents(program ast.Program) map[string]ast.Ide
 }
 
 func (r *identResolver) declareBuiltins(scope scope) {
-	for _, name := range lox.AllBuiltins {
+	for _, name := range r.builtins {
 		scope.DeclareName(name)
 		scope.Define(name)
 		scope.Use(name) // We don't want to raise unused declaration errors for builtins.
@@ -151,13 +235,21 @@ type decl struct {
 // scope represents a lexical scope and keeps track of the identifiers declared in that scope
 type scope struct {
 	decls            map[string]*decl
-	undeclaredUsages map[string][]ast.Ident
+	undeclaredUsages map[string][]undeclaredUsage
+}
+
+// undeclaredUsage is a use of an identifier which hadn't been declared when it was resolved.
+type undeclaredUsage struct {
+	Ident ast.Ident
+	// Similar is the name of a declaration which was visible where the identifier was used and is spelt similarly to
+	// it, or "" if there wasn't one.
+	Similar string
 }
 
 func newScope() scope {
 	return scope{
 		decls:            map[string]*decl{},
-		undeclaredUsages: map[string][]ast.Ident{},
+		undeclaredUsages: map[string][]undeclaredUsage{},
 	}
 }
 
@@ -193,9 +285,10 @@ func (s scope) Use(name string) {
 	s.decls[name].Status |= declStatusUsed
 }
 
-// UseUndeclared marks an undeclared identifier as used in the scope.
-func (s scope) UseUndeclared(ident ast.Ident) {
-	s.undeclaredUsages[ident.Token.Lexeme] = append(s.undeclaredUsages[ident.Token.Lexeme], ident)
+// UseUndeclared marks an undeclared identifier as used in the scope. similar is the name of a visible declaration which
+// is spelt similarly to it, or "" if there isn't one.
+func (s scope) UseUndeclared(ident ast.Ident, similar string) {
+	s.undeclaredUsages[ident.Token.Lexeme] = append(s.undeclaredUsages[ident.Token.Lexeme], undeclaredUsage{Ident: ident, Similar: similar})
 }
 
 // IsDeclared reports whether the identifier has been declared in the scope.
@@ -209,11 +302,12 @@ func (s scope) IsDefined(name string) bool {
 	return s.decls[name].Status&declStatusDefined != 0
 }
 
-// UnusedIdents returns an iterator over the identifiers in the scope that have been declared but not used.
+// UnusedIdents returns an iterator over the identifiers in the scope that have been declared but not used. Identifiers
+// starting with an underscore are excluded, since they're expected to be unused.
 func (s scope) UnusedIdents() iter.Seq[ast.Ident] {
 	return func(yield func(ast.Ident) bool) {
 		for _, decl := range s.decls {
-			if decl.Status&declStatusUsed == 0 {
+			if decl.Status&declStatusUsed == 0 && !strings.HasPrefix(decl.Ident.Token.Lexeme, "_") {
 				if !yield(decl.Ident) {
 					return
 				}
@@ -222,12 +316,13 @@ func (s scope) UnusedIdents() iter.Seq[ast.Ident] {
 	}
 }
 
-// UndeclaredIdents returns an iterator over the identifiers in the scope that were used before they were declared.
-func (s scope) UndeclaredUsages() iter.Seq[ast.Ident] {
-	return func(yield func(ast.Ident) bool) {
-		for _, idents := range s.undeclaredUsages {
-			for _, ident := range idents {
-				if !yield(ident) {
+// UndeclaredUsages returns an iterator over the uses of identifiers in the scope that were used before they were
+// declared.
+func (s scope) UndeclaredUsages() iter.Seq[undeclaredUsage] {
+	return func(yield func(undeclaredUsage) bool) {
+		for _, usages := range s.undeclaredUsages {
+			for _, usage := range usages {
+				if !yield(usage) {
 					return
 				}
 			}
@@ -243,14 +338,23 @@ func (r *identResolver) beginScope() func() {
 		if r.replMode {
 			return
 		}
-		for ident := range scope.UnusedIdents() {
-			r.errs.Addf(ident, "%s has been declared but is never used", ident.Token.Lexeme)
+		isGlobal := r.scopes.Len() == 0
+		if !r.unusedCheckDisabled && !(r.moduleMode && isGlobal) {
+			for ident := range scope.UnusedIdents() {
+				r.errs.AddCodedWarningf(lox.ErrorCodeUnusedDeclaration, ident, "%s has been declared but is never used", ident.Token.Lexeme)
+			}
 		}
-		for ident := range scope.UndeclaredUsages() {
+		for usage := range scope.UndeclaredUsages() {
+			ident := usage.Ident
 			if scope.IsDeclared(ident.Token.Lexeme) {
-				r.errs.Addf(ident, "%s has been used before its declaration", ident.Token.Lexeme)
+				r.errs.AddCodedf(lox.ErrorCodeUsedBeforeDeclaration, ident, "%s has been used before its declaration", ident.Token.Lexeme)
 			} else {
-				r.errs.Addf(ident, "%s has not been declared", ident.Token.Lexeme)
+				if usage.Similar != "" {
+					r.errs.AddCodedf(lox.ErrorCodeUndeclared, ident, "%s has not been declared, did you mean %s?", ident.Token.Lexeme, usage.Similar)
+					r.errs[len(r.errs)-1].AddFixf(ident, usage.Similar, "replace with %s", usage.Similar)
+				} else {
+					r.errs.AddCodedf(lox.ErrorCodeUndeclared, ident, "%s has not been declared", ident.Token.Lexeme)
+				}
 			}
 		}
 	}
@@ -264,13 +368,45 @@ func (r *identResolver) declareIdent(ident ast.Ident) {
 		return
 	}
 	if scope := r.scopes.Peek(); scope.IsDeclared(ident.Token.Lexeme) {
-		r.errs.Addf(ident, "%s has already been declared", ident.Token.Lexeme)
+		r.errs.AddCodedf(lox.ErrorCodeRedeclared, ident, "%s has already been declared", ident.Token.Lexeme)
+		// Built-ins aren't declared in the source code, so there's nothing to point to for them.
+		if decl := scope.DeclaredIdent(ident.Token.Lexeme); decl.Token.StartPos.File != nil {
+			r.errs[len(r.errs)-1].AddRelatedf(decl, "%s was first declared here", ident.Token.Lexeme)
+		}
 	} else {
+		// Built-ins are declared in the global scope, so declaring one there is already reported as a redeclaration.
+		if r.builtinShadowingCheckEnabled && r.scopes.Len() > 1 && slices.Contains(r.builtins, ident.Token.Lexeme) {
+			kind := "function"
+			if slices.Contains(lox.BuiltinModules, ident.Token.Lexeme) {
+				kind = "module"
+			}
+			r.errs.AddCodedf(lox.ErrorCodeShadowedBuiltin, ident, "%s shadows the built-in %s of the same name", ident.Token.Lexeme, kind)
+		}
+		r.checkNotShadowing(ident)
 		scope.Declare(ident)
 		r.identDecls[ident] = ident
 	}
 }
 
+// checkNotShadowing checks that an identifier being declared in a local scope doesn't shadow one declared in an
+// enclosing scope. Shadowing built-ins is left to the built-in shadowing check and a parameter may share the name of its
+// function.
+func (r *identResolver) checkNotShadowing(ident ast.Ident) {
+	name := ident.Token.Lexeme
+	if r.replMode || r.shadowingCheckDisabled || r.scopes.Len() == 1 || strings.HasPrefix(name, "_") || name == r.paramsOf {
+		return
+	}
+	for level, scope := range r.scopes.Backward() {
+		if level < r.scopes.Len()-1 && scope.IsDeclared(name) {
+			if level > 0 || !slices.Contains(r.builtins, name) {
+				r.errs.AddCodedWarningf(lox.ErrorCodeShadowedDeclaration, ident, "%s shadows a declaration in an outer scope", name)
+				r.errs[len(r.errs)-1].AddRelatedf(scope.DeclaredIdent(name), "%s was declared here", name)
+			}
+			return
+		}
+	}
+}
+
 func (r *identResolver) defineIdent(ident ast.Ident) {
 	if ident.Token.Lexeme == token.PlaceholderIdent {
 		return
@@ -302,7 +438,7 @@ func (r *identResolver) resolveIdent(ident ast.Ident, op identOp) {
 			// in, then we can't definitely say that the identifier has been defined yet. It might be defined later
 			// before the function is called.
 			if op == identOpRead && !scope.IsDefined(ident.Token.Lexeme) && !(r.inFun && level <= r.funScopeLevel) {
-				r.errs.Addf(ident, "%s has not been defined", ident.Token.Lexeme)
+				r.errs.AddCodedf(lox.ErrorCodeUndefined, ident, "%s has not been defined", ident.Token.Lexeme)
 			}
 			return
 		}
@@ -314,11 +450,44 @@ func (r *identResolver) resolveIdent(ident ast.Ident, op identOp) {
 		r.identDecls[ident] = globalIdent
 		return
 	}
-	r.scopes.Peek().UseUndeclared(ident)
+	// The variable being initialised isn't declared until after its initialiser, so reading it in its own initialiser
+	// would otherwise be reported as a use before its declaration, which doesn't explain what's wrong.
+	if op == identOpRead && slices.Contains(r.initialisingLocals, ident.Token.Lexeme) {
+		r.errs.AddCodedf(lox.ErrorCodeSelfReferentialInitialiser, ident, "cannot read local variable %s in its own initialiser", ident.Token.Lexeme)
+		return
+	}
+	r.scopes.Peek().UseUndeclared(ident, r.similarName(ident.Token.Lexeme))
+}
+
+// similarName returns the name of a declaration which is visible where an identifier with the given name is being
+// resolved and is spelt similarly to it, or "" if there isn't one.
+func (r *identResolver) similarName(name string) string {
+	return SimilarName(name, func(yield func(string) bool) {
+		for _, scope := range r.scopes.Backward() {
+			for candidate := range scope.decls {
+				if !yield(candidate) {
+					return
+				}
+			}
+		}
+		if r.inFun {
+			// Globals declared later can be used inside a function.
+			for candidate := range r.globalIdents {
+				if !yield(candidate) {
+					return
+				}
+			}
+		}
+	})
 }
 
 func (r *identResolver) walk(node ast.Node) bool {
 	switch node := node.(type) {
+	case ast.ExprStmt:
+		r.checkResultUsed(node)
+		return true
+	case ast.ImportStmt:
+		r.walkImportStmt(node)
 	case ast.VarDecl:
 		r.walkVarDecl(node)
 	case ast.FunDecl:
@@ -329,6 +498,10 @@ func (r *identResolver) walk(node ast.Node) bool {
 		r.walkBlockStmt(node)
 	case ast.ForStmt:
 		r.walkForStmt(node)
+	case ast.ForInStmt:
+		r.walkForInStmt(node)
+	case ast.TryStmt:
+		r.walkTryStmt(node)
 	case ast.FunExpr:
 		r.walkFunExpr(node)
 	case ast.IdentExpr:
@@ -341,9 +514,54 @@ func (r *identResolver) walk(node ast.Node) bool {
 	return false
 }
 
+// checkResultUsed checks that an expression statement has a side effect. Otherwise, its result is discarded without
+// being used, which is almost certainly a mistake.
+func (r *identResolver) checkResultUsed(stmt ast.ExprStmt) {
+	if !r.unusedResultCheckEnabled || r.replMode || hasSideEffects(stmt.Expr) {
+		return
+	}
+	r.errs.AddCodedWarningf(lox.ErrorCodeUnusedResult, stmt, "expression result is not used")
+}
+
+// hasSideEffects reports whether evaluating an expression could have a side effect. Calls, assignments, increments and
+// property accesses, including those of a superclass (which could call a getter), could have a side effect.
+func hasSideEffects(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case ast.LiteralExpr, ast.IdentExpr, ast.ThisExpr, ast.FunExpr:
+		return false
+	case ast.GroupExpr:
+		return hasSideEffects(expr.Expr)
+	case ast.InterpolatedStringExpr:
+		return slices.ContainsFunc(expr.Exprs, hasSideEffects)
+	case ast.UnaryExpr:
+		return hasSideEffects(expr.Right)
+	case ast.BinaryExpr:
+		return (expr.Left != nil && hasSideEffects(expr.Left)) || hasSideEffects(expr.Right)
+	case ast.TernaryExpr:
+		return hasSideEffects(expr.Condition) || hasSideEffects(expr.Then) || hasSideEffects(expr.Else)
+	case ast.CallExpr, ast.GetExpr, ast.SuperExpr, ast.AssignmentExpr, ast.SetExpr, ast.IncrementExpr:
+		return true
+	default:
+		panic(fmt.Sprintf("unexpected expression type: %T", expr))
+	}
+}
+
+func (r *identResolver) walkImportStmt(stmt ast.ImportStmt) {
+	r.declareIdent(stmt.Name)
+	r.defineIdent(stmt.Name)
+}
+
 func (r *identResolver) walkVarDecl(decl ast.VarDecl) {
 	if decl.Initialiser != nil {
+		// Global variables are left to the checks for globals which are used before they're declared.
+		isLocal := r.scopes.Len() > 1
+		if isLocal {
+			r.initialisingLocals = append(r.initialisingLocals, decl.Name.Token.Lexeme)
+		}
 		ast.Walk(decl.Initialiser, r.walk)
+		if isLocal {
+			r.initialisingLocals = r.initialisingLocals[:len(r.initialisingLocals)-1]
+		}
 		r.declareIdent(decl.Name)
 		r.defineIdent(decl.Name)
 	} else {
@@ -357,10 +575,12 @@ func (r *identResolver) walkFunDecl(decl ast.FunDecl) {
 	prevFunScopeLevel := r.funScopeLevel
 	r.funScopeLevel = r.scopes.Len() - 1
 	defer func() { r.funScopeLevel = prevFunScopeLevel }()
-	r.walkFun(decl.Function)
+	r.walkFun(decl.Function, decl.Name.Token.Lexeme)
 }
 
-func (r *identResolver) walkFun(fun ast.Function) {
+// walkFun walks a function. name is the name that the function is declared with, or empty if it's not declared with
+// one.
+func (r *identResolver) walkFun(fun ast.Function, name string) {
 	endScope := r.beginScope()
 	defer endScope()
 
@@ -368,10 +588,12 @@ func (r *identResolver) walkFun(fun ast.Function) {
 	r.inFun = true
 	defer func() { r.inFun = prevInFun }()
 
+	r.paramsOf = name
 	for _, param := range fun.Params {
 		r.declareIdent(param)
 		r.defineIdent(param)
 	}
+	r.paramsOf = ""
 	for _, stmt := range fun.Body.Stmts {
 		ast.Walk(stmt, r.walk)
 	}
@@ -380,6 +602,15 @@ func (r *identResolver) walkFun(fun ast.Function) {
 func (r *identResolver) walkClassDecl(decl ast.ClassDecl) {
 	r.declareIdent(decl.Name)
 	r.defineIdent(decl.Name)
+	if decl.Superclass != nil {
+		ast.Walk(decl.Superclass, r.walk)
+		endSuperclassScope := r.beginScope()
+		defer endSuperclassScope()
+		scope := r.scopes.Peek()
+		scope.DeclareName(token.SuperclassIdent)
+		scope.Define(token.SuperclassIdent)
+		scope.Use(token.SuperclassIdent)
+	}
 	endScope := r.beginScope()
 	defer endScope()
 	scope := r.scopes.Peek()
@@ -387,7 +618,7 @@ func (r *identResolver) walkClassDecl(decl ast.ClassDecl) {
 	scope.Define(token.CurrentInstanceIdent)
 	scope.Use(token.CurrentInstanceIdent)
 	for _, methodDecl := range decl.Methods() {
-		r.walkFun(methodDecl.Function)
+		r.walkFun(methodDecl.Function, "")
 	}
 }
 
@@ -414,8 +645,31 @@ func (r *identResolver) walkForStmt(stmt ast.ForStmt) {
 	ast.Walk(stmt.Body, r.walk)
 }
 
+func (r *identResolver) walkForInStmt(stmt ast.ForInStmt) {
+	ast.Walk(stmt.Iterable, r.walk)
+	endScope := r.beginScope()
+	defer endScope()
+	r.declareIdent(stmt.Name)
+	r.defineIdent(stmt.Name)
+	ast.Walk(stmt.Body, r.walk)
+}
+
+func (r *identResolver) walkTryStmt(stmt ast.TryStmt) {
+	ast.Walk(stmt.Body, r.walk)
+	if stmt.HasCatch() {
+		endScope := r.beginScope()
+		r.declareIdent(stmt.CatchName)
+		r.defineIdent(stmt.CatchName)
+		ast.Walk(stmt.CatchBody, r.walk)
+		endScope()
+	}
+	if stmt.HasFinally() {
+		ast.Walk(stmt.FinallyBody, r.walk)
+	}
+}
+
 func (r *identResolver) walkFunExpr(expr ast.FunExpr) {
-	r.walkFun(expr.Function)
+	r.walkFun(expr.Function, "")
 }
 
 func (r *identResolver) resolveIdentExpr(expr ast.IdentExpr) {
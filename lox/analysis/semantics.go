@@ -1,6 +1,8 @@
 package analysis
 
 import (
+	"slices"
+
 	"github.com/marcuscaisey/lox/lox"
 	"github.com/marcuscaisey/lox/lox/ast"
 	"github.com/marcuscaisey/lox/lox/token"
@@ -19,10 +21,14 @@ const (
 //   - _ cannot be used as a value
 //   - _ cannot be used as a field name
 //   - this can only be used inside a method definition
+//   - super can only be used inside a method definition of a class with a superclass
+//   - classes cannot inherit from themselves
 //   - property getter cannot have parameters
 //   - property setter must have exactly one parameter
 //   - functions cannot have more than 255 parameters
 //   - function calls cannot have more than 255 arguments
+//   - statements cannot follow a return, break, continue, or throw in the same block
+//   - import can only be used at the top level of a program
 func CheckSemantics(program ast.Program) lox.Errors {
 	c := newSemanticChecker()
 	return c.Check(program)
@@ -31,6 +37,8 @@ func CheckSemantics(program ast.Program) lox.Errors {
 type semanticChecker struct {
 	inLoop     bool
 	curFunType funType
+	inSubclass bool
+	inBlock    bool
 
 	errs lox.Errors
 }
@@ -50,17 +58,26 @@ func (c *semanticChecker) walk(node ast.Node) bool {
 		c.walkFun(node.Function, funTypeFunction)
 		return false
 	case ast.ClassDecl:
-		c.checkNoWriteOnlyProperties(node.Methods())
+		c.walkClassDecl(node)
+		return false
 	case ast.MethodDecl:
 		c.checkNumPropertyParams(node)
 		c.walkFun(node.Function, methodFunType(node))
 		return false
+	case ast.ImportStmt:
+		c.checkImportAtTopLevel(node)
+	case ast.BlockStmt:
+		c.walkBlockStmt(node)
+		return false
 	case ast.WhileStmt:
 		c.walkWhileStmt(node)
 		return false
 	case ast.ForStmt:
 		c.walkForStmt(node)
 		return false
+	case ast.ForInStmt:
+		c.walkForInStmt(node)
+		return false
 	case ast.BreakStmt:
 		c.checkBreakInLoop(node)
 	case ast.ContinueStmt:
@@ -75,6 +92,9 @@ func (c *semanticChecker) walk(node ast.Node) bool {
 		c.checkNoPlaceholderAccess(node)
 	case ast.ThisExpr:
 		c.checkThisInMethod(node)
+	case ast.SuperExpr:
+		c.checkSuperInSubclassMethod(node)
+		c.checkNoPlaceholderFieldAccess(node.Method)
 	case ast.CallExpr:
 		c.checkNumArgs(node.Args)
 	case ast.GetExpr:
@@ -86,6 +106,28 @@ func (c *semanticChecker) walk(node ast.Node) bool {
 	return true
 }
 
+func (c *semanticChecker) walkClassDecl(decl ast.ClassDecl) {
+	c.checkNoWriteOnlyProperties(decl.Methods())
+	c.checkNoSelfInheritance(decl)
+
+	prevInSubclass := c.inSubclass
+	c.inSubclass = decl.Superclass != nil
+	defer func() { c.inSubclass = prevInSubclass }()
+
+	if decl.Superclass != nil {
+		ast.Walk(decl.Superclass, c.walk)
+	}
+	for _, stmt := range decl.Body {
+		ast.Walk(stmt, c.walk)
+	}
+}
+
+func (c *semanticChecker) checkNoSelfInheritance(decl ast.ClassDecl) {
+	if superclass, ok := decl.Superclass.(ast.IdentExpr); ok && superclass.Ident.Token.Lexeme == decl.Name.Token.Lexeme {
+		c.errs.AddCodedf(lox.ErrorCodeSelfInheritance, superclass, "class cannot inherit from itself")
+	}
+}
+
 func (c *semanticChecker) walkFun(fun ast.Function, funType funType) {
 	c.checkNumParams(fun.Params)
 
@@ -98,6 +140,11 @@ func (c *semanticChecker) walkFun(fun ast.Function, funType funType) {
 	c.curFunType = funType
 	defer func() { c.curFunType = prevFunType }()
 
+	prevInBlock := c.inBlock
+	c.inBlock = true
+	defer func() { c.inBlock = prevInBlock }()
+
+	c.checkNoUnreachableStmts(fun.Body.Stmts)
 	for _, stmt := range fun.Body.Stmts {
 		ast.Walk(stmt, c.walk)
 	}
@@ -105,7 +152,7 @@ func (c *semanticChecker) walkFun(fun ast.Function, funType funType) {
 
 func (c *semanticChecker) checkNumParams(params token.Ranges[ast.Ident]) {
 	if len(params) > maxParams {
-		c.errs.Addf(params[maxParams], "cannot define more than %d function parameters", maxParams)
+		c.errs.AddCodedf(lox.ErrorCodeTooManyParameters, params[maxParams], "cannot define more than %d function parameters", maxParams)
 	}
 }
 
@@ -131,6 +178,13 @@ func (c *semanticChecker) walkForStmt(stmt ast.ForStmt) {
 	ast.Walk(stmt.Body, c.walk)
 }
 
+func (c *semanticChecker) walkForInStmt(stmt ast.ForInStmt) {
+	ast.Walk(stmt.Iterable, c.walk)
+	endLoop := c.beginLoop()
+	defer endLoop()
+	ast.Walk(stmt.Body, c.walk)
+}
+
 // beginLoop sets the inLoop flag to true and returns a function which resets it to its previous value
 func (c *semanticChecker) beginLoop() func() {
 	prev := c.inLoop
@@ -151,7 +205,7 @@ func (c *semanticChecker) checkNoWriteOnlyProperties(methods []ast.MethodDecl) {
 	}
 	for name, ident := range setterIdentsByName {
 		if !gettersByName[name] {
-			c.errs.Add(ident, "write-only properties are not allowed")
+			c.errs.AddCodedf(lox.ErrorCodeInvalidAccessor, ident, "write-only properties are not allowed")
 		}
 	}
 }
@@ -159,62 +213,126 @@ func (c *semanticChecker) checkNoWriteOnlyProperties(methods []ast.MethodDecl) {
 func (c *semanticChecker) checkNumPropertyParams(decl ast.MethodDecl) {
 	switch {
 	case decl.HasModifier(token.Get) && len(decl.Function.Params) > 0:
-		c.errs.Add(decl.Function.Params[0:], "property getter cannot have parameters")
+		c.errs.AddCodedf(lox.ErrorCodeInvalidAccessor, decl.Function.Params[0:], "property getter cannot have parameters")
 	case decl.HasModifier(token.Set):
 		if len(decl.Function.Params) == 0 {
-			c.errs.Add(decl.Name, "property setter must have a parameter")
+			c.errs.AddCodedf(lox.ErrorCodeInvalidAccessor, decl.Name, "property setter must have a parameter")
 		} else if len(decl.Function.Params) > 1 {
-			c.errs.Add(decl.Function.Params[1:], "property setter can only have one parameter")
+			c.errs.AddCodedf(lox.ErrorCodeInvalidAccessor, decl.Function.Params[1:], "property setter can only have one parameter")
 		}
 	}
 
 }
 
+func (c *semanticChecker) walkBlockStmt(block ast.BlockStmt) {
+	c.checkNoUnreachableStmts(block.Stmts)
+
+	prevInBlock := c.inBlock
+	c.inBlock = true
+	defer func() { c.inBlock = prevInBlock }()
+
+	for _, stmt := range block.Stmts {
+		ast.Walk(stmt, c.walk)
+	}
+}
+
+func (c *semanticChecker) checkImportAtTopLevel(stmt ast.ImportStmt) {
+	if c.inBlock {
+		c.errs.AddCodedf(lox.ErrorCodeMisplacedStatement, stmt, "%m can only be used at the top level", token.Import)
+	}
+}
+
 func (c *semanticChecker) checkBreakInLoop(stmt ast.BreakStmt) {
 	if !c.inLoop {
-		c.errs.Addf(stmt, "%m can only be used inside a loop", token.Break)
+		c.errs.AddCodedf(lox.ErrorCodeMisplacedStatement, stmt, "%m can only be used inside a loop", token.Break)
 	}
 }
 
 func (c *semanticChecker) checkContinueInLoop(stmt ast.ContinueStmt) {
 	if !c.inLoop {
-		c.errs.Addf(stmt, "%m can only be used inside a loop", token.Continue)
+		c.errs.AddCodedf(lox.ErrorCodeMisplacedStatement, stmt, "%m can only be used inside a loop", token.Continue)
+	}
+}
+
+func (c *semanticChecker) checkNoUnreachableStmts(stmts []ast.Stmt) {
+	terminated := false
+	for _, stmt := range stmts {
+		if _, ok := stmt.(ast.CommentStmt); ok {
+			// Comments aren't executed, so they're never unreachable.
+			continue
+		}
+		if terminated {
+			c.errs.AddCodedf(lox.ErrorCodeUnreachableCode, stmt, "unreachable code")
+			return
+		}
+		terminated = isTerminating(stmt)
+	}
+}
+
+// isTerminating reports whether execution never continues past the given statement, because it always ends with a
+// return, break, continue, or throw.
+func isTerminating(stmt ast.Stmt) bool {
+	switch stmt := stmt.(type) {
+	case ast.ReturnStmt, ast.BreakStmt, ast.ContinueStmt, ast.ThrowStmt:
+		return true
+	case ast.InlineCommentStmt:
+		return isTerminating(stmt.Stmt)
+	case ast.BlockStmt:
+		return slices.ContainsFunc(stmt.Stmts, isTerminating)
+	case ast.IfStmt:
+		return stmt.Else != nil && isTerminating(stmt.Then) && isTerminating(stmt.Else)
+	case ast.TryStmt:
+		// An error thrown by the body is caught by the catch clause, after which execution continues unless the catch
+		// clause terminates as well. The finally clause is always executed.
+		return (isTerminating(stmt.Body) && (!stmt.HasCatch() || isTerminating(stmt.CatchBody))) ||
+			(stmt.HasFinally() && isTerminating(stmt.FinallyBody))
+	default:
+		return false
 	}
 }
 
 func (c *semanticChecker) checkReturnInFun(stmt ast.ReturnStmt) {
 	if c.curFunType == funTypeNone {
-		c.errs.Addf(stmt, "%m can only be used inside a function definition", token.Return)
+		c.errs.AddCodedf(lox.ErrorCodeMisplacedStatement, stmt, "%m can only be used inside a function definition", token.Return)
 	}
 }
 
 func (c *semanticChecker) checkNoConstructorReturn(stmt ast.ReturnStmt) {
 	if stmt.Value != nil && c.curFunType.IsConstructor() {
-		c.errs.Addf(stmt, "%s() cannot return a value", token.ConstructorIdent)
+		c.errs.AddCodedf(lox.ErrorCodeConstructorReturnValue, stmt, "%s() cannot return a value", token.ConstructorIdent)
 	}
 }
 
 func (c *semanticChecker) checkNoPlaceholderAccess(expr ast.IdentExpr) {
 	if expr.Ident.Token.Lexeme == token.PlaceholderIdent {
-		c.errs.Addf(expr.Ident, "%s cannot be used as a value", token.PlaceholderIdent)
+		c.errs.AddCodedf(lox.ErrorCodeInvalidPlaceholder, expr.Ident, "%s cannot be used as a value", token.PlaceholderIdent)
 	}
 }
 
 func (c *semanticChecker) checkNoPlaceholderFieldAccess(ident ast.Ident) {
 	if ident.Token.Lexeme == token.PlaceholderIdent {
-		c.errs.Addf(ident, "%s cannot be used as a field name", token.PlaceholderIdent)
+		c.errs.AddCodedf(lox.ErrorCodeInvalidPlaceholder, ident, "%s cannot be used as a field name", token.PlaceholderIdent)
 	}
 }
 
 func (c *semanticChecker) checkThisInMethod(expr ast.ThisExpr) {
 	if !c.curFunType.IsMethod() {
-		c.errs.Addf(expr, "%m can only be used inside a method definition", token.This)
+		c.errs.AddCodedf(lox.ErrorCodeMisplacedExpression, expr, "%m can only be used inside a method definition", token.This)
+	}
+}
+
+func (c *semanticChecker) checkSuperInSubclassMethod(expr ast.SuperExpr) {
+	switch {
+	case !c.curFunType.IsMethod():
+		c.errs.AddCodedf(lox.ErrorCodeMisplacedExpression, expr.Super, "%m can only be used inside a method definition", token.Super)
+	case !c.inSubclass:
+		c.errs.AddCodedf(lox.ErrorCodeMisplacedExpression, expr.Super, "%m can only be used inside a class with a superclass", token.Super)
 	}
 }
 
 func (c *semanticChecker) checkNumArgs(args []ast.Expr) {
 	if len(args) > maxArgs {
-		c.errs.Addf(args[maxArgs], "cannot pass more than %d arguments to function", maxArgs)
+		c.errs.AddCodedf(lox.ErrorCodeTooManyArguments, args[maxArgs], "cannot pass more than %d arguments to function", maxArgs)
 	}
 }
 
@@ -0,0 +1,49 @@
+package analysis
+
+import (
+	"iter"
+	"unicode/utf8"
+)
+
+// SimilarName returns the candidate which is spelt most similarly to name, or "" if none of them are similar enough.
+// Names are similar if at most a third of their characters need to be inserted, deleted, substituted, or swapped with
+// their neighbour to turn one into the other. Ties are broken alphabetically.
+func SimilarName(name string, candidates iter.Seq[string]) string {
+	maxDist := utf8.RuneCountInString(name) / 3
+	similar := ""
+	similarDist := maxDist + 1
+	for candidate := range candidates {
+		if dist := editDistance(name, candidate); dist < similarDist || (dist == similarDist && candidate < similar) {
+			similar, similarDist = candidate, dist
+		}
+	}
+	return similar
+}
+
+// editDistance returns the number of single character insertions, deletions, substitutions, and transpositions of
+// adjacent characters needed to turn a into b. This is the optimal string alignment distance.
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	// dists[i][j] is the distance between the first i characters of a and the first j characters of b.
+	dists := make([][]int, len(ar)+1)
+	for i := range dists {
+		dists[i] = make([]int, len(br)+1)
+		dists[i][0] = i
+	}
+	for j := range dists[0] {
+		dists[0][j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			dists[i][j] = min(dists[i-1][j]+1, dists[i][j-1]+1, dists[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				dists[i][j] = min(dists[i][j], dists[i-2][j-2]+1)
+			}
+		}
+	}
+	return dists[len(ar)][len(br)]
+}
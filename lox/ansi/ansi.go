@@ -34,14 +34,17 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"strings"
 
 	"golang.org/x/term"
 )
 
 // Enabled determines whether ANSI escape sequences will be output by the functions in this package.
-// If stdout and stderr are both connected to a terminal, this will be true.
-var Enabled = term.IsTerminal(int(os.Stdout.Fd())) && term.IsTerminal(int(os.Stderr.Fd()))
+// If stdout and stderr are both connected to a terminal and the NO_COLOR environment variable is not set to a non-empty
+// value, this will be true. See https://no-color.org.
+var Enabled = os.Getenv("NO_COLOR") == "" &&
+	term.IsTerminal(int(os.Stdout.Fd())) && term.IsTerminal(int(os.Stderr.Fd()))
 
 var ansiCodes = map[string]int{
 	"RESET":               0,
@@ -87,6 +90,13 @@ var emptyReplacer = func() *strings.Replacer {
 	return strings.NewReplacer(oldnew...)
 }()
 
+var escapeSequenceRe = regexp.MustCompile(`\x1b\[\d+m`)
+
+// Strip removes any ANSI escape sequences output by the functions in this package from s.
+func Strip(s string) string {
+	return escapeSequenceRe.ReplaceAllLiteralString(s, "")
+}
+
 func replace(s string) string {
 	if Enabled {
 		return ansiReplacer.Replace(s)
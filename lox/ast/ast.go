@@ -17,14 +17,34 @@ type node struct{}
 
 func (node) isNode() {}
 
-// Program is the root node of the AST.
+// Program is the root node of the AST. Its range is the whole of the file that it was parsed from, including any
+// leading or trailing comments and whitespace. If EOF is zero, as it is for a Program which wasn't produced by the
+// parser, then its range is from the start of its first statement to the end of its last.
 type Program struct {
 	Stmts token.Ranges[Stmt] `print:"unnamed"`
+	EOF   token.Token
 	node
 }
 
-func (p Program) Start() token.Position { return p.Stmts[0].Start() }
-func (p Program) End() token.Position   { return p.Stmts[len(p.Stmts)-1].End() }
+func (p Program) Start() token.Position {
+	if p.EOF == (token.Token{}) {
+		if len(p.Stmts) == 0 {
+			return token.Position{}
+		}
+		return p.Stmts[0].Start()
+	}
+	return token.Position{File: p.EOF.StartPos.File, Line: 1, Column: 0}
+}
+
+func (p Program) End() token.Position {
+	if p.EOF == (token.Token{}) {
+		if len(p.Stmts) == 0 {
+			return token.Position{}
+		}
+		return p.Stmts[len(p.Stmts)-1].End()
+	}
+	return p.EOF.EndPos
+}
 
 // Ident is an identifier, such as a variable name.
 type Ident struct {
@@ -72,6 +92,20 @@ type InlineCommentStmt struct {
 func (s InlineCommentStmt) Start() token.Position { return s.Stmt.Start() }
 func (s InlineCommentStmt) End() token.Position   { return s.Comment.EndPos }
 
+// ImportStmt is an import statement, such as import "path/to/module.lox". Name is the name that the module is bound
+// to, which is the file name of the path without its extension. It doesn't appear in the source code, so its token has
+// the same position as Path.
+type ImportStmt struct {
+	Import    token.Token
+	Path      token.Token `print:"unnamed"`
+	Name      Ident
+	Semicolon token.Token
+	stmt
+}
+
+func (s ImportStmt) Start() token.Position { return s.Import.StartPos }
+func (s ImportStmt) End() token.Position   { return s.Semicolon.EndPos }
+
 // VarDecl is a variable declaration, such as var a = 123 or var b.
 type VarDecl struct {
 	Var         token.Token
@@ -96,26 +130,36 @@ func (d FunDecl) Start() token.Position { return d.Fun.StartPos }
 func (d FunDecl) End() token.Position   { return d.Function.Body.End() }
 
 // Function is a function's parameters and body.
+//
+// Arrow is only set for a function expression with a shorthand body, such as fun (x) => x * 2. Its body is a block
+// containing a single return statement of the expression.
 type Function struct {
 	LeftParen token.Token
 	Params    token.Ranges[Ident] `print:"named"`
-	Body      BlockStmt           `print:"named"`
+	Arrow     token.Token
+	Body      BlockStmt `print:"named"`
 	node
 }
 
+// HasShorthandBody reports whether the function was written with a shorthand body.
+func (f Function) HasShorthandBody() bool { return f.Arrow.Type == token.Arrow }
+
 func (f Function) Start() token.Position { return f.LeftParen.StartPos }
 func (f Function) End() token.Position   { return f.Body.End() }
 
 // ClassDecl is a class declaration, such as
 //
-//	class Foo {
+//	class Foo < Bar {
 //	  bar() {
 //	    return "baz";
 //	  }
 //	}
+//
+// Superclass is nil if the class doesn't have a superclass.
 type ClassDecl struct {
 	Class      token.Token
 	Name       Ident              `print:"named"`
+	Superclass Expr               `print:"named"`
 	Body       token.Ranges[Stmt] `print:"named"`
 	RightBrace token.Token
 	stmt
@@ -264,6 +308,63 @@ type ForStmt struct {
 func (f ForStmt) Start() token.Position { return f.For.StartPos }
 func (f ForStmt) End() token.Position   { return f.Body.End() }
 
+// ForInStmt is a for-in statement, such as
+//
+//	for (c in "abc") {
+//	    print c;
+//	}
+type ForInStmt struct {
+	For      token.Token
+	Name     Ident `print:"named"`
+	In       token.Token
+	Iterable Expr `print:"named"`
+	Body     Stmt `print:"named"`
+	stmt
+}
+
+func (f ForInStmt) Start() token.Position { return f.For.StartPos }
+func (f ForInStmt) End() token.Position   { return f.Body.End() }
+
+// TryStmt is a try statement, such as
+//
+//	try {
+//	    f();
+//	} catch (e) {
+//	    print e.message;
+//	} finally {
+//	    g();
+//	}
+//
+// At least one of the catch and finally clauses is present. The fields of a clause which isn't present are zero.
+type TryStmt struct {
+	Try         token.Token
+	Body        BlockStmt `print:"named"`
+	Catch       token.Token
+	CatchName   Ident     `print:"named"`
+	CatchBody   BlockStmt `print:"named"`
+	Finally     token.Token
+	FinallyBody BlockStmt `print:"named"`
+	stmt
+}
+
+func (t TryStmt) Start() token.Position { return t.Try.StartPos }
+func (t TryStmt) End() token.Position {
+	switch {
+	case t.HasFinally():
+		return t.FinallyBody.End()
+	case t.HasCatch():
+		return t.CatchBody.End()
+	default:
+		return t.Body.End()
+	}
+}
+
+// HasCatch reports whether the statement has a catch clause.
+func (t TryStmt) HasCatch() bool { return t.Catch.Type == token.Catch }
+
+// HasFinally reports whether the statement has a finally clause.
+func (t TryStmt) HasFinally() bool { return t.Finally.Type == token.Finally }
+
 // IllegalStmt is an illegal statement, used as a placeholder when parsing fails.
 type IllegalStmt struct {
 	From, To token.Token
@@ -304,6 +405,17 @@ type ReturnStmt struct {
 func (c ReturnStmt) Start() token.Position { return c.Return.StartPos }
 func (c ReturnStmt) End() token.Position   { return c.Semicolon.EndPos }
 
+// ThrowStmt is a throw statement, such as throw "error";
+type ThrowStmt struct {
+	Throw     token.Token
+	Value     Expr `print:"unnamed"`
+	Semicolon token.Token
+	stmt
+}
+
+func (t ThrowStmt) Start() token.Position { return t.Throw.StartPos }
+func (t ThrowStmt) End() token.Position   { return t.Semicolon.EndPos }
+
 // Expr is the interface which all expression nodes implement.
 //
 //gosumtype:decl Expr
@@ -348,6 +460,34 @@ type LiteralExpr struct {
 func (l LiteralExpr) Start() token.Position { return l.Value.StartPos }
 func (l LiteralExpr) End() token.Position   { return l.Value.EndPos }
 
+// InterpolatedStringExpr is a string literal containing interpolated expressions, such as "a ${b} c". Its value is the
+// concatenation of its string parts and the string representations of its expressions.
+type InterpolatedStringExpr struct {
+	// Strings contains the StringHead, StringMiddle, and StringTail tokens which surround each of Exprs, so it always
+	// has one more element than Exprs.
+	Strings []token.Token      `print:"named"`
+	Exprs   token.Ranges[Expr] `print:"named"`
+	expr
+}
+
+func (i InterpolatedStringExpr) Start() token.Position { return i.Strings[0].StartPos }
+func (i InterpolatedStringExpr) End() token.Position   { return i.Strings[len(i.Strings)-1].EndPos }
+
+// StringParts returns the contents of Strings without the quotes and interpolation delimiters which surround them.
+func (i InterpolatedStringExpr) StringParts() []string {
+	parts := make([]string, len(i.Strings))
+	for j, tok := range i.Strings {
+		part := tok.Lexeme[1:] // Remove leading " or }
+		if tok.Type == token.StringTail {
+			part = part[:len(part)-1] // Remove trailing "
+		} else {
+			part = part[:len(part)-2] // Remove trailing ${
+		}
+		parts[j] = part
+	}
+	return parts
+}
+
 // IdentExpr is an identifier expression, such as a or b.
 type IdentExpr struct {
 	Ident Ident
@@ -366,6 +506,16 @@ type ThisExpr struct {
 func (t ThisExpr) Start() token.Position { return t.This.StartPos }
 func (t ThisExpr) End() token.Position   { return t.This.EndPos }
 
+// SuperExpr is an access of a superclass method, such as super.foo.
+type SuperExpr struct {
+	Super  token.Token
+	Method Ident `print:"named"`
+	expr
+}
+
+func (s SuperExpr) Start() token.Position { return s.Super.StartPos }
+func (s SuperExpr) End() token.Position   { return s.Method.End() }
+
 // CallExpr is a call expression, such as add(x, 1).
 type CallExpr struct {
 	Callee     Expr               `print:"named"`
@@ -397,7 +547,31 @@ type UnaryExpr struct {
 func (u UnaryExpr) Start() token.Position { return u.Op.StartPos }
 func (u UnaryExpr) End() token.Position   { return u.Right.End() }
 
+// IncrementExpr is an increment or decrement expression, such as ++a or a.b--.
+// Operand is either an [IdentExpr] or a [GetExpr].
+type IncrementExpr struct {
+	Op      token.Token `print:"named"`
+	Operand Expr        `print:"named"`
+	Postfix bool        `print:"named"`
+	expr
+}
+
+func (i IncrementExpr) Start() token.Position {
+	if i.Postfix {
+		return i.Operand.Start()
+	}
+	return i.Op.StartPos
+}
+
+func (i IncrementExpr) End() token.Position {
+	if i.Postfix {
+		return i.Op.EndPos
+	}
+	return i.Operand.End()
+}
+
 // BinaryExpr is a binary operator expression, such as a + b.
+// Left is nil if the left operand is missing, which is a syntax error.
 type BinaryExpr struct {
 	Left  Expr        `print:"named"`
 	Op    token.Token `print:"named"`
@@ -405,8 +579,13 @@ type BinaryExpr struct {
 	expr
 }
 
-func (b BinaryExpr) Start() token.Position { return b.Left.Start() }
-func (b BinaryExpr) End() token.Position   { return b.Right.End() }
+func (b BinaryExpr) Start() token.Position {
+	if b.Left == nil {
+		return b.Op.StartPos
+	}
+	return b.Left.Start()
+}
+func (b BinaryExpr) End() token.Position { return b.Right.End() }
 
 // TernaryExpr is a ternary operator expression, such as a ? b : c.
 type TernaryExpr struct {
@@ -0,0 +1,90 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/marcuscaisey/lox/lox/token"
+)
+
+// Equal reports whether two AST nodes are structurally equal. Positions are ignored, so two nodes which are parsed from
+// source code which only differs in its layout are equal. Tokens are equal if they have the same type and lexeme, except
+// for number tokens which are equal if they have the same value.
+func Equal(a, b Node) bool {
+	_, _, found := Diff(a, b)
+	return !found
+}
+
+// Diff finds the first difference between two AST nodes, as determined by [Equal]. It returns the innermost nodes of a
+// and b which contain the difference and reports whether one was found.
+func Diff(a, b Node) (aDiff Node, bDiff Node, found bool) {
+	return diff(reflect.ValueOf(a), reflect.ValueOf(b), a, b)
+}
+
+var (
+	tokenType = reflect.TypeFor[token.Token]()
+	nodeType  = reflect.TypeFor[Node]()
+)
+
+// diff finds the first difference between a and b. aNode and bNode are the innermost nodes which contain a and b.
+func diff(a, b reflect.Value, aNode, bNode Node) (Node, Node, bool) {
+	if !a.IsValid() || !b.IsValid() {
+		return aNode, bNode, a.IsValid() != b.IsValid()
+	}
+	if a.Type() != b.Type() {
+		return aNode, bNode, true
+	}
+
+	if a.Type() == tokenType {
+		return aNode, bNode, !tokensEqual(a.Interface().(token.Token), b.Interface().(token.Token))
+	}
+
+	if a.Kind() == reflect.Struct && a.Type().Implements(nodeType) && a.CanInterface() {
+		aNode = a.Interface().(Node)
+		bNode = b.Interface().(Node)
+	}
+
+	switch a.Kind() {
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return aNode, bNode, a.IsNil() != b.IsNil()
+		}
+		return diff(a.Elem(), b.Elem(), aNode, bNode)
+	case reflect.Struct:
+		for i := range a.NumField() {
+			if aDiff, bDiff, found := diff(a.Field(i), b.Field(i), aNode, bNode); found {
+				return aDiff, bDiff, true
+			}
+		}
+		return nil, nil, false
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return aNode, bNode, true
+		}
+		for i := range a.Len() {
+			if aDiff, bDiff, found := diff(a.Index(i), b.Index(i), aNode, bNode); found {
+				return aDiff, bDiff, true
+			}
+		}
+		return nil, nil, false
+	case reflect.Bool, reflect.Int, reflect.String:
+		return aNode, bNode, !a.Equal(b)
+	default:
+		panic(fmt.Sprintf("unexpected kind of value in AST: %s", a.Kind()))
+	}
+}
+
+func tokensEqual(a, b token.Token) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	if a.Type == token.Number {
+		aValue, aErr := strconv.ParseFloat(a.Lexeme, 64)
+		bValue, bErr := strconv.ParseFloat(b.Lexeme, 64)
+		if aErr == nil && bErr == nil {
+			return aValue == bValue
+		}
+	}
+	return a.Lexeme == b.Lexeme
+}
@@ -0,0 +1,115 @@
+package ast_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/marcuscaisey/lox/lox/ast"
+	"github.com/marcuscaisey/lox/lox/parser"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want bool
+	}{
+		{name: "Identical", a: "print 1 + 2;", b: "print 1 + 2;", want: true},
+		{name: "DifferentLayout", a: "print 1 + 2;", b: "print\n  1+2 ;", want: true},
+		{name: "DifferentLiteral", a: "print 1 + 2;", b: "print 1 + 3;", want: false},
+		{name: "SameNumberValue", a: "print 01.50;", b: "print 1.5;", want: true},
+		{name: "DifferentNumberValue", a: "print 1.5;", b: "print 1.05;", want: false},
+		{name: "DifferentOperator", a: "print 1 + 2;", b: "print 1 - 2;", want: false},
+		{name: "DifferentNesting", a: "print 1 + 2 * 3;", b: "print (1 + 2) * 3;", want: false},
+		{name: "DifferentStmtType", a: "print x;", b: "x;", want: false},
+		{name: "NilAndNonNilChild", a: "var x;", b: "var x = nil;", want: false},
+		{name: "DifferentNumberOfStmts", a: "print 1;", b: "print 1; print 1;", want: false},
+		{name: "DifferentNumberOfArgs", a: "f(1);", b: "f(1, 2);", want: false},
+		{
+			name: "NestedDeclarations",
+			a:    "class A { static b(c) { fun d() { return this.e ? c : -1; } } }",
+			b:    "class A {\n  static b(c) {\n    fun d() {\n      return this.e ? c : -1;\n    }\n  }\n}",
+			want: true,
+		},
+		{name: "DifferentModifiers", a: "class A { static b() {} }", b: "class A { b() {} }", want: false},
+		{name: "DifferentComments", a: "print 1; // a", b: "print 1; // b", want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := mustParse(t, test.a)
+			b := mustParse(t, test.b)
+			if got := ast.Equal(a, b); got != test.want {
+				t.Errorf("Equal(%q, %q) = %t, want %t", test.a, test.b, got, test.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		a         string
+		b         string
+		wantFound bool
+		wantADiff string
+		wantBDiff string
+	}{
+		{name: "Equal", a: "print 1 + 2;", b: "print\n  1+2 ;"},
+		{
+			name:      "DifferentLiteral",
+			a:         "print 1;\nprint 1 + 2;",
+			b:         "print 1;\n\nprint 1 + 3;",
+			wantFound: true,
+			wantADiff: "ast.LiteralExpr 2:10",
+			wantBDiff: "ast.LiteralExpr 3:10",
+		},
+		{
+			name:      "DifferentNumberOfStmts",
+			a:         "{ print 1; }",
+			b:         "{ print 1; print 2; }",
+			wantFound: true,
+			wantADiff: "ast.BlockStmt 1:0",
+			wantBDiff: "ast.BlockStmt 1:0",
+		},
+		{
+			name:      "DifferentStmtType",
+			a:         "if (a) print x;",
+			b:         "if (a) x;",
+			wantFound: true,
+			wantADiff: "ast.IfStmt 1:0",
+			wantBDiff: "ast.IfStmt 1:0",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			aDiff, bDiff, found := ast.Diff(mustParse(t, test.a), mustParse(t, test.b))
+			if found != test.wantFound {
+				t.Fatalf("Diff(%q, %q) found = %t, want %t", test.a, test.b, found, test.wantFound)
+			}
+			if !found {
+				return
+			}
+			if got := describeNode(aDiff); got != test.wantADiff {
+				t.Errorf("Diff(%q, %q) aDiff = %s, want %s", test.a, test.b, got, test.wantADiff)
+			}
+			if got := describeNode(bDiff); got != test.wantBDiff {
+				t.Errorf("Diff(%q, %q) bDiff = %s, want %s", test.a, test.b, got, test.wantBDiff)
+			}
+		})
+	}
+}
+
+func describeNode(node ast.Node) string {
+	return fmt.Sprintf("%T %d:%d", node, node.Start().Line, node.Start().Column)
+}
+
+func mustParse(t *testing.T, src string) ast.Program {
+	t.Helper()
+	program, err := parser.Parse(strings.NewReader(src), parser.WithComments())
+	if err != nil {
+		t.Fatalf("parsing %q: %s", src, err)
+	}
+	return program
+}
@@ -1,14 +1,37 @@
 package ast
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/marcuscaisey/lox/lox/token"
 )
 
+// PrintFormat is a format that an AST can be printed in.
+type PrintFormat string
+
+const (
+	// PrintFormatSExpr is an indented s-expression.
+	PrintFormatSExpr PrintFormat = "sexpr"
+	// PrintFormatTree is an indented tree drawn with box-drawing characters, like the output of the tree command.
+	PrintFormatTree PrintFormat = "tree"
+	// PrintFormatJSON is a JSON object for each node, containing its type and fields.
+	PrintFormatJSON PrintFormat = "json"
+	// PrintFormatDOT is a Graphviz DOT graph, which can be rendered with the dot command.
+	PrintFormatDOT PrintFormat = "dot"
+)
+
+// PrintFormats returns all of the formats that an AST can be printed in.
+func PrintFormats() []PrintFormat {
+	return []PrintFormat{PrintFormatSExpr, PrintFormatTree, PrintFormatJSON, PrintFormatDOT}
+}
+
 // Print prints an AST Node to stdout as an indented s-expression.
 func Print(node Node) {
 	fmt.Println(Sprint(node))
@@ -16,22 +39,69 @@ func Print(node Node) {
 
 // Sprint formats an AST Node as an indented s-expression.
 func Sprint(node Node) string {
-	return sprint(node, 0)
+	return newPrintNode(node).sexpr(0)
 }
 
-func sprint(node Node, depth int) string {
+// Fprint prints an AST Node to w in the given format.
+func Fprint(w io.Writer, node Node, format PrintFormat) error {
+	root := newPrintNode(node)
+	var s string
+	switch format {
+	case PrintFormatSExpr:
+		s = root.sexpr(0)
+	case PrintFormatTree:
+		var b strings.Builder
+		b.WriteString(root.label)
+		root.writeTree(&b, "")
+		s = b.String()
+	case PrintFormatJSON:
+		data, err := json.Marshal(root)
+		if err != nil {
+			return err
+		}
+		s = string(data)
+	case PrintFormatDOT:
+		var b strings.Builder
+		b.WriteString("digraph AST {\n  node [shape=box];\n")
+		id := 0
+		root.writeDOT(&b, &id)
+		b.WriteString("}")
+		s = b.String()
+	default:
+		return fmt.Errorf("unknown AST print format %q", format)
+	}
+	_, err := fmt.Fprintln(w, s)
+	return err
+}
+
+// printNode is a node of the tree which is printed for an AST. Nodes without fields are leaves, such as tokens and
+// identifiers.
+type printNode struct {
+	label  string
+	value  any // Value of a leaf when printed as JSON
+	fields []printField
+}
+
+type printField struct {
+	name  string
+	named bool // Whether the name of the field is printed in the s-expression and tree formats
+	list  bool
+	nodes []*printNode
+}
+
+func newPrintNode(node Node) *printNode {
 	switch node := node.(type) {
 	case LiteralExpr:
-		return node.Value.Lexeme
+		return newPrintLeaf(node.Value.Lexeme)
 	case IdentExpr:
-		return node.Ident.Token.Lexeme
+		return newPrintLeaf(node.Ident.Token.Lexeme)
 	default:
 	}
 
 	nodeType := reflect.TypeOf(node)
 	nodeValue := reflect.ValueOf(node)
 
-	var children []string
+	n := &printNode{label: nodeType.Name()}
 	for i := 0; i < nodeType.NumField(); i++ {
 		field := nodeType.Field(i)
 		value := nodeValue.Field(i)
@@ -42,69 +112,87 @@ func sprint(node Node, depth int) string {
 		}
 
 		if field.Type.Kind() == reflect.Slice {
-			prefix := ""
-			extraDepth := 0
-			if named {
-				children = append(children, field.Name+": [")
-				if value.Len() == 0 {
-					children[len(children)-1] += "]"
-					continue
-				}
-				prefix = "  "
-				extraDepth = 1
-			}
+			f := printField{name: field.Name, named: named, list: true}
 			for j := 0; j < value.Len(); j++ {
-				child, ok := childString(value.Index(j), depth+1+extraDepth)
+				child, ok := newPrintChild(value.Index(j))
 				if !ok {
 					panic(fmt.Sprintf("%s field %s element %d has unsupported type: %T", nodeType.Name(), field.Name, j, value.Index(j).Interface()))
 				}
-				children = append(children, prefix+child)
-			}
-			if named {
-				children = append(children, "]")
+				f.nodes = append(f.nodes, child)
 			}
+			n.fields = append(n.fields, f)
 			continue
 		}
 
-		prefix := ""
-		if named {
-			prefix = field.Name + ": "
-		}
-
 		if value.Kind() == reflect.Interface && value.IsNil() {
 			continue
 		}
 
-		child, ok := childString(value, depth+1)
+		child, ok := newPrintChild(value)
 		if !ok {
 			panic(fmt.Sprintf("%s field %s has unsupported type: %T", nodeType.Name(), field.Name, value.Interface()))
 		}
-		children = append(children, prefix+child)
+		n.fields = append(n.fields, printField{name: field.Name, named: named, nodes: []*printNode{child}})
 	}
-
-	return sexpr(nodeType.Name(), depth, children...)
+	return n
 }
 
-func childString(value reflect.Value, depth int) (string, bool) {
-	var child string
+func newPrintChild(value reflect.Value) (*printNode, bool) {
 	switch value := value.Interface().(type) {
 	case token.Token:
-		child = value.Lexeme
+		return newPrintLeaf(value.Lexeme), true
 	case Ident:
-		child = value.Token.Lexeme
+		return newPrintLeaf(value.Token.Lexeme), true
 	case Node:
-		child = sprint(value, depth)
+		return newPrintNode(value), true
 	case bool:
-		child = fmt.Sprint(value)
+		return &printNode{label: fmt.Sprint(value), value: value}, true
 	default:
-		return "", false
+		return nil, false
 	}
-	return child, true
 }
 
-func sexpr(name string, depth int, children ...string) string {
+func newPrintLeaf(lexeme string) *printNode {
+	return &printNode{label: lexeme, value: lexeme}
+}
+
+func (n *printNode) isLeaf() bool {
+	return n.value != nil
+}
+
+func (n *printNode) sexpr(depth int) string {
+	if n.isLeaf() {
+		return n.label
+	}
+	var children []string
+	for _, f := range n.fields {
+		if !f.list {
+			prefix := ""
+			if f.named {
+				prefix = f.name + ": "
+			}
+			children = append(children, prefix+f.nodes[0].sexpr(depth+1))
+			continue
+		}
+		if !f.named {
+			for _, child := range f.nodes {
+				children = append(children, child.sexpr(depth+1))
+			}
+			continue
+		}
+		if len(f.nodes) == 0 {
+			children = append(children, f.name+": []")
+			continue
+		}
+		children = append(children, f.name+": [")
+		for _, child := range f.nodes {
+			children = append(children, "  "+child.sexpr(depth+2))
+		}
+		children = append(children, "]")
+	}
+
 	var b strings.Builder
-	fmt.Fprint(&b, "(", name)
+	fmt.Fprint(&b, "(", n.label)
 	for _, child := range children {
 		fmt.Fprint(&b, "\n", strings.Repeat("  ", depth+1), child)
 	}
@@ -112,6 +200,104 @@ func sexpr(name string, depth int, children ...string) string {
 	return b.String()
 }
 
+// writeTree writes the branches below the node to b. indent is written before each branch.
+func (n *printNode) writeTree(b *strings.Builder, indent string) {
+	type branch struct {
+		label string
+		node  *printNode // nil if the branch is a list of nodes
+		list  []*printNode
+	}
+	var branches []branch
+	for _, f := range n.fields {
+		switch {
+		case !f.list:
+			label := f.nodes[0].label
+			if f.named {
+				label = f.name + ": " + label
+			}
+			branches = append(branches, branch{label: label, node: f.nodes[0]})
+		case !f.named:
+			for _, child := range f.nodes {
+				branches = append(branches, branch{label: child.label, node: child})
+			}
+		case len(f.nodes) == 0:
+			branches = append(branches, branch{label: f.name + ": []"})
+		default:
+			branches = append(branches, branch{label: f.name, list: f.nodes})
+		}
+	}
+
+	for i, br := range branches {
+		connector, childIndent := "├── ", indent+"│   "
+		if i == len(branches)-1 {
+			connector, childIndent = "└── ", indent+"    "
+		}
+		fmt.Fprint(b, "\n", indent, connector, br.label)
+		switch {
+		case br.node != nil:
+			br.node.writeTree(b, childIndent)
+		case len(br.list) > 0:
+			list := &printNode{}
+			for _, child := range br.list {
+				list.fields = append(list.fields, printField{nodes: []*printNode{child}})
+			}
+			list.writeTree(b, childIndent)
+		}
+	}
+}
+
+// writeDOT writes the node and its descendants to b as DOT statements and returns the node's ID. id is the number used
+// in the ID of the next node to be written.
+func (n *printNode) writeDOT(b *strings.Builder, id *int) string {
+	nodeID := fmt.Sprintf("n%d", *id)
+	*id++
+	fmt.Fprintf(b, "  %s [label=%s];\n", nodeID, strconv.Quote(n.label))
+	for _, f := range n.fields {
+		for i, child := range f.nodes {
+			childID := child.writeDOT(b, id)
+			edgeLabel := f.name
+			if f.list {
+				edgeLabel = fmt.Sprintf("%s[%d]", f.name, i)
+			}
+			fmt.Fprintf(b, "  %s -> %s [label=%s];\n", nodeID, childID, strconv.Quote(edgeLabel))
+		}
+	}
+	return nodeID
+}
+
+// MarshalJSON marshals the node as its value if it's a leaf, or otherwise as an object containing its type and fields
+// in the order that they're declared.
+func (n *printNode) MarshalJSON() ([]byte, error) {
+	if n.isLeaf() {
+		return json.Marshal(n.value)
+	}
+	var b bytes.Buffer
+	b.WriteString(`{"type":`)
+	label, err := json.Marshal(n.label)
+	if err != nil {
+		return nil, err
+	}
+	b.Write(label)
+	for _, f := range n.fields {
+		var value any
+		switch {
+		case !f.list:
+			value = f.nodes[0]
+		case f.nodes == nil:
+			value = []*printNode{}
+		default:
+			value = f.nodes
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, ",%q:%s", f.name, data)
+	}
+	b.WriteString("}")
+	return b.Bytes(), nil
+}
+
 func parsePrintTag(structName string, field reflect.StructField) (named bool, ok bool) {
 	tags := strings.Split(field.Tag.Get("print"), ",")
 	if len(tags) == 1 && tags[0] == "" {
@@ -0,0 +1,97 @@
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/marcuscaisey/lox/lox/ast"
+)
+
+func TestFprint(t *testing.T) {
+	program := mustParse(t, "var x = 1 + 2;\nfun f() {}\n")
+	tests := []struct {
+		format ast.PrintFormat
+		want   string
+	}{
+		{
+			format: ast.PrintFormatSExpr,
+			want: `(Program
+  (VarDecl
+    Name: x
+    Initialiser: (BinaryExpr
+      Left: 1
+      Op: +
+      Right: 2))
+  (FunDecl
+    Name: f
+    Function: (Function
+      Params: []
+      Body: (BlockStmt))))
+`,
+		},
+		{
+			format: ast.PrintFormatTree,
+			want: `Program
+├── VarDecl
+│   ├── Name: x
+│   └── Initialiser: BinaryExpr
+│       ├── Left: 1
+│       ├── Op: +
+│       └── Right: 2
+└── FunDecl
+    ├── Name: f
+    └── Function: Function
+        ├── Params: []
+        └── Body: BlockStmt
+`,
+		},
+		{
+			format: ast.PrintFormatJSON,
+			want: `{"type":"Program","Stmts":[` +
+				`{"type":"VarDecl","Name":"x","Initialiser":{"type":"BinaryExpr","Left":"1","Op":"+","Right":"2"}},` +
+				`{"type":"FunDecl","Name":"f","Function":{"type":"Function","Params":[],"Body":{"type":"BlockStmt","Stmts":[]}}}]}
+`,
+		},
+		{
+			format: ast.PrintFormatDOT,
+			want: `digraph AST {
+  node [shape=box];
+  n0 [label="Program"];
+  n1 [label="VarDecl"];
+  n2 [label="x"];
+  n1 -> n2 [label="Name"];
+  n3 [label="BinaryExpr"];
+  n4 [label="1"];
+  n3 -> n4 [label="Left"];
+  n5 [label="+"];
+  n3 -> n5 [label="Op"];
+  n6 [label="2"];
+  n3 -> n6 [label="Right"];
+  n1 -> n3 [label="Initialiser"];
+  n0 -> n1 [label="Stmts[0]"];
+  n7 [label="FunDecl"];
+  n8 [label="f"];
+  n7 -> n8 [label="Name"];
+  n9 [label="Function"];
+  n10 [label="BlockStmt"];
+  n9 -> n10 [label="Body"];
+  n7 -> n9 [label="Function"];
+  n0 -> n7 [label="Stmts[1]"];
+}
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(string(test.format), func(t *testing.T) {
+			var b strings.Builder
+			if err := ast.Fprint(&b, program, test.format); err != nil {
+				t.Fatalf("Fprint() returned error: %s", err)
+			}
+			if diff := cmp.Diff(test.want, b.String()); diff != "" {
+				t.Errorf("Fprint() output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package ast_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/marcuscaisey/lox/lox/ast"
+	"github.com/marcuscaisey/lox/lox/token"
+)
+
+func TestNodeRange(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		node func(ast.Program) ast.Node
+		want string
+	}{
+		{
+			name: "EmptyProgram",
+			src:  "",
+			node: func(p ast.Program) ast.Node { return p },
+			want: "1:0-1:0",
+		},
+		{
+			name: "ProgramWithOnlyComments",
+			src:  "// a\n// b\n",
+			node: func(p ast.Program) ast.Node { return p },
+			want: "1:0-3:0",
+		},
+		{
+			name: "ProgramWithLeadingAndTrailingWhitespace",
+			src:  "\n\n  print 1;  \n\n",
+			node: func(p ast.Program) ast.Node { return p },
+			want: "1:0-5:0",
+		},
+		{
+			name: "ProgramWithoutEOF",
+			src:  "\n  print 1;\nprint 2;  \n",
+			node: func(p ast.Program) ast.Node { return ast.Program{Stmts: p.Stmts} },
+			want: "2:2-3:8",
+		},
+		{
+			name: "EmptyProgramWithoutEOF",
+			src:  "",
+			node: func(p ast.Program) ast.Node { return ast.Program{} },
+			want: "0:0-0:0",
+		},
+		{
+			name: "EmptyBlock",
+			src:  "{\n}",
+			node: func(p ast.Program) ast.Node { return p.Stmts[0] },
+			want: "1:0-2:1",
+		},
+		{
+			name: "SingleStatementBlock",
+			src:  "{ print 1; }",
+			node: func(p ast.Program) ast.Node { return p.Stmts[0].(ast.BlockStmt).Stmts[0] },
+			want: "1:2-1:10",
+		},
+		{
+			name: "EmptyFunctionBody",
+			src:  "fun f() {}",
+			node: func(p ast.Program) ast.Node { return p.Stmts[0].(ast.FunDecl).Function.Body },
+			want: "1:8-1:10",
+		},
+		{
+			name: "SingleStatementFunctionBody",
+			src:  "fun f() { return 1; }",
+			node: func(p ast.Program) ast.Node { return p.Stmts[0].(ast.FunDecl).Function.Body.Stmts[0] },
+			want: "1:10-1:19",
+		},
+		{
+			name: "EmptyClass",
+			src:  "class A {}",
+			node: func(p ast.Program) ast.Node { return p.Stmts[0] },
+			want: "1:0-1:10",
+		},
+		{
+			name: "EmptyMethodBody",
+			src:  "class A { b() {} }",
+			node: func(p ast.Program) ast.Node { return p.Stmts[0].(ast.ClassDecl).Body[0] },
+			want: "1:10-1:16",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			program := mustParse(t, test.src)
+			node := test.node(program)
+			if got := formatRange(node.Start(), node.End()); got != test.want {
+				t.Errorf("range = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func formatRange(start, end token.Position) string {
+	return fmt.Sprintf("%d:%d-%d:%d", start.Line, start.Column, end.Line, end.Column)
+}
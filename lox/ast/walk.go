@@ -13,6 +13,8 @@ func Walk(node Node, f func(Node) bool) {
 	case CommentStmt:
 	case InlineCommentStmt:
 		Walk(node.Stmt, f)
+	case ImportStmt:
+		Walk(node.Name, f)
 	case VarDecl:
 		Walk(node.Name, f)
 		if node.Initialiser != nil {
@@ -26,6 +28,9 @@ func Walk(node Node, f func(Node) bool) {
 		walkSlice(node.Body.Stmts, f)
 	case ClassDecl:
 		Walk(node.Name, f)
+		if node.Superclass != nil {
+			Walk(node.Superclass, f)
+		}
 		walkSlice(node.Body, f)
 	case MethodDecl:
 		Walk(node.Name, f)
@@ -56,6 +61,19 @@ func Walk(node Node, f func(Node) bool) {
 			Walk(node.Update, f)
 		}
 		Walk(node.Body, f)
+	case ForInStmt:
+		Walk(node.Name, f)
+		Walk(node.Iterable, f)
+		Walk(node.Body, f)
+	case TryStmt:
+		Walk(node.Body, f)
+		if node.HasCatch() {
+			Walk(node.CatchName, f)
+			Walk(node.CatchBody, f)
+		}
+		if node.HasFinally() {
+			Walk(node.FinallyBody, f)
+		}
 	case IllegalStmt:
 	case BreakStmt:
 	case ContinueStmt:
@@ -63,14 +81,20 @@ func Walk(node Node, f func(Node) bool) {
 		if node.Value != nil {
 			Walk(node.Value, f)
 		}
+	case ThrowStmt:
+		Walk(node.Value, f)
 	case FunExpr:
 		Walk(node.Function, f)
 	case GroupExpr:
 		Walk(node.Expr, f)
 	case LiteralExpr:
+	case InterpolatedStringExpr:
+		walkSlice(node.Exprs, f)
 	case IdentExpr:
 		Walk(node.Ident, f)
 	case ThisExpr:
+	case SuperExpr:
+		Walk(node.Method, f)
 	case CallExpr:
 		Walk(node.Callee, f)
 		walkSlice(node.Args, f)
@@ -79,8 +103,12 @@ func Walk(node Node, f func(Node) bool) {
 		Walk(node.Name, f)
 	case UnaryExpr:
 		Walk(node.Right, f)
+	case IncrementExpr:
+		Walk(node.Operand, f)
 	case BinaryExpr:
-		Walk(node.Left, f)
+		if node.Left != nil {
+			Walk(node.Left, f)
+		}
 		Walk(node.Right, f)
 	case TernaryExpr:
 		Walk(node.Condition, f)
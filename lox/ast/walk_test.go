@@ -0,0 +1,113 @@
+package ast_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/marcuscaisey/lox/lox/ast"
+)
+
+const walkTestProgram = `var a = 1;
+{
+  {
+    a = a ? 2 : 3;
+  }
+}
+if (a) print a; else print -a;
+for (var i = 0; i < 2; i = i + 1) f(i, fun(x) { return x; });
+`
+
+func TestWalk(t *testing.T) {
+	program := mustParse(t, walkTestProgram)
+
+	var got []string
+	ast.Walk(program, func(node ast.Node) bool {
+		got = append(got, describeWalkedNode(node))
+		return true
+	})
+
+	want := []string{
+		"ast.Program 1:0",
+		"ast.VarDecl 1:4",
+		"ast.Ident 1:4",
+		"ast.LiteralExpr 1:8",
+		"ast.BlockStmt 2:0",
+		"ast.BlockStmt 3:2",
+		"ast.ExprStmt 4:4",
+		"ast.AssignmentExpr 4:4",
+		"ast.Ident 4:4",
+		"ast.TernaryExpr 4:8",
+		"ast.IdentExpr 4:8",
+		"ast.Ident 4:8",
+		"ast.LiteralExpr 4:12",
+		"ast.LiteralExpr 4:16",
+		"ast.IfStmt 7:0",
+		"ast.IdentExpr 7:4",
+		"ast.Ident 7:4",
+		"ast.PrintStmt 7:7",
+		"ast.IdentExpr 7:13",
+		"ast.Ident 7:13",
+		"ast.PrintStmt 7:21",
+		"ast.UnaryExpr 7:27",
+		"ast.IdentExpr 7:28",
+		"ast.Ident 7:28",
+		"ast.ForStmt 8:0",
+		"ast.VarDecl 8:9",
+		"ast.Ident 8:9",
+		"ast.LiteralExpr 8:13",
+		"ast.BinaryExpr 8:16",
+		"ast.IdentExpr 8:16",
+		"ast.Ident 8:16",
+		"ast.LiteralExpr 8:20",
+		"ast.AssignmentExpr 8:23",
+		"ast.Ident 8:23",
+		"ast.BinaryExpr 8:27",
+		"ast.IdentExpr 8:27",
+		"ast.Ident 8:27",
+		"ast.LiteralExpr 8:31",
+		"ast.ExprStmt 8:34",
+		"ast.CallExpr 8:34",
+		"ast.IdentExpr 8:34",
+		"ast.Ident 8:34",
+		"ast.IdentExpr 8:36",
+		"ast.Ident 8:36",
+		"ast.FunExpr 8:39",
+		"ast.Function 8:42",
+		"ast.Ident 8:43",
+		"ast.ReturnStmt 8:48",
+		"ast.IdentExpr 8:55",
+		"ast.Ident 8:55",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("incorrect nodes visited (-want +got):\n%s", diff)
+	}
+}
+
+func TestWalkSkipsChildren(t *testing.T) {
+	program := mustParse(t, walkTestProgram)
+
+	var got []string
+	ast.Walk(program, func(node ast.Node) bool {
+		got = append(got, describeWalkedNode(node))
+		// Only visit the top level statements.
+		_, ok := node.(ast.Program)
+		return ok
+	})
+
+	want := []string{
+		"ast.Program 1:0",
+		"ast.VarDecl 1:4",
+		"ast.BlockStmt 2:0",
+		"ast.IfStmt 7:0",
+		"ast.ForStmt 8:0",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("incorrect nodes visited (-want +got):\n%s", diff)
+	}
+}
+
+func describeWalkedNode(node ast.Node) string {
+	return fmt.Sprintf("%T %d:%d", node, node.Start().Line, node.Start().Column)
+}
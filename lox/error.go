@@ -1,6 +1,7 @@
 package lox
 
 import (
+	"encoding/json"
 	"fmt"
 	"slices"
 	"strings"
@@ -12,14 +13,202 @@ import (
 	"github.com/marcuscaisey/lox/lox/token"
 )
 
+// ErrorCode identifies a kind of [Error] so that tools can handle it specially, such as by offering a fix for it.
+type ErrorCode string
+
+// Every error which is reported by the lexer, parser, static analysis, or at runtime has a code. Each code has an
+// extended description which can be looked up with [ErrorCode.Explanation].
+const (
+	// ErrorCodeIllegalCharacter is the code of the error reported when the source code contains a character which
+	// can't start any token. The error's range is the character.
+	ErrorCodeIllegalCharacter ErrorCode = "illegal-character"
+	// ErrorCodeUnterminatedString is the code of the error reported when a string literal is missing its closing quote.
+	// The error's range is the string literal.
+	ErrorCodeUnterminatedString ErrorCode = "unterminated-string"
+	// ErrorCodeInvalidUTF8 is the code of the error reported when the source code isn't valid UTF-8. The error's range
+	// is the invalid byte.
+	ErrorCodeInvalidUTF8 ErrorCode = "invalid-utf8"
+	// ErrorCodeUnexpectedToken is the code of the error reported when the parser encounters a token which isn't valid
+	// where it appears. The error's range is the token.
+	ErrorCodeUnexpectedToken ErrorCode = "unexpected-token"
+	// ErrorCodeMissingSemicolon is the code of the error reported when a statement is missing its trailing semicolon.
+	// The error's range is the token which the semicolon should follow.
+	ErrorCodeMissingSemicolon ErrorCode = "missing-semicolon"
+	// ErrorCodeMisplacedComment is the code of the error reported when a comment appears in the middle of an
+	// expression. The error's range is the comment.
+	ErrorCodeMisplacedComment ErrorCode = "misplaced-comment"
+	// ErrorCodeInvalidAssignmentTarget is the code of the error reported when the target of an assignment, increment,
+	// or decrement isn't a variable or property. The error's range is the target.
+	ErrorCodeInvalidAssignmentTarget ErrorCode = "invalid-assignment-target"
+	// ErrorCodeInvalidModuleName is the code of the error reported when the name of an imported module isn't a valid
+	// identifier. The error's range is the import path.
+	ErrorCodeInvalidModuleName ErrorCode = "invalid-module-name"
+	// ErrorCodeNestingTooDeep is the code of the error reported when statements or expressions are nested too deeply to
+	// be parsed. The error's range is the token at which the limit was exceeded.
+	ErrorCodeNestingTooDeep ErrorCode = "nesting-too-deep"
+	// ErrorCodeShadowedBuiltin is the code of the error reported when a declaration shadows a built-in. The error's
+	// range is the identifier of the declaration.
+	ErrorCodeShadowedBuiltin ErrorCode = "shadowed-builtin"
+	// ErrorCodeShadowedDeclaration is the code of the warning reported when a local declaration shadows one in an
+	// enclosing scope. The error's range is the identifier of the declaration.
+	ErrorCodeShadowedDeclaration ErrorCode = "shadowed-declaration"
+	// ErrorCodeUnusedResult is the code of the warning reported when the result of an expression statement which has no
+	// side effects is not used. The error's range is the statement.
+	ErrorCodeUnusedResult ErrorCode = "unused-result"
+	// ErrorCodeUnusedDeclaration is the code of the warning reported when an identifier is declared and never used. The
+	// error's range is the identifier of the declaration.
+	ErrorCodeUnusedDeclaration ErrorCode = "unused-declaration"
+	// ErrorCodeUndeclared is the code of the error reported when an identifier is used which has not been declared. The
+	// error's range is the identifier.
+	ErrorCodeUndeclared ErrorCode = "undeclared"
+	// ErrorCodeUsedBeforeDeclaration is the code of the error reported when an identifier is used before the
+	// declaration in the same scope. The error's range is the identifier.
+	ErrorCodeUsedBeforeDeclaration ErrorCode = "used-before-declaration"
+	// ErrorCodeUndefined is the code of the error reported when a variable is used which has been declared but not
+	// defined yet. The error's range is the identifier.
+	ErrorCodeUndefined ErrorCode = "undefined"
+	// ErrorCodeRedeclared is the code of the error reported when an identifier is declared more than once in the same
+	// scope. The error's range is the identifier of the second declaration.
+	ErrorCodeRedeclared ErrorCode = "redeclared"
+	// ErrorCodeSelfReferentialInitialiser is the code of the error reported when a local variable is read in its own
+	// initialiser. The error's range is the identifier.
+	ErrorCodeSelfReferentialInitialiser ErrorCode = "self-referential-initialiser"
+	// ErrorCodeSelfInheritance is the code of the error reported when a class inherits from itself. The error's range
+	// is the superclass.
+	ErrorCodeSelfInheritance ErrorCode = "self-inheritance"
+	// ErrorCodeTooManyParameters is the code of the error reported when a function has more parameters than the
+	// maximum. The error's range is the parameters over the maximum.
+	ErrorCodeTooManyParameters ErrorCode = "too-many-parameters"
+	// ErrorCodeTooManyArguments is the code of the error reported when a call passes more arguments than the maximum.
+	// The error's range is the arguments over the maximum.
+	ErrorCodeTooManyArguments ErrorCode = "too-many-arguments"
+	// ErrorCodeInvalidAccessor is the code of the error reported when a property getter or setter has the wrong number
+	// of parameters, or a property has a setter without a getter. The error's range is the offending parameters or
+	// name.
+	ErrorCodeInvalidAccessor ErrorCode = "invalid-accessor"
+	// ErrorCodeMisplacedStatement is the code of the error reported when a statement is used outside of the context
+	// where it's valid, such as break outside of a loop. The error's range is the statement.
+	ErrorCodeMisplacedStatement ErrorCode = "misplaced-statement"
+	// ErrorCodeMisplacedExpression is the code of the error reported when this or super is used outside of a method, or
+	// super is used in a class without a superclass. The error's range is the keyword.
+	ErrorCodeMisplacedExpression ErrorCode = "misplaced-expression"
+	// ErrorCodeConstructorReturnValue is the code of the error reported when a constructor returns a value. The error's
+	// range is the return statement.
+	ErrorCodeConstructorReturnValue ErrorCode = "constructor-return-value"
+	// ErrorCodeUnreachableCode is the code of the error reported when a statement follows one which always exits the
+	// enclosing block. The error's range is the statement.
+	ErrorCodeUnreachableCode ErrorCode = "unreachable-code"
+	// ErrorCodeInvalidPlaceholder is the code of the error reported when the placeholder identifier _ is used as a
+	// value or field name. The error's range is the identifier.
+	ErrorCodeInvalidPlaceholder ErrorCode = "invalid-placeholder"
+	// ErrorCodeImportFailed is the code of the error reported when a module can't be imported. The error's range is
+	// the import path.
+	ErrorCodeImportFailed ErrorCode = "import-failed"
+	// ErrorCodeImportCycle is the code of the error reported when a module imports itself, directly or indirectly. The
+	// error's range is the import path.
+	ErrorCodeImportCycle ErrorCode = "import-cycle"
+	// ErrorCodeInvalidOperand is the code of the runtime error reported when an operator is used with a value of a type
+	// which it doesn't support. The error's range is the operator or the offending operand.
+	ErrorCodeInvalidOperand ErrorCode = "invalid-operand"
+	// ErrorCodeNilOperand is the code of the runtime error reported when an operand of an arithmetic or comparison
+	// operator is nil. The error's range is the operand.
+	ErrorCodeNilOperand ErrorCode = "nil-operand"
+	// ErrorCodeDivisionByZero is the code of the runtime error reported when a number is divided by or taken modulo 0.
+	// The error's range is the operator.
+	ErrorCodeDivisionByZero ErrorCode = "division-by-zero"
+	// ErrorCodeNotCallable is the code of the runtime error reported when a value which isn't a function or class is
+	// called. The error's range is the callee.
+	ErrorCodeNotCallable ErrorCode = "not-callable"
+	// ErrorCodeWrongArgumentCount is the code of the runtime error reported when a function is called with the wrong
+	// number of arguments. The error's range is the call or the extra arguments.
+	ErrorCodeWrongArgumentCount ErrorCode = "wrong-argument-count"
+	// ErrorCodeNotAClass is the code of the runtime error reported when a class inherits from a value which isn't a
+	// class. The error's range is the superclass.
+	ErrorCodeNotAClass ErrorCode = "not-a-class"
+	// ErrorCodeNotIterable is the code of the runtime error reported when a for-in loop iterates over a value which
+	// can't be iterated over. The error's range is the iterable.
+	ErrorCodeNotIterable ErrorCode = "not-iterable"
+	// ErrorCodeNoProperty is the code of the runtime error reported when a property is accessed which doesn't exist.
+	// The error's range is the property name.
+	ErrorCodeNoProperty ErrorCode = "no-property"
+	// ErrorCodeInvalidPropertyAccess is the code of the runtime error reported when a property is accessed or assigned
+	// on a value which doesn't have properties. The error's range is the property access.
+	ErrorCodeInvalidPropertyAccess ErrorCode = "invalid-property-access"
+	// ErrorCodeReadOnlyProperty is the code of the runtime error reported when a property which has a getter and no
+	// setter is assigned to. The error's range is the property name.
+	ErrorCodeReadOnlyProperty ErrorCode = "read-only-property"
+	// ErrorCodeBuiltinError is the code of the runtime error reported when a built-in function fails, such as when
+	// it's passed an argument of the wrong type. The error's range is the call.
+	ErrorCodeBuiltinError ErrorCode = "builtin-error"
+	// ErrorCodeUncaughtError is the code of the runtime error reported when a thrown value isn't caught. The error's
+	// range is the throw statement.
+	ErrorCodeUncaughtError ErrorCode = "uncaught-error"
+	// ErrorCodeStackOverflow is the code of the runtime error reported when the maximum call depth is exceeded. The
+	// error's range is the call which exceeded it.
+	ErrorCodeStackOverflow ErrorCode = "stack-overflow"
+)
+
+// Severity is the severity of an [Error].
+type Severity int
+
+const (
+	// SeverityError is the severity of an error which prevents a program from being executed.
+	SeverityError Severity = iota
+	// SeverityWarning is the severity of an error which is reported but doesn't prevent a program from being executed.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		panic(fmt.Sprintf("unexpected severity: %d", int(s)))
+	}
+}
+
 // Error describes an error that occurred during the execution of a Lox program.
 // It can describe any error which can be attributed to a range of characters in the source code.
 type Error struct {
+	Msg      string
+	Code     ErrorCode // Empty if the error doesn't have a code.
+	Severity Severity
+	Start    token.Position
+	End      token.Position
+	Related  []Related // Other ranges of characters which help to explain the error
+	Fixes    []Fix     // Suggested fixes for the error
+}
+
+// Related is a range of characters in the source code which is related to an [Error], such as the original
+// declaration of an identifier which has been declared again.
+type Related struct {
 	Msg   string
 	Start token.Position
 	End   token.Position
 }
 
+// Fix is a suggested fix for an [Error] which replaces the range of characters between Start and End with NewText.
+type Fix struct {
+	Msg     string
+	Start   token.Position
+	End     token.Position
+	NewText string
+}
+
+// AddFixf adds a [Fix] to the error which replaces the given range with newText.
+// The message is constructed from the given format string and arguments, as in [fmt.Sprintf].
+func (e *Error) AddFixf(rang token.Range, newText string, format string, args ...any) {
+	e.Fixes = append(e.Fixes, Fix{Msg: fmt.Sprintf(format, args...), Start: rang.Start(), End: rang.End(), NewText: newText})
+}
+
+// AddRelatedf adds a [Related] range to the error.
+// The message is constructed from the given format string and arguments, as in [fmt.Sprintf].
+func (e *Error) AddRelatedf(rang token.Range, format string, args ...any) {
+	e.Related = append(e.Related, Related{Msg: fmt.Sprintf(format, args...), Start: rang.Start(), End: rang.End()})
+}
+
 // NewError creates a [*Error] with the given message and range.
 func NewError(rang token.Range, message string) error {
 	return NewErrorf(rang, "%s", message)
@@ -37,63 +226,160 @@ func NewErrorf(rang token.Range, format string, args ...any) error {
 
 }
 
+// NewCodedError is like [NewError] but also sets the code of the error.
+func NewCodedError(code ErrorCode, rang token.Range, message string) error {
+	return NewCodedErrorf(code, rang, "%s", message)
+}
+
+// NewCodedErrorf is like [NewErrorf] but also sets the code of the error.
+func NewCodedErrorf(code ErrorCode, rang token.Range, format string, args ...any) error {
+	e := NewErrorf(rang, format, args...).(*Error)
+	e.Code = code
+	return e
+}
+
 // Error formats the error by displaying the error message and highlighting the range of characters in the source code
-// that the error applies to.
+// that the error applies to. Each related range is displayed in the same way beneath it, followed by the message of
+// each suggested fix.
 //
 // For example:
 //
-//	test.lox:2:7: error: unterminated string literal
-//	print "bar;
-//	      ~~~~~
+//	test.lox:2:5: error: x has already been declared
+//	var x = 2;
+//	    ~
+//	test.lox:1:5: note: x was first declared here
+//	var x = 1;
+//	    ~
 func (e *Error) Error() string {
 	var b strings.Builder
-	buildString := func() string {
-		return strings.TrimSuffix(b.String(), "\n")
+	colour := "${RED}"
+	if e.Severity == SeverityWarning {
+		colour = "${YELLOW}"
 	}
+	ansi.Fprintf(&b, "${BOLD}%m: "+colour+"%s${DEFAULT}: %s${DEFAULT}${RESET_BOLD}\n", e.Start, e.Severity, e.Msg)
+	writeSource(&b, e.Start, e.End, colour)
+	for _, related := range e.Related {
+		ansi.Fprintf(&b, "${BOLD}%m: ${CYAN}note${DEFAULT}: %s${RESET_BOLD}\n", related.Start, related.Msg)
+		writeSource(&b, related.Start, related.End, "${CYAN}")
+	}
+	for _, fix := range e.Fixes {
+		ansi.Fprintf(&b, "${BOLD}%m: ${GREEN}hint${DEFAULT}: %s${RESET_BOLD}\n", fix.Start, fix.Msg)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
 
-	ansi.Fprintf(&b, "${BOLD}%m: ${RED}error${DEFAULT}: %s${DEFAULT}${RESET_BOLD}\n", e.Start, e.Msg)
-
-	lines := make([]string, e.End.Line-e.Start.Line+1)
-	for i := e.Start.Line; i <= e.End.Line; i++ {
-		line := e.Start.File.Line(i)
+// writeSource writes the lines of source code between start and end to b, highlighting the range between them in the
+// given colour.
+func writeSource(b *strings.Builder, start, end token.Position, colour string) {
+	lines := make([]string, end.Line-start.Line+1)
+	for i := start.Line; i <= end.Line; i++ {
+		line := start.File.Line(i)
 		if !utf8.Valid(line) {
-			// If any of the lines are not valid UTF-8 then we can't display the source code, so just return the error
+			// If any of the lines are not valid UTF-8 then we can't display the source code, so just display the error
 			// message on its own. This is a very rare case and it's not worth the effort to handle it any better.
-			return buildString()
+			return
 		}
-		lines[i-e.Start.Line] = string(line)
+		lines[i-start.Line] = string(line)
 	}
 
 	printLine := func(line string) {
-		ansi.Fprint(&b, "${FAINT}", line, "${RESET_BOLD}\n")
+		ansi.Fprint(b, "${FAINT}", token.ExpandTabs(line), "${RESET_BOLD}\n")
 	}
 	printLineHighlight := func(line string, start, end int) {
-		leadingWhitespace := strings.Repeat(" ", runewidth.StringWidth(line[:start]))
-		tildes := strings.Repeat("~", runewidth.StringWidth(line[start:end]))
-		ansi.Fprint(&b, leadingWhitespace, "${FAINT}${RED}", tildes, "${DEFAULT}${RESET_BOLD}\n")
+		startWidth := runewidth.StringWidth(token.ExpandTabs(line[:start]))
+		endWidth := runewidth.StringWidth(token.ExpandTabs(line[:end]))
+		leadingWhitespace := strings.Repeat(" ", startWidth)
+		tildes := strings.Repeat("~", endWidth-startWidth)
+		ansi.Fprint(b, leadingWhitespace, "${FAINT}", colour, tildes, "${DEFAULT}${RESET_BOLD}\n")
 	}
 
 	printLine(lines[0])
-	if e.Start == e.End {
+	if start == end {
 		// There's nothing to highlight
-		return buildString()
+		return
 	}
 
 	if len(lines) == 1 {
-		printLineHighlight(lines[0], e.Start.Column, e.End.Column)
+		printLineHighlight(lines[0], start.Column, end.Column)
 	} else {
-		printLineHighlight(lines[0], e.Start.Column, len(lines[0]))
+		printLineHighlight(lines[0], start.Column, len(lines[0]))
 		for _, line := range lines[1 : len(lines)-1] {
 			printLine(line)
 			printLineHighlight(line, 0, len(line))
 		}
 		if lastLine := lines[len(lines)-1]; len(lastLine) > 0 {
 			printLine(lastLine)
-			printLineHighlight(lastLine, 0, e.End.Column)
+			printLineHighlight(lastLine, 0, end.Column)
 		}
 	}
+}
+
+// FormatPlain formats the error in the same way as [Error.Error] but never includes ANSI escape sequences, regardless of
+// whether [ansi.Enabled] is set.
+func (e *Error) FormatPlain() string {
+	return ansi.Strip(e.Error())
+}
 
-	return buildString()
+type jsonPosition struct {
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+func newJSONPosition(pos token.Position) jsonPosition {
+	var file string
+	if pos.File != nil {
+		file = pos.File.Name
+	}
+	return jsonPosition{File: file, Line: pos.Line, Column: pos.ColumnUTF16() + 1}
+}
+
+// MarshalJSON implements [json.Marshaler]. The error is encoded as an object containing its message, its code if it
+// has one, its severity, the start and end positions of the range of characters that it applies to, and its related
+// ranges and suggested fixes if it has any. Lines and columns are 1-based. Columns are counted in UTF-16 code units, as
+// they are in SARIF and LSP, so unlike the columns displayed by [Error.Error], they don't depend on how wide characters
+// and tabs are displayed.
+//
+// For example:
+//
+//	{"message":"unterminated string literal","severity":"error","start":{"file":"test.lox","line":2,"column":7},"end":{"file":"test.lox","line":2,"column":12}}
+func (e *Error) MarshalJSON() ([]byte, error) {
+	type jsonRelated struct {
+		Message string       `json:"message"`
+		Start   jsonPosition `json:"start"`
+		End     jsonPosition `json:"end"`
+	}
+	type jsonFix struct {
+		Message string       `json:"message"`
+		Start   jsonPosition `json:"start"`
+		End     jsonPosition `json:"end"`
+		NewText string       `json:"newText"`
+	}
+	related := make([]jsonRelated, len(e.Related))
+	for i, r := range e.Related {
+		related[i] = jsonRelated{Message: r.Msg, Start: newJSONPosition(r.Start), End: newJSONPosition(r.End)}
+	}
+	fixes := make([]jsonFix, len(e.Fixes))
+	for i, f := range e.Fixes {
+		fixes[i] = jsonFix{Message: f.Msg, Start: newJSONPosition(f.Start), End: newJSONPosition(f.End), NewText: f.NewText}
+	}
+	return json.Marshal(struct {
+		Message  string        `json:"message"`
+		Code     ErrorCode     `json:"code,omitempty"`
+		Severity string        `json:"severity"`
+		Start    jsonPosition  `json:"start"`
+		End      jsonPosition  `json:"end"`
+		Related  []jsonRelated `json:"related,omitempty"`
+		Fixes    []jsonFix     `json:"fixes,omitempty"`
+	}{
+		Message:  e.Msg,
+		Code:     e.Code,
+		Severity: e.Severity.String(),
+		Start:    newJSONPosition(e.Start),
+		End:      newJSONPosition(e.End),
+		Related:  related,
+		Fixes:    fixes,
+	})
 }
 
 // Errors is a list of [*Error]s.
@@ -111,9 +397,28 @@ func (e *Errors) Addf(rang token.Range, format string, args ...any) {
 	*e = append(*e, NewErrorf(rang, format, args...).(*Error))
 }
 
-// Sort sorts the errors by their start position.
+// AddCodedf is like [Errors.Addf] but also sets the code of the error.
+func (e *Errors) AddCodedf(code ErrorCode, rang token.Range, format string, args ...any) {
+	*e = append(*e, NewCodedErrorf(code, rang, format, args...).(*Error))
+}
+
+// AddWarningf adds a [*Error] with [SeverityWarning] to the list of errors.
+// The parameters are the same as for [NewErrorf].
+func (e *Errors) AddWarningf(rang token.Range, format string, args ...any) {
+	e.Addf(rang, format, args...)
+	(*e)[len(*e)-1].Severity = SeverityWarning
+}
+
+// AddCodedWarningf is like [Errors.AddWarningf] but also sets the code of the warning.
+func (e *Errors) AddCodedWarningf(code ErrorCode, rang token.Range, format string, args ...any) {
+	e.AddWarningf(rang, format, args...)
+	(*e)[len(*e)-1].Code = code
+}
+
+// Sort sorts the errors by their start position. Errors with the same start position are kept in the order that they
+// were added.
 func (e Errors) Sort() {
-	slices.SortFunc(e, func(e1, e2 *Error) int {
+	slices.SortStableFunc(e, func(e1, e2 *Error) int {
 		return e1.Start.Compare(e2.Start)
 	})
 }
@@ -131,12 +436,36 @@ func (e Errors) Error() string {
 	return strings.Join(msgs, "\n")
 }
 
-// Err returns the error list unchanged if its non-empty, otherwise nil.
+// Err returns the error list sorted by start position and with duplicate errors removed if it contains any errors with
+// [SeverityError], otherwise nil. Two errors are duplicates if they have the same message and range. Warnings are
+// included in the returned list but don't cause it to be returned on their own.
 // This should be used to return an [Errors] from a function as an [error] so that it becomes an untyped nil if there
 // are no errors.
 func (e Errors) Err() error {
-	if len(e) == 0 {
+	if !slices.ContainsFunc(e, func(err *Error) bool { return err.Severity == SeverityError }) {
 		return nil
 	}
-	return e
+	e.Sort()
+	return e.dedupe()
+}
+
+// dedupe removes duplicate errors from the error list, which must be sorted by start position, and returns the
+// result. The first occurrence of each error is kept.
+func (e Errors) dedupe() Errors {
+	deduped := e[:0]
+	// Duplicates have the same start position, so only the errors since the start position last changed need to be
+	// checked.
+	sameStartIdx := 0
+	for _, err := range e {
+		if len(deduped) > 0 && deduped[sameStartIdx].Start != err.Start {
+			sameStartIdx = len(deduped)
+		}
+		isDuplicate := slices.ContainsFunc(deduped[sameStartIdx:], func(other *Error) bool {
+			return other.Msg == err.Msg && other.End == err.End
+		})
+		if !isDuplicate {
+			deduped = append(deduped, err)
+		}
+	}
+	return deduped
 }
@@ -0,0 +1,289 @@
+package lox
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/marcuscaisey/lox/lox/ansi"
+	"github.com/marcuscaisey/lox/lox/token"
+)
+
+func TestErrorsErrRemovesDuplicates(t *testing.T) {
+	file := token.NewFile("test.lox", []byte("var x = y;\nprint x;\n"))
+	rang := func(line, start, end int) token.Range {
+		return token.Token{
+			StartPos: token.Position{File: file, Line: line, Column: start},
+			EndPos:   token.Position{File: file, Line: line, Column: end},
+		}
+	}
+
+	var errs Errors
+	errs.Add(rang(2, 6, 7), "x has not been defined")
+	errs.Add(rang(1, 8, 9), "y has not been defined")
+	errs.Add(rang(1, 8, 9), "y has not been used")
+	errs.Add(rang(1, 8, 9), "y has not been defined")
+	errs.Add(rang(1, 4, 9), "y has not been defined")
+	errs.Add(rang(2, 6, 7), "x has not been defined")
+
+	err := errs.Err()
+	gotErrs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("Err() returned %T, want Errors", err)
+	}
+
+	want := []string{
+		"test.lox:1:5: error: y has not been defined",
+		"test.lox:1:9: error: y has not been defined",
+		"test.lox:1:9: error: y has not been used",
+		"test.lox:2:7: error: x has not been defined",
+	}
+	if len(gotErrs) != len(want) {
+		t.Fatalf("Err() returned %d errors, want %d:\n%s", len(gotErrs), len(want), gotErrs)
+	}
+	for i, wantPrefix := range want {
+		if got := gotErrs[i].Error(); !strings.HasPrefix(got, wantPrefix) {
+			t.Errorf("error %d = %q, want prefix %q", i, got, wantPrefix)
+		}
+	}
+}
+
+func TestErrorsErrWarnings(t *testing.T) {
+	file := token.NewFile("test.lox", []byte("var x = y;\n"))
+	xRange := token.Token{
+		StartPos: token.Position{File: file, Line: 1, Column: 4},
+		EndPos:   token.Position{File: file, Line: 1, Column: 5},
+	}
+	yRange := token.Token{
+		StartPos: token.Position{File: file, Line: 1, Column: 8},
+		EndPos:   token.Position{File: file, Line: 1, Column: 9},
+	}
+
+	var errs Errors
+	errs.AddWarningf(xRange, "%s has been declared but is never used", "x")
+	if err := errs.Err(); err != nil {
+		t.Fatalf("Err() with only warnings = %q, want nil", err)
+	}
+
+	errs.Addf(yRange, "%s has not been declared", "y")
+	err := errs.Err()
+	if err == nil {
+		t.Fatal("Err() with an error and a warning = nil, want non-nil")
+	}
+	want := "test.lox:1:5: warning: x has been declared but is never used\n" +
+		"var x = y;\n" +
+		"    ~\n" +
+		"test.lox:1:9: error: y has not been declared\n" +
+		"var x = y;\n" +
+		"        ~"
+	if got := err.Error(); got != want {
+		t.Errorf("Err().Error() = %q, want %q", got, want)
+	}
+}
+
+func TestErrorsAddCodedWarningf(t *testing.T) {
+	file := token.NewFile("test.lox", []byte("var x = 1;\n"))
+	rang := token.Token{
+		StartPos: token.Position{File: file, Line: 1, Column: 4},
+		EndPos:   token.Position{File: file, Line: 1, Column: 5},
+	}
+
+	var errs Errors
+	errs.AddCodedWarningf(ErrorCodeUnusedDeclaration, rang, "%s has been declared but is never used", "x")
+
+	if len(errs) != 1 {
+		t.Fatalf("AddCodedWarningf() added %d errors, want 1", len(errs))
+	}
+	got := errs[0]
+	if got.Msg != "x has been declared but is never used" || got.Code != ErrorCodeUnusedDeclaration || got.Severity != SeverityWarning {
+		t.Errorf("AddCodedWarningf() added {Msg: %q, Code: %q, Severity: %s}, want {Msg: %q, Code: %q, Severity: %s}",
+			got.Msg, got.Code, got.Severity, "x has been declared but is never used", ErrorCodeUnusedDeclaration, SeverityWarning)
+	}
+}
+
+func TestErrorTabWidth(t *testing.T) {
+	file := token.NewFile("test.lox", []byte("{\n\tprint\tx;\n}\n"))
+	err := &Error{
+		Msg:   "x has not been declared",
+		Start: token.Position{File: file, Line: 2, Column: 7},
+		End:   token.Position{File: file, Line: 2, Column: 8},
+	}
+	tests := []struct {
+		tabWidth int
+		want     string
+	}{
+		{
+			tabWidth: 2,
+			want: "test.lox:2:9: error: x has not been declared\n" +
+				"  print x;\n" +
+				"        ~",
+		},
+		{
+			tabWidth: 4,
+			want: "test.lox:2:13: error: x has not been declared\n" +
+				"    print   x;\n" +
+				"            ~",
+		},
+		{
+			tabWidth: 8,
+			want: "test.lox:2:17: error: x has not been declared\n" +
+				"        print   x;\n" +
+				"                ~",
+		},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprint(test.tabWidth), func(t *testing.T) {
+			defer func(tabWidth int) { token.TabWidth = tabWidth }(token.TabWidth)
+			token.TabWidth = test.tabWidth
+			if got := err.Error(); got != test.want {
+				t.Errorf("Error() =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestErrorFormatPlain(t *testing.T) {
+	file := token.NewFile("test.lox", []byte("print \"héllo\" + x;\n"))
+	err := &Error{
+		Msg:   "x has not been declared",
+		Start: token.Position{File: file, Line: 1, Column: 17},
+		End:   token.Position{File: file, Line: 1, Column: 18},
+	}
+	want := "test.lox:1:17: error: x has not been declared\n" +
+		"print \"héllo\" + x;\n" +
+		"                ~"
+
+	prevEnabled := ansi.Enabled
+	t.Cleanup(func() { ansi.Enabled = prevEnabled })
+	for _, enabled := range []bool{false, true} {
+		ansi.Enabled = enabled
+		if got := err.FormatPlain(); got != want {
+			t.Errorf("FormatPlain() with ansi.Enabled = %t = %q, want %q", enabled, got, want)
+		}
+	}
+}
+
+func TestErrorMarshalJSONColumns(t *testing.T) {
+	// The tab and the wide characters are displayed as more columns than the UTF-16 code units that they're encoded as.
+	file := token.NewFile("test.lox", []byte("\tprint \"世界\" + x;\n"))
+	err := &Error{
+		Msg:   "x has not been declared",
+		Start: token.Position{File: file, Line: 1, Column: 18},
+		End:   token.Position{File: file, Line: 1, Column: 19},
+	}
+
+	data, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+	var got struct {
+		Start struct{ Column int }
+		End   struct{ Column int }
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Start.Column != 15 || got.End.Column != 16 {
+		t.Errorf("json.Marshal() = %s, want start column 15 and end column 16", data)
+	}
+}
+
+func TestErrorRelated(t *testing.T) {
+	file := token.NewFile("test.lox", []byte("var x = 1;\nvar x = 2;\n"))
+	err := &Error{
+		Msg:   "x has already been declared",
+		Start: token.Position{File: file, Line: 2, Column: 4},
+		End:   token.Position{File: file, Line: 2, Column: 5},
+	}
+	err.AddRelatedf(token.Token{
+		StartPos: token.Position{File: file, Line: 1, Column: 4},
+		EndPos:   token.Position{File: file, Line: 1, Column: 5},
+	}, "%s was first declared here", "x")
+	want := "test.lox:2:5: error: x has already been declared\n" +
+		"var x = 2;\n" +
+		"    ~\n" +
+		"test.lox:1:5: note: x was first declared here\n" +
+		"var x = 1;\n" +
+		"    ~"
+
+	if got := err.FormatPlain(); got != want {
+		t.Errorf("FormatPlain() = %q, want %q", got, want)
+	}
+}
+
+func TestErrorFixes(t *testing.T) {
+	file := token.NewFile("test.lox", []byte("var count = 1;\nprint cuont;\n"))
+	err := &Error{
+		Msg:   "cuont has not been declared",
+		Start: token.Position{File: file, Line: 2, Column: 6},
+		End:   token.Position{File: file, Line: 2, Column: 11},
+	}
+	err.AddFixf(token.Token{
+		StartPos: token.Position{File: file, Line: 2, Column: 6},
+		EndPos:   token.Position{File: file, Line: 2, Column: 11},
+	}, "count", "replace with %s", "count")
+	want := "test.lox:2:7: error: cuont has not been declared\n" +
+		"print cuont;\n" +
+		"      ~~~~~\n" +
+		"test.lox:2:7: hint: replace with count"
+
+	if got := err.FormatPlain(); got != want {
+		t.Errorf("FormatPlain() = %q, want %q", got, want)
+	}
+}
+
+func TestErrorCodesHaveExplanations(t *testing.T) {
+	codes := []ErrorCode{
+		ErrorCodeIllegalCharacter,
+		ErrorCodeUnterminatedString,
+		ErrorCodeInvalidUTF8,
+		ErrorCodeUnexpectedToken,
+		ErrorCodeMissingSemicolon,
+		ErrorCodeMisplacedComment,
+		ErrorCodeInvalidAssignmentTarget,
+		ErrorCodeInvalidModuleName,
+		ErrorCodeNestingTooDeep,
+		ErrorCodeShadowedBuiltin,
+		ErrorCodeShadowedDeclaration,
+		ErrorCodeUnusedResult,
+		ErrorCodeUnusedDeclaration,
+		ErrorCodeUndeclared,
+		ErrorCodeUsedBeforeDeclaration,
+		ErrorCodeUndefined,
+		ErrorCodeRedeclared,
+		ErrorCodeSelfReferentialInitialiser,
+		ErrorCodeSelfInheritance,
+		ErrorCodeTooManyParameters,
+		ErrorCodeTooManyArguments,
+		ErrorCodeInvalidAccessor,
+		ErrorCodeMisplacedStatement,
+		ErrorCodeMisplacedExpression,
+		ErrorCodeConstructorReturnValue,
+		ErrorCodeUnreachableCode,
+		ErrorCodeInvalidPlaceholder,
+		ErrorCodeImportFailed,
+		ErrorCodeImportCycle,
+		ErrorCodeInvalidOperand,
+		ErrorCodeNilOperand,
+		ErrorCodeDivisionByZero,
+		ErrorCodeNotCallable,
+		ErrorCodeWrongArgumentCount,
+		ErrorCodeNotAClass,
+		ErrorCodeNotIterable,
+		ErrorCodeNoProperty,
+		ErrorCodeInvalidPropertyAccess,
+		ErrorCodeReadOnlyProperty,
+		ErrorCodeBuiltinError,
+		ErrorCodeUncaughtError,
+		ErrorCodeStackOverflow,
+	}
+	for _, code := range codes {
+		if _, ok := code.Explanation(); !ok {
+			t.Errorf("%s has no explanation", code)
+		}
+	}
+	if got := len(ErrorCodes()); got != len(codes) {
+		t.Errorf("len(ErrorCodes()) = %d, want %d", got, len(codes))
+	}
+}
@@ -0,0 +1,671 @@
+package lox
+
+import (
+	"maps"
+	"slices"
+)
+
+var errorCodeExplanations = map[ErrorCode]string{
+	ErrorCodeIllegalCharacter: `The source code contains a character which can't start any token.
+
+Lox source code can only contain characters which make up identifiers, numbers, strings, comments, operators, and
+punctuation. Any other character is only allowed inside a string or a comment.
+
+Erroneous code example:
+
+    var price = 5 # 2;
+
+Fixed code:
+
+    var price = 5 * 2;
+`,
+	ErrorCodeUnterminatedString: `A string literal is missing its closing quote.
+
+Every string literal must end with a " on the same line that it started on.
+
+Erroneous code example:
+
+    print "Hello, World!;
+
+Fixed code:
+
+    print "Hello, World!";
+`,
+	ErrorCodeInvalidUTF8: `The source code isn't valid UTF-8.
+
+Lox source code must be encoded as UTF-8. This is usually caused by a file which was saved with a different encoding,
+such as Latin-1. The error points at the first byte of each invalid sequence. Converting the file to UTF-8 fixes it.
+`,
+	ErrorCodeUnexpectedToken: `A token appears where it isn't valid.
+
+The parser expected a token of a particular kind, such as an expression or a closing parenthesis, but found something
+else. The error message says what was expected.
+
+Erroneous code example:
+
+    var a = 2;
+    if (a > 1 {
+        print a;
+    }
+
+Fixed code:
+
+    var a = 2;
+    if (a > 1) {
+        print a;
+    }
+`,
+	ErrorCodeMissingSemicolon: `A statement is missing the semicolon which ends it.
+
+Expression, print, variable, break, continue, return, throw, and import statements must all end with a semicolon. The
+error points at the token which the semicolon should follow.
+
+Erroneous code example:
+
+    print "Hello, World!"
+
+Fixed code:
+
+    print "Hello, World!";
+`,
+	ErrorCodeMisplacedComment: `A comment appears in the middle of an expression.
+
+Comments can only appear where a declaration could appear or at the end of a statement.
+
+Erroneous code example:
+
+    var total = 1 + // the first value
+        2;
+
+Fixed code:
+
+    // 1 is the first value
+    var total = 1 + 2;
+`,
+	ErrorCodeInvalidAssignmentTarget: `The target of an assignment, increment, or decrement isn't a variable or a property.
+
+Only a variable or a property of an object can be assigned to, incremented, or decremented.
+
+Erroneous code example:
+
+    var a = 1;
+    var b = 2;
+    a + b = 3;
+
+Fixed code:
+
+    var a = 1;
+    var b = 2;
+    a = 3 - b;
+`,
+	ErrorCodeInvalidModuleName: `The name of an imported module isn't a valid identifier.
+
+An imported module is bound to a variable with the same name as its file, without the .lox extension. That name must
+be a valid identifier, so it can only contain letters, digits, and underscores, and it can't start with a digit.
+
+Erroneous code example:
+
+    import "string-utils.lox";
+
+Fixed code:
+
+    import "string_utils.lox";
+`,
+	ErrorCodeNestingTooDeep: `Statements or expressions are nested too deeply to be parsed.
+
+There's a limit on how deeply blocks, parentheses, and other constructs can be nested inside one another. It's only
+exceeded by generated code or by mistake, such as by a long run of opening parentheses. Moving some of the nested code
+into functions or variables fixes it.
+`,
+	ErrorCodeShadowedBuiltin: `A declaration shadows a built-in function or module.
+
+Declaring a local variable, function, class, or parameter with the same name as a built-in makes the built-in
+inaccessible for the rest of the scope. This is only reported when it's been enabled, such as with the
+reportShadowedBuiltins setting of loxls.
+
+Erroneous code example:
+
+    fun describe(type) {
+        print type(type); // type is the parameter, not the built-in function
+    }
+
+Fixed code:
+
+    fun describe(kind) {
+        print type(kind);
+    }
+`,
+	ErrorCodeShadowedDeclaration: `A local declaration shadows a declaration in an enclosing scope.
+
+Declaring a local variable, function, class, or parameter with the same name as one in an enclosing scope makes the outer
+one inaccessible for the rest of the scope, which is often a mistake. Names which start with an underscore are never
+reported.
+
+Erroneous code example:
+
+    var count = 1;
+    fun reset() {
+        var count = 0; // Declares a new variable instead of assigning to the global one
+    }
+
+Fixed code:
+
+    var count = 1;
+    fun reset() {
+        count = 0;
+    }
+`,
+	ErrorCodeUnusedResult: `The result of an expression statement which has no side effects isn't used.
+
+An expression statement is only useful if evaluating the expression does something, such as calling a function or
+assigning to a variable. Otherwise, its result is thrown away and the statement does nothing.
+
+Erroneous code example:
+
+    var a = 1;
+    a == 2;
+
+Fixed code:
+
+    var a = 1;
+    print a == 2;
+`,
+	ErrorCodeUnusedDeclaration: `An identifier is declared and never used.
+
+This is reported as a warning for variables, functions, classes, parameters, and imports. Global declarations in an
+imported module aren't reported, since they can be used by the program which imports it. Declaring the identifier as _
+instead means that its value is intentionally unused.
+
+Erroneous code example:
+
+    fun greet(name, greeting) {
+        print "Hello, " + name;
+    }
+
+Fixed code:
+
+    fun greet(name, _) {
+        print "Hello, " + name;
+    }
+`,
+	ErrorCodeUndeclared: `An identifier is used which hasn't been declared.
+
+Every identifier must be declared by a variable, function, or class declaration, a parameter, or an import before it
+can be used. This is often caused by a typo in its name, so a declaration with a similar name is suggested if there is
+one.
+
+Erroneous code example:
+
+    var count = 1;
+    print cuont;
+
+Fixed code:
+
+    var count = 1;
+    print count;
+`,
+	ErrorCodeUsedBeforeDeclaration: `An identifier is used before it's declared in the same scope.
+
+Identifiers can't be used earlier in a block than they're declared, even if a declaration with the same name in an
+enclosing scope would otherwise be used.
+
+Erroneous code example:
+
+    {
+        print x;
+        var x = 1;
+    }
+
+Fixed code:
+
+    {
+        var x = 1;
+        print x;
+    }
+`,
+	ErrorCodeUndefined: `A variable is used which has been declared but not defined yet.
+
+A variable which is declared without an initialiser has no value until it's assigned one, so it can't be read before
+then. This is also reported when a property of a module is accessed before the module has defined it.
+
+Erroneous code example:
+
+    var total;
+    print total;
+
+Fixed code:
+
+    var total = 0;
+    print total;
+`,
+	ErrorCodeRedeclared: `An identifier is declared more than once in the same scope.
+
+Each variable, function, class, parameter, and import in a scope must have a different name. Names can be reused in
+nested scopes.
+
+Erroneous code example:
+
+    var count = 1;
+    var count = 2;
+
+Fixed code:
+
+    var count = 1;
+    count = 2;
+`,
+	ErrorCodeSelfReferentialInitialiser: `A local variable is read in its own initialiser.
+
+A local variable isn't declared until after its initialiser has been evaluated, so the initialiser can't refer to it.
+To declare a recursive function, use a function declaration instead.
+
+Erroneous code example:
+
+    {
+        var fib = fun(n) { return n < 2 ? n : fib(n - 1) + fib(n - 2); };
+    }
+
+Fixed code:
+
+    {
+        fun fib(n) { return n < 2 ? n : fib(n - 1) + fib(n - 2); }
+    }
+`,
+	ErrorCodeSelfInheritance: `A class inherits from itself.
+
+A class can only inherit from a different class.
+
+Erroneous code example:
+
+    class A < A {}
+
+Fixed code:
+
+    class Base {}
+    class A < Base {}
+`,
+	ErrorCodeTooManyParameters: `A function has more than 255 parameters.
+
+Group the values into an instance of a class and pass that instead.
+`,
+	ErrorCodeTooManyArguments: `A function is called with more than 255 arguments.
+
+Group the values into an instance of a class and pass that instead.
+`,
+	ErrorCodeInvalidAccessor: `A property getter or setter is declared incorrectly.
+
+A getter can't have any parameters and a setter must have exactly one, which is the value being assigned. A property
+can't have a setter without a getter.
+
+Erroneous code example:
+
+    class Circle {
+        get radius(unit) {
+            return this._radius;
+        }
+    }
+
+Fixed code:
+
+    class Circle {
+        get radius() {
+            return this._radius;
+        }
+    }
+`,
+	ErrorCodeMisplacedStatement: `A statement is used outside of the context where it's valid.
+
+break and continue can only be used inside a loop, return can only be used inside a function, and import can only be
+used at the top level of a file.
+
+Erroneous code example:
+
+    var done = true;
+    if (done) {
+        break;
+    }
+
+Fixed code:
+
+    var done = true;
+    while (true) {
+        if (done) {
+            break;
+        }
+    }
+`,
+	ErrorCodeMisplacedExpression: `this or super is used outside of the context where it's valid.
+
+this and super can only be used inside a method, and super can only be used inside a class which has a superclass.
+
+Erroneous code example:
+
+    class A {
+        greet() {
+            return super.greet();
+        }
+    }
+
+Fixed code:
+
+    class Base {
+        greet() {
+            return "Hello";
+        }
+    }
+    class A < Base {
+        greet() {
+            return super.greet();
+        }
+    }
+`,
+	ErrorCodeConstructorReturnValue: `A constructor returns a value.
+
+init() always returns the instance being constructed, so a return statement inside it can't have a value. An empty
+return statement can still be used to return early.
+
+Erroneous code example:
+
+    class Point {
+        init(x) {
+            this.x = x;
+            return this;
+        }
+    }
+
+Fixed code:
+
+    class Point {
+        init(x) {
+            this.x = x;
+        }
+    }
+`,
+	ErrorCodeUnreachableCode: `A statement can never be executed.
+
+A statement which follows a return, break, continue, or throw statement in the same block can never be reached.
+
+Erroneous code example:
+
+    fun double(x) {
+        return x * 2;
+        print "doubled";
+    }
+
+Fixed code:
+
+    fun double(x) {
+        print "doubled";
+        return x * 2;
+    }
+`,
+	ErrorCodeInvalidPlaceholder: `The placeholder identifier _ is used as a value or a field name.
+
+_ can be declared any number of times to mark a value as intentionally unused, so it never refers to a value itself.
+
+Erroneous code example:
+
+    fun first(a, _) {
+        return _;
+    }
+
+Fixed code:
+
+    fun second(_, b) {
+        return b;
+    }
+`,
+	ErrorCodeImportFailed: `A module can't be imported.
+
+The path of an imported module is relative to the directory of the file which imports it. The error message says why
+the file couldn't be imported, such as because it doesn't exist or it contains syntax errors.
+
+Erroneous code example:
+
+    import "lib/countr.lox";
+
+Fixed code:
+
+    import "lib/counter.lox";
+`,
+	ErrorCodeImportCycle: `A module imports itself, directly or through other modules.
+
+Modules are executed when they're first imported, so a cycle of imports can't be executed. Move the declarations which
+the modules share into a separate module which they both import.
+`,
+	ErrorCodeInvalidOperand: `An operator is used with a value of a type which it doesn't support.
+
+For example, + can only be used with two numbers or two strings, - can only be used with numbers, and a string can
+only be multiplied by a non-negative integer.
+
+Erroneous code example:
+
+    var total = "5";
+    print total - 1;
+
+Fixed code:
+
+    var total = 5;
+    print total - 1;
+`,
+	ErrorCodeNilOperand: `An operand of an arithmetic or comparison operator is nil.
+
+This is usually caused by a function which doesn't return a value, or by a variable which has been assigned nil.
+
+Erroneous code example:
+
+    fun count() {}
+    print count() + 1;
+
+Fixed code:
+
+    fun count() {
+        return 0;
+    }
+    print count() + 1;
+`,
+	ErrorCodeDivisionByZero: `A number is divided by 0 or taken modulo 0.
+
+Erroneous code example:
+
+    fun mean(total, n) {
+        return total / n;
+    }
+    print mean(0, 0);
+
+Fixed code:
+
+    fun mean(total, n) {
+        if (n == 0) {
+            return 0;
+        }
+        return total / n;
+    }
+    print mean(0, 0);
+`,
+	ErrorCodeNotCallable: `A value is called which isn't a function or a class.
+
+Erroneous code example:
+
+    var greeting = "Hello";
+    print greeting();
+
+Fixed code:
+
+    var greeting = "Hello";
+    print greeting;
+`,
+	ErrorCodeWrongArgumentCount: `A function is called with the wrong number of arguments.
+
+A function must be called with exactly one argument for each of its parameters. A class is called with the arguments of
+its init() method.
+
+Erroneous code example:
+
+    fun add(a, b) {
+        return a + b;
+    }
+    print add(1);
+
+Fixed code:
+
+    fun add(a, b) {
+        return a + b;
+    }
+    print add(1, 2);
+`,
+	ErrorCodeNotAClass: `A class inherits from a value which isn't a class.
+
+Erroneous code example:
+
+    var Base = "Base";
+    class A < Base {}
+
+Fixed code:
+
+    class Base {}
+    class A < Base {}
+`,
+	ErrorCodeNotIterable: `A for-in loop iterates over a value which can't be iterated over.
+
+Erroneous code example:
+
+    for (c in 123) {
+        print c;
+    }
+
+Fixed code:
+
+    for (c in "123") {
+        print c;
+    }
+`,
+	ErrorCodeNoProperty: `A property is accessed which doesn't exist.
+
+The property isn't a field or method of the object, or it isn't declared by the module.
+
+Erroneous code example:
+
+    class Point {
+        init(x) {
+            this.x = x;
+        }
+    }
+    print Point(1).y;
+
+Fixed code:
+
+    class Point {
+        init(x) {
+            this.x = x;
+        }
+    }
+    print Point(1).x;
+`,
+	ErrorCodeInvalidPropertyAccess: `A property is accessed or assigned on a value which doesn't have properties.
+
+Only instances of classes have properties which can be accessed and assigned. The properties of a module can be accessed
+but not assigned.
+
+Erroneous code example:
+
+    var n = 1;
+    print n.value;
+
+Fixed code:
+
+    class Box {
+        init(value) {
+            this.value = value;
+        }
+    }
+    var n = Box(1);
+    print n.value;
+`,
+	ErrorCodeReadOnlyProperty: `A property which has a getter and no setter is assigned to.
+
+Erroneous code example:
+
+    class Circle {
+        get radius() {
+            return 1;
+        }
+    }
+    Circle().radius = 2;
+
+Fixed code:
+
+    class Circle {
+        get radius() {
+            return this._radius;
+        }
+        set radius(value) {
+            this._radius = value;
+        }
+    }
+    Circle().radius = 2;
+`,
+	ErrorCodeBuiltinError: `A built-in function failed.
+
+This is reported when a built-in function is passed an argument of the wrong type or which is out of range, or when
+error() is called. The error message says what went wrong.
+
+Erroneous code example:
+
+    print len(123);
+
+Fixed code:
+
+    print len("123");
+`,
+	ErrorCodeUncaughtError: `A value was thrown which wasn't caught.
+
+A thrown value is caught by the nearest enclosing try statement which has a catch block. If there isn't one, then the
+program stops and the value is reported.
+
+Erroneous code example:
+
+    throw "something went wrong";
+
+Fixed code:
+
+    try {
+        throw "something went wrong";
+    } catch (e) {
+        print e;
+    }
+`,
+	ErrorCodeStackOverflow: `The maximum call depth was exceeded.
+
+This is usually caused by a recursive function which never reaches its base case. The maximum call depth can be
+changed with the -max-call-depth flag of golox.
+
+Erroneous code example:
+
+    fun count(n) {
+        return count(n + 1);
+    }
+    count(0);
+
+Fixed code:
+
+    fun count(n) {
+        if (n == 10) {
+            return n;
+        }
+        return count(n + 1);
+    }
+    count(0);
+`,
+}
+
+// Explanation returns an extended description of the errors which have the code, including an example of code which
+// causes them and how to fix it. ok is false if the code isn't known.
+func (c ErrorCode) Explanation() (explanation string, ok bool) {
+	explanation, ok = errorCodeExplanations[c]
+	return explanation, ok
+}
+
+// ErrorCodes returns all of the known error codes in alphabetical order.
+func ErrorCodes() []ErrorCode {
+	return slices.Sorted(maps.Keys(errorCodeExplanations))
+}
@@ -9,89 +9,148 @@ import (
 	"github.com/marcuscaisey/lox/lox/token"
 )
 
-const indentSize = 4
+const defaultIndentSize = 4
+
+// Option can be passed to [Node] to configure its behaviour.
+type Option func(*formatter)
+
+// WithIndentSize sets the number of spaces used for each level of indentation. The default is 4.
+func WithIndentSize(n int) Option {
+	return func(f *formatter) {
+		f.indentSize = n
+	}
+}
+
+// WithLeadingZerosTrimmed sets whether leading zeros are removed from number literals, so that 007 is formatted as 7.
+// The default is true.
+func WithLeadingZerosTrimmed(enabled bool) Option {
+	return func(f *formatter) {
+		f.trimLeadingZeros = enabled
+	}
+}
+
+// WithTrailingZerosTrimmed sets whether trailing zeros are removed from the fractional part of number literals, so that
+// 1.50 is formatted as 1.5. A single zero is kept after the decimal point of a whole number, so that 2.00 is formatted as
+// 2.0. The default is true.
+func WithTrailingZerosTrimmed(enabled bool) Option {
+	return func(f *formatter) {
+		f.trimTrailingZeros = enabled
+	}
+}
 
 // Node formats node in canonical Lox style and returns the result. node is expected to be a syntactically correct.
-func Node(node ast.Node) string {
+func Node(node ast.Node, opts ...Option) string {
+	f := &formatter{
+		indentSize:        defaultIndentSize,
+		trimLeadingZeros:  true,
+		trimTrailingZeros: true,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f.format(node)
+}
+
+type formatter struct {
+	indentSize        int
+	trimLeadingZeros  bool
+	trimTrailingZeros bool
+}
+
+func (f *formatter) format(node ast.Node) string {
 	switch node := node.(type) {
 	case ast.Program:
-		return formatProgram(node)
+		return f.formatProgram(node)
 	case ast.Ident:
-		return formatIdent(node)
+		return f.formatIdent(node)
 	case ast.CommentStmt:
-		return formatCommentStmt(node)
+		return f.formatCommentStmt(node)
 	case ast.InlineCommentStmt:
-		return formatCommentedStmt(node)
+		return f.formatCommentedStmt(node)
+	case ast.ImportStmt:
+		return f.formatImportStmt(node)
 	case ast.VarDecl:
-		return formatVarDecl(node)
+		return f.formatVarDecl(node)
 	case ast.FunDecl:
-		return formatFunDecl(node)
+		return f.formatFunDecl(node)
 	case ast.Function:
-		return formatFun(node)
+		return f.formatFun(node)
 	case ast.ClassDecl:
-		return formatClassDecl(node)
+		return f.formatClassDecl(node)
 	case ast.MethodDecl:
-		return formatMethodDecl(node)
+		return f.formatMethodDecl(node)
 	case ast.ExprStmt:
-		return formatExprStmt(node)
+		return f.formatExprStmt(node)
 	case ast.PrintStmt:
-		return formatPrintStmt(node)
+		return f.formatPrintStmt(node)
 	case ast.BlockStmt:
-		return formatBlockStmt(node)
+		return f.formatBlockStmt(node)
 	case ast.IfStmt:
-		return formatIfStmt(node)
+		return f.formatIfStmt(node)
 	case ast.WhileStmt:
-		return formatWhileStmt(node)
+		return f.formatWhileStmt(node)
 	case ast.ForStmt:
-		return formatForStmt(node)
+		return f.formatForStmt(node)
+	case ast.ForInStmt:
+		return f.formatForInStmt(node)
 	case ast.BreakStmt:
-		return formatBreakStmt(node)
+		return f.formatBreakStmt(node)
 	case ast.ContinueStmt:
-		return formatContinueStmt(node)
+		return f.formatContinueStmt(node)
 	case ast.ReturnStmt:
-		return formatReturnStmt(node)
+		return f.formatReturnStmt(node)
+	case ast.TryStmt:
+		return f.formatTryStmt(node)
+	case ast.ThrowStmt:
+		return f.formatThrowStmt(node)
 	case ast.FunExpr:
-		return formatFunExpr(node)
+		return f.formatFunExpr(node)
 	case ast.GroupExpr:
-		return formatGroupExpr(node)
+		return f.formatGroupExpr(node)
 	case ast.LiteralExpr:
-		return formatLiteralExpr(node)
+		return f.formatLiteralExpr(node)
+	case ast.InterpolatedStringExpr:
+		return f.formatInterpolatedStringExpr(node)
 	case ast.IdentExpr:
-		return formatIdentExpr(node)
+		return f.formatIdentExpr(node)
 	case ast.ThisExpr:
-		return formatThisExpr(node)
+		return f.formatThisExpr(node)
+	case ast.SuperExpr:
+		return f.formatSuperExpr(node)
 	case ast.CallExpr:
-		return formatCallExpr(node)
+		return f.formatCallExpr(node)
 	case ast.GetExpr:
-		return formatGetExpr(node)
+		return f.formatGetExpr(node)
 	case ast.UnaryExpr:
-		return formatUnaryExpr(node)
+		return f.formatUnaryExpr(node)
+	case ast.IncrementExpr:
+		return f.formatIncrementExpr(node)
 	case ast.BinaryExpr:
-		return formatBinaryExpr(node)
+		return f.formatBinaryExpr(node)
 	case ast.TernaryExpr:
-		return formatTernaryExpr(node)
+		return f.formatTernaryExpr(node)
 	case ast.AssignmentExpr:
-		return formatAssignmentExpr(node)
+		return f.formatAssignmentExpr(node)
 	case ast.SetExpr:
-		return formatSetExpr(node)
+		return f.formatSetExpr(node)
 	case ast.IllegalStmt:
 		panic("IllegalStmt cannot be formatted")
 	}
 	panic("unreachable")
 }
 
-func formatIdent(ident ast.Ident) string {
+func (f *formatter) formatIdent(ident ast.Ident) string {
 	return ident.Token.Lexeme
 }
 
-func formatProgram(program ast.Program) string {
-	return fmt.Sprint(formatStmts(program.Stmts), "\n")
+func (f *formatter) formatProgram(program ast.Program) string {
+	return fmt.Sprint(f.formatStmts(program.Stmts), "\n")
 }
 
-func formatStmts[T ast.Stmt](stmts []T) string {
+func (f *formatter) formatStmts(stmts []ast.Stmt) string {
 	var b strings.Builder
 	for i, stmt := range stmts {
-		fmt.Fprint(&b, Node(stmt))
+		fmt.Fprint(&b, f.format(stmt))
 		if i < len(stmts)-1 {
 			fmt.Fprintln(&b)
 			if stmts[i+1].Start().Line-stmts[i].End().Line > 1 {
@@ -102,80 +161,92 @@ func formatStmts[T ast.Stmt](stmts []T) string {
 	return b.String()
 }
 
-func formatCommentStmt(stmt ast.CommentStmt) string {
+func (f *formatter) formatCommentStmt(stmt ast.CommentStmt) string {
 	return stmt.Comment.Lexeme
 }
 
-func formatCommentedStmt(stmt ast.InlineCommentStmt) string {
-	return fmt.Sprintf("%s %s", Node(stmt.Stmt), stmt.Comment.Lexeme)
+func (f *formatter) formatCommentedStmt(stmt ast.InlineCommentStmt) string {
+	return fmt.Sprintf("%s %s", f.format(stmt.Stmt), stmt.Comment.Lexeme)
+}
+
+func (f *formatter) formatImportStmt(stmt ast.ImportStmt) string {
+	return fmt.Sprintf("import %s;", stmt.Path.Lexeme)
 }
 
-func formatVarDecl(decl ast.VarDecl) string {
+func (f *formatter) formatVarDecl(decl ast.VarDecl) string {
 	if decl.Initialiser != nil {
-		return fmt.Sprintf("var %s = %s;", Node(decl.Name), Node(decl.Initialiser))
+		return fmt.Sprintf("var %s = %s;", f.format(decl.Name), f.format(decl.Initialiser))
 	} else {
-		return fmt.Sprintf("var %s;", Node(decl.Name))
+		return fmt.Sprintf("var %s;", f.format(decl.Name))
 	}
 }
 
-func formatFunDecl(decl ast.FunDecl) string {
-	return fmt.Sprintf("fun %s%s", Node(decl.Name), Node(decl.Function))
+func (f *formatter) formatFunDecl(decl ast.FunDecl) string {
+	return fmt.Sprintf("fun %s%s", f.format(decl.Name), f.format(decl.Function))
 }
 
-func formatFun(fun ast.Function) string {
+func (f *formatter) formatFun(fun ast.Function) string {
 	var b strings.Builder
 	fmt.Fprintf(&b, "(")
 	for i, param := range fun.Params {
-		fmt.Fprint(&b, Node(param))
+		fmt.Fprint(&b, f.format(param))
 		if i < len(fun.Params)-1 {
 			fmt.Fprint(&b, ", ")
 		}
 	}
-	fmt.Fprintf(&b, ") %s", formatBlock(fun.Body.Stmts))
+	if fun.HasShorthandBody() {
+		returnStmt := fun.Body.Stmts[0].(ast.ReturnStmt)
+		fmt.Fprintf(&b, ") => %s", f.format(returnStmt.Value))
+	} else {
+		fmt.Fprintf(&b, ") %s", f.formatBlock(fun.Body.Stmts))
+	}
 	return b.String()
 }
 
-func formatClassDecl(decl ast.ClassDecl) string {
-	return fmt.Sprintf("class %s %s", Node(decl.Name), formatBlock(decl.Body))
+func (f *formatter) formatClassDecl(decl ast.ClassDecl) string {
+	if decl.Superclass != nil {
+		return fmt.Sprintf("class %s < %s %s", f.format(decl.Name), f.format(decl.Superclass), f.formatBlock(decl.Body))
+	}
+	return fmt.Sprintf("class %s %s", f.format(decl.Name), f.formatBlock(decl.Body))
 }
 
-func formatMethodDecl(decl ast.MethodDecl) string {
+func (f *formatter) formatMethodDecl(decl ast.MethodDecl) string {
 	var b strings.Builder
 	for _, modifier := range decl.Modifiers {
 		fmt.Fprintf(&b, "%s ", modifier.Lexeme)
 	}
-	fmt.Fprintf(&b, "%s%s", Node(decl.Name), Node(decl.Function))
+	fmt.Fprintf(&b, "%s%s", f.format(decl.Name), f.format(decl.Function))
 	return b.String()
 }
 
-func formatExprStmt(stmt ast.ExprStmt) string {
-	return fmt.Sprintf("%s;", Node(stmt.Expr))
+func (f *formatter) formatExprStmt(stmt ast.ExprStmt) string {
+	return fmt.Sprintf("%s;", f.format(stmt.Expr))
 }
 
-func formatPrintStmt(stmt ast.PrintStmt) string {
-	return fmt.Sprintf("print %s;", Node(stmt.Expr))
+func (f *formatter) formatPrintStmt(stmt ast.PrintStmt) string {
+	return fmt.Sprintf("print %s;", f.format(stmt.Expr))
 }
 
-func formatBlockStmt(stmt ast.BlockStmt) string {
-	return formatBlock(stmt.Stmts)
+func (f *formatter) formatBlockStmt(stmt ast.BlockStmt) string {
+	return f.formatBlock(stmt.Stmts)
 }
 
-func formatBlock[T ast.Stmt](stmts []T) string {
+func (f *formatter) formatBlock(stmts []ast.Stmt) string {
 	if len(stmts) > 0 {
-		return fmt.Sprintf("{\n%s\n}", indent(formatStmts(stmts)))
+		return fmt.Sprintf("{\n%s\n}", f.indent(f.formatStmts(stmts)))
 	} else {
 		return "{}"
 	}
 }
 
-func formatIfStmt(stmt ast.IfStmt) string {
+func (f *formatter) formatIfStmt(stmt ast.IfStmt) string {
 	var b strings.Builder
-	fmt.Fprintf(&b, "if (%s)", Node(stmt.Condition))
+	fmt.Fprintf(&b, "if (%s)", f.format(stmt.Condition))
 	var thenIsBlock bool
 	if _, thenIsBlock = stmt.Then.(ast.BlockStmt); thenIsBlock {
-		fmt.Fprint(&b, " ", Node(stmt.Then))
+		fmt.Fprint(&b, " ", f.format(stmt.Then))
 	} else {
-		fmt.Fprint(&b, "\n", indent(Node(stmt.Then)))
+		fmt.Fprint(&b, "\n", f.indent(f.format(stmt.Then)))
 	}
 	if stmt.Else != nil {
 		if thenIsBlock {
@@ -185,87 +256,151 @@ func formatIfStmt(stmt ast.IfStmt) string {
 		}
 		switch stmt.Else.(type) {
 		case ast.IfStmt, ast.BlockStmt:
-			fmt.Fprint(&b, "else ", Node(stmt.Else))
+			fmt.Fprint(&b, "else ", f.format(stmt.Else))
 		default:
-			fmt.Fprint(&b, "else\n", indent(Node(stmt.Else)))
+			fmt.Fprint(&b, "else\n", f.indent(f.format(stmt.Else)))
 		}
 	}
 	return b.String()
 }
 
-func formatWhileStmt(stmt ast.WhileStmt) string {
+func (f *formatter) formatWhileStmt(stmt ast.WhileStmt) string {
 	if _, ok := stmt.Body.(ast.BlockStmt); ok {
-		return fmt.Sprintf("while (%s) %s", Node(stmt.Condition), Node(stmt.Body))
+		return fmt.Sprintf("while (%s) %s", f.format(stmt.Condition), f.format(stmt.Body))
 	} else {
-		return fmt.Sprintf("while (%s)\n%s", Node(stmt.Condition), indent(Node(stmt.Body)))
+		return fmt.Sprintf("while (%s)\n%s", f.format(stmt.Condition), f.indent(f.format(stmt.Body)))
 	}
 }
 
-func formatForStmt(stmt ast.ForStmt) string {
+func (f *formatter) formatForStmt(stmt ast.ForStmt) string {
 	var b strings.Builder
 	fmt.Fprint(&b, "for (")
 	if stmt.Initialise != nil {
-		fmt.Fprintf(&b, "%s", Node(stmt.Initialise))
+		fmt.Fprintf(&b, "%s", f.format(stmt.Initialise))
 	} else {
 		fmt.Fprint(&b, ";")
 	}
 	if stmt.Condition != nil {
-		fmt.Fprintf(&b, " %s", Node(stmt.Condition))
+		fmt.Fprintf(&b, " %s", f.format(stmt.Condition))
 	}
 	fmt.Fprint(&b, ";")
 	if stmt.Update != nil {
-		fmt.Fprintf(&b, " %s", Node(stmt.Update))
+		fmt.Fprintf(&b, " %s", f.format(stmt.Update))
 	}
 	fmt.Fprint(&b, ")")
 	if _, ok := stmt.Body.(ast.BlockStmt); ok {
-		fmt.Fprintf(&b, " %s", Node(stmt.Body))
+		fmt.Fprintf(&b, " %s", f.format(stmt.Body))
+	} else {
+		fmt.Fprintf(&b, "\n%s", f.indent(f.format(stmt.Body)))
+	}
+	return b.String()
+}
+
+func (f *formatter) formatForInStmt(stmt ast.ForInStmt) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "for (%s in %s)", f.format(stmt.Name), f.format(stmt.Iterable))
+	if _, ok := stmt.Body.(ast.BlockStmt); ok {
+		fmt.Fprintf(&b, " %s", f.format(stmt.Body))
 	} else {
-		fmt.Fprintf(&b, "\n%s", indent(Node(stmt.Body)))
+		fmt.Fprintf(&b, "\n%s", f.indent(f.format(stmt.Body)))
 	}
 	return b.String()
 }
 
-func formatBreakStmt(ast.BreakStmt) string {
+func (f *formatter) formatBreakStmt(ast.BreakStmt) string {
 	return "break;"
 }
 
-func formatContinueStmt(ast.ContinueStmt) string {
+func (f *formatter) formatContinueStmt(ast.ContinueStmt) string {
 	return "continue;"
 }
 
-func formatReturnStmt(stmt ast.ReturnStmt) string {
+func (f *formatter) formatReturnStmt(stmt ast.ReturnStmt) string {
 	if stmt.Value != nil {
-		return fmt.Sprintf("return %s;", Node(stmt.Value))
+		return fmt.Sprintf("return %s;", f.format(stmt.Value))
 	} else {
 		return "return;"
 	}
 }
 
-func formatFunExpr(expr ast.FunExpr) string {
-	return fmt.Sprintf("fun%s", Node(expr.Function))
+func (f *formatter) formatTryStmt(stmt ast.TryStmt) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "try %s", f.format(stmt.Body))
+	if stmt.HasCatch() {
+		fmt.Fprintf(&b, " catch (%s) %s", f.format(stmt.CatchName), f.format(stmt.CatchBody))
+	}
+	if stmt.HasFinally() {
+		fmt.Fprintf(&b, " finally %s", f.format(stmt.FinallyBody))
+	}
+	return b.String()
+}
+
+func (f *formatter) formatThrowStmt(stmt ast.ThrowStmt) string {
+	return fmt.Sprintf("throw %s;", f.format(stmt.Value))
+}
+
+func (f *formatter) formatFunExpr(expr ast.FunExpr) string {
+	return fmt.Sprintf("fun%s", f.format(expr.Function))
 }
 
-func formatGroupExpr(expr ast.GroupExpr) string {
-	return fmt.Sprintf("(%s)", Node(expr.Expr))
+func (f *formatter) formatGroupExpr(expr ast.GroupExpr) string {
+	return fmt.Sprintf("(%s)", f.format(expr.Expr))
 }
 
-func formatLiteralExpr(expr ast.LiteralExpr) string {
+func (f *formatter) formatLiteralExpr(expr ast.LiteralExpr) string {
+	if expr.Value.Type == token.Number {
+		return f.formatNumber(expr.Value.Lexeme)
+	}
 	return expr.Value.Lexeme
 }
 
-func formatIdentExpr(expr ast.IdentExpr) string {
+func (f *formatter) formatInterpolatedStringExpr(expr ast.InterpolatedStringExpr) string {
+	var b strings.Builder
+	for i, interpolated := range expr.Exprs {
+		fmt.Fprint(&b, expr.Strings[i].Lexeme, f.format(interpolated))
+	}
+	fmt.Fprint(&b, expr.Strings[len(expr.Strings)-1].Lexeme)
+	return b.String()
+}
+
+// formatNumber formats a number literal. The literal is expected to be of the form digits or digits.digits.
+func (f *formatter) formatNumber(lexeme string) string {
+	intPart, fracPart, hasFrac := strings.Cut(lexeme, ".")
+	if f.trimLeadingZeros {
+		intPart = strings.TrimLeft(intPart, "0")
+		if intPart == "" {
+			intPart = "0"
+		}
+	}
+	if f.trimTrailingZeros && hasFrac {
+		fracPart = strings.TrimRight(fracPart, "0")
+		if fracPart == "" {
+			fracPart = "0"
+		}
+	}
+	if !hasFrac {
+		return intPart
+	}
+	return intPart + "." + fracPart
+}
+
+func (f *formatter) formatIdentExpr(expr ast.IdentExpr) string {
 	return expr.Ident.Token.Lexeme
 }
 
-func formatThisExpr(ast.ThisExpr) string {
+func (f *formatter) formatThisExpr(ast.ThisExpr) string {
 	return "this"
 }
 
-func formatCallExpr(expr ast.CallExpr) string {
+func (f *formatter) formatSuperExpr(expr ast.SuperExpr) string {
+	return fmt.Sprintf("super.%s", f.format(expr.Method))
+}
+
+func (f *formatter) formatCallExpr(expr ast.CallExpr) string {
 	var b strings.Builder
-	fmt.Fprintf(&b, "%s(", Node(expr.Callee))
+	fmt.Fprintf(&b, "%s(", f.format(expr.Callee))
 	for i, arg := range expr.Args {
-		fmt.Fprint(&b, Node(arg))
+		fmt.Fprint(&b, f.format(arg))
 		if i < len(expr.Args)-1 {
 			fmt.Fprint(&b, ", ")
 		}
@@ -274,41 +409,53 @@ func formatCallExpr(expr ast.CallExpr) string {
 	return b.String()
 }
 
-func formatGetExpr(expr ast.GetExpr) string {
-	return fmt.Sprintf("%s.%s", Node(expr.Object), Node(expr.Name))
+func (f *formatter) formatGetExpr(expr ast.GetExpr) string {
+	return fmt.Sprintf("%s.%s", f.format(expr.Object), f.format(expr.Name))
+}
+
+func (f *formatter) formatUnaryExpr(expr ast.UnaryExpr) string {
+	right := f.format(expr.Right)
+	if expr.Op.Type == token.Minus && strings.HasPrefix(right, "-") {
+		// - -a can't be formatted as --a since that would be lexed as a decrement.
+		return fmt.Sprintf("%s %s", expr.Op.Lexeme, right)
+	}
+	return fmt.Sprintf("%s%s", expr.Op.Lexeme, right)
 }
 
-func formatUnaryExpr(expr ast.UnaryExpr) string {
-	return fmt.Sprintf("%s%s", expr.Op.Lexeme, Node(expr.Right))
+func (f *formatter) formatIncrementExpr(expr ast.IncrementExpr) string {
+	if expr.Postfix {
+		return fmt.Sprintf("%s%s", f.format(expr.Operand), expr.Op.Lexeme)
+	}
+	return fmt.Sprintf("%s%s", expr.Op.Lexeme, f.format(expr.Operand))
 }
 
-func formatBinaryExpr(expr ast.BinaryExpr) string {
+func (f *formatter) formatBinaryExpr(expr ast.BinaryExpr) string {
 	leftSpace := " "
 	if expr.Op.Type == token.Comma {
 		// Comma operator is a special case where we don't want a space before it. A binary expression with a comma
 		// operator should be formatted as "a, b" rather than "a , b".
 		leftSpace = ""
 	}
-	return fmt.Sprintf("%s%s%s %s", Node(expr.Left), leftSpace, expr.Op.Lexeme, Node(expr.Right))
+	return fmt.Sprintf("%s%s%s %s", f.format(expr.Left), leftSpace, expr.Op.Lexeme, f.format(expr.Right))
 }
 
-func formatTernaryExpr(expr ast.TernaryExpr) string {
-	return fmt.Sprint(Node(expr.Condition), " ? ", Node(expr.Then), " : ", Node(expr.Else))
+func (f *formatter) formatTernaryExpr(expr ast.TernaryExpr) string {
+	return fmt.Sprint(f.format(expr.Condition), " ? ", f.format(expr.Then), " : ", f.format(expr.Else))
 }
 
-func formatAssignmentExpr(expr ast.AssignmentExpr) string {
-	return fmt.Sprintf("%s = %s", Node(expr.Left), Node(expr.Right))
+func (f *formatter) formatAssignmentExpr(expr ast.AssignmentExpr) string {
+	return fmt.Sprintf("%s = %s", f.format(expr.Left), f.format(expr.Right))
 }
 
-func formatSetExpr(expr ast.SetExpr) string {
-	return fmt.Sprintf("%s.%s = %s", Node(expr.Object), Node(expr.Name), Node(expr.Value))
+func (f *formatter) formatSetExpr(expr ast.SetExpr) string {
+	return fmt.Sprintf("%s.%s = %s", f.format(expr.Object), f.format(expr.Name), f.format(expr.Value))
 }
 
-func indent(s string) string {
+func (f *formatter) indent(s string) string {
 	lines := strings.Split(s, "\n")
 	for i, line := range lines {
 		if line != "" {
-			lines[i] = strings.Repeat(" ", indentSize) + line
+			lines[i] = strings.Repeat(" ", f.indentSize) + line
 		}
 	}
 	return strings.Join(lines, "\n")
@@ -0,0 +1,93 @@
+package format_test
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcuscaisey/lox/lox/ast"
+	"github.com/marcuscaisey/lox/lox/format"
+	"github.com/marcuscaisey/lox/lox/parser"
+)
+
+const testdataDir = "../../test/testdata"
+
+// TestRoundTrip checks that formatting a program doesn't change its AST.
+func TestRoundTrip(t *testing.T) {
+	err := filepath.WalkDir(testdataDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".lox" {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		program, err := parser.Parse(bytes.NewReader(src), parser.WithComments())
+		if err != nil {
+			// Programs with syntax errors can't be formatted.
+			return nil
+		}
+
+		t.Run(strings.TrimPrefix(path, testdataDir+"/"), func(t *testing.T) {
+			formatted := format.Node(program)
+			formattedProgram, err := parser.Parse(strings.NewReader(formatted), parser.WithComments())
+			if err != nil {
+				t.Fatalf("parsing formatted program: %s\nformatted program:\n%s", err, formatted)
+			}
+			if !ast.Equal(program, formattedProgram) {
+				t.Errorf("formatted program has a different AST\nformatted program:\n%s", formatted)
+			}
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		opts []format.Option
+		want string
+	}{
+		{name: "Integer", src: "print 7;", want: "print 7;\n"},
+		{name: "LeadingZeros", src: "print 007;", want: "print 7;\n"},
+		{name: "Zero", src: "print 000;", want: "print 0;\n"},
+		{name: "LeadingZerosBeforeFraction", src: "print 00.5;", want: "print 0.5;\n"},
+		{name: "TrailingZeros", src: "print 1.50;", want: "print 1.5;\n"},
+		{name: "ZeroFraction", src: "print 2.00;", want: "print 2.0;\n"},
+		{name: "SingleZeroFraction", src: "print 2.0;", want: "print 2.0;\n"},
+		{name: "ZeroFractionOfZero", src: "print 00.000;", want: "print 0.0;\n"},
+		{
+			name: "LeadingZerosKept",
+			src:  "print 007.50;",
+			opts: []format.Option{format.WithLeadingZerosTrimmed(false)},
+			want: "print 007.5;\n",
+		},
+		{
+			name: "TrailingZerosKept",
+			src:  "print 007.50;",
+			opts: []format.Option{format.WithTrailingZerosTrimmed(false)},
+			want: "print 7.50;\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			program, err := parser.Parse(strings.NewReader(test.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := format.Node(program, test.opts...); got != test.want {
+				t.Errorf("Node(%q) = %q, want %q", test.src, got, test.want)
+			}
+		})
+	}
+}
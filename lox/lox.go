@@ -8,7 +8,38 @@ const (
 	BuiltinType string = "type"
 	// BuiltinError is the name of the built-in error function.
 	BuiltinError string = "error"
+	// BuiltinLen is the name of the built-in len function.
+	BuiltinLen string = "len"
+	// BuiltinSubstring is the name of the built-in substring function.
+	BuiltinSubstring string = "substring"
+	// BuiltinIndexOf is the name of the built-in indexOf function.
+	BuiltinIndexOf string = "indexOf"
+	// BuiltinCharAt is the name of the built-in charAt function.
+	BuiltinCharAt string = "charAt"
+	// BuiltinToUpper is the name of the built-in toUpper function.
+	BuiltinToUpper string = "toUpper"
+	// BuiltinToLower is the name of the built-in toLower function.
+	BuiltinToLower string = "toLower"
+	// BuiltinTrim is the name of the built-in trim function.
+	BuiltinTrim string = "trim"
+	// BuiltinMath is the name of the built-in math module.
+	BuiltinMath string = "math"
 )
 
+// BuiltinModules contains the names of the built-in objects which are modules rather than functions.
+var BuiltinModules = []string{BuiltinMath}
+
 // AllBuiltins contains the names of all objects that are built-in to the language.
-var AllBuiltins = []string{BuiltinClock, BuiltinType, BuiltinError}
+var AllBuiltins = []string{
+	BuiltinClock,
+	BuiltinType,
+	BuiltinError,
+	BuiltinLen,
+	BuiltinSubstring,
+	BuiltinIndexOf,
+	BuiltinCharAt,
+	BuiltinToUpper,
+	BuiltinToLower,
+	BuiltinTrim,
+	BuiltinMath,
+}
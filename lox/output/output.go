@@ -0,0 +1,113 @@
+// Package output implements the selection of the format that commands print their output in.
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/marcuscaisey/lox/lox"
+	"github.com/marcuscaisey/lox/lox/ansi"
+)
+
+// Format is a format that output can be printed in.
+type Format int
+
+const (
+	// Pretty is the human readable format. Diagnostics are highlighted using colour if stdout and stderr are both
+	// connected to a terminal.
+	Pretty Format = iota
+	// JSON is the machine readable format. Each piece of output is printed as a JSON object on its own line.
+	JSON
+	// SARIF is the SARIF 2.1.0 format understood by code scanning tools such as GitHub code scanning. The errors
+	// reported by a command should be collected in a [SARIFLog], which is printed once the command has finished. Any
+	// other output is printed in the human readable format.
+	SARIF
+)
+
+// Flags are the command line flags which select the output format.
+type Flags struct {
+	pretty *bool
+	json   *bool
+	sarif  *bool
+}
+
+// RegisterFlags registers the -pretty and -json flags with the given flag set.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	return &Flags{
+		pretty: fs.Bool("pretty", false, "Print output in the human readable format with colour (default when connected to a terminal)"),
+		json:   fs.Bool("json", false, "Print output in the machine readable JSON format"),
+	}
+}
+
+// RegisterSARIFFlag registers the -sarif flag with the given flag set. It should only be registered by commands whose
+// only output other than that of the program being run is errors.
+func (f *Flags) RegisterSARIFFlag(fs *flag.FlagSet) {
+	f.sarif = fs.Bool("sarif", false, "Print errors in the SARIF 2.1.0 format")
+}
+
+// Format returns the output format selected by the flags. It should be called after the flags have been parsed.
+// If -pretty was provided, then colour is enabled even if stdout and stderr aren't connected to a terminal. An error is
+// returned if more than one format was provided.
+func (f *Flags) Format() (Format, error) {
+	sarif := f.sarif != nil && *f.sarif
+	switch {
+	case *f.pretty && *f.json:
+		return 0, errors.New("-pretty and -json cannot be provided together")
+	case sarif && (*f.pretty || *f.json):
+		return 0, errors.New("-sarif cannot be provided with -pretty or -json")
+	case sarif:
+		return SARIF, nil
+	case *f.json:
+		return JSON, nil
+	case *f.pretty:
+		ansi.Enabled = true
+		return Pretty, nil
+	default:
+		return Pretty, nil
+	}
+}
+
+// PrintError prints an error to w in the given format.
+// In the JSON format, an error which is a [lox.Errors] or a [*lox.Error] is printed as an object containing a list of
+// diagnostics:
+//
+//	{"diagnostics":[{"message":"...","start":{...},"end":{...}}]}
+//
+// Any other error is printed as an object containing its message:
+//
+//	{"error":"..."}
+//
+// In the SARIF format, the error is printed as a log containing a single run whose results are its diagnostics. Commands
+// which can report more than one error should collect them in a [SARIFLog] instead.
+func PrintError(w io.Writer, format Format, err error) {
+	switch format {
+	case Pretty:
+		fmt.Fprintln(w, err)
+	case JSON:
+		var loxErrs lox.Errors
+		var loxErr *lox.Error
+		switch {
+		case errors.As(err, &loxErrs):
+			loxErrs.Sort()
+			PrintJSON(w, map[string]any{"diagnostics": loxErrs})
+		case errors.As(err, &loxErr):
+			PrintJSON(w, map[string]any{"diagnostics": lox.Errors{loxErr}})
+		default:
+			PrintJSON(w, map[string]any{"error": err.Error()})
+		}
+	case SARIF:
+		printSARIF(w, err)
+	}
+}
+
+// PrintJSON prints v to w as a JSON object on its own line.
+func PrintJSON(w io.Writer, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("unexpected error marshalling %T to JSON: %s", v, err))
+	}
+	fmt.Fprintf(w, "%s\n", data)
+}
@@ -0,0 +1,211 @@
+package output
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/marcuscaisey/lox/lox"
+	"github.com/marcuscaisey/lox/lox/token"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Results     []sarifResult     `json:"results"`
+	Invocations []sarifInvocation `json:"invocations,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId,omitempty"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+	Fixes            []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion          `json:"deletedRegion"`
+	InsertedContent sarifArtifactContent `json:"insertedContent"`
+}
+
+type sarifArtifactContent struct {
+	Text string `json:"text"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifRegion is a range of characters in a file. Lines and columns are 1-based and columns are measured in UTF-16 code
+// units, which is the default in SARIF.
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+// SARIFLog collects the errors reported during a run of a command so that they can be printed as a single SARIF log
+// containing a single run. Code scanning tools expect one log per run, so errors can't be printed as they're reported.
+type SARIFLog struct {
+	tool          string
+	errs          lox.Errors
+	notifications []sarifNotification
+}
+
+// NewSARIFLog returns an empty log of a run of the named tool.
+func NewSARIFLog(tool string) *SARIFLog {
+	return &SARIFLog{tool: tool}
+}
+
+// Add adds err to the log. If err is a [lox.Errors] or a [*lox.Error], then each of its diagnostics is added as a
+// result. Otherwise, it's added as a notification that the run failed.
+func (l *SARIFLog) Add(err error) {
+	var loxErrs lox.Errors
+	var loxErr *lox.Error
+	switch {
+	case errors.As(err, &loxErrs):
+		l.errs = append(l.errs, loxErrs...)
+	case errors.As(err, &loxErr):
+		l.errs = append(l.errs, loxErr)
+	default:
+		l.notifications = append(l.notifications, sarifNotification{Level: "error", Message: sarifMessage{Text: err.Error()}})
+	}
+}
+
+// Print prints the log to w as JSON on its own line. The results are sorted by their position.
+func (l *SARIFLog) Print(w io.Writer) {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: l.tool}},
+		Results: []sarifResult{},
+	}
+	errs := slices.Clone(l.errs)
+	errs.Sort()
+	for _, e := range errs {
+		run.Results = append(run.Results, newSARIFResult(e))
+	}
+	if len(l.notifications) > 0 {
+		run.Invocations = []sarifInvocation{{
+			ExecutionSuccessful:        false,
+			ToolExecutionNotifications: l.notifications,
+		}}
+	}
+	PrintJSON(w, sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	})
+}
+
+// printSARIF prints err to w as a SARIF log containing a single run of the current command.
+func printSARIF(w io.Writer, err error) {
+	log := NewSARIFLog(filepath.Base(os.Args[0]))
+	log.Add(err)
+	log.Print(w)
+}
+
+func newSARIFResult(e *lox.Error) sarifResult {
+	result := sarifResult{
+		RuleID:    string(e.Code),
+		Level:     e.Severity.String(),
+		Message:   sarifMessage{Text: e.Msg},
+		Locations: []sarifLocation{newSARIFLocation(e.Start, e.End)},
+	}
+	for _, related := range e.Related {
+		location := newSARIFLocation(related.Start, related.End)
+		location.Message = &sarifMessage{Text: related.Msg}
+		result.RelatedLocations = append(result.RelatedLocations, location)
+	}
+	for _, fix := range e.Fixes {
+		location := newSARIFLocation(fix.Start, fix.End)
+		result.Fixes = append(result.Fixes, sarifFix{
+			Description: sarifMessage{Text: fix.Msg},
+			ArtifactChanges: []sarifArtifactChange{{
+				ArtifactLocation: location.PhysicalLocation.ArtifactLocation,
+				Replacements: []sarifReplacement{{
+					DeletedRegion:   location.PhysicalLocation.Region,
+					InsertedContent: sarifArtifactContent{Text: fix.NewText},
+				}},
+			}},
+		})
+	}
+	return result
+}
+
+func newSARIFLocation(start, end token.Position) sarifLocation {
+	var uri string
+	if start.File != nil {
+		uri = filepath.ToSlash(start.File.Name)
+	}
+	return sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: uri},
+			Region: sarifRegion{
+				StartLine:   start.Line,
+				StartColumn: sarifColumn(start),
+				EndLine:     end.Line,
+				EndColumn:   sarifColumn(end),
+			},
+		},
+	}
+}
+
+func sarifColumn(pos token.Position) int {
+	return pos.ColumnUTF16() + 1
+}
@@ -0,0 +1,66 @@
+package output_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/marcuscaisey/lox/lox"
+	"github.com/marcuscaisey/lox/lox/output"
+	"github.com/marcuscaisey/lox/lox/token"
+)
+
+var update = flag.Bool("update", false, "updates the golden files")
+
+func TestSARIFLog(t *testing.T) {
+	file := token.NewFile("dir/test.lox", []byte("var x = 1;\n{\n    var x = \"😀\" + y;\n}\n"))
+	rang := func(line, start, end int) token.Range {
+		return token.Token{
+			StartPos: token.Position{File: file, Line: line, Column: start},
+			EndPos:   token.Position{File: file, Line: line, Column: end},
+		}
+	}
+
+	var warnings lox.Errors
+	warnings.AddWarningf(rang(3, 8, 9), "x shadows a declaration in an outer scope")
+	warnings[0].Code = lox.ErrorCodeShadowedDeclaration
+	warnings[0].AddRelatedf(rang(1, 4, 5), "x is declared here")
+	undeclared := &lox.Error{Msg: "y has not been declared, did you mean x?", Code: lox.ErrorCodeUndeclared}
+	undeclared.Start, undeclared.End = rang(3, 21, 22).Start(), rang(3, 21, 22).End()
+	undeclared.AddFixf(rang(3, 21, 22), "x", "replace with x")
+
+	log := output.NewSARIFLog("golox")
+	// The error is added before the warning, but comes after it in the results since it's reported later in the file.
+	log.Add(undeclared)
+	log.Add(warnings)
+	log.Add(errors.New("something went wrong"))
+	var b bytes.Buffer
+	log.Print(&b)
+
+	if n := bytes.Count(b.Bytes(), []byte("\n")); n != 1 {
+		t.Fatalf("Print() printed %d lines, want 1:\n%s", n, b.String())
+	}
+	var got bytes.Buffer
+	if err := json.Indent(&got, b.Bytes(), "", "  "); err != nil {
+		t.Fatalf("Print() printed invalid JSON: %s\n%s", err, b.String())
+	}
+	goldenPath := filepath.Join("testdata", "sarif.golden")
+	if *update {
+		if err := os.WriteFile(goldenPath, got.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), got.String()); diff != "" {
+		t.Errorf("Print() printed incorrect log (-want +got):\n%s", diff)
+	}
+}
@@ -2,25 +2,31 @@ package parser
 
 import (
 	"io"
+	"iter"
 	"strings"
 	"unicode/utf8"
 
+	"github.com/marcuscaisey/lox/lox"
 	"github.com/marcuscaisey/lox/lox/token"
 )
 
 const eof = -1
 
 // errorHandler is the function which handles syntax errors encountered during lexing.
-// It's passed the offending token and a format string and arguments to construct an error message from.
-type errorHandler func(tok token.Token, format string, args ...any)
-
-// lexer converts Lox source code into lexical tokens.
-// Tokens are read from the lexer using the Next method.
-// Syntax errors are handled by calling the error handler function which can be set using SetErrorHandler. The default
-// error handler is a no-op.
-type lexer struct {
+// It's passed the offending token, the code of the error, and a format string and arguments to construct an error
+// message from.
+type errorHandler func(tok token.Token, code lox.ErrorCode, format string, args ...any)
+
+// Lexer converts Lox source code into lexical tokens.
+// Tokens are read from the lexer using the Next method or by ranging over the Tokens iterator.
+type Lexer struct {
 	src        []byte
 	errHandler errorHandler
+	comments   bool
+	errs       lox.Errors
+	// interpolationDepths contains the number of unclosed braces in each interpolated expression being lexed, innermost
+	// last.
+	interpolationDepths []int
 
 	ch           rune           // character currently being considered
 	pos          token.Position // position of character currently being considered
@@ -29,27 +35,62 @@ type lexer struct {
 	lastReadSize int            // size of last rune read
 }
 
-// newLexer constructs a lexer which will lex the source code read from an io.Reader.
-func newLexer(r io.Reader) (*lexer, error) {
+// LexerOption can be passed to [NewLexer] to configure lexing behaviour.
+type LexerOption func(*Lexer)
+
+// WithCommentTokens enables the lexing of comment tokens. By default, comments are skipped.
+func WithCommentTokens() LexerOption {
+	return func(l *Lexer) {
+		l.comments = true
+	}
+}
+
+// NewLexer constructs a [*Lexer] which will lex the source code read from an io.Reader. Syntax errors encountered
+// during lexing are returned by the Err method. If the io.Reader also has a Name method, then its result is used as the
+// filename of the token positions.
+func NewLexer(r io.Reader, opts ...LexerOption) (*Lexer, error) {
+	file, err := readFile(r)
+	if err != nil {
+		return nil, err
+	}
+	l := newLexer(file, nil)
+	l.comments = false
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l, nil
+}
+
+// readFile reads all of the source code from an io.Reader into a [*token.File].
+func readFile(r io.Reader) (*token.File, error) {
 	src, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
-	filename := name(r)
+	return token.NewFile(name(r), src), nil
+}
 
-	l := &lexer{
-		src:        src,
-		errHandler: func(token.Token, string, ...any) {},
+// newLexer constructs a lexer which will lex the source code in a file. errHandler is called for each syntax error
+// encountered. If errHandler is nil, then errors are collected and returned by the Err method instead. Comment tokens
+// are always returned.
+func newLexer(file *token.File, errHandler errorHandler) *Lexer {
+	l := &Lexer{
+		src:        file.Contents(),
+		errHandler: errHandler,
+		comments:   true,
 		pos: token.Position{
-			File:   token.NewFile(filename, src),
+			File:   file,
 			Line:   1,
 			Column: 0,
 		},
 	}
+	if l.errHandler == nil {
+		l.errHandler = l.addError
+	}
 
 	l.next()
 
-	return l, nil
+	return l
 }
 
 func name(v any) string {
@@ -59,13 +100,40 @@ func name(v any) string {
 	return ""
 }
 
-// SetErrorHandler sets the error handler function which will be called when a syntax error is encountered.
-func (l *lexer) SetErrorHandler(errHandler errorHandler) {
-	l.errHandler = errHandler
+// Next returns the next token. An EOF token is returned if the end of the source code has been reached.
+func (l *Lexer) Next() token.Token {
+	for {
+		tok := l.lex()
+		if tok.Type != token.Comment || l.comments {
+			return tok
+		}
+	}
+}
+
+// Tokens returns an iterator over the remaining tokens. The final token yielded is an EOF token.
+func (l *Lexer) Tokens() iter.Seq[token.Token] {
+	return func(yield func(token.Token) bool) {
+		for {
+			tok := l.Next()
+			if !yield(tok) || tok.Type == token.EOF {
+				return
+			}
+		}
+	}
 }
 
-// Next returns the next token. An EOF token is returned if the end of the source code has been reached.
-func (l *lexer) Next() token.Token {
+// Err returns the syntax errors which have been encountered so far as a [lox.Errors], or nil if there weren't any.
+// It should be called once all tokens have been read.
+func (l *Lexer) Err() error {
+	return l.errs.Err()
+}
+
+func (l *Lexer) addError(tok token.Token, code lox.ErrorCode, format string, args ...any) {
+	l.errs.AddCodedf(code, tok, format, args...)
+}
+
+// lex lexes the next token.
+func (l *Lexer) lex() token.Token {
 	l.skipWhitespace()
 
 	startOffset := l.offset
@@ -82,14 +150,26 @@ func (l *lexer) Next() token.Token {
 		tok.Type = token.Dot
 	case l.ch == '=':
 		tok.Type = token.Equal
-		if l.peek() == '=' {
+		switch l.peek() {
+		case '=':
 			l.next()
 			tok.Type = token.EqualEqual
+		case '>':
+			l.next()
+			tok.Type = token.Arrow
 		}
 	case l.ch == '+':
 		tok.Type = token.Plus
+		if l.peek() == '+' {
+			l.next()
+			tok.Type = token.PlusPlus
+		}
 	case l.ch == '-':
 		tok.Type = token.Minus
+		if l.peek() == '-' {
+			l.next()
+			tok.Type = token.MinusMinus
+		}
 	case l.ch == '*':
 		tok.Type = token.Asterisk
 	case l.ch == '/':
@@ -102,6 +182,9 @@ func (l *lexer) Next() token.Token {
 			tok.Type = token.Slash
 			break
 		}
+	case l.ch == '~' && l.peek() == '/':
+		l.next()
+		tok.Type = token.TildeSlash
 	case l.ch == '%':
 		tok.Type = token.Percent
 	case l.ch == '<':
@@ -132,19 +215,20 @@ func (l *lexer) Next() token.Token {
 		tok.Type = token.RightParen
 	case l.ch == '{':
 		tok.Type = token.LeftBrace
+		if n := len(l.interpolationDepths); n > 0 {
+			l.interpolationDepths[n-1]++
+		}
+	case l.ch == '}' && len(l.interpolationDepths) > 0 && l.interpolationDepths[len(l.interpolationDepths)-1] == 0:
+		// This brace closes an interpolated expression, so the rest of the string follows it.
+		l.interpolationDepths = l.interpolationDepths[:len(l.interpolationDepths)-1]
+		return l.lexString(tok, token.StringMiddle, token.StringTail)
 	case l.ch == '}':
 		tok.Type = token.RightBrace
-	case l.ch == '"':
-		lit, terminated := l.consumeString()
-		tok.EndPos = l.pos
-		tok.Lexeme = lit
-		if terminated {
-			tok.Type = token.String
-		} else {
-			tok.Type = token.Illegal
-			l.errHandler(tok, "unterminated string literal")
+		if n := len(l.interpolationDepths); n > 0 {
+			l.interpolationDepths[n-1]--
 		}
-		return tok
+	case l.ch == '"':
+		return l.lexString(tok, token.StringHead, token.String)
 	case isDigit(l.ch):
 		tok.Type = token.Number
 		tok.Lexeme = l.consumeNumber()
@@ -162,7 +246,7 @@ func (l *lexer) Next() token.Token {
 		tok.EndPos = l.pos
 		tok.Type = token.Illegal
 		tok.Lexeme = string(ch)
-		l.errHandler(tok, "illegal character %#U", ch)
+		l.errHandler(tok, lox.ErrorCodeIllegalCharacter, "illegal character %#U", ch)
 		return tok
 	}
 
@@ -173,25 +257,29 @@ func (l *lexer) Next() token.Token {
 	return tok
 }
 
-func (l *lexer) skipWhitespace() {
-	for isWhitespace(l.ch) {
+// skipWhitespace skips whitespace and invalid UTF-8 bytes. Invalid bytes have already been reported by next, so they're
+// skipped to recover from the error.
+func (l *Lexer) skipWhitespace() {
+	for isWhitespace(l.ch) || l.isInvalidByte() {
 		l.next()
 	}
 }
 
-func (l *lexer) consumeSingleLineComment() string {
+func (l *Lexer) consumeSingleLineComment() string {
 	l.next() // /
 	l.next() // /
 	var b strings.Builder
 	b.WriteString("//")
 	for l.ch != '\n' && l.ch != eof {
-		b.WriteRune(l.ch)
+		if !l.isInvalidByte() {
+			b.WriteRune(l.ch)
+		}
 		l.next()
 	}
 	return b.String()
 }
 
-func (l *lexer) consumeNumber() string {
+func (l *Lexer) consumeNumber() string {
 	var b strings.Builder
 	for isDigit(l.ch) {
 		b.WriteRune(l.ch)
@@ -210,24 +298,61 @@ func (l *lexer) consumeNumber() string {
 	return b.String()
 }
 
-func (l *lexer) consumeString() (s string, terminated bool) {
-	l.next()
+// lexString lexes the rest of a string token which starts at the current character, either a " or the } which closes
+// an interpolated expression. The token has type interpolatedType if it's followed by an interpolated expression and
+// terminatedType if it's terminated by a ".
+func (l *Lexer) lexString(tok token.Token, interpolatedType token.Type, terminatedType token.Type) token.Token {
+	lit, end := l.consumeString()
+	tok.EndPos = l.pos
+	tok.Lexeme = lit
+	switch end {
+	case stringEndInterpolation:
+		tok.Type = interpolatedType
+		l.interpolationDepths = append(l.interpolationDepths, 0)
+	case stringEndQuote:
+		tok.Type = terminatedType
+	case stringEndUnterminated:
+		tok.Type = token.Illegal
+		l.errHandler(tok, lox.ErrorCodeUnterminatedString, "unterminated string literal")
+	}
+	return tok
+}
+
+type stringEnd int
+
+const (
+	stringEndUnterminated stringEnd = iota
+	stringEndQuote
+	stringEndInterpolation
+)
+
+// consumeString consumes the current character and the rest of the string up to and including the closing " or the
+// ${ which starts an interpolated expression.
+func (l *Lexer) consumeString() (s string, end stringEnd) {
 	var b strings.Builder
-	b.WriteRune('"')
+	b.WriteRune(l.ch)
+	l.next()
 	for {
 		if l.ch == eof || l.ch == '\n' || l.ch == '\r' {
-			return b.String(), false
+			return b.String(), stringEndUnterminated
 		}
 		ch := l.ch
-		b.WriteRune(ch)
+		if !l.isInvalidByte() {
+			b.WriteRune(ch)
+		}
 		l.next()
 		if ch == '"' {
-			return b.String(), true
+			return b.String(), stringEndQuote
+		}
+		if ch == '$' && l.ch == '{' {
+			b.WriteRune(l.ch)
+			l.next()
+			return b.String(), stringEndInterpolation
 		}
 	}
 }
 
-func (l *lexer) consumeIdent() string {
+func (l *Lexer) consumeIdent() string {
 	var b strings.Builder
 	for isAlphaNumeric(l.ch) {
 		b.WriteRune(l.ch)
@@ -257,9 +382,22 @@ func isAlphaNumeric(r rune) bool {
 	return isAlpha(r) || isDigit(r)
 }
 
+// isIdent reports whether s would be lexed as a single identifier.
+func isIdent(s string) bool {
+	if s == "" || token.IdentType(s) != token.Ident {
+		return false
+	}
+	for i, r := range s {
+		if !isAlpha(r) && !(i > 0 && isDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
 // next reads the next character into s.ch and advances the lexer.
 // If the end of the source code has been reached, s.ch is set to eof.
-func (l *lexer) next() {
+func (l *Lexer) next() {
 	if l.ch == eof {
 		return
 	}
@@ -281,9 +419,9 @@ func (l *lexer) next() {
 	r, size := utf8.DecodeRune(l.src[l.readOffset:])
 	l.lastReadSize = size
 	l.readOffset += size
+	l.ch = r
 
-	if r == utf8.RuneError {
-		// If we get here then we've read exactly one invalid UTF-8 byte
+	if l.isInvalidByte() {
 		tok := token.Token{
 			StartPos: l.pos,
 			EndPos:   l.pos,
@@ -291,17 +429,21 @@ func (l *lexer) next() {
 			Lexeme:   string(l.src[l.offset : l.offset+1]),
 		}
 		tok.EndPos.Column++
-		l.errHandler(tok, "invalid UTF-8 byte %#x", l.src[l.offset])
-		l.next()
-		return
+		l.errHandler(tok, lox.ErrorCodeInvalidUTF8, "invalid UTF-8 byte %#x", l.src[l.offset])
 	}
+}
 
-	l.ch = r
+// isInvalidByte reports whether the character currently being considered is an invalid UTF-8 byte. Invalid bytes are
+// reported when they're read and then treated like whitespace, so that they end the token being lexed without
+// becoming part of the next one.
+func (l *Lexer) isInvalidByte() bool {
+	// utf8.DecodeRune returns (utf8.RuneError, 1) for an invalid byte. A valid encoding of utf8.RuneError has size 3.
+	return l.ch == utf8.RuneError && l.lastReadSize == 1
 }
 
 // peek returns the next character without advancing the lexer.
 // If the end of the source code has been reached, eof is returned.
-func (l *lexer) peek() rune {
+func (l *Lexer) peek() rune {
 	if l.readOffset >= len(l.src) {
 		return eof
 	}
@@ -0,0 +1,226 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/marcuscaisey/lox/lox"
+	"github.com/marcuscaisey/lox/lox/token"
+)
+
+func TestLexerInvalidUTF8(t *testing.T) {
+	tests := []struct {
+		name       string
+		src        string
+		wantTokens []string
+		wantErrors []string
+	}{
+		{
+			name:       "StrayByteBetweenTokens",
+			src:        "print \xff1;",
+			wantTokens: []string{"1:0 print", "1:7 1", "1:8 ;", "1:9 EOF"},
+			wantErrors: []string{"1:6 invalid UTF-8 byte 0xff"},
+		},
+		{
+			name:       "StrayByteInIdentifier",
+			src:        "ab\xffcd",
+			wantTokens: []string{"1:0 ab", "1:3 cd", "1:5 EOF"},
+			wantErrors: []string{"1:2 invalid UTF-8 byte 0xff"},
+		},
+		{
+			name:       "StrayByteInString",
+			src:        "\"a\xffb\"",
+			wantTokens: []string{"1:0 \"ab\"", "1:5 EOF"},
+			wantErrors: []string{"1:2 invalid UTF-8 byte 0xff"},
+		},
+		{
+			name:       "StrayByteAtStartOfLine",
+			src:        "a\n\xffb\nc",
+			wantTokens: []string{"1:0 a", "2:1 b", "3:0 c", "3:1 EOF"},
+			wantErrors: []string{"2:0 invalid UTF-8 byte 0xff"},
+		},
+		{
+			name:       "ConsecutiveStrayBytes",
+			src:        "\xff\xfe\nb",
+			wantTokens: []string{"2:0 b", "2:1 EOF"},
+			wantErrors: []string{"1:0 invalid UTF-8 byte 0xff", "1:1 invalid UTF-8 byte 0xfe"},
+		},
+		{
+			name:       "TruncatedMultiByteCharacter",
+			src:        "\xc3 é",
+			wantTokens: []string{"1:4 EOF"},
+			wantErrors: []string{"1:0 invalid UTF-8 byte 0xc3", "1:2 illegal character U+00E9 'é'"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var gotErrors []string
+			l := newLexer(token.NewFile("", []byte(test.src)), func(tok token.Token, _ lox.ErrorCode, format string, args ...any) {
+				gotErrors = append(gotErrors, fmt.Sprintf("%d:%d %s", tok.StartPos.Line, tok.StartPos.Column, fmt.Sprintf(format, args...)))
+			})
+
+			var gotTokens []string
+			for {
+				tok := l.Next()
+				if tok.Type != token.Illegal {
+					gotTokens = append(gotTokens, formatToken(tok))
+				}
+				if tok.Type == token.EOF {
+					break
+				}
+			}
+
+			if diff := cmp.Diff(test.wantTokens, gotTokens); diff != "" {
+				t.Errorf("incorrect tokens (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.wantErrors, gotErrors); diff != "" {
+				t.Errorf("incorrect errors (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+const tokensTestProgram = `// Greet someone.
+var name = "Bob"; // The name.
+print name + 1;
+`
+
+func TestLexerTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []LexerOption
+		want []string
+	}{
+		{
+			name: "WithoutComments",
+			want: []string{
+				"2:0 var", "2:4 name", "2:9 =", "2:11 \"Bob\"", "2:16 ;",
+				"3:0 print", "3:6 name", "3:11 +", "3:13 1", "3:14 ;",
+				"4:0 EOF",
+			},
+		},
+		{
+			name: "WithComments",
+			opts: []LexerOption{WithCommentTokens()},
+			want: []string{
+				"1:0 // Greet someone.",
+				"2:0 var", "2:4 name", "2:9 =", "2:11 \"Bob\"", "2:16 ;", "2:18 // The name.",
+				"3:0 print", "3:6 name", "3:11 +", "3:13 1", "3:14 ;",
+				"4:0 EOF",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l, err := NewLexer(bytes.NewReader([]byte(tokensTestProgram)), test.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for tok := range l.Tokens() {
+				got = append(got, formatToken(tok))
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("incorrect tokens (-want +got):\n%s", diff)
+			}
+			if err := l.Err(); err != nil {
+				t.Errorf("Err() = %s, want nil", err)
+			}
+		})
+	}
+}
+
+func TestLexerInterpolatedString(t *testing.T) {
+	l, err := NewLexer(bytes.NewReader([]byte(`"a ${b("${c}")} d ${fun() {}} {e}"`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for tok := range l.Tokens() {
+		got = append(got, fmt.Sprintf("%s %s", formatToken(tok), tok.Type))
+	}
+
+	want := []string{
+		`1:0 "a ${ StringHead`,
+		"1:5 b Ident",
+		"1:6 ( LeftParen",
+		`1:7 "${ StringHead`,
+		"1:10 c Ident",
+		`1:11 }" StringTail`,
+		"1:13 ) RightParen",
+		"1:14 } d ${ StringMiddle",
+		"1:20 fun Fun",
+		"1:23 ( LeftParen",
+		"1:24 ) RightParen",
+		"1:26 { LeftBrace",
+		"1:27 } RightBrace",
+		`1:28 } {e}" StringTail`,
+		"1:34 EOF EOF",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("incorrect tokens (-want +got):\n%s", diff)
+	}
+	if err := l.Err(); err != nil {
+		t.Errorf("Err() = %s, want nil", err)
+	}
+}
+
+func TestLexerTokensStopsEarly(t *testing.T) {
+	l, err := NewLexer(bytes.NewReader([]byte("1 2 3 4")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for tok := range l.Tokens() {
+		got = append(got, formatToken(tok))
+		if tok.Lexeme == "2" {
+			break
+		}
+	}
+	for tok := range l.Tokens() {
+		got = append(got, formatToken(tok))
+	}
+
+	want := []string{"1:0 1", "1:2 2", "1:4 3", "1:6 4", "1:7 EOF"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("incorrect tokens (-want +got):\n%s", diff)
+	}
+}
+
+func TestLexerErr(t *testing.T) {
+	l, err := NewLexer(bytes.NewReader([]byte("\xff\nprint \"a;")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range l.Tokens() {
+	}
+
+	var loxErrs lox.Errors
+	if !errors.As(l.Err(), &loxErrs) {
+		t.Fatalf("Err() = %v, want lox.Errors", l.Err())
+	}
+	var got []string
+	for _, e := range loxErrs {
+		got = append(got, fmt.Sprintf("%d:%d %s", e.Start.Line, e.Start.Column, e.Msg))
+	}
+	want := []string{"1:0 invalid UTF-8 byte 0xff", "2:6 unterminated string literal"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("incorrect errors (-want +got):\n%s", diff)
+	}
+}
+
+func formatToken(tok token.Token) string {
+	lexeme := tok.Lexeme
+	if tok.Type == token.EOF {
+		lexeme = "EOF"
+	}
+	return fmt.Sprintf("%d:%d %s", tok.StartPos.Line, tok.StartPos.Column, lexeme)
+}
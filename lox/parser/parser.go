@@ -4,7 +4,9 @@ package parser
 import (
 	"fmt"
 	"io"
+	"path/filepath"
 	"slices"
+	"strings"
 
 	"github.com/marcuscaisey/lox/lox"
 	"github.com/marcuscaisey/lox/lox/ast"
@@ -21,18 +23,35 @@ func WithComments() Option {
 	}
 }
 
+// defaultMaxNestingDepth is the maximum nesting depth used if [WithMaxNestingDepth] isn't passed to [Parse].
+const defaultMaxNestingDepth = 10000
+
+// WithMaxNestingDepth sets the maximum depth that statements and expressions can be nested to. If a program nests any
+// deeper than this, then parsing stops with a syntax error at the point that the limit was exceeded. This guards against
+// overflowing the stack when parsing adversarial input.
+func WithMaxNestingDepth(depth int) Option {
+	return func(p *parser) {
+		p.maxNestingDepth = depth
+	}
+}
+
 // Parse parses the source code read from r.
 // If an error is returned then an incomplete AST will still be returned along with it. If there are syntax errors then
 // this error will be a [lox.Errors] containing all of the errors.
 func Parse(r io.Reader, opts ...Option) (ast.Program, error) {
-	lexer, err := newLexer(r)
+	file, err := readFile(r)
 	if err != nil {
 		return ast.Program{}, fmt.Errorf("parsing lox source: %w", err)
 	}
+	return ParseFile(file, opts...)
+}
 
-	p := &parser{lexer: lexer}
-	lexer.SetErrorHandler(func(tok token.Token, format string, args ...any) {
-		p.addErrorf(tok, format, args...)
+// ParseFile is like [Parse] but parses the source code in a [*token.File]. The positions in the returned AST refer to
+// the file, so it can be shared with later phases which need to look up the source code.
+func ParseFile(file *token.File, opts ...Option) (ast.Program, error) {
+	p := &parser{maxNestingDepth: defaultMaxNestingDepth}
+	p.lexer = newLexer(file, func(tok token.Token, code lox.ErrorCode, format string, args ...any) {
+		p.addCodedErrorf(code, tok, format, args...)
 	})
 	for _, opt := range opts {
 		opt(p)
@@ -42,7 +61,7 @@ func Parse(r io.Reader, opts ...Option) (ast.Program, error) {
 }
 
 type parser struct {
-	lexer   *lexer
+	lexer   *Lexer
 	prevTok token.Token
 	tok     token.Token // token currently being considered
 	nextTok token.Token
@@ -51,12 +70,29 @@ type parser struct {
 	lastErrPos token.Position
 
 	parseComments bool
+
+	nestingDepth    int
+	maxNestingDepth int
 }
 
 // Parse parses the source code and returns the root node of the abstract syntax tree.
 // If an error is returned then an incomplete AST will still be returned along with it. If there are syntax errors then
 // this error will be a [lox.Errors] containing all of the errors.
-func (p *parser) Parse() (ast.Program, error) {
+func (p *parser) Parse() (program ast.Program, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(abort); !ok {
+				panic(r)
+			}
+			// Consume the rest of the tokens so that any lexical errors are still reported and we have an EOF token for
+			// the program.
+			for p.tok.Type != token.EOF {
+				p.next()
+			}
+			program = ast.Program{EOF: p.tok}
+			err = p.errs.Err()
+		}
+	}()
 	// Populate tok and nextTok
 	p.next()
 	p.next()
@@ -64,8 +100,10 @@ func (p *parser) Parse() (ast.Program, error) {
 }
 
 func (p *parser) parseProgram() ast.Program {
+	stmts := p.parseDeclsUntil(token.EOF)
 	return ast.Program{
-		Stmts: p.parseDeclsUntil(token.EOF),
+		Stmts: stmts,
+		EOF:   p.tok,
 	}
 }
 
@@ -106,7 +144,7 @@ func (p *parser) sync() token.Token {
 			finalTok := p.tok
 			p.next()
 			return finalTok
-		case token.Print, token.Var, token.If, token.LeftBrace, token.While, token.For, token.Break, token.Continue, token.EOF:
+		case token.Print, token.Import, token.Var, token.If, token.LeftBrace, token.While, token.For, token.Break, token.Continue, token.Try, token.Throw, token.EOF:
 			return finalTok
 		default:
 		}
@@ -120,6 +158,8 @@ func (p *parser) parseDecl() ast.Stmt {
 	switch tok := p.tok; {
 	case p.match(token.Comment):
 		stmt = p.parseCommentStmt(tok)
+	case p.match(token.Import):
+		stmt = p.parseImportStmt(tok)
 	case p.match(token.Var):
 		stmt = p.parseVarDecl(tok)
 	case p.tok.Type == token.Fun && p.nextTok.Type == token.Ident:
@@ -142,6 +182,17 @@ func (p *parser) parseCommentStmt(commentTok token.Token) ast.CommentStmt {
 	return ast.CommentStmt{Comment: commentTok}
 }
 
+func (p *parser) parseImportStmt(importTok token.Token) ast.ImportStmt {
+	path := p.expectf(token.String, "expected module path")
+	semicolon := p.expectSemicolon()
+	stem := strings.TrimSuffix(filepath.Base(path.Lexeme[1:len(path.Lexeme)-1]), ".lox")
+	if !isIdent(stem) {
+		p.addCodedErrorf(lox.ErrorCodeInvalidModuleName, path, "module name %q is not a valid identifier", stem)
+	}
+	name := token.Token{StartPos: path.StartPos, EndPos: path.EndPos, Type: token.Ident, Lexeme: stem}
+	return ast.ImportStmt{Import: importTok, Path: path, Name: ast.Ident{Token: name}, Semicolon: semicolon}
+}
+
 func (p *parser) parseVarDecl(varTok token.Token) ast.VarDecl {
 	name := p.expectf(token.Ident, "expected variable name")
 	var value ast.Expr
@@ -157,12 +208,17 @@ func (p *parser) parseFunDecl(funTok token.Token) ast.FunDecl {
 	return ast.FunDecl{
 		Fun:      funTok,
 		Name:     ast.Ident{Token: name},
-		Function: p.parseFun(),
+		Function: p.parseFun(false),
 	}
 }
 
 func (p *parser) parseClassDecl(classTok token.Token) ast.ClassDecl {
 	name := p.expectf(token.Ident, "expected class name")
+	var superclass ast.Expr
+	if p.match(token.Less) {
+		superclassName := p.expectf(token.Ident, "expected superclass name")
+		superclass = ast.IdentExpr{Ident: ast.Ident{Token: superclassName}}
+	}
 	p.expect(token.LeftBrace)
 	var body []ast.Stmt
 	for {
@@ -187,6 +243,7 @@ func (p *parser) parseClassDecl(classTok token.Token) ast.ClassDecl {
 	return ast.ClassDecl{
 		Class:      classTok,
 		Name:       ast.Ident{Token: name},
+		Superclass: superclass,
 		Body:       body,
 		RightBrace: rightBrace,
 	}
@@ -213,23 +270,66 @@ func (p *parser) parseMethodDecl() (ast.MethodDecl, bool) {
 	return ast.MethodDecl{
 		Modifiers: modifiers,
 		Name:      ast.Ident{Token: name},
-		Function:  p.parseFun(),
+		Function:  p.parseFun(false),
 	}, true
 }
 
-func (p *parser) parseFun() ast.Function {
+// parseFun parses a function's parameters and body. If allowShorthand is true, then the body can be written in
+// shorthand form as => followed by an expression.
+func (p *parser) parseFun(allowShorthand bool) ast.Function {
+	p.nest("function")
+	defer p.unnest()
 	leftParen := p.expect(token.LeftParen)
 	var params token.Ranges[ast.Ident]
 	if !p.match(token.RightParen) {
 		params = p.parseParams()
 		p.expect(token.RightParen)
 	}
-	leftBrace := p.expect(token.LeftBrace)
-	body := p.parseBlock(leftBrace)
-	return ast.Function{
-		LeftParen: leftParen,
-		Params:    params,
-		Body:      body,
+	if !allowShorthand {
+		leftBrace := p.expect(token.LeftBrace)
+		return ast.Function{
+			LeftParen: leftParen,
+			Params:    params,
+			Body:      p.parseBlock(leftBrace),
+		}
+	}
+	switch tok := p.tok; {
+	case p.match(token.LeftBrace):
+		return ast.Function{
+			LeftParen: leftParen,
+			Params:    params,
+			Body:      p.parseBlock(tok),
+		}
+	case p.match(token.Arrow):
+		return ast.Function{
+			LeftParen: leftParen,
+			Params:    params,
+			Arrow:     tok,
+			Body:      shorthandBody(tok, p.parseExprPrec(precAssignment)),
+		}
+	default:
+		p.addCodedErrorf(lox.ErrorCodeUnexpectedToken, tok, "expected %m or %m", token.LeftBrace, token.Arrow)
+		panic(unwind{})
+	}
+}
+
+// shorthandBody returns the body of a function written in shorthand form as arrow followed by value, which is a block
+// containing a single return statement of value. The braces, return keyword, and semicolon don't appear in the source,
+// so they're given empty ranges around the arrow and value.
+func shorthandBody(arrow token.Token, value ast.Expr) ast.BlockStmt {
+	emptyToken := func(t token.Type, pos token.Position) token.Token {
+		return token.Token{Type: t, StartPos: pos, EndPos: pos}
+	}
+	return ast.BlockStmt{
+		LeftBrace: emptyToken(token.LeftBrace, arrow.StartPos),
+		Stmts: token.Ranges[ast.Stmt]{
+			ast.ReturnStmt{
+				Return:    emptyToken(token.Return, value.Start()),
+				Value:     value,
+				Semicolon: emptyToken(token.Semicolon, value.End()),
+			},
+		},
+		RightBrace: emptyToken(token.RightBrace, value.End()),
 	}
 }
 
@@ -246,6 +346,8 @@ func (p *parser) parseParams() token.Ranges[ast.Ident] {
 }
 
 func (p *parser) parseStmt() ast.Stmt {
+	p.nest("statement")
+	defer p.unnest()
 	var stmt ast.Stmt
 	switch tok := p.tok; {
 	case p.match(token.Print):
@@ -264,6 +366,10 @@ func (p *parser) parseStmt() ast.Stmt {
 		stmt = p.parseContinueStmt(tok)
 	case p.match(token.Return):
 		stmt = p.parseReturnStmt(tok)
+	case p.match(token.Try):
+		stmt = p.parseTryStmt(tok)
+	case p.match(token.Throw):
+		stmt = p.parseThrowStmt(tok)
 	default:
 		stmt = p.parseExprStmt()
 	}
@@ -323,8 +429,11 @@ func (p *parser) parseWhileStmt(whileTok token.Token) ast.WhileStmt {
 	return ast.WhileStmt{While: whileTok, Condition: condition, Body: body}
 }
 
-func (p *parser) parseForStmt(forTok token.Token) ast.ForStmt {
+func (p *parser) parseForStmt(forTok token.Token) ast.Stmt {
 	p.expect(token.LeftParen)
+	if p.tok.Type == token.Ident && p.nextTok.Type == token.In {
+		return p.parseForInStmt(forTok)
+	}
 	var initialise ast.Stmt
 	switch tok := p.tok; {
 	case p.match(token.Var):
@@ -347,6 +456,16 @@ func (p *parser) parseForStmt(forTok token.Token) ast.ForStmt {
 	return ast.ForStmt{For: forTok, Initialise: initialise, Condition: condition, Update: update, Body: body}
 }
 
+// parseForInStmt parses the rest of a for-in statement after its opening parenthesis.
+func (p *parser) parseForInStmt(forTok token.Token) ast.ForInStmt {
+	name := p.expect(token.Ident)
+	in := p.expect(token.In)
+	iterable := p.parseExpr()
+	p.expect(token.RightParen)
+	body := p.parseStmt()
+	return ast.ForInStmt{For: forTok, Name: ast.Ident{Token: name}, In: in, Iterable: iterable, Body: body}
+}
+
 func (p *parser) parseBreakStmt(breakTok token.Token) ast.BreakStmt {
 	semicolon := p.expectSemicolon()
 	return ast.BreakStmt{Break: breakTok, Semicolon: semicolon}
@@ -367,104 +486,158 @@ func (p *parser) parseReturnStmt(returnTok token.Token) ast.ReturnStmt {
 	return ast.ReturnStmt{Return: returnTok, Value: value, Semicolon: semicolon}
 }
 
-func (p *parser) parseExpr() ast.Expr {
-	return p.parseCommaExpr()
-}
-
-func (p *parser) parseCommaExpr() ast.Expr {
-	return p.parseBinaryExpr(p.parseAssignmentExpr, token.Comma)
-}
-
-func (p *parser) parseAssignmentExpr() ast.Expr {
-	expr := p.parseTernaryExpr()
-	if p.match(token.Equal) {
-		switch left := expr.(type) {
-		case ast.IdentExpr:
-			right := p.parseAssignmentExpr()
-			expr = ast.AssignmentExpr{
-				Left:  left.Ident,
-				Right: right,
-			}
-		case ast.GetExpr:
-			right := p.parseAssignmentExpr()
-			expr = ast.SetExpr{
-				Object: left.Object,
-				Name:   left.Name,
-				Value:  right,
-			}
-		default:
-			p.addError(expr, "invalid assignment target")
-		}
+func (p *parser) parseTryStmt(tryTok token.Token) ast.TryStmt {
+	stmt := ast.TryStmt{Try: tryTok}
+	stmt.Body = p.parseBlock(p.expect(token.LeftBrace))
+	if catchTok, ok := p.match2(token.Catch); ok {
+		stmt.Catch = catchTok
+		p.expect(token.LeftParen)
+		stmt.CatchName = ast.Ident{Token: p.expectf(token.Ident, "expected error variable name")}
+		p.expect(token.RightParen)
+		stmt.CatchBody = p.parseBlock(p.expect(token.LeftBrace))
 	}
-	return expr
-}
-
-func (p *parser) parseTernaryExpr() ast.Expr {
-	expr := p.parseLogicalOrExpr()
-	if p.match(token.Question) {
-		then := p.parseExpr()
-		p.expect(token.Colon)
-		elseExpr := p.parseTernaryExpr()
-		expr = ast.TernaryExpr{
-			Condition: expr,
-			Then:      then,
-			Else:      elseExpr,
-		}
+	if finallyTok, ok := p.match2(token.Finally); ok {
+		stmt.Finally = finallyTok
+		stmt.FinallyBody = p.parseBlock(p.expect(token.LeftBrace))
 	}
-	return expr
-}
-
-func (p *parser) parseLogicalOrExpr() ast.Expr {
-	return p.parseBinaryExpr(p.parseLogicalAndExpr, token.Or)
+	if !stmt.HasCatch() && !stmt.HasFinally() {
+		p.addCodedErrorf(lox.ErrorCodeUnexpectedToken, p.tok, "expected %m or %m", token.Catch, token.Finally)
+		panic(unwind{})
+	}
+	return stmt
 }
 
-func (p *parser) parseLogicalAndExpr() ast.Expr {
-	return p.parseBinaryExpr(p.parseEqualityExpr, token.And)
-}
+func (p *parser) parseThrowStmt(throwTok token.Token) ast.ThrowStmt {
+	value := p.parseExpr()
+	semicolon := p.expectSemicolon()
+	return ast.ThrowStmt{Throw: throwTok, Value: value, Semicolon: semicolon}
+}
+
+// precedence is the precedence of an operator. Operators with a higher precedence bind more tightly.
+type precedence int
+
+const (
+	precComma precedence = iota + 1
+	precAssignment
+	precTernary
+	precLogicalOr
+	precLogicalAnd
+	precEquality
+	precRelational
+	precAdditive
+	precMultiplicative
+	precUnary
+)
 
-func (p *parser) parseEqualityExpr() ast.Expr {
-	return p.parseBinaryExpr(p.parseRelationalExpr, token.EqualEqual, token.BangEqual)
+// infixPrecedences maps each infix operator to its precedence. Infix operators are parsed as left-associative
+// [ast.BinaryExpr] nodes unless they're handled specially by parseInfixExpr, so adding a new binary operator only
+// requires adding it to this table.
+var infixPrecedences = map[token.Type]precedence{
+	token.Comma:        precComma,
+	token.Equal:        precAssignment,
+	token.Question:     precTernary,
+	token.Or:           precLogicalOr,
+	token.And:          precLogicalAnd,
+	token.EqualEqual:   precEquality,
+	token.BangEqual:    precEquality,
+	token.Less:         precRelational,
+	token.LessEqual:    precRelational,
+	token.Greater:      precRelational,
+	token.GreaterEqual: precRelational,
+	token.Plus:         precAdditive,
+	token.Minus:        precAdditive,
+	token.Asterisk:     precMultiplicative,
+	token.Slash:        precMultiplicative,
+	token.TildeSlash:   precMultiplicative,
+	token.Percent:      precMultiplicative,
 }
 
-func (p *parser) parseRelationalExpr() ast.Expr {
-	return p.parseBinaryExpr(p.parseAdditiveExpr, token.Less, token.LessEqual, token.Greater, token.GreaterEqual)
+func (p *parser) parseExpr() ast.Expr {
+	return p.parseExprPrec(precComma)
 }
 
-func (p *parser) parseAdditiveExpr() ast.Expr {
-	return p.parseBinaryExpr(p.parseMultiplicativeExpr, token.Plus, token.Minus)
+// parseExprPrec parses an expression whose infix operators all have a precedence of at least minPrec.
+func (p *parser) parseExprPrec(minPrec precedence) ast.Expr {
+	p.nest("expression")
+	defer p.unnest()
+	expr := p.parsePrefixExpr()
+	for {
+		prec, ok := infixPrecedences[p.tok.Type]
+		if !ok || prec < minPrec {
+			return expr
+		}
+		expr = p.parseInfixExpr(expr, prec)
+	}
 }
 
-func (p *parser) parseMultiplicativeExpr() ast.Expr {
-	return p.parseBinaryExpr(p.parseUnaryExpr, token.Asterisk, token.Slash, token.Percent)
+// parseInfixExpr parses an expression whose left operand has already been parsed and whose operator is the current
+// token. prec is the precedence of the operator.
+func (p *parser) parseInfixExpr(left ast.Expr, prec precedence) ast.Expr {
+	op := p.tok
+	p.next()
+	switch op.Type {
+	case token.Equal:
+		return p.parseAssignmentExpr(left)
+	case token.Question:
+		return p.parseTernaryExpr(left)
+	default:
+		right := p.parseExprPrec(prec + 1)
+		return ast.BinaryExpr{
+			Left:  left,
+			Op:    op,
+			Right: right,
+		}
+	}
 }
 
-// parseBinaryExpr parses a binary expression which uses the given operators. next is a function which parses an
-// expression of next highest precedence.
-func (p *parser) parseBinaryExpr(next func() ast.Expr, operators ...token.Type) ast.Expr {
-	expr := next()
-	for {
-		op, ok := p.match2(operators...)
-		if !ok {
-			break
-		}
-		right := next()
-		expr = ast.BinaryExpr{
-			Left:  expr,
-			Op:    op,
+// parseAssignmentExpr parses the right hand side of an assignment expression whose target is left.
+func (p *parser) parseAssignmentExpr(left ast.Expr) ast.Expr {
+	switch left := left.(type) {
+	case ast.IdentExpr:
+		right := p.parseExprPrec(precAssignment)
+		return ast.AssignmentExpr{
+			Left:  left.Ident,
 			Right: right,
 		}
+	case ast.GetExpr:
+		right := p.parseExprPrec(precAssignment)
+		return ast.SetExpr{
+			Object: left.Object,
+			Name:   left.Name,
+			Value:  right,
+		}
+	default:
+		p.addCodedError(lox.ErrorCodeInvalidAssignmentTarget, left, "invalid assignment target")
+		// Parse the right hand side anyway so that it's not reported as a syntax error as well.
+		p.parseExprPrec(precAssignment)
+		return left
 	}
-	return expr
 }
 
-func (p *parser) parseUnaryExpr() ast.Expr {
+// parseTernaryExpr parses the then and else operands of a ternary expression whose condition is condition.
+func (p *parser) parseTernaryExpr(condition ast.Expr) ast.Expr {
+	then := p.parseExpr()
+	p.expect(token.Colon)
+	elseExpr := p.parseExprPrec(precTernary)
+	return ast.TernaryExpr{
+		Condition: condition,
+		Then:      then,
+		Else:      elseExpr,
+	}
+}
+
+func (p *parser) parsePrefixExpr() ast.Expr {
 	if op, ok := p.match2(token.Bang, token.Minus); ok {
-		right := p.parseUnaryExpr()
+		right := p.parseExprPrec(precUnary)
 		return ast.UnaryExpr{
 			Op:    op,
 			Right: right,
 		}
 	}
+	if op, ok := p.match2(token.PlusPlus, token.MinusMinus); ok {
+		operand := p.parseExprPrec(precUnary)
+		return p.newIncrementExpr(op, operand, false)
+	}
 	return p.parseCallExpr()
 }
 
@@ -490,16 +663,38 @@ func (p *parser) parseCallExpr() ast.Expr {
 				Object: expr,
 				Name:   ast.Ident{Token: name},
 			}
+		case p.match(token.PlusPlus, token.MinusMinus):
+			expr = p.newIncrementExpr(p.prevTok, expr, true)
 		default:
 			return expr
 		}
 	}
 }
 
+// newIncrementExpr returns an increment expression which applies op to operand, reporting an error if operand isn't a
+// variable or property.
+func (p *parser) newIncrementExpr(op token.Token, operand ast.Expr, postfix bool) ast.Expr {
+	switch operand.(type) {
+	case ast.IdentExpr, ast.GetExpr:
+		return ast.IncrementExpr{
+			Op:      op,
+			Operand: operand,
+			Postfix: postfix,
+		}
+	default:
+		if op.Type == token.PlusPlus {
+			p.addCodedError(lox.ErrorCodeInvalidAssignmentTarget, operand, "invalid increment target")
+		} else {
+			p.addCodedError(lox.ErrorCodeInvalidAssignmentTarget, operand, "invalid decrement target")
+		}
+		return operand
+	}
+}
+
 func (p *parser) parseArgs() []ast.Expr {
 	var args []ast.Expr
 	for {
-		args = append(args, p.parseAssignmentExpr())
+		args = append(args, p.parseExprPrec(precAssignment))
 		if !p.match(token.Comma) {
 			break
 		}
@@ -511,10 +706,16 @@ func (p *parser) parsePrimaryExpr() ast.Expr {
 	switch tok := p.tok; {
 	case p.match(token.Number, token.String, token.True, token.False, token.Nil):
 		return ast.LiteralExpr{Value: tok}
+	case p.match(token.StringHead):
+		return p.parseInterpolatedStringExpr(tok)
 	case p.match(token.Ident):
 		return ast.IdentExpr{Ident: ast.Ident{Token: tok}}
 	case p.match(token.This):
 		return ast.ThisExpr{This: tok}
+	case p.match(token.Super):
+		p.expect(token.Dot)
+		method := p.expectf(token.Ident, "expected superclass method name")
+		return ast.SuperExpr{Super: tok, Method: ast.Ident{Token: method}}
 	case p.match(token.Fun):
 		return p.parseFunExpr(tok)
 	case p.match(token.LeftParen):
@@ -522,38 +723,41 @@ func (p *parser) parsePrimaryExpr() ast.Expr {
 		rightParen := p.expect(token.RightParen)
 		return ast.GroupExpr{LeftParen: tok, Expr: expr, RightParen: rightParen}
 	// Error productions
-	case p.match(token.EqualEqual, token.BangEqual, token.Less, token.LessEqual, token.Greater, token.GreaterEqual, token.Asterisk, token.Slash, token.Plus):
-		p.addErrorf(tok, "binary operator %m must have left and right operands", tok.Type)
-		var right ast.Expr
-		switch tok.Type {
-		case token.EqualEqual, token.BangEqual:
-			right = p.parseEqualityExpr()
-		case token.Less, token.LessEqual, token.Greater, token.GreaterEqual:
-			right = p.parseRelationalExpr()
-		case token.Plus:
-			right = p.parseMultiplicativeExpr()
-		case token.Asterisk, token.Slash:
-			right = p.parseUnaryExpr()
-		default:
-		}
+	case p.match(token.EqualEqual, token.BangEqual, token.Less, token.LessEqual, token.Greater, token.GreaterEqual, token.Asterisk, token.Slash, token.TildeSlash, token.Percent, token.Plus):
+		p.addCodedErrorf(lox.ErrorCodeUnexpectedToken, tok, "binary operator %m must have left and right operands", tok.Type)
+		right := p.parseExprPrec(infixPrecedences[tok.Type] + 1)
 		return ast.BinaryExpr{
 			Op:    tok,
 			Right: right,
 		}
 	default:
 		if tok.Type == token.Comment {
-			p.addError(tok, "comments can only appear where declarations can appear or at the end of statements")
+			p.addCodedError(lox.ErrorCodeMisplacedComment, tok, "comments can only appear where declarations can appear or at the end of statements")
 		} else {
-			p.addError(tok, "expected expression")
+			p.addCodedError(lox.ErrorCodeUnexpectedToken, tok, "expected expression")
 		}
 		panic(unwind{})
 	}
 }
 
+func (p *parser) parseInterpolatedStringExpr(head token.Token) ast.InterpolatedStringExpr {
+	expr := ast.InterpolatedStringExpr{Strings: []token.Token{head}}
+	for {
+		expr.Exprs = append(expr.Exprs, p.parseExpr())
+		if middle, ok := p.match2(token.StringMiddle); ok {
+			expr.Strings = append(expr.Strings, middle)
+			continue
+		}
+		tail := p.expectf(token.StringTail, "expected } after interpolated expression")
+		expr.Strings = append(expr.Strings, tail)
+		return expr
+	}
+}
+
 func (p *parser) parseFunExpr(funTok token.Token) ast.FunExpr {
 	return ast.FunExpr{
 		Fun:      funTok,
-		Function: p.parseFun(),
+		Function: p.parseFun(true),
 	}
 }
 
@@ -595,7 +799,7 @@ func (p *parser) expectf(t token.Type, format string, a ...any) token.Token {
 	if tok, ok := p.match2(t); ok {
 		return tok
 	}
-	p.addErrorf(p.tok, format, a...)
+	p.addCodedErrorf(lox.ErrorCodeUnexpectedToken, p.tok, format, a...)
 	panic(unwind{})
 }
 
@@ -606,7 +810,7 @@ func (p *parser) expectSemicolon() token.Token {
 		return tok
 	}
 	if p.lastErrPos != p.tok.Start() {
-		p.addErrorf(p.prevTok, "expected trailing %m", token.Semicolon)
+		p.addCodedErrorf(lox.ErrorCodeMissingSemicolon, p.prevTok, "expected trailing %m", token.Semicolon)
 	}
 	panic(unwind{})
 }
@@ -618,19 +822,39 @@ func (p *parser) next() {
 	p.nextTok = p.lexer.Next()
 }
 
-func (p *parser) addError(rang token.Range, message string) {
-	p.addErrorf(rang, "%s", message)
+func (p *parser) addCodedError(code lox.ErrorCode, rang token.Range, message string) {
+	p.addCodedErrorf(code, rang, "%s", message)
 }
 
-func (p *parser) addErrorf(rang token.Range, format string, args ...any) {
+// addCodedErrorf adds an error with the given code, unless an error has already been added at the same position.
+func (p *parser) addCodedErrorf(code lox.ErrorCode, rang token.Range, format string, args ...any) {
 	start := rang.Start()
 	if len(p.errs) > 0 && start == p.lastErrPos {
 		return
 	}
 	p.lastErrPos = start
-	p.errs.Addf(rang, format, args...)
+	p.errs.AddCodedf(code, rang, format, args...)
 }
 
+// nest increments the nesting depth. It should be paired with a deferred call to unnest. If the maximum nesting depth
+// is exceeded, then a "%s nesting too deep" error is added and the method panics to abort parsing altogether, since the
+// parser can't synchronise with the next statement without descending any deeper.
+func (p *parser) nest(kind string) {
+	p.nestingDepth++
+	if p.nestingDepth > p.maxNestingDepth {
+		p.addCodedErrorf(lox.ErrorCodeNestingTooDeep, p.tok, "%s nesting too deep", kind)
+		panic(abort{})
+	}
+}
+
+// unnest decrements the nesting depth.
+func (p *parser) unnest() {
+	p.nestingDepth--
+}
+
+// abort is used as a panic value to stop parsing when the parser can't recover from an error.
+type abort struct{}
+
 // unwind is used as a panic value so that we can unwind the stack and recover from a parsing error without having to
 // check for errors after every call to each parsing method.
 type unwind struct{}
@@ -0,0 +1,217 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcuscaisey/lox/lox"
+	"github.com/marcuscaisey/lox/lox/ast"
+	"github.com/marcuscaisey/lox/lox/format"
+)
+
+// seedCorpusDir is the directory containing the Lox files which are used as the seed corpus for FuzzParse.
+const seedCorpusDir = "../../test/testdata"
+
+func FuzzParse(f *testing.F) {
+	err := filepath.WalkDir(seedCorpusDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".lox" {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		f.Add(src)
+		return nil
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, src []byte) {
+		for _, opts := range [][]Option{nil, {WithComments()}} {
+			program, err := Parse(bytes.NewReader(src), opts...)
+			if err != nil {
+				var loxErrs lox.Errors
+				if !errors.As(err, &loxErrs) {
+					t.Fatalf("Parse returned %T, want lox.Errors: %s", err, err)
+				}
+				// Formatting the errors highlights the source code that they apply to, which checks that their
+				// positions are valid.
+				_ = loxErrs.Error()
+				continue
+			}
+			_ = format.Node(program)
+		}
+	})
+}
+
+func TestParseExprPrecedence(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{src: "1 + 2 * 3", want: "(+ 1 (* 2 3))"},
+		{src: "1 * 2 + 3", want: "(+ (* 1 2) 3)"},
+		{src: "1 - 2 - 3", want: "(- (- 1 2) 3)"},
+		{src: "1 % 2 / 3", want: "(/ (% 1 2) 3)"},
+		{src: "-1 * -2", want: "(* (- 1) (- 2))"},
+		{src: "!a.b(c)", want: "(! (call (. a b) c))"},
+		{src: "-a++", want: "(- (post++ a))"},
+		{src: "++a.b", want: "(pre++ (. a b))"},
+		{src: "a-- - --b", want: "(- (post-- a) (pre-- b))"},
+		{src: "1 < 2 == 3 >= 4", want: "(== (< 1 2) (>= 3 4))"},
+		{src: "a or b and c == d", want: "(or a (and b (== c d)))"},
+		{src: "a ? b : c ? d : e", want: "(? a b (? c d e))"},
+		{src: "a ? b, c : d", want: "(? a (, b c) d)"},
+		{src: "a or b ? c : d", want: "(? (or a b) c d)"},
+		{src: "a = b = c", want: "(= a (= b c))"},
+		{src: "a.b = c ? d : e", want: "(= (. a b) (? c d e))"},
+		{src: "a = 1, b = 2", want: "(, (= a 1) (= b 2))"},
+		{src: "f(a = 1, b)", want: "(call f (= a 1) b)"},
+		{src: "(1 + 2) * 3", want: "(* (group (+ 1 2)) 3)"},
+	}
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			program, err := Parse(bytes.NewReader([]byte(test.src + ";")))
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %s", test.src, err)
+			}
+			got := sexpr(program.Stmts[0].(ast.ExprStmt).Expr)
+			if got != test.want {
+				t.Errorf("Parse(%q) = %s, want %s", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseNestingTooDeep(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		opts    []Option
+		wantErr string
+	}{
+		{
+			name:    "Parentheses",
+			src:     "print " + strings.Repeat("(", 1_000_000) + "1" + strings.Repeat(")", 1_000_000) + ";",
+			wantErr: "1:10005 expression nesting too deep",
+		},
+		{
+			name:    "Blocks",
+			src:     strings.Repeat("{", 1_000_000) + strings.Repeat("}", 1_000_000),
+			wantErr: "1:10000 statement nesting too deep",
+		},
+		{
+			name:    "Functions",
+			src:     strings.Repeat("fun f() {", 1_000_000) + strings.Repeat("}", 1_000_000),
+			wantErr: "1:90005 function nesting too deep",
+		},
+		{
+			name:    "CustomLimit",
+			src:     "print (((1)));",
+			opts:    []Option{WithMaxNestingDepth(3)},
+			wantErr: "1:8 expression nesting too deep",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(test.src), test.opts...)
+			var loxErrs lox.Errors
+			if !errors.As(err, &loxErrs) {
+				t.Fatalf("Parse() returned error %v, want lox.Errors", err)
+			}
+			var got []string
+			for _, e := range loxErrs {
+				got = append(got, fmt.Sprintf("%d:%d %s", e.Start.Line, e.Start.Column, e.Msg))
+			}
+			if len(got) != 1 || got[0] != test.wantErr {
+				t.Errorf("Parse() returned errors %q, want [%q]", got, test.wantErr)
+			}
+		})
+	}
+}
+
+// sexpr returns an S-expression representation of an expression which makes its structure explicit.
+func sexpr(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case ast.LiteralExpr:
+		return expr.Value.Lexeme
+	case ast.IdentExpr:
+		return expr.Ident.Token.Lexeme
+	case ast.GroupExpr:
+		return fmt.Sprintf("(group %s)", sexpr(expr.Expr))
+	case ast.CallExpr:
+		s := "(call " + sexpr(expr.Callee)
+		for _, arg := range expr.Args {
+			s += " " + sexpr(arg)
+		}
+		return s + ")"
+	case ast.GetExpr:
+		return fmt.Sprintf("(. %s %s)", sexpr(expr.Object), expr.Name.Token.Lexeme)
+	case ast.UnaryExpr:
+		return fmt.Sprintf("(%s %s)", expr.Op.Lexeme, sexpr(expr.Right))
+	case ast.IncrementExpr:
+		if expr.Postfix {
+			return fmt.Sprintf("(post%s %s)", expr.Op.Lexeme, sexpr(expr.Operand))
+		}
+		return fmt.Sprintf("(pre%s %s)", expr.Op.Lexeme, sexpr(expr.Operand))
+	case ast.BinaryExpr:
+		return fmt.Sprintf("(%s %s %s)", expr.Op.Lexeme, sexpr(expr.Left), sexpr(expr.Right))
+	case ast.TernaryExpr:
+		return fmt.Sprintf("(? %s %s %s)", sexpr(expr.Condition), sexpr(expr.Then), sexpr(expr.Else))
+	case ast.AssignmentExpr:
+		return fmt.Sprintf("(= %s %s)", expr.Left.Token.Lexeme, sexpr(expr.Right))
+	case ast.SetExpr:
+		return fmt.Sprintf("(= (. %s %s) %s)", sexpr(expr.Object), expr.Name.Token.Lexeme, sexpr(expr.Value))
+	default:
+		return fmt.Sprintf("<%T>", expr)
+	}
+}
+
+// benchmarkProgram is repeated to create a large program for BenchmarkParse.
+const benchmarkProgram = `// Returns the nth Fibonacci number.
+fun fib(n) {
+    if (n < 2) {
+        return n;
+    }
+    return fib(n - 1) + fib(n - 2);
+}
+
+class Counter {
+    init() {
+        this.count = 0;
+    }
+
+    increment() {
+        this.count = this.count + 1;
+        return this;
+    }
+}
+
+var counter = Counter();
+for (var i = 0; i < 10; i = i + 1) {
+    counter.increment();
+    print i % 2 == 0 ? fib(i) : "odd";
+}
+`
+
+func BenchmarkParse(b *testing.B) {
+	src := bytes.Repeat([]byte(benchmarkProgram), 10000)
+	b.SetBytes(int64(len(src)))
+	b.ResetTimer()
+	for range b.N {
+		if _, err := Parse(bytes.NewReader(src)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
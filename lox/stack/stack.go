@@ -50,7 +50,19 @@ func (s *Stack[E]) Clear() {
 	*s = (*s)[:0]
 }
 
-// Backward returns an iterator over index-value pairs in the stack, traversing it backward with descending indices.
+// All returns an iterator over index-value pairs in the stack, traversing it from bottom to top with ascending indices.
+func (s *Stack[E]) All() iter.Seq2[int, E] {
+	return func(yield func(int, E) bool) {
+		for i, v := range *s {
+			if !yield(i, v) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over index-value pairs in the stack, traversing it from top to bottom with descending
+// indices.
 func (s *Stack[E]) Backward() iter.Seq2[int, E] {
 	return func(yield func(int, E) bool) {
 		for i := s.Len() - 1; i >= 0; i-- {
@@ -64,7 +76,7 @@ func (s *Stack[E]) Backward() iter.Seq2[int, E] {
 func (s *Stack[E]) String() string {
 	var b strings.Builder
 	fmt.Fprint(&b, "stack([")
-	for i, v := range *s {
+	for i, v := range s.All() {
 		fmt.Fprintf(&b, "%v", v)
 		if i < len(*s)-1 {
 			fmt.Fprint(&b, ", ")
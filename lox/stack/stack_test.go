@@ -0,0 +1,37 @@
+package stack
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestIterators(t *testing.T) {
+	s := New[string]()
+	s.Push("a")
+	s.Push("b")
+	s.Push("c")
+
+	var all []string
+	for i, v := range s.All() {
+		all = append(all, fmt.Sprintf("%d%s", i, v))
+	}
+	if want := []string{"0a", "1b", "2c"}; !slices.Equal(all, want) {
+		t.Errorf("All() yielded %q, want %q", all, want)
+	}
+
+	var backward []string
+	for i, v := range s.Backward() {
+		backward = append(backward, fmt.Sprintf("%d%s", i, v))
+	}
+	if want := []string{"2c", "1b", "0a"}; !slices.Equal(backward, want) {
+		t.Errorf("Backward() yielded %q, want %q", backward, want)
+	}
+
+	for _, v := range s.Backward() {
+		if v != "c" {
+			t.Errorf("Backward() yielded %q first, want %q", v, "c")
+		}
+		break
+	}
+}
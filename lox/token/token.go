@@ -2,8 +2,10 @@
 package token
 
 import (
+	"bytes"
 	"cmp"
 	"fmt"
+	"strings"
 	"unicode"
 	"unicode/utf16"
 
@@ -26,10 +28,13 @@ const (
 	PlaceholderIdent = "_"
 	// CurrentInstanceIdent is the identifier used to refer the current instance of the class in a method.
 	CurrentInstanceIdent = thisIdent
+	// SuperclassIdent is the identifier used to refer to the superclass of the class in a method.
+	SuperclassIdent = superIdent
 	// ConstructorIdent is the identifier used for the constructor method for classes.
 	ConstructorIdent = "init"
 
-	thisIdent = "this"
+	thisIdent  = "this"
+	superIdent = "super"
 )
 
 //go:generate go run golang.org/x/tools/cmd/stringer -type Type
@@ -65,11 +70,23 @@ const (
 	Static
 	Get
 	Set
+	Import
+	In
+	Throw
+	Try
+	Catch
+	Finally
 	keywordsEnd
 
 	// Literals
 	Ident
 	String
+	// StringHead, StringMiddle, and StringTail are the string parts of an interpolated string, which surround its
+	// interpolated expressions. For example, "a ${b} c ${d} e" is lexed as the StringHead "a ${, the tokens of b, the
+	// StringMiddle } c ${, the tokens of d, and the StringTail } e".
+	StringHead
+	StringMiddle
+	StringTail
 	Number
 	Comment
 
@@ -78,10 +95,14 @@ const (
 	Comma
 	Dot
 	Equal
+	Arrow
 	Plus
+	PlusPlus
 	Minus
+	MinusMinus
 	Asterisk
 	Slash
+	TildeSlash
 	Percent
 	Less
 	LessEqual
@@ -121,13 +142,22 @@ var typeStrings = map[Type]string{
 	Return:        "return",
 	Class:         "class",
 	This:          thisIdent,
-	Super:         "super",
+	Super:         superIdent,
 	Static:        "static",
 	Get:           "get",
 	Set:           "set",
+	Import:        "import",
+	In:            "in",
+	Throw:         "throw",
+	Try:           "try",
+	Catch:         "catch",
+	Finally:       "finally",
 	typesEnd:      "typesEnd",
 	Ident:         "identifier",
 	String:        "string",
+	StringHead:    "start of interpolated string",
+	StringMiddle:  "middle of interpolated string",
+	StringTail:    "end of interpolated string",
 	Number:        "number",
 	Comment:       "comment",
 	Semicolon:     ";",
@@ -135,15 +165,19 @@ var typeStrings = map[Type]string{
 	Dot:           ".",
 	Equal:         "=",
 	Plus:          "+",
+	PlusPlus:      "++",
 	Minus:         "-",
+	MinusMinus:    "--",
 	Asterisk:      "*",
 	Slash:         "/",
+	TildeSlash:    "~/",
 	Percent:       "%",
 	Less:          "<",
 	LessEqual:     "<=",
 	Greater:       ">",
 	GreaterEqual:  ">=",
 	EqualEqual:    "==",
+	Arrow:         "=>",
 	BangEqual:     "!=",
 	Bang:          "!",
 	Question:      "?",
@@ -172,6 +206,20 @@ func IdentType(ident string) Type {
 	return Ident
 }
 
+// Keywords returns all of the keywords in the order that their types are declared.
+func Keywords() []string {
+	keywords := make([]string, 0, keywordsEnd-keywordsStart-1)
+	for t := keywordsStart + 1; t < keywordsEnd; t++ {
+		keywords = append(keywords, typeStrings[t])
+	}
+	return keywords
+}
+
+// IsKeyword reports whether the type is a keyword.
+func (t Type) IsKeyword() bool {
+	return keywordsStart < t && t < keywordsEnd
+}
+
 // Format implements fmt.Formatter. All verbs have the default behaviour, except for 'm' (message) which formats the
 // type for use in an error message.
 func (t Type) Format(f fmt.State, verb rune) {
@@ -207,6 +255,30 @@ func (t Token) String() string {
 	return fmt.Sprintf("%s: %s [%s]", t.StartPos, t.Lexeme, t.Type)
 }
 
+// TabWidth is the number of columns between tab stops. It determines how wide tabs are displayed as by
+// [Position.ColumnWidth] and [ExpandTabs]. It must be positive.
+var TabWidth = 8
+
+// ExpandTabs replaces each tab in a line with the number of spaces needed to reach the next tab stop.
+func ExpandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		if r == '\t' {
+			spaces := TabWidth - width%TabWidth
+			b.WriteString(strings.Repeat(" ", spaces))
+			width += spaces
+			continue
+		}
+		b.WriteRune(r)
+		width += runewidth.RuneWidth(r)
+	}
+	return b.String()
+}
+
 // Position is a position in a file.
 type Position struct {
 	File   *File
@@ -232,14 +304,19 @@ func (p Position) ColumnUTF16() int {
 	return len(utf16.Encode([]rune(string(line[:p.Column]))))
 }
 
+// ColumnWidth returns the 1-based column as it's displayed in a terminal, taking into account the width of each
+// character. Tabs are expanded to the next tab stop, as determined by [TabWidth].
+func (p Position) ColumnWidth() int {
+	line := p.File.Line(p.Line)
+	return runewidth.StringWidth(ExpandTabs(string(line[:p.Column]))) + 1
+}
+
 func (p Position) String() string {
 	var prefix string
 	if p.File != nil && p.File.Name != "" {
 		prefix = p.File.Name + ":"
 	}
-	line := p.File.Line(p.Line)
-	col := runewidth.StringWidth(string(line[:p.Column])) + 1
-	return fmt.Sprintf("%s%d:%d", prefix, p.Line, col)
+	return fmt.Sprintf("%s%d:%d", prefix, p.Line, p.ColumnWidth())
 }
 
 // Format implements fmt.Formatter. All verbs have the default behaviour, except for 'm' (message) which formats the
@@ -251,9 +328,7 @@ func (p Position) Format(f fmt.State, verb rune) {
 		if p.File != nil && p.File.Name != "" {
 			prefix = ansi.Sprint("${CYAN}", p.File.Name, "${DEFAULT}:")
 		}
-		line := p.File.Line(p.Line)
-		col := runewidth.StringWidth(string(line[:p.Column])) + 1
-		ansi.Fprint(f, prefix, "${YELLOW}", p.Line, "${DEFAULT}:${YELLOW}", col, "${DEFAULT}")
+		ansi.Fprint(f, prefix, "${YELLOW}", p.Line, "${DEFAULT}:${YELLOW}", p.ColumnWidth(), "${DEFAULT}")
 	case 's':
 		fmt.Fprint(f, p.String())
 	default:
@@ -280,7 +355,8 @@ func (r Ranges[T]) End() Position {
 	return r[len(r)-1].End()
 }
 
-// File is a simple representation of a file.
+// File is a simple representation of a file. The offsets of the start of each line are computed when it's created, so
+// that lines can be looked up in constant time.
 type File struct {
 	Name        string
 	contents    []byte
@@ -289,17 +365,30 @@ type File struct {
 
 // NewFile returns a new File with the given contents.
 func NewFile(name string, contents []byte) *File {
-	f := &File{
-		Name:     name,
-		contents: contents,
-	}
-	f.lineOffsets = append(f.lineOffsets, 0)
-	for i := 0; i < len(contents); i++ {
-		if contents[i] == '\n' {
-			f.lineOffsets = append(f.lineOffsets, i+1)
+	lineOffsets := make([]int, 1, bytes.Count(contents, []byte{'\n'})+1)
+	for offset := 0; ; {
+		i := bytes.IndexByte(contents[offset:], '\n')
+		if i == -1 {
+			break
 		}
+		offset += i + 1
+		lineOffsets = append(lineOffsets, offset)
 	}
-	return f
+	return &File{
+		Name:        name,
+		contents:    contents,
+		lineOffsets: lineOffsets,
+	}
+}
+
+// Contents returns the contents of the file. The returned slice must not be modified.
+func (f *File) Contents() []byte {
+	return f.contents
+}
+
+// NumLines returns the number of lines in the file.
+func (f *File) NumLines() int {
+	return len(f.lineOffsets)
 }
 
 // Line returns the nth line of the file.
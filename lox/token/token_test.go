@@ -0,0 +1,28 @@
+package token
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFileLine(t *testing.T) {
+	file := NewFile("test.lox", []byte("a\n\nbc\n"))
+	want := []string{"a", "", "bc", ""}
+	if got := file.NumLines(); got != len(want) {
+		t.Fatalf("NumLines() = %d, want %d", got, len(want))
+	}
+	for i, wantLine := range want {
+		if got := string(file.Line(i + 1)); got != wantLine {
+			t.Errorf("Line(%d) = %q, want %q", i+1, got, wantLine)
+		}
+	}
+}
+
+func BenchmarkNewFile(b *testing.B) {
+	contents := bytes.Repeat([]byte("var x = 1; // A typical line of code.\n"), 100000)
+	b.SetBytes(int64(len(contents)))
+	b.ResetTimer()
+	for range b.N {
+		NewFile("bench.lox", contents)
+	}
+}
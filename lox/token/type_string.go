@@ -32,39 +32,52 @@ func _() {
 	_ = x[Static-21]
 	_ = x[Get-22]
 	_ = x[Set-23]
-	_ = x[keywordsEnd-24]
-	_ = x[Ident-25]
-	_ = x[String-26]
-	_ = x[Number-27]
-	_ = x[Comment-28]
-	_ = x[Semicolon-29]
-	_ = x[Comma-30]
-	_ = x[Dot-31]
-	_ = x[Equal-32]
-	_ = x[Plus-33]
-	_ = x[Minus-34]
-	_ = x[Asterisk-35]
-	_ = x[Slash-36]
-	_ = x[Percent-37]
-	_ = x[Less-38]
-	_ = x[LessEqual-39]
-	_ = x[Greater-40]
-	_ = x[GreaterEqual-41]
-	_ = x[EqualEqual-42]
-	_ = x[BangEqual-43]
-	_ = x[Bang-44]
-	_ = x[Question-45]
-	_ = x[Colon-46]
-	_ = x[LeftParen-47]
-	_ = x[RightParen-48]
-	_ = x[LeftBrace-49]
-	_ = x[RightBrace-50]
-	_ = x[typesEnd-51]
+	_ = x[Import-24]
+	_ = x[In-25]
+	_ = x[Throw-26]
+	_ = x[Try-27]
+	_ = x[Catch-28]
+	_ = x[Finally-29]
+	_ = x[keywordsEnd-30]
+	_ = x[Ident-31]
+	_ = x[String-32]
+	_ = x[StringHead-33]
+	_ = x[StringMiddle-34]
+	_ = x[StringTail-35]
+	_ = x[Number-36]
+	_ = x[Comment-37]
+	_ = x[Semicolon-38]
+	_ = x[Comma-39]
+	_ = x[Dot-40]
+	_ = x[Equal-41]
+	_ = x[Arrow-42]
+	_ = x[Plus-43]
+	_ = x[PlusPlus-44]
+	_ = x[Minus-45]
+	_ = x[MinusMinus-46]
+	_ = x[Asterisk-47]
+	_ = x[Slash-48]
+	_ = x[TildeSlash-49]
+	_ = x[Percent-50]
+	_ = x[Less-51]
+	_ = x[LessEqual-52]
+	_ = x[Greater-53]
+	_ = x[GreaterEqual-54]
+	_ = x[EqualEqual-55]
+	_ = x[BangEqual-56]
+	_ = x[Bang-57]
+	_ = x[Question-58]
+	_ = x[Colon-59]
+	_ = x[LeftParen-60]
+	_ = x[RightParen-61]
+	_ = x[LeftBrace-62]
+	_ = x[RightBrace-63]
+	_ = x[typesEnd-64]
 }
 
-const _Type_name = "IllegalEOFkeywordsStartPrintVarTrueFalseNilIfElseAndOrWhileForBreakContinueFunReturnClassThisSuperStaticGetSetkeywordsEndIdentStringNumberCommentSemicolonCommaDotEqualPlusMinusAsteriskSlashPercentLessLessEqualGreaterGreaterEqualEqualEqualBangEqualBangQuestionColonLeftParenRightParenLeftBraceRightBracetypesEnd"
+const _Type_name = "IllegalEOFkeywordsStartPrintVarTrueFalseNilIfElseAndOrWhileForBreakContinueFunReturnClassThisSuperStaticGetSetImportInThrowTryCatchFinallykeywordsEndIdentStringStringHeadStringMiddleStringTailNumberCommentSemicolonCommaDotEqualArrowPlusPlusPlusMinusMinusMinusAsteriskSlashTildeSlashPercentLessLessEqualGreaterGreaterEqualEqualEqualBangEqualBangQuestionColonLeftParenRightParenLeftBraceRightBracetypesEnd"
 
-var _Type_index = [...]uint16{0, 7, 10, 23, 28, 31, 35, 40, 43, 45, 49, 52, 54, 59, 62, 67, 75, 78, 84, 89, 93, 98, 104, 107, 110, 121, 126, 132, 138, 145, 154, 159, 162, 167, 171, 176, 184, 189, 196, 200, 209, 216, 228, 238, 247, 251, 259, 264, 273, 283, 292, 302, 310}
+var _Type_index = [...]uint16{0, 7, 10, 23, 28, 31, 35, 40, 43, 45, 49, 52, 54, 59, 62, 67, 75, 78, 84, 89, 93, 98, 104, 107, 110, 116, 118, 123, 126, 131, 138, 149, 154, 160, 170, 182, 192, 198, 205, 214, 219, 222, 227, 232, 236, 244, 249, 259, 267, 272, 282, 289, 293, 302, 309, 321, 331, 340, 344, 352, 357, 366, 376, 385, 395, 403}
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_Type_index)-1) {
@@ -7,17 +7,33 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+
+	"github.com/marcuscaisey/lox/lox"
 	"github.com/marcuscaisey/lox/lox/ast"
 	"github.com/marcuscaisey/lox/lox/format"
+	"github.com/marcuscaisey/lox/lox/output"
 	"github.com/marcuscaisey/lox/lox/parser"
 )
 
 var (
-	write    = flag.Bool("w", false, "Write result to (source) file instead of stdout")
-	printAST = flag.Bool("p", false, "Print the AST only")
+	write          = flag.Bool("w", false, "Write result to (source) file instead of stdout")
+	printDiff      = flag.Bool("d", false, "Display diff instead of the formatted source")
+	printAST       = flag.Bool("p", false, "Print the AST only")
+	checkRoundTrip = flag.Bool("check-roundtrip", false, "Check that formatting doesn't change the AST instead of printing the result")
+
+	trimLeadingZeros  = flag.Bool("trim-leading-zeros", true, "Remove leading zeros from number literals")
+	trimTrailingZeros = flag.Bool("trim-trailing-zeros", true, "Remove trailing zeros from the fractional part of number literals")
+
+	outFlags = output.RegisterFlags(flag.CommandLine)
 )
 
+var outFormat output.Format
+
 func usage() {
 	fmt.Fprintf(flag.CommandLine.Output(), "Usage: loxfmt [flags] [path]\n")
 	fmt.Fprintf(flag.CommandLine.Output(), "\n")
@@ -45,20 +61,48 @@ func main() {
 		exitWithUsageErr("error: cannot use -w with standard input")
 	}
 
+	if *write && *checkRoundTrip {
+		exitWithUsageErr("cannot use -w with -check-roundtrip")
+	}
+
+	if *printDiff && *write {
+		exitWithUsageErr("cannot use -d with -w")
+	}
+
+	if *printDiff && *checkRoundTrip {
+		exitWithUsageErr("cannot use -d with -check-roundtrip")
+	}
+
+	var err error
+	outFormat, err = outFlags.Format()
+	if err != nil {
+		exitWithUsageErr(err.Error())
+	}
+
 	if err := run(flag.Arg(0)); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		if outFormat == output.JSON {
+			output.PrintError(os.Stderr, outFormat, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
 		os.Exit(1)
 	}
 }
 
 func run(path string) error {
-	var reader io.Reader = os.Stdin
+	var data []byte
+	var err error
 	if path != "" {
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return err
-		}
-		reader = newNamedReader(bytes.NewReader(data), path)
+		data, err = os.ReadFile(path)
+	} else {
+		data, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return err
+	}
+	var reader io.Reader = bytes.NewReader(data)
+	if path != "" {
+		reader = newNamedReader(reader, path)
 	}
 
 	program, err := parser.Parse(reader, parser.WithComments())
@@ -70,11 +114,26 @@ func run(path string) error {
 		return err
 	}
 
-	formatted := format.Node(program)
-	if *write {
+	formatted := format.Node(program,
+		format.WithLeadingZerosTrimmed(*trimLeadingZeros),
+		format.WithTrailingZerosTrimmed(*trimTrailingZeros),
+	)
+	if *checkRoundTrip {
+		return checkFormattedAST(program, formatted)
+	}
+	if *printDiff {
+		diff := computeDiff(path, string(data), formatted)
+		if outFormat == output.JSON {
+			output.PrintJSON(os.Stdout, map[string]any{"diff": diff})
+		} else {
+			fmt.Print(diff)
+		}
+	} else if *write {
 		if err := os.WriteFile(path, []byte(formatted), 0644); err != nil {
 			return fmt.Errorf("failed to write formatted source to file: %w", err)
 		}
+	} else if outFormat == output.JSON {
+		output.PrintJSON(os.Stdout, map[string]any{"formatted": formatted})
 	} else {
 		fmt.Print(formatted)
 	}
@@ -82,6 +141,33 @@ func run(path string) error {
 	return nil
 }
 
+// computeDiff returns the unified diff between the source read from path and its formatted version. The diff is empty
+// if the source is already formatted.
+func computeDiff(path string, source string, formatted string) string {
+	if path == "" {
+		path = "<standard input>"
+	}
+	edits := myers.ComputeEdits(span.URIFromPath(path), source, formatted)
+	return fmt.Sprint(gotextdiff.ToUnified(path+".orig", path, source, edits))
+}
+
+// checkFormattedAST checks that the formatted source of a program parses to an AST which is equal to the program's.
+func checkFormattedAST(program ast.Program, formatted string) error {
+	formattedProgram, err := parser.Parse(strings.NewReader(formatted), parser.WithComments())
+	if err != nil {
+		return fmt.Errorf("parsing formatted source: %w", err)
+	}
+	if diff, formattedDiff, found := ast.Diff(program, formattedProgram); found {
+		return lox.NewErrorf(diff, "formatting changes the AST: %s differs from the one at %s in the formatted source",
+			nodeName(diff), formattedDiff.Start())
+	}
+	return nil
+}
+
+func nodeName(node ast.Node) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", node), "ast.")
+}
+
 type namedReader struct {
 	io.Reader
 	name string
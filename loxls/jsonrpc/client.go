@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sync/atomic"
 )
 
 // Client is a JSON-RPC client.
@@ -11,6 +12,8 @@ type Client struct {
 	in     io.Reader
 	out    io.Writer
 	server *server
+	// lastRequestID is the ID of the last request that was sent.
+	lastRequestID atomic.Int64
 }
 
 func newClient(in io.Reader, out io.Writer, server *server) *Client {
@@ -38,6 +41,25 @@ func (c *Client) Notify(method string, params any) error {
 	return nil
 }
 
+// Request sends a request to the server. The response isn't waited for and is ignored when it's received, so this
+// should only be used for requests whose result isn't needed.
+func (c *Client) Request(method string, params any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("sending %q request: marshalling parameters to JSON: %s", method, err)
+	}
+	req := &request{
+		JSONRPC: validJSONRPC,
+		ID:      intOrStr{int: int(c.lastRequestID.Add(1)), isInt: true},
+		Method:  method,
+		Params:  ptrTo(json.RawMessage(data)),
+	}
+	if err := c.server.write(req); err != nil {
+		return fmt.Errorf("sending %q request: %s", method, err)
+	}
+	return nil
+}
+
 func ptrTo[T any](v T) *T {
 	return &v
 }
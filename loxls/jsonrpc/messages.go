@@ -2,6 +2,7 @@ package jsonrpc
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -25,6 +26,9 @@ type request struct {
 	ID      intOrStr         `json:"id"`               // The request id.
 	Method  string           `json:"method"`           // The method to be invoked.
 	Params  *json.RawMessage `json:"params,omitempty"` // The method's params.
+
+	// ctx is cancelled when the client cancels the request. It's set when the request is dispatched.
+	ctx context.Context
 }
 
 func (r *request) isMessage() {}
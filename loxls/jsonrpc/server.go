@@ -4,6 +4,7 @@ package jsonrpc
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,14 +13,28 @@ import (
 	"mime"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Handler handles JSON-RPC requests and notifications.
+// Requests and notifications are handled one at a time in the order that they're received, so a request always observes
+// the effects of the notifications which were received before it. The exception is notifications which are handled by
+// Preempt.
+//
+// $/cancelRequest notifications are handled by the server, which cancels the context passed to HandleRequest for the
+// request with the given ID. They aren't passed to the handler.
 type Handler interface {
-	// HandleRequest responds to a JSON-RPC request.
-	HandleRequest(method string, params *json.RawMessage) (any, error)
+	// HandleRequest responds to a JSON-RPC request. ctx is cancelled if the client cancels the request, in which case the
+	// handler should stop early.
+	HandleRequest(ctx context.Context, method string, params *json.RawMessage) (any, error)
 	// HandleNotification handles a JSON-RPC notification.
 	HandleNotification(method string, params *json.RawMessage)
+	// Preempt is called with each request and notification as soon as it's received, which may be whilst earlier
+	// messages are still being handled. This allows messages such as cancellations to take effect on the request which is
+	// currently being handled. It reports whether it handled a notification, in which case the notification isn't passed
+	// to HandleNotification. Requests are always passed to HandleRequest afterwards, so the result is ignored for them.
+	Preempt(method string, params *json.RawMessage) bool
 	// SetClient sets the client that the handler can use to send requests and notifications to the server's client.
 	SetClient(*Client)
 }
@@ -33,8 +48,14 @@ func Serve(in io.Reader, out io.Writer, handler Handler) error {
 type server struct {
 	in      *bufio.Reader
 	out     io.Writer
+	outMu   sync.Mutex
 	handler Handler
 	client  *Client
+
+	// cancels contains the functions which cancel the contexts of the requests which haven't been responded to yet, keyed
+	// by their IDs.
+	cancels   map[intOrStr]context.CancelFunc
+	cancelsMu sync.Mutex
 }
 
 func newServer(in io.Reader, out io.Writer, handler Handler) *server {
@@ -42,6 +63,7 @@ func newServer(in io.Reader, out io.Writer, handler Handler) *server {
 		in:      bufio.NewReader(in),
 		out:     out,
 		handler: handler,
+		cancels: map[intOrStr]context.CancelFunc{},
 	}
 	client := newClient(in, out, server)
 	handler.SetClient(client)
@@ -50,6 +72,25 @@ func newServer(in io.Reader, out io.Writer, handler Handler) *server {
 }
 
 func (s *server) Serve() error {
+	// Messages are handled by a separate goroutine so that notifications can be preempted whilst a slow message is being
+	// handled.
+	queue := newMessageQueue()
+	handled := make(chan struct{})
+	go func() {
+		defer close(handled)
+		for {
+			msg, ok := queue.Pop()
+			if !ok {
+				return
+			}
+			s.handle(msg)
+		}
+	}()
+	defer func() {
+		queue.Close()
+		<-handled
+	}()
+
 	for {
 		msg, err := s.read()
 		if err != nil {
@@ -68,9 +109,7 @@ func (s *server) Serve() error {
 			return fmt.Errorf("serving jsonrpc requests: %v", err)
 		}
 
-		if err := s.handle(msg); err != nil {
-			return fmt.Errorf("serving jsonrpc requests: %v", err)
-		}
+		s.dispatch(msg, queue)
 	}
 }
 
@@ -91,6 +130,9 @@ func (s *server) read() (message, error) {
 	if err != nil {
 		return nil, fmt.Errorf("reading message: reading content: %w", err)
 	}
+	if int64(len(content)) < headers.ContentLength {
+		return nil, fmt.Errorf("reading message: reading content: expected %d bytes, got %d: %w", headers.ContentLength, len(content), io.ErrUnexpectedEOF)
+	}
 
 	msg, err := unmarshalMessage(content)
 	if err != nil {
@@ -106,12 +148,18 @@ const (
 	validMediaType      = "application/vscode-jsonrpc"
 )
 
+// readHeaders reads the header part of a message, which is terminated by an empty line. Unknown headers are ignored.
+// io.EOF is returned if the input ends before the message starts, otherwise io.ErrUnexpectedEOF is returned if it ends
+// before the header part does.
 func (s *server) readHeaders() (*headers, error) {
 	headers := &headers{}
 	contentLengthPresent := false
-	for {
+	for first := true; ; first = false {
 		line, err := s.readHeaderLine()
 		if err != nil {
+			if !first && errors.Is(err, io.EOF) {
+				err = fmt.Errorf("reading header line: %w", io.ErrUnexpectedEOF)
+			}
 			return nil, err
 		}
 		if line == "" {
@@ -131,6 +179,9 @@ func (s *server) readHeaders() (*headers, error) {
 			if err != nil {
 				return nil, fmt.Errorf("invalid %s header %q: %s", contentLengthHeader, value, err)
 			}
+			if n < 0 {
+				return nil, fmt.Errorf("invalid %s header %q: must not be negative", contentLengthHeader, value)
+			}
 			headers.ContentLength = n
 
 		case strings.ToLower(contentTypeHeader):
@@ -147,7 +198,7 @@ func (s *server) readHeaders() (*headers, error) {
 			headers.ContentType = value
 
 		default:
-			return nil, fmt.Errorf("unknown header: %q", line)
+			slog.Debug("Ignoring unknown header", "header", line)
 		}
 	}
 
@@ -158,11 +209,17 @@ func (s *server) readHeaders() (*headers, error) {
 	return headers, nil
 }
 
+// readHeaderLine reads a header line, which is terminated by \r\n, and returns it without the terminator. io.EOF is
+// returned if the input ends before the line starts, otherwise io.ErrUnexpectedEOF is returned if it ends before the
+// line does.
 func (s *server) readHeaderLine() (string, error) {
 	var b strings.Builder
 	for {
 		s, err := s.in.ReadString('\n')
 		if err != nil {
+			if errors.Is(err, io.EOF) && b.Len()+len(s) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
 			return "", fmt.Errorf("reading header line: %w", err)
 		}
 		b.WriteString(s)
@@ -178,39 +235,40 @@ func (s *server) write(msg message) error {
 	if err != nil {
 		return fmt.Errorf("writing message: %w", err)
 	}
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
 	if _, err := fmt.Fprintf(s.out, "%s: %d\r\n\r\n%s", contentLengthHeader, len(content), content); err != nil {
 		return fmt.Errorf("writing message: %w", err)
 	}
 	return nil
 }
 
-func (s *server) handle(msg message) error {
+// dispatch adds a request or notification to the queue of messages to be handled, unless it's a notification which the
+// handler preempts or a $/cancelRequest notification.
+func (s *server) dispatch(msg message, queue *messageQueue) {
 	switch msg := msg.(type) {
 	case *request:
-		result, err := s.handler.HandleRequest(msg.Method, msg.Params)
-		resp := &response{JSONRPC: validJSONRPC, ID: &msg.ID}
-		if err != nil {
-			var respErr *responseError
-			if errors.As(err, &respErr) {
-				resp.Error = respErr
-			} else {
-				resp.Error = newInternalError(err.Error())
-			}
-		} else {
-			resultBytes, err := json.Marshal(result)
-			if err != nil {
-				resp.Error = newInternalError(fmt.Sprintf("unable to marshal result: %v", err))
-			} else {
-				rawMsg := json.RawMessage(resultBytes)
-				resp.Result = &rawMsg
-			}
-		}
-		if writeErr := s.write(resp); writeErr != nil {
-			return fmt.Errorf("handling message: %w", writeErr)
-		}
+		slog.Debug("Received request", "method", msg.Method, "id", msg.ID.String())
+		s.handler.Preempt(msg.Method, msg.Params)
+		ctx, cancel := context.WithCancel(context.Background())
+		msg.ctx = ctx
+		s.cancelsMu.Lock()
+		s.cancels[msg.ID] = cancel
+		s.cancelsMu.Unlock()
+		queue.Push(msg)
 
 	case *notification:
-		s.handler.HandleNotification(msg.Method, msg.Params)
+		slog.Debug("Received notification", "method", msg.Method)
+		if msg.Method == cancelRequestMethod {
+			s.cancelRequest(msg.Params)
+			return
+		}
+		start := time.Now()
+		if s.handler.Preempt(msg.Method, msg.Params) {
+			slog.Debug("Handled notification", "method", msg.Method, "duration", time.Since(start), "preempted", true)
+			return
+		}
+		queue.Push(msg)
 
 	case *response:
 		var msgJSON string
@@ -222,6 +280,132 @@ func (s *server) handle(msg message) error {
 		}
 		slog.Info("Ignoring response message", "message", msgJSON)
 	}
+}
+
+const cancelRequestMethod = "$/cancelRequest"
+
+// cancelRequest cancels the context of the request whose ID is given by the params of a $/cancelRequest notification.
+// Requests which have already been responded to are ignored.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#cancelRequest
+func (s *server) cancelRequest(jsonParams *json.RawMessage) {
+	var params struct {
+		ID intOrStr `json:"id"`
+	}
+	if jsonParams == nil {
+		slog.Warn("Ignoring $/cancelRequest notification without params")
+		return
+	}
+	if err := json.Unmarshal(*jsonParams, &params); err != nil {
+		slog.Warn("Ignoring invalid $/cancelRequest notification", "error", err)
+		return
+	}
+	s.cancelsMu.Lock()
+	cancel, ok := s.cancels[params.ID]
+	s.cancelsMu.Unlock()
+	if ok {
+		slog.Debug("Cancelling request", "id", params.ID.String())
+		cancel()
+	}
+}
+
+// handle passes a request or notification to the handler.
+func (s *server) handle(msg message) {
+	switch msg := msg.(type) {
+	case *request:
+		if err := s.handleRequest(msg); err != nil {
+			slog.Error("Failed to handle request", "method", msg.Method, "id", msg.ID.String(), "error", err)
+		}
+
+	case *notification:
+		start := time.Now()
+		s.handler.HandleNotification(msg.Method, msg.Params)
+		slog.Debug("Handled notification", "method", msg.Method, "duration", time.Since(start))
+	}
+}
 
+func (s *server) handleRequest(req *request) error {
+	start := time.Now()
+	result, err := s.handler.HandleRequest(req.ctx, req.Method, req.Params)
+	duration := time.Since(start)
+	s.cancelsMu.Lock()
+	if cancel, ok := s.cancels[req.ID]; ok {
+		cancel()
+		delete(s.cancels, req.ID)
+	}
+	s.cancelsMu.Unlock()
+	resp := &response{JSONRPC: validJSONRPC, ID: &req.ID}
+	if err != nil {
+		var respErr *responseError
+		if errors.As(err, &respErr) {
+			resp.Error = respErr
+		} else {
+			resp.Error = newInternalError(err.Error())
+		}
+	} else {
+		resultBytes, err := json.Marshal(result)
+		if err != nil {
+			resp.Error = newInternalError(fmt.Sprintf("unable to marshal result: %v", err))
+		} else {
+			rawMsg := json.RawMessage(resultBytes)
+			resp.Result = &rawMsg
+		}
+	}
+	if resp.Error != nil {
+		slog.Warn("Request failed", "method", req.Method, "id", req.ID.String(), "duration", duration, "error", resp.Error.Message)
+	} else {
+		slog.Debug("Handled request", "method", req.Method, "id", req.ID.String(), "duration", duration)
+	}
+	if writeErr := s.write(resp); writeErr != nil {
+		return fmt.Errorf("handling request: %w", writeErr)
+	}
 	return nil
 }
+
+// messageQueue is a first-in-first-out queue of messages which are waiting to be handled. It's unbounded so that
+// reading messages never blocks on handling them. It's safe for concurrent use.
+type messageQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	msgs   []message
+	closed bool
+}
+
+func newMessageQueue() *messageQueue {
+	q := &messageQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// Push adds a message to the back of the queue.
+func (q *messageQueue) Push(msg message) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.msgs = append(q.msgs, msg)
+	q.cond.Signal()
+}
+
+// Pop removes the message at the front of the queue and returns it, waiting for one to be pushed if the queue is empty.
+// ok is false if the queue has been closed and all of its messages have been popped.
+func (q *messageQueue) Pop() (msg message, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.msgs) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if len(q.msgs) == 0 {
+		return nil, false
+	}
+	msg = q.msgs[0]
+	q.msgs[0] = nil
+	q.msgs = q.msgs[1:]
+	return msg, true
+}
+
+// Close closes the queue. Messages which have already been pushed can still be popped.
+func (q *messageQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
@@ -0,0 +1,285 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type nopHandler struct{}
+
+func (nopHandler) HandleRequest(context.Context, string, *json.RawMessage) (any, error) {
+	return nil, nil
+}
+func (nopHandler) HandleNotification(string, *json.RawMessage) {}
+func (nopHandler) Preempt(string, *json.RawMessage) bool       { return false }
+func (nopHandler) SetClient(*Client)                           {}
+
+func frame(content string, headers ...string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Content-Length: %d\r\n", len(content))
+	for _, header := range headers {
+		b.WriteString(header + "\r\n")
+	}
+	b.WriteString("\r\n")
+	b.WriteString(content)
+	return b.String()
+}
+
+const (
+	testRequest      = `{"jsonrpc":"2.0","id":1,"method":"foo","params":{"text":"a\r\n\r\nb"}}`
+	testNotification = `{"jsonrpc":"2.0","method":"bar"}`
+)
+
+// readAll reads messages until an error is returned and returns the methods of the messages read and the error.
+func readAll(r io.Reader) ([]string, error) {
+	s := newServer(r, io.Discard, nopHandler{})
+	var methods []string
+	for {
+		msg, err := s.read()
+		if err != nil {
+			return methods, err
+		}
+		switch msg := msg.(type) {
+		case *request:
+			methods = append(methods, msg.Method)
+		case *notification:
+			methods = append(methods, msg.Method)
+		case *response:
+			methods = append(methods, "")
+		}
+	}
+}
+
+func TestReadFraming(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "ConcatenatedMessages",
+			input: frame(testRequest) + frame(testNotification),
+		},
+		{
+			name:  "ContentType",
+			input: frame(testRequest, "Content-Type: application/vscode-jsonrpc; charset=utf-8") + frame(testNotification),
+		},
+		{
+			name:  "UnknownHeader",
+			input: frame(testRequest, "X-Foo: bar") + frame(testNotification),
+		},
+	}
+	for _, test := range tests {
+		readers := []struct {
+			name string
+			r    io.Reader
+		}{
+			{name: "WholeInput", r: strings.NewReader(test.input)},
+			{name: "OneByteAtATime", r: iotest.OneByteReader(strings.NewReader(test.input))},
+		}
+		for _, reader := range readers {
+			t.Run(test.name+"/"+reader.name, func(t *testing.T) {
+				methods, err := readAll(reader.r)
+				if !errors.Is(err, io.EOF) {
+					t.Errorf("read() returned error %v after last message, want io.EOF", err)
+				}
+				if diff := cmp.Diff([]string{"foo", "bar"}, methods); diff != "" {
+					t.Errorf("incorrect messages read (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+func TestReadFramingErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+		wantIs  error
+	}{
+		{
+			name:    "NonNumericContentLength",
+			input:   "Content-Length: ten\r\n\r\n" + testNotification,
+			wantErr: `invalid Content-Length header "ten"`,
+		},
+		{
+			name:    "NegativeContentLength",
+			input:   "Content-Length: -1\r\n\r\n" + testNotification,
+			wantErr: `invalid Content-Length header "-1": must not be negative`,
+		},
+		{
+			name:    "MissingContentLength",
+			input:   "Content-Type: application/vscode-jsonrpc\r\n\r\n" + testNotification,
+			wantErr: "missing Content-Length header",
+		},
+		{
+			name:    "HeaderWithoutColon",
+			input:   "Content-Length 10\r\n\r\n" + testNotification,
+			wantErr: "header line does not contain colon",
+		},
+		{
+			name:    "HeaderTerminatedByNewlineOnly",
+			input:   "Content-Length: 32\n\n" + testNotification,
+			wantErr: "reading header line",
+			wantIs:  io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "TruncatedHeaders",
+			input:   "Content-Length: 32\r\n",
+			wantErr: "reading header line",
+			wantIs:  io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "TruncatedContent",
+			input:   frame(testNotification)[:40],
+			wantErr: "expected 32 bytes, got 18",
+			wantIs:  io.ErrUnexpectedEOF,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := readAll(iotest.OneByteReader(strings.NewReader(test.input)))
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("read() returned error %v, want error containing %q", err, test.wantErr)
+			}
+			if test.wantIs != nil && !errors.Is(err, test.wantIs) {
+				t.Errorf("read() returned error %v, want %v", err, test.wantIs)
+			}
+			if errors.Is(err, io.EOF) {
+				t.Errorf("read() returned io.EOF, which would stop the server as if the input ended cleanly")
+			}
+		})
+	}
+}
+
+// recordingHandler records the methods of the messages that it handles. Handling a "slow" request takes a while and
+// handling a "block" request waits until a "cancel" notification has been preempted. Handling a "wait" request waits
+// until the request is cancelled.
+type recordingHandler struct {
+	mu        sync.Mutex
+	handled   []string
+	preempted chan struct{}
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{preempted: make(chan struct{})}
+}
+
+func (h *recordingHandler) HandleRequest(ctx context.Context, method string, _ *json.RawMessage) (any, error) {
+	switch method {
+	case "slow":
+		time.Sleep(20 * time.Millisecond)
+	case "block":
+		select {
+		case <-h.preempted:
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("timed out waiting for cancel notification")
+		}
+	case "wait":
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("timed out waiting for request to be cancelled")
+		}
+	}
+	h.record(method)
+	return nil, nil
+}
+
+func (h *recordingHandler) HandleNotification(method string, _ *json.RawMessage) {
+	h.record(method)
+}
+
+func (h *recordingHandler) Preempt(method string, _ *json.RawMessage) bool {
+	if method != "cancel" {
+		return false
+	}
+	close(h.preempted)
+	return true
+}
+
+func (h *recordingHandler) SetClient(*Client) {}
+
+func (h *recordingHandler) record(method string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handled = append(h.handled, method)
+}
+
+func testRequestWithMethod(id int, method string) string {
+	return frame(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q}`, id, method))
+}
+
+func testNotificationWithMethod(method string) string {
+	return frame(fmt.Sprintf(`{"jsonrpc":"2.0","method":%q}`, method))
+}
+
+func TestServeHandlesMessagesInOrder(t *testing.T) {
+	input := testRequestWithMethod(1, "slow") +
+		testNotificationWithMethod("didChange") +
+		testRequestWithMethod(2, "hover") +
+		testRequestWithMethod(3, "slow") +
+		testNotificationWithMethod("didClose")
+	handler := newRecordingHandler()
+	if err := Serve(strings.NewReader(input), io.Discard, handler); err != nil {
+		t.Fatalf("Serve returned error: %s", err)
+	}
+
+	want := []string{"slow", "didChange", "hover", "slow", "didClose"}
+	if diff := cmp.Diff(want, handler.handled); diff != "" {
+		t.Errorf("messages handled in incorrect order (-want +got):\n%s", diff)
+	}
+}
+
+func TestServePreemptsNotifications(t *testing.T) {
+	// The block request can only complete once the cancel notification after it has been preempted.
+	input := testRequestWithMethod(1, "block") +
+		testNotificationWithMethod("cancel") +
+		testNotificationWithMethod("didChange")
+	handler := newRecordingHandler()
+	var out strings.Builder
+	if err := Serve(strings.NewReader(input), &out, handler); err != nil {
+		t.Fatalf("Serve returned error: %s", err)
+	}
+
+	want := []string{"block", "didChange"}
+	if diff := cmp.Diff(want, handler.handled); diff != "" {
+		t.Errorf("incorrect messages handled (-want +got):\n%s", diff)
+	}
+	if strings.Contains(out.String(), `"error"`) {
+		t.Errorf("block request returned error: %s", out.String())
+	}
+}
+
+func TestServeCancelsRequests(t *testing.T) {
+	// The wait request can only complete once the $/cancelRequest notification after it has cancelled it. The
+	// notification for the already handled request should be ignored.
+	input := testRequestWithMethod(1, "hover") +
+		testRequestWithMethod(2, "wait") +
+		frame(`{"jsonrpc":"2.0","method":"$/cancelRequest","params":{"id":1}}`) +
+		frame(`{"jsonrpc":"2.0","method":"$/cancelRequest","params":{"id":2}}`) +
+		testNotificationWithMethod("didChange")
+	handler := newRecordingHandler()
+	var out strings.Builder
+	if err := Serve(strings.NewReader(input), &out, handler); err != nil {
+		t.Fatalf("Serve returned error: %s", err)
+	}
+
+	want := []string{"hover", "wait", "didChange"}
+	if diff := cmp.Diff(want, handler.handled); diff != "" {
+		t.Errorf("incorrect messages handled (-want +got):\n%s", diff)
+	}
+	if strings.Contains(out.String(), `"error"`) {
+		t.Errorf("wait request returned error: %s", out.String())
+	}
+}
@@ -20,7 +20,17 @@ func (c *client) TextDocumentPublishDiagnostics(params *protocol.PublishDiagnost
 	return c.jsonrpcClient.Notify("textDocument/publishDiagnostics", params)
 }
 
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#client_registerCapability
+func (c *client) ClientRegisterCapability(params *protocol.RegistrationParams) error {
+	return c.jsonrpcClient.Request("client/registerCapability", params)
+}
+
 // https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#window_logMessage
 func (c *client) WindowLogMessage(params *protocol.LogMessageParams) error {
 	return c.jsonrpcClient.Notify("window/logMessage", params)
 }
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#progress
+func (c *client) Progress(params *protocol.ProgressParams) error {
+	return c.jsonrpcClient.Notify("$/progress", params)
+}
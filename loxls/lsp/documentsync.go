@@ -3,26 +3,43 @@ package lsp
 import (
 	"errors"
 	"fmt"
-	"strings"
+	"time"
 
 	"github.com/marcuscaisey/lox/lox"
 	"github.com/marcuscaisey/lox/lox/analysis"
 	"github.com/marcuscaisey/lox/lox/ast"
 	"github.com/marcuscaisey/lox/lox/parser"
+	"github.com/marcuscaisey/lox/lox/token"
 	"github.com/marcuscaisey/lox/loxls/jsonrpc"
 	"github.com/marcuscaisey/lox/loxls/lsp/protocol"
 )
 
 type document struct {
 	URI        string
+	Version    int
 	Text       string
+	File       *token.File
 	Program    ast.Program
+	Nodes      *nodeIndex
 	IdentDecls map[ast.Ident]ast.Ident
 	HasErrors  bool
+	Errs       lox.Errors // Errors and warnings which have been published as diagnostics
 }
 
-// document returns the document with the given URI, or an error if it doesn't exist.
+// pendingChange is a change to a document which will be analysed once no further changes have been made for the
+// diagnostics delay.
+type pendingChange struct {
+	Version int
+	Text    string
+	Timer   *time.Timer
+}
+
+// document returns the document with the given URI, or an error if it doesn't exist. Any pending change to the
+// document is applied first.
 func (h *Handler) document(uri string) (*document, error) {
+	if err := h.applyPendingChange(uri); err != nil {
+		return nil, err
+	}
 	doc, ok := h.docsByURI[uri]
 	if !ok {
 		return nil, jsonrpc.NewError(jsonrpc.InvalidParams, "Document not found", map[string]any{"uri": uri})
@@ -45,17 +62,52 @@ func (h *Handler) textDocumentDidChange(params *protocol.DidChangeTextDocumentPa
 		case *protocol.IncrementalTextDocumentContentChangeEvent:
 			return errors.New("textDocument/didChange: incremental updates not supported")
 		case *protocol.FullTextDocumentContentChangeEvent:
-			if err := h.updateDoc(params.TextDocument.Uri, params.TextDocument.Version, string(change.Text)); err != nil {
-				return fmt.Errorf("textDocument/didChange: %s", err)
-			}
+			h.scheduleUpdateDoc(params.TextDocument.Uri, params.TextDocument.Version, string(change.Text))
 			return nil
 		}
 	}
 	return nil
 }
 
+// scheduleUpdateDoc updates a document once the diagnostics delay has passed, replacing any pending change to it.
+func (h *Handler) scheduleUpdateDoc(uri string, version int, src string) {
+	h.discardPendingChange(uri)
+	change := &pendingChange{Version: version, Text: src}
+	change.Timer = time.AfterFunc(h.diagnosticsDelay, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.pendingChangesByURI[uri] != change {
+			// The change has already been applied or replaced.
+			return
+		}
+		if err := h.applyPendingChange(uri); err != nil {
+			h.log.Errorf("textDocument/didChange: %s", err)
+		}
+	})
+	h.pendingChangesByURI[uri] = change
+}
+
+// applyPendingChange updates a document with its pending change, if it has one.
+func (h *Handler) applyPendingChange(uri string) error {
+	change, ok := h.pendingChangesByURI[uri]
+	if !ok {
+		return nil
+	}
+	h.discardPendingChange(uri)
+	return h.updateDoc(uri, change.Version, change.Text)
+}
+
+// discardPendingChange discards the pending change to a document, if it has one.
+func (h *Handler) discardPendingChange(uri string) {
+	if change, ok := h.pendingChangesByURI[uri]; ok {
+		change.Timer.Stop()
+		delete(h.pendingChangesByURI, uri)
+	}
+}
+
 func (h *Handler) updateDoc(uri string, version int, src string) error {
-	program, err := parser.Parse(strings.NewReader(string(src)), parser.WithComments())
+	file := token.NewFile(uri, []byte(src))
+	program, err := parser.ParseFile(file, parser.WithComments())
 
 	var loxErrs lox.Errors
 	var identDecls map[ast.Ident]ast.Ident
@@ -64,7 +116,20 @@ func (h *Handler) updateDoc(uri string, version int, src string) error {
 			return err
 		}
 	} else {
-		identDecls, loxErrs = analysis.ResolveIdents(program)
+		settings := h.settings.Get()
+		var opts []analysis.ResolveIdentsOption
+		if settings.Strict {
+			opts = append(opts, analysis.WithUnusedResultCheck())
+		} else {
+			opts = append(opts, analysis.WithoutUnusedCheck())
+		}
+		if settings.ReportShadowedBuiltins {
+			opts = append(opts, analysis.WithBuiltinShadowingCheck())
+		}
+		if !settings.ReportShadowedDeclarations {
+			opts = append(opts, analysis.WithoutShadowingCheck())
+		}
+		identDecls, loxErrs = analysis.ResolveIdents(program, opts...)
 		loxErrs = append(loxErrs, analysis.CheckSemantics(program)...)
 		loxErrs.Sort()
 	}
@@ -73,18 +138,31 @@ func (h *Handler) updateDoc(uri string, version int, src string) error {
 	for i, e := range loxErrs {
 		diagnostics[i] = &protocol.Diagnostic{
 			Range:    newRange(e.Start, e.End),
-			Severity: protocol.DiagnosticSeverityError,
+			Severity: diagnosticSeverity(e),
 			Source:   "loxls",
 			Message:  e.Msg,
 		}
+		if e.Code != "" {
+			diagnostics[i].Code = &protocol.IntegerOrString{Value: protocol.String(e.Code)}
+		}
+		for _, related := range e.Related {
+			diagnostics[i].RelatedInformation = append(diagnostics[i].RelatedInformation, &protocol.DiagnosticRelatedInformation{
+				Location: &protocol.Location{Uri: uri, Range: newRange(related.Start, related.End)},
+				Message:  related.Msg,
+			})
+		}
 	}
 
 	h.docsByURI[uri] = &document{
 		URI:        uri,
+		Version:    version,
 		Text:       src,
+		File:       file,
 		Program:    program,
+		Nodes:      newNodeIndex(program),
 		IdentDecls: identDecls,
 		HasErrors:  err != nil,
+		Errs:       loxErrs,
 	}
 
 	return h.client.TextDocumentPublishDiagnostics(&protocol.PublishDiagnosticsParams{
@@ -96,6 +174,7 @@ func (h *Handler) updateDoc(uri string, version int, src string) error {
 
 // https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_didClose
 func (h *Handler) textDocumentDidClose(params *protocol.DidCloseTextDocumentParams) error {
+	h.discardPendingChange(params.TextDocument.Uri)
 	doc, err := h.document(params.TextDocument.Uri)
 	if err != nil {
 		return err
@@ -103,3 +182,17 @@ func (h *Handler) textDocumentDidClose(params *protocol.DidCloseTextDocumentPara
 	delete(h.docsByURI, doc.URI)
 	return nil
 }
+
+// diagnosticSeverity returns the severity of the diagnostic which reports the given error. Warnings and errors reported
+// by opt-in checks which don't affect whether a program is valid are reported as warnings.
+func diagnosticSeverity(err *lox.Error) protocol.DiagnosticSeverity {
+	if err.Severity == lox.SeverityWarning {
+		return protocol.DiagnosticSeverityWarning
+	}
+	switch err.Code {
+	case lox.ErrorCodeShadowedBuiltin:
+		return protocol.DiagnosticSeverityWarning
+	default:
+		return protocol.DiagnosticSeverityError
+	}
+}
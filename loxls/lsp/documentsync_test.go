@@ -0,0 +1,310 @@
+package lsp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/marcuscaisey/lox/loxls/lsp/protocol"
+)
+
+func TestShadowedBuiltinDiagnostics(t *testing.T) {
+	const src = "fun f(clock) {\n    var type = clock;\n    return type;\n}\nprint f(1);\n"
+	tests := []struct {
+		name     string
+		initOpts any
+		want     []*protocol.Diagnostic
+	}{
+		{
+			name: "DisabledByDefault",
+			want: []*protocol.Diagnostic{},
+		},
+		{
+			name:     "Enabled",
+			initOpts: map[string]any{"reportShadowedBuiltins": true},
+			want: []*protocol.Diagnostic{
+				{
+					Range: &protocol.Range{
+						Start: &protocol.Position{Line: 0, Character: 6},
+						End:   &protocol.Position{Line: 0, Character: 11},
+					},
+					Severity: protocol.DiagnosticSeverityWarning,
+					Code:     &protocol.IntegerOrString{Value: protocol.String("shadowed-builtin")},
+					Source:   "loxls",
+					Message:  "clock shadows the built-in function of the same name",
+				},
+				{
+					Range: &protocol.Range{
+						Start: &protocol.Position{Line: 1, Character: 8},
+						End:   &protocol.Position{Line: 1, Character: 12},
+					},
+					Severity: protocol.DiagnosticSeverityWarning,
+					Code:     &protocol.IntegerOrString{Value: protocol.String("shadowed-builtin")},
+					Source:   "loxls",
+					Message:  "type shadows the built-in function of the same name",
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := startServer(t)
+			s.Initialize(t, test.initOpts)
+
+			s.Notify(t, "textDocument/didOpen", map[string]any{
+				"textDocument": map[string]any{"uri": "file:///test.lox", "languageId": "lox", "version": 1, "text": src},
+			})
+			var params protocol.PublishDiagnosticsParams
+			if err := json.Unmarshal(s.WaitForNotification(t, "textDocument/publishDiagnostics"), &params); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(test.want, params.Diagnostics); diff != "" {
+				t.Errorf("incorrect diagnostics (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestShadowedDeclarationDiagnostics(t *testing.T) {
+	const src = "var x = 1;\n{\n    var x = 2;\n    print x;\n}\nprint x;\n"
+	tests := []struct {
+		name     string
+		initOpts any
+		want     []*protocol.Diagnostic
+	}{
+		{
+			name: "EnabledByDefault",
+			want: []*protocol.Diagnostic{
+				{
+					Range: &protocol.Range{
+						Start: &protocol.Position{Line: 2, Character: 8},
+						End:   &protocol.Position{Line: 2, Character: 9},
+					},
+					Severity: protocol.DiagnosticSeverityWarning,
+					Code:     &protocol.IntegerOrString{Value: protocol.String("shadowed-declaration")},
+					Source:   "loxls",
+					Message:  "x shadows a declaration in an outer scope",
+					RelatedInformation: []*protocol.DiagnosticRelatedInformation{
+						{
+							Location: &protocol.Location{
+								Uri: "file:///test.lox",
+								Range: &protocol.Range{
+									Start: &protocol.Position{Line: 0, Character: 4},
+									End:   &protocol.Position{Line: 0, Character: 5},
+								},
+							},
+							Message: "x was declared here",
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "Disabled",
+			initOpts: map[string]any{"reportShadowedDeclarations": false},
+			want:     []*protocol.Diagnostic{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := startServer(t)
+			s.Initialize(t, test.initOpts)
+
+			s.Notify(t, "textDocument/didOpen", map[string]any{
+				"textDocument": map[string]any{"uri": "file:///test.lox", "languageId": "lox", "version": 1, "text": src},
+			})
+			var params protocol.PublishDiagnosticsParams
+			if err := json.Unmarshal(s.WaitForNotification(t, "textDocument/publishDiagnostics"), &params); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(test.want, params.Diagnostics); diff != "" {
+				t.Errorf("incorrect diagnostics (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnusedResultDiagnostics(t *testing.T) {
+	const src = "var a = 1;\na == 2;\n"
+	tests := []struct {
+		name     string
+		initOpts any
+		want     []*protocol.Diagnostic
+	}{
+		{
+			name: "Strict",
+			want: []*protocol.Diagnostic{
+				{
+					Range: &protocol.Range{
+						Start: &protocol.Position{Line: 1, Character: 0},
+						End:   &protocol.Position{Line: 1, Character: 7},
+					},
+					Severity: protocol.DiagnosticSeverityWarning,
+					Code:     &protocol.IntegerOrString{Value: protocol.String("unused-result")},
+					Source:   "loxls",
+					Message:  "expression result is not used",
+				},
+			},
+		},
+		{
+			name:     "NotStrict",
+			initOpts: map[string]any{"strict": false},
+			want:     []*protocol.Diagnostic{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := startServer(t)
+			s.Initialize(t, test.initOpts)
+
+			s.Notify(t, "textDocument/didOpen", map[string]any{
+				"textDocument": map[string]any{"uri": "file:///test.lox", "languageId": "lox", "version": 1, "text": src},
+			})
+			var params protocol.PublishDiagnosticsParams
+			if err := json.Unmarshal(s.WaitForNotification(t, "textDocument/publishDiagnostics"), &params); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(test.want, params.Diagnostics); diff != "" {
+				t.Errorf("incorrect diagnostics (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDidChangeDebouncesDiagnostics(t *testing.T) {
+	const uri = "file:///test.lox"
+	s := startServer(t, WithDiagnosticsDelay(50*time.Millisecond))
+	s.Initialize(t, nil)
+
+	s.Notify(t, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": "print 1;\n"},
+	})
+	s.WaitForNotification(t, "textDocument/publishDiagnostics")
+
+	for i, text := range []string{"print\n", "print x\n", "print x;\n"} {
+		s.Notify(t, "textDocument/didChange", map[string]any{
+			"textDocument":   map[string]any{"uri": uri, "version": 2 + i},
+			"contentChanges": []map[string]any{{"text": text}},
+		})
+	}
+	var params protocol.PublishDiagnosticsParams
+	if err := json.Unmarshal(s.WaitForNotification(t, "textDocument/publishDiagnostics"), &params); err != nil {
+		t.Fatal(err)
+	}
+
+	if params.Version != 4 {
+		t.Errorf("diagnostics published for version %d, want 4", params.Version)
+	}
+	wantMessages := []string{"x has not been declared"}
+	var gotMessages []string
+	for _, diagnostic := range params.Diagnostics {
+		gotMessages = append(gotMessages, diagnostic.Message)
+	}
+	if diff := cmp.Diff(wantMessages, gotMessages); diff != "" {
+		t.Errorf("incorrect diagnostic messages (-want +got):\n%s", diff)
+	}
+}
+
+func TestRequestAppliesPendingChange(t *testing.T) {
+	const uri = "file:///test.lox"
+	s := startServer(t, WithDiagnosticsDelay(time.Hour))
+	s.Initialize(t, nil)
+
+	s.Notify(t, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": "print 1;\n"},
+	})
+	s.WaitForNotification(t, "textDocument/publishDiagnostics")
+
+	s.Notify(t, "textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": uri, "version": 2},
+		"contentChanges": []map[string]any{{"text": "var x = 1;\nprint x;\n"}},
+	})
+	resp := s.Request(t, 1, "textDocument/definition", map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     map[string]any{"line": 1, "character": 6},
+	})
+	if resp.Error != nil {
+		t.Fatalf("textDocument/definition returned error: %+v", resp.Error)
+	}
+	var location *protocol.Location
+	if err := json.Unmarshal(resp.Result, &location); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &protocol.Range{
+		Start: &protocol.Position{Line: 0, Character: 4},
+		End:   &protocol.Position{Line: 0, Character: 5},
+	}
+	if location == nil {
+		t.Fatal("textDocument/definition returned null, want location of x")
+	}
+	if diff := cmp.Diff(want, location.Range); diff != "" {
+		t.Errorf("incorrect definition range (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnusedResultSideEffects(t *testing.T) {
+	// Each line ends with whether an unused result should be reported for it.
+	const src = `class Foo {
+    get value() {
+        return 1;
+    }
+}
+var foo = Foo();
+fun f() {
+    return 1;
+}
+var a = 1;
+var b = 2;
+1; // unused
+a; // unused
+a == b; // unused
+-a + (b * 2); // unused
+a ? b : !a; // unused
+a, b; // unused
+{
+    a and b; // unused
+}
+f();
+foo.value;
+a = 2;
+foo.b = 2;
+a++;
+a == f();
+a, f();
+a or f();
+a ? b = 1 : a;
+`
+	s := startServer(t)
+	s.Initialize(t, nil)
+
+	s.Notify(t, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": "file:///test.lox", "languageId": "lox", "version": 1, "text": src},
+	})
+	var params protocol.PublishDiagnosticsParams
+	if err := json.Unmarshal(s.WaitForNotification(t, "textDocument/publishDiagnostics"), &params); err != nil {
+		t.Fatal(err)
+	}
+
+	var want []int
+	for i, line := range strings.Split(src, "\n") {
+		if strings.HasSuffix(line, "// unused") {
+			want = append(want, i)
+		}
+	}
+	var got []int
+	for _, diagnostic := range params.Diagnostics {
+		if diagnostic.Message == "expression result is not used" {
+			got = append(got, diagnostic.Range.Start.Line)
+		}
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("incorrect lines with unused results (-want +got):\n%s", diff)
+	}
+}
@@ -2,9 +2,13 @@
 package lsp
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/marcuscaisey/lox/loxls/jsonrpc"
 	"github.com/marcuscaisey/lox/loxls/lsp/protocol"
@@ -12,27 +16,103 @@ import (
 
 const version = "0.3.0"
 
+const (
+	defaultDiagnosticsDelay = 200 * time.Millisecond
+	// defaultMinFilesForProgress is the default minimum number of files that a workspace scan must cover for progress to
+	// be reported. Scans of fewer files are fast enough that reporting progress would just cause the progress indicator
+	// to flicker.
+	defaultMinFilesForProgress = 50
+)
+
 // Handler handles JSON-RPC requests and notifications.
 type Handler struct {
-	client *client
-	log    *logger
+	client   *client
+	log      *logger
+	logLevel slog.Level
+	settings *settingsStore
+	progress *progressTracker
+	// osExit terminates the process with the given status code. It's a variable so that it can be replaced in tests.
+	osExit func(code int)
+	// diagnosticsDelay is how long to wait after a document is changed before analysing it and publishing diagnostics.
+	diagnosticsDelay time.Duration
+	// minFilesForProgress is the minimum number of files that a workspace scan must cover for progress to be reported.
+	minFilesForProgress int
+	// requestsCtx is cancelled when the server is shut down so that requests which are in-flight stop early.
+	requestsCtx    context.Context
+	cancelRequests context.CancelFunc
 
-	initialized  bool
-	shuttingDown bool
-	docsByURI    map[string]*document
+	// mu is held whilst handling each request and notification so that they don't run concurrently with the analysis of
+	// pending changes. Messages which are preempted don't acquire it, so that they can take effect on a request which
+	// is currently being handled.
+	mu             sync.Mutex
+	initialized    bool
+	shuttingDown   bool
+	docsByURI      map[string]*document
+	workspaceRoots []string
+	// pendingChangesByURI contains the changes to documents which haven't been analysed yet.
+	pendingChangesByURI map[string]*pendingChange
+	// workspaceIndex caches the Lox files in the workspace. It's nil unless the client has been asked to notify the
+	// server of changes to them.
+	workspaceIndex *workspaceIndex
 
 	clientSupportsHierarchicalDocumentSymbols bool
+	clientSupportsPrepareRename               bool
+	clientSupportsWatchedFilesRegistration    bool
+}
+
+// HandlerOption can be passed to [NewHandler] to configure the handler.
+type HandlerOption func(*Handler)
+
+// WithLogLevel sets the minimum level of the messages which are logged to the client. The default is [slog.LevelInfo].
+func WithLogLevel(level slog.Level) HandlerOption {
+	return func(h *Handler) {
+		h.logLevel = level
+	}
+}
+
+// WithDiagnosticsDelay sets how long to wait after a document is changed before analysing it and publishing
+// diagnostics. Changes made within the delay of each other are analysed together. Requests which need the analysis of
+// a document don't wait for the delay. The default is 200ms.
+func WithDiagnosticsDelay(delay time.Duration) HandlerOption {
+	return func(h *Handler) {
+		h.diagnosticsDelay = delay
+	}
+}
+
+// WithMinFilesForProgress sets the minimum number of files that a workspace scan must cover for its progress to be
+// reported to the client. The default is 50.
+func WithMinFilesForProgress(n int) HandlerOption {
+	return func(h *Handler) {
+		h.minFilesForProgress = n
+	}
 }
 
 // NewHandler returns a new Handler.
-func NewHandler() *Handler {
-	return &Handler{
-		docsByURI: map[string]*document{},
+func NewHandler(opts ...HandlerOption) *Handler {
+	h := &Handler{
+		logLevel:            slog.LevelInfo,
+		diagnosticsDelay:    defaultDiagnosticsDelay,
+		minFilesForProgress: defaultMinFilesForProgress,
+		settings:            newSettingsStore(),
+		osExit:              os.Exit,
+		docsByURI:           map[string]*document{},
+		pendingChangesByURI: map[string]*pendingChange{},
+	}
+	h.requestsCtx, h.cancelRequests = context.WithCancel(context.Background())
+	for _, opt := range opts {
+		opt(h)
 	}
+	return h
 }
 
-// HandleRequest responds to a JSON-RPC request.
-func (h *Handler) HandleRequest(method string, jsonParams *json.RawMessage) (any, error) {
+// HandleRequest responds to a JSON-RPC request. ctx is also cancelled when the server is shut down.
+func (h *Handler) HandleRequest(ctx context.Context, method string, jsonParams *json.RawMessage) (any, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(h.requestsCtx, cancel)
+	defer stop()
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	if !h.initialized && method != "initialize" {
 		return nil, jsonrpc.NewError(jsonrpc.ErrorCode(protocol.ErrorCodesServerNotInitialized), "Server not initialized", nil)
 	}
@@ -46,10 +126,38 @@ func (h *Handler) HandleRequest(method string, jsonParams *json.RawMessage) (any
 		return h.shutdown()
 	case "textDocument/definition":
 		return handleRequest(h.textDocumentDefinition, jsonParams)
+	case "textDocument/completion":
+		return handleRequest(h.textDocumentCompletion, jsonParams)
+	case "textDocument/signatureHelp":
+		return handleRequest(h.textDocumentSignatureHelp, jsonParams)
+	case "textDocument/hover":
+		return handleRequest(h.textDocumentHover, jsonParams)
+	case "textDocument/references":
+		return handleRequest(h.textDocumentReferences, jsonParams)
+	case "textDocument/prepareRename":
+		return handleRequest(h.textDocumentPrepareRename, jsonParams)
+	case "textDocument/rename":
+		return handleRequest(h.textDocumentRename, jsonParams)
 	case "textDocument/documentSymbol":
 		return handleRequest(h.textDocumentDocumentSymbol, jsonParams)
+	case "textDocument/foldingRange":
+		return handleRequest(h.textDocumentFoldingRange, jsonParams)
+	case "textDocument/semanticTokens/full":
+		return handleRequest(h.textDocumentSemanticTokensFull, jsonParams)
+	case "textDocument/semanticTokens/range":
+		return handleRequest(h.textDocumentSemanticTokensRange, jsonParams)
 	case "textDocument/formatting":
 		return handleRequest(h.textDocumentFormatting, jsonParams)
+	case "textDocument/rangeFormatting":
+		return handleRequest(h.textDocumentRangeFormatting, jsonParams)
+	case "textDocument/codeAction":
+		return handleRequest(h.textDocumentCodeAction, jsonParams)
+	case "textDocument/codeLens":
+		return handleRequest(h.textDocumentCodeLens, jsonParams)
+	case "codeLens/resolve":
+		return handleRequest(h.codeLensResolve, jsonParams)
+	case "workspace/symbol":
+		return handleRequestContext(ctx, h.workspaceSymbol, jsonParams)
 	default:
 		return nil, jsonrpc.NewMethodNotFoundError(method)
 	}
@@ -65,8 +173,17 @@ func handleRequest[T any, R any](handler requestHandler[T, R], jsonParams *json.
 	return handler(params)
 }
 
+type requestHandlerContext[T any, R any] func(context.Context, T) (R, error)
+
+// handleRequestContext is like handleRequest but for handlers which should stop early when ctx is cancelled.
+func handleRequestContext[T any, R any](ctx context.Context, handler requestHandlerContext[T, R], jsonParams *json.RawMessage) (any, error) {
+	return handleRequest(func(params T) (R, error) { return handler(ctx, params) }, jsonParams)
+}
+
 // HandleNotification responds to a JSON-RPC notification.
 func (h *Handler) HandleNotification(method string, jsonParams *json.RawMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	if err := h.handleNotification(method, jsonParams); err != nil {
 		h.log.Error(err.Error())
 	}
@@ -81,19 +198,41 @@ func (h *Handler) handleNotification(method string, jsonParams *json.RawMessage)
 	}
 	switch method {
 	case "initialized":
-		// No further initialisation needed
+		return h.watchWorkspaceFiles()
 	case "textDocument/didOpen":
 		return handleNotification(method, h.textDocumentDidOpen, jsonParams)
 	case "textDocument/didChange":
 		return handleNotification(method, h.textDocumentDidChange, jsonParams)
 	case "textDocument/didClose":
 		return handleNotification(method, h.textDocumentDidClose, jsonParams)
+	case "workspace/didChangeConfiguration":
+		return handleNotification(method, h.workspaceDidChangeConfiguration, jsonParams)
+	case "workspace/didChangeWatchedFiles":
+		return handleNotification(method, h.workspaceDidChangeWatchedFiles, jsonParams)
 	case "exit":
 		return h.exit()
 	default:
 		return fmt.Errorf("%s method not found", method)
 	}
-	return nil
+}
+
+// Preempt handles window/workDoneProgress/cancel notifications as soon as they're received so that they can cancel the
+// request which is currently being handled. All other notifications are left to be handled in order. shutdown requests
+// cancel the requests which are in-flight when they're received but are still handled in order. $/cancelRequest
+// notifications are handled by the JSON-RPC server.
+func (h *Handler) Preempt(method string, jsonParams *json.RawMessage) bool {
+	switch method {
+	case "shutdown":
+		h.cancelRequests()
+		return false
+	case "window/workDoneProgress/cancel":
+		if err := handleNotification(method, h.windowWorkDoneProgressCancel, jsonParams); err != nil {
+			h.log.Error(err.Error())
+		}
+		return true
+	default:
+		return false
+	}
 }
 
 type notificationHandler[T any] func(T) error
@@ -109,42 +248,65 @@ func handleNotification[T any](method string, handler notificationHandler[T], js
 // SetClient sets the client that the handler can use to send requests and notifications to the server's client.
 func (h *Handler) SetClient(client *jsonrpc.Client) {
 	h.client = newClient(client)
-	h.log = newLogger(h.client)
+	h.log = newLogger(h.client, h.logLevel)
+	h.progress = newProgressTracker(h.client)
 	h.log.Infof("Lox language server %s starting", version)
 }
 
+// logger logs messages to the client with window/logMessage notifications. Messages are also logged with [slog] so
+// that they're written to the server's log.
 type logger struct {
 	client *client
+	level  slog.Level
 }
 
-func newLogger(client *client) *logger {
+func newLogger(client *client, level slog.Level) *logger {
 	return &logger{
 		client: client,
+		level:  level,
 	}
 }
 
 func (l *logger) Info(a ...any) {
-	l.log(protocol.MessageTypeInfo, fmt.Sprint(a...))
+	l.log(slog.LevelInfo, fmt.Sprint(a...))
 }
 
 func (l *logger) Infof(format string, a ...any) {
-	l.log(protocol.MessageTypeInfo, fmt.Sprintf(format, a...))
+	l.log(slog.LevelInfo, fmt.Sprintf(format, a...))
 }
 
 func (l *logger) Error(a ...any) {
-	l.log(protocol.MessageTypeError, fmt.Sprint(a...))
+	l.log(slog.LevelError, fmt.Sprint(a...))
 }
 
 func (l *logger) Errorf(format string, a ...any) {
-	l.log(protocol.MessageTypeError, fmt.Sprintf(format, a...))
+	l.log(slog.LevelError, fmt.Sprintf(format, a...))
 }
 
-func (l *logger) log(typ protocol.MessageType, msg string) {
+func (l *logger) log(level slog.Level, msg string) {
+	slog.Log(context.Background(), level, msg)
+	if level < l.level {
+		return
+	}
 	err := l.client.WindowLogMessage(&protocol.LogMessageParams{
-		Type:    typ,
+		Type:    messageType(level),
 		Message: msg,
 	})
 	if err != nil {
 		slog.Warn("Failed to log", "error", err)
 	}
 }
+
+// messageType returns the [protocol.MessageType] which corresponds to a [slog.Level].
+func messageType(level slog.Level) protocol.MessageType {
+	switch {
+	case level >= slog.LevelError:
+		return protocol.MessageTypeError
+	case level >= slog.LevelWarn:
+		return protocol.MessageTypeWarning
+	case level >= slog.LevelInfo:
+		return protocol.MessageTypeInfo
+	default:
+		return protocol.MessageTypeLog
+	}
+}
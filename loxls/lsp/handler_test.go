@@ -0,0 +1,283 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/marcuscaisey/lox/loxls/jsonrpc"
+)
+
+type testServer struct {
+	in        io.WriteCloser
+	msgs      chan []byte
+	exitCodes chan int
+}
+
+type testResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    jsonrpc.ErrorCode `json:"code"`
+		Message string            `json:"message"`
+	} `json:"error"`
+}
+
+func TestLogLevel(t *testing.T) {
+	const uri = "file:///a.lox"
+	const infoMsg = "textDocument/formatting: " + uri + " has errors. Skipping formatting."
+	const errorMsg = "foo/bar method not found"
+	tests := []struct {
+		name string
+		opts []HandlerOption
+		want []string
+	}{
+		{
+			name: "Default",
+			want: []string{infoMsg, errorMsg},
+		},
+		{
+			name: "Error",
+			opts: []HandlerOption{WithLogLevel(slog.LevelError)},
+			want: []string{errorMsg},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := startServer(t, test.opts...)
+			s.Initialize(t, nil)
+			s.Notify(t, "textDocument/didOpen", map[string]any{
+				"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": "print 1"},
+			})
+
+			var got []string
+			collectLogMessage := func(data []byte) {
+				var notif struct {
+					Params struct {
+						Message string `json:"message"`
+					} `json:"params"`
+				}
+				if err := json.Unmarshal(data, &notif); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, notif.Params.Message)
+			}
+			// Formatting a document with errors logs infoMsg before responding.
+			s.write(t, newTestMessage("textDocument/formatting", map[string]any{
+				"textDocument": map[string]any{"uri": uri},
+				"options":      map[string]any{"tabSize": 4, "insertSpaces": true},
+			}, map[string]any{"id": 1}))
+			for {
+				method, data := s.next(t)
+				if method == "" {
+					break
+				}
+				if method == "window/logMessage" {
+					collectLogMessage(data)
+				}
+			}
+			// An unknown notification logs errorMsg.
+			s.Notify(t, "foo/bar", map[string]any{})
+			for len(got) == 0 || got[len(got)-1] != errorMsg {
+				if method, data := s.next(t); method == "window/logMessage" {
+					collectLogMessage(data)
+				}
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("incorrect window/logMessage messages (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func startServer(t *testing.T, opts ...HandlerOption) *testServer {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	handler := NewHandler(opts...)
+	exitCodes := make(chan int, 1)
+	handler.osExit = func(code int) { exitCodes <- code }
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := jsonrpc.Serve(inR, outW, handler); err != nil {
+			t.Errorf("jsonrpc.Serve returned error: %s", err)
+		}
+		outW.Close()
+	}()
+	t.Cleanup(func() {
+		inW.Close()
+		<-done
+	})
+
+	// Messages are read continuously so that the server never blocks writing to its output.
+	msgs := make(chan []byte, 100)
+	go func() {
+		defer close(msgs)
+		out := bufio.NewReader(outR)
+		for {
+			msg, err := readMessage(out)
+			if err != nil {
+				return
+			}
+			msgs <- msg
+		}
+	}()
+
+	return &testServer{in: inW, msgs: msgs, exitCodes: exitCodes}
+}
+
+// Initialize performs the initialize handshake with the server using the given initialization options.
+func (s *testServer) Initialize(t *testing.T, initOpts any) {
+	t.Helper()
+	params := map[string]any{"processId": nil, "rootUri": nil, "capabilities": map[string]any{}}
+	if initOpts != nil {
+		params["initializationOptions"] = initOpts
+	}
+	if resp := s.Request(t, 0, "initialize", params); resp.Error != nil {
+		t.Fatalf("initialize returned error: %+v", resp.Error)
+	}
+	s.Notify(t, "initialized", map[string]any{})
+}
+
+// Request sends a request and returns its response, skipping any notifications sent by the server in the meantime.
+func (s *testServer) Request(t *testing.T, id int, method string, params any) *testResponse {
+	t.Helper()
+	s.write(t, newTestMessage(method, params, map[string]any{"id": id}))
+	return s.Response(t, id)
+}
+
+// Response returns the next response sent by the server, skipping any notifications. The response must be to the
+// request with the given ID.
+func (s *testServer) Response(t *testing.T, id int) *testResponse {
+	t.Helper()
+	for {
+		msgMethod, data := s.next(t)
+		if msgMethod != "" {
+			continue
+		}
+		var resp testResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.ID != id {
+			t.Fatalf("received response to request %d, want response to request %d", resp.ID, id)
+		}
+		return &resp
+	}
+}
+
+// WaitForNotification returns the params of the next notification with the given method sent by the server, skipping
+// any other messages.
+func (s *testServer) WaitForNotification(t *testing.T, method string) json.RawMessage {
+	t.Helper()
+	for {
+		msgMethod, data := s.next(t)
+		if msgMethod != method {
+			continue
+		}
+		var notif struct {
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(data, &notif); err != nil {
+			t.Fatal(err)
+		}
+		return notif.Params
+	}
+}
+
+// next returns the next message sent by the server along with its method, which is empty for responses.
+func (s *testServer) next(t *testing.T) (string, []byte) {
+	t.Helper()
+	var data []byte
+	select {
+	case msg, ok := <-s.msgs:
+		if !ok {
+			t.Fatal("server output closed before expected message received")
+		}
+		data = msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for message from server")
+	}
+	var msg struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg.Method, data
+}
+
+// Notify sends a notification.
+func (s *testServer) Notify(t *testing.T, method string, params any) {
+	t.Helper()
+	s.write(t, newTestMessage(method, params, map[string]any{}))
+}
+
+// ExitCode returns the code that the server exited with.
+func (s *testServer) ExitCode(t *testing.T) int {
+	t.Helper()
+	select {
+	case code := <-s.exitCodes:
+		return code
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for server to exit")
+		return 0
+	}
+}
+
+// newTestMessage adds the method and params to the given message. The params are omitted if they're nil.
+func newTestMessage(method string, params any, msg map[string]any) map[string]any {
+	msg["jsonrpc"] = "2.0"
+	msg["method"] = method
+	if params != nil {
+		msg["params"] = params
+	}
+	return msg
+}
+
+func (s *testServer) write(t *testing.T, msg any) {
+	t.Helper()
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fmt.Fprintf(s.in, "Content-Length: %d\r\n\r\n%s", len(data), data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	var contentLength int
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSuffix(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, "Content-Length: "); ok {
+			contentLength, err = strconv.Atoi(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	data := make([]byte, contentLength)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
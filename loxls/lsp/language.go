@@ -1,11 +1,19 @@
 package lsp
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
+	"github.com/marcuscaisey/lox/lox"
 	"github.com/marcuscaisey/lox/lox/ast"
 	"github.com/marcuscaisey/lox/lox/format"
+	"github.com/marcuscaisey/lox/lox/parser"
+	"github.com/marcuscaisey/lox/lox/token"
+	"github.com/marcuscaisey/lox/loxls/jsonrpc"
 	"github.com/marcuscaisey/lox/loxls/lsp/protocol"
 )
 
@@ -16,19 +24,323 @@ func (h *Handler) textDocumentDefinition(params *protocol.DefinitionParams) (*pr
 		return nil, err
 	}
 
-	var ident ast.Ident
-	ast.Walk(doc.Program, func(n ast.Node) bool {
-		switch n := n.(type) {
-		case ast.Ident:
-			if posInRange(params.Position, n) {
-				ident = n
+	pos, err := newTokenPosition(params.Position, doc.File)
+	if err != nil {
+		return nil, jsonrpc.NewError(jsonrpc.InvalidParams, "Invalid position", map[string]any{"error": err.Error()})
+	}
+
+	ident, ok := doc.Nodes.InnermostNode(pos).(ast.Ident)
+	if !ok {
+		return nil, nil
+	}
+
+	decl, ok := doc.IdentDecls[ident]
+	if !ok {
+		return nil, nil
+	}
+
+	return &protocol.LocationOrLocationSlice{
+		Value: &protocol.Location{
+			Uri:   doc.URI,
+			Range: newRange(decl.Start(), decl.End()),
+		},
+	}, nil
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_completion
+func (h *Handler) textDocumentCompletion(params *protocol.CompletionParams) (*protocol.CompletionItemSliceOrCompletionList, error) {
+	doc, err := h.document(params.TextDocument.Uri)
+	if err != nil {
+		return nil, err
+	}
+
+	pos, err := newTokenPosition(params.Position, doc.File)
+	if err != nil {
+		return nil, jsonrpc.NewError(jsonrpc.InvalidParams, "Invalid position", map[string]any{"error": err.Error()})
+	}
+
+	// Properties can't be completed since we don't know the type of the object.
+	line := doc.File.Line(pos.Line)[:pos.Column]
+	wordStart := len(line)
+	for wordStart > 0 && isIdentByte(line[wordStart-1]) {
+		wordStart--
+	}
+	if wordStart > 0 && line[wordStart-1] == '.' {
+		return nil, nil
+	}
+
+	itemsByLabel := map[string]*protocol.CompletionItem{}
+	for _, keyword := range token.Keywords() {
+		itemsByLabel[keyword] = &protocol.CompletionItem{Label: keyword, Kind: protocol.CompletionItemKindKeyword}
+	}
+	for _, builtin := range lox.AllBuiltins {
+		item := &protocol.CompletionItem{
+			Label:  builtin,
+			Kind:   protocol.CompletionItemKindFunction,
+			Detail: "Built-in function",
+		}
+		if slices.Contains(lox.BuiltinModules, builtin) {
+			item.Kind = protocol.CompletionItemKindModule
+			item.Detail = "Built-in module"
+		}
+		itemsByLabel[builtin] = item
+	}
+	// Declarations in inner scopes are visited last so that they replace those that they shadow.
+	for _, decl := range visibleDecls(doc.Program, pos) {
+		item := &protocol.CompletionItem{Label: decl.Name.Token.Lexeme}
+		switch {
+		case decl.Function != nil:
+			item.Kind = protocol.CompletionItemKindFunction
+			item.Detail = namedSignature("fun "+item.Label, *decl.Function)
+		case decl.Class != nil:
+			item.Kind = protocol.CompletionItemKindClass
+			item.Detail = "class " + item.Label
+		case decl.Import != nil:
+			item.Kind = protocol.CompletionItemKindModule
+			item.Detail = format.Node(*decl.Import)
+		case decl.IsParam:
+			item.Kind = protocol.CompletionItemKindVariable
+			item.Detail = "(parameter) " + item.Label
+		default:
+			item.Kind = protocol.CompletionItemKindVariable
+			item.Detail = "var " + item.Label
+		}
+		itemsByLabel[item.Label] = item
+	}
+
+	items := slices.SortedFunc(maps.Values(itemsByLabel), func(x, y *protocol.CompletionItem) int {
+		return strings.Compare(x.Label, y.Label)
+	})
+	return &protocol.CompletionItemSliceOrCompletionList{Value: protocol.CompletionItemSlice(items)}, nil
+}
+
+// isIdentByte reports whether b can appear in an identifier.
+func isIdentByte(b byte) bool {
+	return 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' || b == '_'
+}
+
+// visibleDecl is a declaration which is visible at a position in a program.
+type visibleDecl struct {
+	Name     ast.Ident
+	Function *ast.Function   // Set if the declaration is of a function
+	Class    *ast.ClassDecl  // Set if the declaration is of a class
+	Import   *ast.ImportStmt // Set if the declaration is of an imported module
+	IsParam  bool
+}
+
+// visibleDecls returns the declarations which are visible at a position in a program, starting with the outermost
+// scope. Global declarations are visible before they're declared inside of a function, since the function could be
+// called after they've been declared.
+func visibleDecls(program ast.Program, pos token.Position) []visibleDecl {
+	contains := func(n ast.Node) bool {
+		return n.Start().Compare(pos) <= 0 && pos.Compare(n.End()) <= 0
+	}
+
+	inFun := false
+	ast.Walk(program, func(n ast.Node) bool {
+		switch n.(type) {
+		case ast.Program:
+			return true
+		case ast.Function:
+			inFun = inFun || contains(n)
+		}
+		return !inFun && contains(n)
+	})
+
+	var decls []visibleDecl
+	declare := func(stmt ast.Stmt, declaredLater bool) {
+		if inlineCommentStmt, ok := stmt.(ast.InlineCommentStmt); ok {
+			stmt = inlineCommentStmt.Stmt
+		}
+		var decl visibleDecl
+		switch stmt := stmt.(type) {
+		case ast.VarDecl:
+			// A variable isn't visible in its own initialiser.
+			if !declaredLater && stmt.End().Compare(pos) > 0 {
+				return
 			}
-			return false
+			decl = visibleDecl{Name: stmt.Name}
+		case ast.FunDecl:
+			decl = visibleDecl{Name: stmt.Name, Function: &stmt.Function}
+		case ast.ClassDecl:
+			decl = visibleDecl{Name: stmt.Name, Class: &stmt}
+		case ast.ImportStmt:
+			decl = visibleDecl{Name: stmt.Name, Import: &stmt}
 		default:
+			return
+		}
+		if decl.Name.Token.Lexeme == token.PlaceholderIdent || (!declaredLater && decl.Name.Start().Compare(pos) >= 0) {
+			return
+		}
+		decls = append(decls, decl)
+	}
+
+	ast.Walk(program, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case ast.Program:
+			for _, stmt := range n.Stmts {
+				declare(stmt, inFun)
+			}
 			return true
+		case ast.BlockStmt:
+			if !contains(n) {
+				return false
+			}
+			for _, stmt := range n.Stmts {
+				declare(stmt, false)
+			}
+		case ast.Function:
+			if !contains(n) {
+				return false
+			}
+			for _, param := range n.Params {
+				if param.Token.Lexeme != token.PlaceholderIdent {
+					decls = append(decls, visibleDecl{Name: param, IsParam: true})
+				}
+			}
+			// The statements of the body are walked directly rather than as a block.
+			for _, stmt := range n.Body.Stmts {
+				declare(stmt, false)
+			}
+		case ast.ForStmt:
+			if !contains(n) {
+				return false
+			}
+			if n.Initialise != nil {
+				declare(n.Initialise, false)
+			}
+		case ast.ForInStmt:
+			// The loop variable is only visible in the body.
+			if contains(n.Body) && n.Name.Token.Lexeme != token.PlaceholderIdent {
+				decls = append(decls, visibleDecl{Name: n.Name})
+			}
+		case ast.TryStmt:
+			// The error variable is only visible in the catch clause.
+			if n.HasCatch() && contains(n.CatchBody) && n.CatchName.Token.Lexeme != token.PlaceholderIdent {
+				decls = append(decls, visibleDecl{Name: n.CatchName})
+			}
 		}
+		return contains(n)
 	})
-	if ident == (ast.Ident{}) {
+	return decls
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_signatureHelp
+func (h *Handler) textDocumentSignatureHelp(params *protocol.SignatureHelpParams) (*protocol.SignatureHelp, error) {
+	doc, err := h.document(params.TextDocument.Uri)
+	if err != nil {
+		return nil, err
+	}
+
+	pos, err := newTokenPosition(params.Position, doc.File)
+	if err != nil {
+		return nil, jsonrpc.NewError(jsonrpc.InvalidParams, "Invalid position", map[string]any{"error": err.Error()})
+	}
+
+	callee, argIdx, ok := enclosingCall(doc.File, pos)
+	if !ok {
+		return nil, nil
+	}
+
+	// The document is likely to contain syntax errors whilst a call is being typed, so the callee is found by name
+	// amongst the declarations which are visible rather than by resolving it.
+	var decl visibleDecl
+	for _, d := range slices.Backward(visibleDecls(doc.Program, pos)) {
+		if d.Name.Token.Lexeme == callee {
+			decl = d
+			break
+		}
+	}
+
+	var fun ast.Function
+	switch {
+	case decl.Function != nil:
+		fun = *decl.Function
+	case decl.Class != nil:
+		for _, method := range decl.Class.Methods() {
+			if method.IsConstructor() {
+				fun = method.Function
+			}
+		}
+	default:
+		return nil, nil
+	}
+
+	signature := &protocol.SignatureInformation{
+		Label:      namedSignature(callee, fun),
+		Parameters: make([]*protocol.ParameterInformation, len(fun.Params)),
+	}
+	for i, param := range fun.Params {
+		signature.Parameters[i] = &protocol.ParameterInformation{Label: param.Token.Lexeme}
+	}
+	if comment := docComment(doc.Program, decl.Name); comment != "" {
+		signature.Documentation = &protocol.MarkupContent{Kind: protocol.MarkupKindMarkdown, Value: comment}
+	}
+	return &protocol.SignatureHelp{
+		Signatures:      []*protocol.SignatureInformation{signature},
+		ActiveParameter: uint32(argIdx),
+	}, nil
+}
+
+// enclosingCall returns the name of the function being called by the innermost call whose arguments contain a position
+// and the index of the argument that the position is in. ok is false if the position isn't inside the arguments of a
+// call to a named function. Calls which haven't been closed yet are included, since the file is lexed rather than
+// parsed.
+func enclosingCall(file *token.File, pos token.Position) (callee string, argIdx int, ok bool) {
+	lexer, err := parser.NewLexer(bytes.NewReader(file.Contents()))
+	if err != nil {
+		return "", 0, false
+	}
+	type call struct {
+		callee string // Empty if the parenthesis doesn't open the arguments of a call to a named function
+		argIdx int
+	}
+	var calls []call
+	var prev, prevPrev token.Token
+	for tok := range lexer.Tokens() {
+		if tok.Type == token.EOF || tok.EndPos.Compare(pos) > 0 {
+			break
+		}
+		switch tok.Type {
+		case token.LeftParen:
+			c := call{}
+			// Method calls aren't included since we don't know the type of the object.
+			if prev.Type == token.Ident && prevPrev.Type != token.Dot && prevPrev.Type != token.Fun {
+				c.callee = prev.Lexeme
+			}
+			calls = append(calls, c)
+		case token.RightParen:
+			if len(calls) > 0 {
+				calls = calls[:len(calls)-1]
+			}
+		case token.Comma:
+			if len(calls) > 0 {
+				calls[len(calls)-1].argIdx++
+			}
+		}
+		prev, prevPrev = tok, prev
+	}
+	if len(calls) == 0 || calls[len(calls)-1].callee == "" {
+		return "", 0, false
+	}
+	c := calls[len(calls)-1]
+	return c.callee, c.argIdx, true
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_hover
+func (h *Handler) textDocumentHover(params *protocol.HoverParams) (*protocol.Hover, error) {
+	doc, err := h.document(params.TextDocument.Uri)
+	if err != nil {
+		return nil, err
+	}
+
+	pos, err := newTokenPosition(params.Position, doc.File)
+	if err != nil {
+		return nil, jsonrpc.NewError(jsonrpc.InvalidParams, "Invalid position", map[string]any{"error": err.Error()})
+	}
+
+	ident, ok := doc.Nodes.InnermostNode(pos).(ast.Ident)
+	if !ok {
 		return nil, nil
 	}
 
@@ -37,14 +349,495 @@ func (h *Handler) textDocumentDefinition(params *protocol.DefinitionParams) (*pr
 		return nil, nil
 	}
 
-	return &protocol.LocationOrLocationSlice{
-		Value: &protocol.Location{
-			Uri:   doc.URI,
-			Range: newRange(decl.Start(), decl.End()),
+	var b strings.Builder
+	if decl.Start().File == nil {
+		// Built-ins aren't declared in the source code.
+		if slices.Contains(lox.BuiltinModules, decl.Token.Lexeme) {
+			fmt.Fprintf(&b, "```lox\n%s\n```\n\nBuilt-in module", decl.Token.Lexeme)
+		} else {
+			fmt.Fprintf(&b, "```lox\nfun %s\n```\n\nBuilt-in function", decl.Token.Lexeme)
+		}
+	} else {
+		detail, owner := declarationDetail(doc.Program, decl)
+		fmt.Fprintf(&b, "```lox\n%s\n```", detail)
+		if comment := docComment(doc.Program, decl); comment != "" {
+			fmt.Fprintf(&b, "\n\n%s", comment)
+		}
+		if owner != "" {
+			fmt.Fprintf(&b, "\n\nParameter of `%s`", owner)
+		}
+		line := strings.TrimSpace(string(doc.File.Line(decl.Start().Line)))
+		fmt.Fprintf(&b, "\n\n---\n\nDeclared on line %d:\n```lox\n%s\n```", decl.Start().Line, line)
+	}
+
+	return &protocol.Hover{
+		Contents: &protocol.MarkupContent{
+			Kind:  protocol.MarkupKindMarkdown,
+			Value: b.String(),
 		},
+		Range: newRange(ident.Start(), ident.End()),
+	}, nil
+}
+
+// declarationDetail returns a description of the declaration of an identifier, such as "var x" or "fun f(a, b)". If
+// the identifier is a parameter, then the signature of its function is also returned.
+func declarationDetail(program ast.Program, decl ast.Ident) (detail string, owner string) {
+	var walkFun func(fun ast.Function, signature string)
+	var visit func(n ast.Node) bool
+	walkFun = func(fun ast.Function, signature string) {
+		if slices.Contains(fun.Params, decl) {
+			detail = "(parameter) " + decl.Token.Lexeme
+			owner = signature
+			return
+		}
+		ast.Walk(fun.Body, visit)
+	}
+	visit = func(n ast.Node) bool {
+		if detail != "" {
+			return false
+		}
+		switch n := n.(type) {
+		case ast.ImportStmt:
+			if n.Name == decl {
+				detail = format.Node(n)
+				return false
+			}
+		case ast.VarDecl:
+			if n.Name == decl {
+				detail = "var " + decl.Token.Lexeme
+				return false
+			}
+		case ast.ForInStmt:
+			if n.Name == decl {
+				detail = "var " + decl.Token.Lexeme
+				return false
+			}
+		case ast.TryStmt:
+			if n.HasCatch() && n.CatchName == decl {
+				detail = "var " + decl.Token.Lexeme
+				return false
+			}
+		case ast.FunDecl:
+			signature := namedSignature("fun "+n.Name.Token.Lexeme, n.Function)
+			if n.Name == decl {
+				detail = signature
+			} else {
+				walkFun(n.Function, signature)
+			}
+			return false
+		case ast.ClassDecl:
+			if n.Name == decl {
+				detail = "class " + decl.Token.Lexeme
+				return false
+			}
+			for _, method := range n.Methods() {
+				walkFun(method.Function, namedSignature(n.Name.Token.Lexeme+"."+method.Name.Token.Lexeme, method.Function))
+			}
+			return false
+		case ast.FunExpr:
+			walkFun(n.Function, format.Signature(n.Function))
+			return false
+		}
+		return true
+	}
+	ast.Walk(program, visit)
+	return detail, owner
+}
+
+// namedSignature returns the signature of a function with the given name, such as "fun f(a, b)".
+func namedSignature(name string, fun ast.Function) string {
+	return name + strings.TrimPrefix(format.Signature(fun), "fun")
+}
+
+// docComment returns the text of the comments on the lines directly above the declaration of an identifier, with the
+// leading slashes removed. An empty string is returned if there aren't any.
+func docComment(program ast.Program, decl ast.Ident) string {
+	var comment string
+	found := false
+	visitStmts := func(stmts []ast.Stmt) {
+		for i, stmt := range stmts {
+			if inlineCommentStmt, ok := stmt.(ast.InlineCommentStmt); ok {
+				stmt = inlineCommentStmt.Stmt
+			}
+			var name ast.Ident
+			switch stmt := stmt.(type) {
+			case ast.ImportStmt:
+				name = stmt.Name
+			case ast.VarDecl:
+				name = stmt.Name
+			case ast.FunDecl:
+				name = stmt.Name
+			case ast.ClassDecl:
+				name = stmt.Name
+			case ast.MethodDecl:
+				name = stmt.Name
+			default:
+				continue
+			}
+			if name != decl {
+				continue
+			}
+			found = true
+			var lines []string
+			line := stmt.Start().Line
+			for j := i - 1; j >= 0; j-- {
+				commentStmt, ok := stmts[j].(ast.CommentStmt)
+				if !ok || commentStmt.End().Line != line-1 {
+					break
+				}
+				text := strings.TrimPrefix(commentStmt.Comment.Lexeme, "//")
+				lines = append(lines, strings.TrimPrefix(text, " "))
+				line = commentStmt.Start().Line
+			}
+			slices.Reverse(lines)
+			comment = strings.Join(lines, "\n")
+			return
+		}
+	}
+	ast.Walk(program, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		switch n := n.(type) {
+		case ast.Program:
+			visitStmts(n.Stmts)
+		case ast.BlockStmt:
+			visitStmts(n.Stmts)
+		case ast.ClassDecl:
+			visitStmts(n.Body)
+		}
+		return true
+	})
+	return comment
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_references
+func (h *Handler) textDocumentReferences(params *protocol.ReferenceParams) ([]*protocol.Location, error) {
+	doc, err := h.document(params.TextDocument.Uri)
+	if err != nil {
+		return nil, err
+	}
+
+	pos, err := newTokenPosition(params.Position, doc.File)
+	if err != nil {
+		return nil, jsonrpc.NewError(jsonrpc.InvalidParams, "Invalid position", map[string]any{"error": err.Error()})
+	}
+
+	ident, ok := doc.Nodes.InnermostNode(pos).(ast.Ident)
+	if !ok {
+		return nil, nil
+	}
+
+	decl, ok := doc.IdentDecls[ident]
+	if !ok {
+		return nil, nil
+	}
+
+	refs := references(doc, decl)
+	if params.Context != nil && params.Context.IncludeDeclaration {
+		refs = append([]ast.Ident{decl}, refs...)
+	}
+	locations := make([]*protocol.Location, len(refs))
+	for i, ref := range refs {
+		locations[i] = &protocol.Location{Uri: doc.URI, Range: newRange(ref.Start(), ref.End())}
+	}
+	return locations, nil
+}
+
+// references returns the identifiers in a document which refer to a declaration, sorted by their position. The
+// declaration itself isn't included.
+func references(doc *document, decl ast.Ident) []ast.Ident {
+	var refs []ast.Ident
+	for ident, identDecl := range doc.IdentDecls {
+		if identDecl == decl && ident != decl && ident.Token.Lexeme != token.PlaceholderIdent {
+			refs = append(refs, ident)
+		}
+	}
+	slices.SortFunc(refs, func(a, b ast.Ident) int {
+		return a.Start().Compare(b.Start())
+	})
+	return refs
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_prepareRename
+func (h *Handler) textDocumentPrepareRename(params *protocol.PrepareRenameParams) (*protocol.PrepareRenamePlaceholder, error) {
+	doc, err := h.document(params.TextDocument.Uri)
+	if err != nil {
+		return nil, err
+	}
+
+	ident, _, ok, err := renameTarget(doc, params.Position)
+	if !ok || err != nil {
+		return nil, err
+	}
+
+	return &protocol.PrepareRenamePlaceholder{
+		Range:       newRange(ident.Start(), ident.End()),
+		Placeholder: ident.Token.Lexeme,
 	}, nil
 }
 
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_rename
+func (h *Handler) textDocumentRename(params *protocol.RenameParams) (*protocol.WorkspaceEdit, error) {
+	doc, err := h.document(params.TextDocument.Uri)
+	if err != nil {
+		return nil, err
+	}
+
+	_, decl, ok, err := renameTarget(doc, params.Position)
+	if !ok || err != nil {
+		return nil, err
+	}
+
+	if !isValidIdent(params.NewName) {
+		return nil, jsonrpc.NewError(jsonrpc.ErrorCode(protocol.LSPErrorCodesRequestFailed), fmt.Sprintf("%q is not a valid identifier", params.NewName), nil)
+	}
+
+	idents := append([]ast.Ident{decl}, references(doc, decl)...)
+	edits := make([]*protocol.TextEdit, len(idents))
+	for i, ident := range idents {
+		edits[i] = &protocol.TextEdit{Range: newRange(ident.Start(), ident.End()), NewText: params.NewName}
+	}
+	return newWorkspaceEdit(doc.URI, edits...), nil
+}
+
+// renameTarget returns the identifier at a position in a document and its declaration, if it can be renamed. ok is
+// false if there's no identifier at the position. An error is returned if there is one but it can't be renamed.
+func renameTarget(doc *document, position *protocol.Position) (ident ast.Ident, decl ast.Ident, ok bool, err error) {
+	pos, err := newTokenPosition(position, doc.File)
+	if err != nil {
+		return ast.Ident{}, ast.Ident{}, false, jsonrpc.NewError(jsonrpc.InvalidParams, "Invalid position", map[string]any{"error": err.Error()})
+	}
+
+	node, parent := doc.Nodes.InnermostNodeAndParent(pos)
+	ident, ok = node.(ast.Ident)
+	if !ok {
+		return ast.Ident{}, ast.Ident{}, false, nil
+	}
+
+	name := ident.Token.Lexeme
+	if name == token.PlaceholderIdent {
+		return ast.Ident{}, ast.Ident{}, false, jsonrpc.NewError(jsonrpc.ErrorCode(protocol.LSPErrorCodesRequestFailed), "The blank identifier cannot be renamed", nil)
+	}
+	decl, ok = doc.IdentDecls[ident]
+	if !ok {
+		switch parent.(type) {
+		case ast.IdentExpr, ast.AssignmentExpr:
+		default:
+			// Property and method names aren't resolved to a declaration.
+			return ast.Ident{}, ast.Ident{}, false, nil
+		}
+		return ast.Ident{}, ast.Ident{}, false, jsonrpc.NewError(jsonrpc.ErrorCode(protocol.LSPErrorCodesRequestFailed), fmt.Sprintf("%s has not been declared", name), nil)
+	}
+	if decl.Start().File == nil {
+		// Built-ins aren't declared in the source code.
+		return ast.Ident{}, ast.Ident{}, false, jsonrpc.NewError(jsonrpc.ErrorCode(protocol.LSPErrorCodesRequestFailed), fmt.Sprintf("%s is a built-in and cannot be renamed", name), nil)
+	}
+	_, declParent := doc.Nodes.InnermostNodeAndParent(decl.Start())
+	if _, ok := declParent.(ast.ImportStmt); ok {
+		// The name of an imported module is the file name of its path.
+		return ast.Ident{}, ast.Ident{}, false, jsonrpc.NewError(jsonrpc.ErrorCode(protocol.LSPErrorCodesRequestFailed), fmt.Sprintf("%s is an imported module and cannot be renamed", name), nil)
+	}
+	return ident, decl, true, nil
+}
+
+// isValidIdent reports whether s is an identifier which can be declared.
+func isValidIdent(s string) bool {
+	if s == "" || s == token.PlaceholderIdent || token.IdentType(s) != token.Ident {
+		return false
+	}
+	for i, r := range s {
+		isAlpha := ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || r == '_'
+		isDigit := '0' <= r && r <= '9'
+		if !isAlpha && !(i > 0 && isDigit) {
+			return false
+		}
+	}
+	return true
+}
+
+// semanticTokenTypes is the legend of token types which are returned by textDocument/semanticTokens requests.
+var semanticTokenTypes = []protocol.SemanticTokenTypes{
+	protocol.SemanticTokenTypesKeyword,
+	protocol.SemanticTokenTypesString,
+	protocol.SemanticTokenTypesNumber,
+	protocol.SemanticTokenTypesComment,
+	protocol.SemanticTokenTypesClass,
+	protocol.SemanticTokenTypesFunction,
+	protocol.SemanticTokenTypesMethod,
+	protocol.SemanticTokenTypesProperty,
+	protocol.SemanticTokenTypesParameter,
+	protocol.SemanticTokenTypesVariable,
+	protocol.SemanticTokenTypesNamespace,
+}
+
+// semanticTokenModifiers is the legend of token modifiers which are returned by textDocument/semanticTokens requests.
+var semanticTokenModifiers = []protocol.SemanticTokenModifiers{
+	protocol.SemanticTokenModifiersDeclaration,
+	protocol.SemanticTokenModifiersDefaultLibrary,
+}
+
+type semanticToken struct {
+	Start     token.Position
+	End       token.Position
+	Type      protocol.SemanticTokenTypes
+	Modifiers []protocol.SemanticTokenModifiers
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#semanticTokens_fullRequest
+func (h *Handler) textDocumentSemanticTokensFull(params *protocol.SemanticTokensParams) (*protocol.SemanticTokens, error) {
+	doc, err := h.document(params.TextDocument.Uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return &protocol.SemanticTokens{Data: encodeSemanticTokens(semanticTokens(doc))}, nil
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#semanticTokens_rangeRequest
+func (h *Handler) textDocumentSemanticTokensRange(params *protocol.SemanticTokensRangeParams) (*protocol.SemanticTokens, error) {
+	doc, err := h.document(params.TextDocument.Uri)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := newTokenPosition(params.Range.Start, doc.File)
+	if err != nil {
+		return nil, jsonrpc.NewError(jsonrpc.InvalidParams, "Invalid range", map[string]any{"error": err.Error()})
+	}
+	end, err := newTokenPosition(params.Range.End, doc.File)
+	if err != nil {
+		return nil, jsonrpc.NewError(jsonrpc.InvalidParams, "Invalid range", map[string]any{"error": err.Error()})
+	}
+
+	tokens := slices.DeleteFunc(semanticTokens(doc), func(tok semanticToken) bool {
+		return tok.End.Compare(start) <= 0 || tok.Start.Compare(end) >= 0
+	})
+	return &protocol.SemanticTokens{Data: encodeSemanticTokens(tokens)}, nil
+}
+
+// semanticTokens returns the semantic tokens of a document in the order that they appear. Keywords, literals, and
+// comments are found by lexing the document. Identifiers are classified by the kind of their declaration.
+func semanticTokens(doc *document) []semanticToken {
+	identTokens := identSemanticTokens(doc)
+
+	lexer, err := parser.NewLexer(bytes.NewReader(doc.File.Contents()), parser.WithCommentTokens())
+	if err != nil {
+		return nil
+	}
+	var tokens []semanticToken
+	for tok := range lexer.Tokens() {
+		// The lexer's positions refer to its own copy of the file.
+		start, end := tok.StartPos, tok.EndPos
+		start.File, end.File = doc.File, doc.File
+		switch {
+		case tok.Type == token.Ident:
+			if identToken, ok := identTokens[start]; ok {
+				tokens = append(tokens, identToken)
+			}
+		case tok.Type.IsKeyword():
+			tokens = append(tokens, semanticToken{Start: start, End: end, Type: protocol.SemanticTokenTypesKeyword})
+		case tok.Type == token.String, tok.Type == token.StringHead, tok.Type == token.StringMiddle, tok.Type == token.StringTail:
+			tokens = append(tokens, semanticToken{Start: start, End: end, Type: protocol.SemanticTokenTypesString})
+		case tok.Type == token.Number:
+			tokens = append(tokens, semanticToken{Start: start, End: end, Type: protocol.SemanticTokenTypesNumber})
+		case tok.Type == token.Comment:
+			tokens = append(tokens, semanticToken{Start: start, End: end, Type: protocol.SemanticTokenTypesComment})
+		}
+	}
+	return tokens
+}
+
+// identSemanticTokens returns the semantic tokens of the identifiers in a document, keyed by their start position.
+func identSemanticTokens(doc *document) map[token.Position]semanticToken {
+	declTypes := map[ast.Ident]protocol.SemanticTokenTypes{}
+	var uses []ast.Ident
+	var properties []ast.Ident
+	ast.Walk(doc.Program, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case ast.ImportStmt:
+			declTypes[n.Name] = protocol.SemanticTokenTypesNamespace
+		case ast.VarDecl:
+			declTypes[n.Name] = protocol.SemanticTokenTypesVariable
+		case ast.FunDecl:
+			declTypes[n.Name] = protocol.SemanticTokenTypesFunction
+		case ast.ClassDecl:
+			declTypes[n.Name] = protocol.SemanticTokenTypesClass
+		case ast.MethodDecl:
+			declTypes[n.Name] = protocol.SemanticTokenTypesMethod
+		case ast.Function:
+			for _, param := range n.Params {
+				declTypes[param] = protocol.SemanticTokenTypesParameter
+			}
+		case ast.IdentExpr:
+			uses = append(uses, n.Ident)
+		case ast.AssignmentExpr:
+			uses = append(uses, n.Left)
+		case ast.GetExpr:
+			properties = append(properties, n.Name)
+		case ast.SetExpr:
+			properties = append(properties, n.Name)
+		case ast.SuperExpr:
+			declTypes[n.Method] = protocol.SemanticTokenTypesMethod
+		}
+		return true
+	})
+
+	tokens := make(map[token.Position]semanticToken, len(declTypes)+len(uses)+len(properties))
+	for ident, typ := range declTypes {
+		tokens[ident.Start()] = semanticToken{
+			Start:     ident.Start(),
+			End:       ident.End(),
+			Type:      typ,
+			Modifiers: []protocol.SemanticTokenModifiers{protocol.SemanticTokenModifiersDeclaration},
+		}
+	}
+	for _, ident := range properties {
+		tokens[ident.Start()] = semanticToken{Start: ident.Start(), End: ident.End(), Type: protocol.SemanticTokenTypesProperty}
+	}
+	for _, ident := range uses {
+		tok := semanticToken{Start: ident.Start(), End: ident.End(), Type: protocol.SemanticTokenTypesVariable}
+		if decl, ok := doc.IdentDecls[ident]; ok {
+			if decl.Start().File == nil {
+				// Built-ins aren't declared in the source code.
+				tok.Type = protocol.SemanticTokenTypesFunction
+				if slices.Contains(lox.BuiltinModules, ident.Token.Lexeme) {
+					tok.Type = protocol.SemanticTokenTypesNamespace
+				}
+				tok.Modifiers = []protocol.SemanticTokenModifiers{protocol.SemanticTokenModifiersDefaultLibrary}
+			} else if typ, ok := declTypes[decl]; ok {
+				tok.Type = typ
+			}
+		}
+		tokens[ident.Start()] = tok
+	}
+	return tokens
+}
+
+// encodeSemanticTokens encodes semantic tokens in the relative format described in the specification. All tokens are
+// on a single line since none of them can contain a newline.
+func encodeSemanticTokens(tokens []semanticToken) []uint32 {
+	data := make([]uint32, 0, 5*len(tokens))
+	prev := &protocol.Position{}
+	for _, tok := range tokens {
+		start := newPosition(tok.Start)
+		deltaLine := start.Line - prev.Line
+		deltaChar := start.Character
+		if deltaLine == 0 {
+			deltaChar -= prev.Character
+		}
+		length := tok.End.ColumnUTF16() - start.Character
+		typ := slices.Index(semanticTokenTypes, tok.Type)
+		modifiers := 0
+		for _, modifier := range tok.Modifiers {
+			modifiers |= 1 << slices.Index(semanticTokenModifiers, modifier)
+		}
+		data = append(data, uint32(deltaLine), uint32(deltaChar), uint32(length), uint32(typ), uint32(modifiers))
+		prev = start
+	}
+	return data
+}
+
 // https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_documentSymbol
 func (h *Handler) textDocumentDocumentSymbol(params *protocol.DocumentSymbolParams) (*protocol.SymbolInformationSliceOrDocumentSymbolSlice, error) {
 	doc, err := h.document(params.TextDocument.Uri)
@@ -52,10 +845,33 @@ func (h *Handler) textDocumentDocumentSymbol(params *protocol.DocumentSymbolPara
 		return nil, err
 	}
 
+	docSymbols := documentSymbols(doc.Program)
+	var symbols protocol.SymbolInformationSliceOrDocumentSymbolSliceValue = docSymbols
+	if !h.clientSupportsHierarchicalDocumentSymbols {
+		symbols = toSymbolInformations(docSymbols, doc.URI)
+	}
+	return &protocol.SymbolInformationSliceOrDocumentSymbolSlice{Value: symbols}, nil
+}
+
+// documentSymbols returns the symbols declared in a node. The symbols declared in the body of a function are returned
+// as children of the function's symbol.
+func documentSymbols(node ast.Node) protocol.DocumentSymbolSlice {
 	var docSymbols protocol.DocumentSymbolSlice
-	ast.Walk(doc.Program, func(n ast.Node) bool {
+	ast.Walk(node, func(n ast.Node) bool {
 		switch n := n.(type) {
+		case ast.ImportStmt:
+			docSymbols = append(docSymbols, &protocol.DocumentSymbol{
+				Name:           n.Name.Token.Lexeme,
+				Detail:         n.Path.Lexeme,
+				Kind:           protocol.SymbolKindModule,
+				Range:          newRange(n.Start(), n.End()),
+				SelectionRange: newRange(n.Name.Start(), n.Name.End()),
+			})
+			return false
 		case ast.VarDecl:
+			if n.Name.Token.Lexeme == token.PlaceholderIdent {
+				return false
+			}
 			docSymbols = append(docSymbols, &protocol.DocumentSymbol{
 				Name:           n.Name.Token.Lexeme,
 				Kind:           protocol.SymbolKindVariable,
@@ -70,6 +886,7 @@ func (h *Handler) textDocumentDocumentSymbol(params *protocol.DocumentSymbolPara
 				Kind:           protocol.SymbolKindFunction,
 				Range:          newRange(n.Start(), n.End()),
 				SelectionRange: newRange(n.Name.Start(), n.Name.End()),
+				Children:       documentSymbols(n.Function.Body),
 			})
 			return false
 		case ast.ClassDecl:
@@ -102,6 +919,7 @@ func (h *Handler) textDocumentDocumentSymbol(params *protocol.DocumentSymbolPara
 					Kind:           kind,
 					Range:          newRange(decl.Start(), decl.End()),
 					SelectionRange: newRange(decl.Name.Start(), decl.Name.End()),
+					Children:       documentSymbols(decl.Function.Body),
 				})
 			}
 			return false
@@ -109,12 +927,7 @@ func (h *Handler) textDocumentDocumentSymbol(params *protocol.DocumentSymbolPara
 			return true
 		}
 	})
-
-	var symbols protocol.SymbolInformationSliceOrDocumentSymbolSliceValue = docSymbols
-	if !h.clientSupportsHierarchicalDocumentSymbols {
-		symbols = toSymbolInformations(docSymbols, doc.URI)
-	}
-	return &protocol.SymbolInformationSliceOrDocumentSymbolSlice{Value: symbols}, nil
+	return docSymbols
 }
 
 func toSymbolInformations(docSymbols protocol.DocumentSymbolSlice, uri string) protocol.SymbolInformationSlice {
@@ -139,6 +952,72 @@ func toSymbolInformations(docSymbols protocol.DocumentSymbolSlice, uri string) p
 	return symbolInfos
 }
 
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_foldingRange
+func (h *Handler) textDocumentFoldingRange(params *protocol.FoldingRangeParams) ([]*protocol.FoldingRange, error) {
+	doc, err := h.document(params.TextDocument.Uri)
+	if err != nil {
+		return nil, err
+	}
+	return foldingRanges(doc.Program), nil
+}
+
+// foldingRanges returns the folding ranges of a program in the order that they start. Blocks and class bodies are
+// folded up to the line before their closing brace so that it stays visible. Runs of consecutive comment lines are
+// folded as a single range.
+func foldingRanges(program ast.Program) []*protocol.FoldingRange {
+	var ranges []*protocol.FoldingRange
+	addBraces := func(leftBrace, rightBrace token.Position) {
+		// Lines in the protocol are 0-based.
+		startLine, endLine := leftBrace.Line-1, rightBrace.Line-2
+		if endLine > startLine {
+			ranges = append(ranges, &protocol.FoldingRange{StartLine: startLine, EndLine: endLine})
+		}
+	}
+	var commentLines []int
+	ast.Walk(program, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case ast.BlockStmt:
+			addBraces(n.LeftBrace.StartPos, n.RightBrace.StartPos)
+		case ast.Function:
+			// The body of a function isn't visited as a BlockStmt. A shorthand body doesn't have any braces.
+			if !n.HasShorthandBody() {
+				addBraces(n.Body.LeftBrace.StartPos, n.Body.RightBrace.StartPos)
+			}
+		case ast.ClassDecl:
+			// The position of the left brace isn't stored, so the end of what comes before it is used instead.
+			beforeLeftBrace := n.Name.End()
+			if n.Superclass != nil {
+				beforeLeftBrace = n.Superclass.End()
+			}
+			addBraces(beforeLeftBrace, n.RightBrace.StartPos)
+		case ast.CommentStmt:
+			commentLines = append(commentLines, n.Start().Line)
+		}
+		return true
+	})
+
+	slices.Sort(commentLines)
+	for i := 0; i < len(commentLines); {
+		j := i + 1
+		for j < len(commentLines) && commentLines[j] == commentLines[j-1]+1 {
+			j++
+		}
+		if j-i > 1 {
+			ranges = append(ranges, &protocol.FoldingRange{
+				StartLine: commentLines[i] - 1,
+				EndLine:   commentLines[j-1] - 1,
+				Kind:      protocol.FoldingRangeKindComment,
+			})
+		}
+		i = j
+	}
+
+	slices.SortStableFunc(ranges, func(a, b *protocol.FoldingRange) int {
+		return a.StartLine - b.StartLine
+	})
+	return ranges
+}
+
 // https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_formatting
 func (h *Handler) textDocumentFormatting(params *protocol.DocumentFormattingParams) ([]*protocol.TextEdit, error) {
 	doc, err := h.document(params.TextDocument.Uri)
@@ -152,19 +1031,468 @@ func (h *Handler) textDocumentFormatting(params *protocol.DocumentFormattingPara
 		return nil, nil
 	}
 
-	formatted := format.Node(doc.Program)
+	formatted := format.Node(doc.Program, format.WithIndentSize(h.settings.Get().IndentSize))
 	if formatted == doc.Text {
 		return nil, nil
 	}
 
-	textLines := strings.Split(strings.TrimSuffix(doc.Text, "\n"), "\n")
 	return []*protocol.TextEdit{
 		{
-			Range: &protocol.Range{
-				Start: &protocol.Position{Line: 0},
-				End:   &protocol.Position{Line: len(textLines)},
-			},
+			Range:   newRange(doc.Program.Start(), doc.Program.End()),
 			NewText: formatted,
 		},
 	}, nil
 }
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_rangeFormatting
+func (h *Handler) textDocumentRangeFormatting(params *protocol.DocumentRangeFormattingParams) ([]*protocol.TextEdit, error) {
+	doc, err := h.document(params.TextDocument.Uri)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := newTokenPosition(params.Range.Start, doc.File)
+	if err != nil {
+		return nil, jsonrpc.NewError(jsonrpc.InvalidParams, "Invalid range", map[string]any{"error": err.Error()})
+	}
+	end, err := newTokenPosition(params.Range.End, doc.File)
+	if err != nil {
+		return nil, jsonrpc.NewError(jsonrpc.InvalidParams, "Invalid range", map[string]any{"error": err.Error()})
+	}
+
+	if doc.HasErrors {
+		h.log.Infof("textDocument/rangeFormatting: %s has errors. Skipping formatting.", params.TextDocument.Uri)
+		return nil, nil
+	}
+
+	f := &rangeFormatter{
+		file:       doc.File,
+		start:      start,
+		end:        end,
+		indentSize: h.settings.Get().IndentSize,
+	}
+	f.formatStmts(doc.Program.Stmts, 0)
+	return f.edits, nil
+}
+
+// rangeFormatter formats the statements which are fully contained in a range. Statements which only partially overlap
+// the range are left as they are, but the statements nested inside them are still considered.
+type rangeFormatter struct {
+	file       *token.File
+	start, end token.Position
+	indentSize int
+	edits      []*protocol.TextEdit
+}
+
+func (f *rangeFormatter) formatStmts(stmts []ast.Stmt, depth int) {
+	for _, stmt := range stmts {
+		start := stmtStart(stmt)
+		switch {
+		case f.start.Compare(start) <= 0 && stmt.End().Compare(f.end) <= 0:
+			f.formatStmt(stmt, start, depth)
+		case start.Compare(f.end) < 0 && f.start.Compare(stmt.End()) < 0:
+			for _, nested := range nestedStmts(stmt) {
+				f.formatStmts(nested, depth+1)
+			}
+		}
+	}
+}
+
+func (f *rangeFormatter) formatStmt(stmt ast.Stmt, start token.Position, depth int) {
+	indent := strings.Repeat(" ", depth*f.indentSize)
+	lines := strings.Split(format.Node(stmt, format.WithIndentSize(f.indentSize)), "\n")
+	for i, line := range lines {
+		if i > 0 && line != "" {
+			lines[i] = indent + line
+		}
+	}
+	formatted := strings.Join(lines, "\n")
+
+	// The statement is reindented if it's the first thing on its line.
+	if len(bytes.TrimSpace(f.file.Line(start.Line)[:start.Column])) == 0 {
+		start.Column = 0
+		formatted = indent + formatted
+	}
+
+	if formatted == textBetween(f.file, start, stmt.End()) {
+		return
+	}
+	f.edits = append(f.edits, &protocol.TextEdit{
+		Range:   newRange(start, stmt.End()),
+		NewText: formatted,
+	})
+}
+
+// textBetween returns the text of a file between two positions.
+func textBetween(file *token.File, start, end token.Position) string {
+	if start.Line == end.Line {
+		return string(file.Line(start.Line)[start.Column:end.Column])
+	}
+	var b strings.Builder
+	b.Write(file.Line(start.Line)[start.Column:])
+	for line := start.Line + 1; line < end.Line; line++ {
+		b.WriteByte('\n')
+		b.Write(file.Line(line))
+	}
+	b.WriteByte('\n')
+	b.Write(file.Line(end.Line)[:end.Column])
+	return b.String()
+}
+
+// stmtStart returns the position of the first character of a statement. This differs from stmt.Start() for variable
+// declarations, which start at their name.
+func stmtStart(stmt ast.Stmt) token.Position {
+	switch stmt := stmt.(type) {
+	case ast.VarDecl:
+		return stmt.Var.StartPos
+	case ast.InlineCommentStmt:
+		return stmtStart(stmt.Stmt)
+	default:
+		return stmt.Start()
+	}
+}
+
+// nestedStmts returns the lists of statements which are nested one level of indentation inside a statement.
+func nestedStmts(stmt ast.Stmt) [][]ast.Stmt {
+	switch stmt := stmt.(type) {
+	case ast.InlineCommentStmt:
+		return nestedStmts(stmt.Stmt)
+	case ast.BlockStmt:
+		return [][]ast.Stmt{stmt.Stmts}
+	case ast.FunDecl:
+		return [][]ast.Stmt{stmt.Function.Body.Stmts}
+	case ast.MethodDecl:
+		return [][]ast.Stmt{stmt.Function.Body.Stmts}
+	case ast.ClassDecl:
+		return [][]ast.Stmt{stmt.Body}
+	case ast.IfStmt:
+		nested := nestedStmts(stmt.Then)
+		if stmt.Else != nil {
+			nested = append(nested, nestedStmts(stmt.Else)...)
+		}
+		return nested
+	case ast.WhileStmt:
+		return nestedStmts(stmt.Body)
+	case ast.ForStmt:
+		return nestedStmts(stmt.Body)
+	case ast.ForInStmt:
+		return nestedStmts(stmt.Body)
+	case ast.TryStmt:
+		nested := [][]ast.Stmt{stmt.Body.Stmts}
+		if stmt.HasCatch() {
+			nested = append(nested, stmt.CatchBody.Stmts)
+		}
+		if stmt.HasFinally() {
+			nested = append(nested, stmt.FinallyBody.Stmts)
+		}
+		return nested
+	default:
+		return nil
+	}
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_codeAction
+func (h *Handler) textDocumentCodeAction(params *protocol.CodeActionParams) ([]*protocol.CommandOrCodeAction, error) {
+	doc, err := h.document(params.TextDocument.Uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(params.Context.Only) > 0 && !slices.Contains(params.Context.Only, protocol.CodeActionKindQuickFix) {
+		return nil, nil
+	}
+
+	var actions []*protocol.CommandOrCodeAction
+	for _, diagnostic := range params.Context.Diagnostics {
+		diagnosticActions := suggestedFixActions(doc, diagnostic)
+		switch diagnosticCode(diagnostic) {
+		case lox.ErrorCodeMissingSemicolon:
+			// The diagnostic's range is the token which the semicolon should follow.
+			insertPos := diagnostic.Range.End
+			diagnosticActions = append(diagnosticActions, &protocol.CodeAction{
+				Title:       "Add semicolon",
+				IsPreferred: true,
+				Edit:        newWorkspaceEdit(doc.URI, &protocol.TextEdit{Range: &protocol.Range{Start: insertPos, End: insertPos}, NewText: ";"}),
+			})
+		case lox.ErrorCodeUnusedDeclaration:
+			diagnosticActions = append(diagnosticActions, unusedDeclarationActions(doc, diagnostic)...)
+		case lox.ErrorCodeUndeclared:
+			diagnosticActions = append(diagnosticActions, undeclaredActions(doc, diagnostic)...)
+		}
+		for _, action := range diagnosticActions {
+			action.Kind = protocol.CodeActionKindQuickFix
+			action.Diagnostics = []*protocol.Diagnostic{diagnostic}
+			actions = append(actions, &protocol.CommandOrCodeAction{Value: action})
+		}
+	}
+	return actions, nil
+}
+
+// suggestedFixActions returns the fixes suggested by the error which a diagnostic reports. The error is found by
+// matching the diagnostic's range and message against the errors found in the document.
+func suggestedFixActions(doc *document, diagnostic *protocol.Diagnostic) []*protocol.CodeAction {
+	for _, e := range doc.Errs {
+		rang := newRange(e.Start, e.End)
+		if e.Msg != diagnostic.Message || *rang.Start != *diagnostic.Range.Start || *rang.End != *diagnostic.Range.End {
+			continue
+		}
+		actions := make([]*protocol.CodeAction, len(e.Fixes))
+		for i, fix := range e.Fixes {
+			actions[i] = &protocol.CodeAction{
+				Title:       fix.Msg,
+				IsPreferred: len(e.Fixes) == 1,
+				Edit:        newWorkspaceEdit(doc.URI, &protocol.TextEdit{Range: newRange(fix.Start, fix.End), NewText: fix.NewText}),
+			}
+		}
+		return actions
+	}
+	return nil
+}
+
+// unusedDeclarationActions returns the fixes for an unused declaration diagnostic: prefixing the identifier with an
+// underscore and, if it's a variable, removing its declaration.
+func unusedDeclarationActions(doc *document, diagnostic *protocol.Diagnostic) []*protocol.CodeAction {
+	ident, parent, ok := diagnosticIdent(doc, diagnostic)
+	if !ok {
+		return nil
+	}
+	name := ident.Token.Lexeme
+	if importStmt, ok := parent.(ast.ImportStmt); ok {
+		// The name of an imported module can't be changed without changing its path.
+		return []*protocol.CodeAction{
+			{
+				Title: fmt.Sprintf("Remove import of %s", name),
+				Edit:  newWorkspaceEdit(doc.URI, &protocol.TextEdit{Range: deletionRange(doc.File, importStmt.Start(), importStmt.End()), NewText: ""}),
+			},
+		}
+	}
+	start := newPosition(ident.Start())
+	actions := []*protocol.CodeAction{
+		{
+			Title: fmt.Sprintf("Rename %s to _%s", name, name),
+			Edit:  newWorkspaceEdit(doc.URI, &protocol.TextEdit{Range: &protocol.Range{Start: start, End: start}, NewText: "_"}),
+		},
+	}
+	if varDecl, ok := parent.(ast.VarDecl); ok {
+		actions = append(actions, &protocol.CodeAction{
+			Title: fmt.Sprintf("Remove declaration of %s", name),
+			Edit:  newWorkspaceEdit(doc.URI, &protocol.TextEdit{Range: deletionRange(doc.File, varDecl.Var.StartPos, varDecl.End()), NewText: ""}),
+		})
+	}
+	return actions
+}
+
+// undeclaredActions returns the fix for an undeclared identifier diagnostic, which declares the identifier as a
+// variable on the line before the statement which contains it.
+func undeclaredActions(doc *document, diagnostic *protocol.Diagnostic) []*protocol.CodeAction {
+	ident, _, ok := diagnosticIdent(doc, diagnostic)
+	if !ok {
+		return nil
+	}
+	stmt, ok := enclosingListStmt(doc.Program, ident)
+	if !ok {
+		return nil
+	}
+	line := doc.File.Line(stmt.Start().Line)
+	indent := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]
+	insertPos := &protocol.Position{Line: stmt.Start().Line - 1, Character: 0}
+	return []*protocol.CodeAction{
+		{
+			Title: fmt.Sprintf("Declare %s", ident.Token.Lexeme),
+			Edit: newWorkspaceEdit(doc.URI, &protocol.TextEdit{
+				Range:   &protocol.Range{Start: insertPos, End: insertPos},
+				NewText: fmt.Sprintf("%svar %s;\n", indent, ident.Token.Lexeme),
+			}),
+		},
+	}
+}
+
+// diagnosticIdent returns the identifier which a diagnostic's range starts at and its parent node. ok is false if there
+// isn't one, which can happen if the document has changed since the diagnostic was published.
+func diagnosticIdent(doc *document, diagnostic *protocol.Diagnostic) (ident ast.Ident, parent ast.Node, ok bool) {
+	pos, err := newTokenPosition(diagnostic.Range.Start, doc.File)
+	if err != nil {
+		return ast.Ident{}, nil, false
+	}
+	node, parent := doc.Nodes.InnermostNodeAndParent(pos)
+	ident, ok = node.(ast.Ident)
+	if !ok || ident.Start() != pos {
+		return ast.Ident{}, nil, false
+	}
+	return ident, parent, true
+}
+
+// enclosingListStmt returns the statement which contains a node and is directly inside a program, block, or function
+// body. ok is false if there isn't one.
+func enclosingListStmt(program ast.Program, node ast.Node) (stmt ast.Stmt, ok bool) {
+	contains := func(n ast.Node) bool {
+		return n.Start().Compare(node.Start()) <= 0 && node.End().Compare(n.End()) <= 0
+	}
+	visitStmts := func(stmts []ast.Stmt) {
+		for _, s := range stmts {
+			if contains(s) {
+				stmt, ok = s, true
+			}
+		}
+	}
+	// Inner statement lists are visited after the ones that enclose them, so the innermost statement is found last.
+	ast.Walk(program, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case ast.Program:
+			visitStmts(n.Stmts)
+			return true
+		case ast.BlockStmt:
+			visitStmts(n.Stmts)
+		case ast.Function:
+			// The return statement of a shorthand body doesn't appear in the source.
+			if !n.HasShorthandBody() {
+				visitStmts(n.Body.Stmts)
+			}
+		}
+		return contains(n)
+	})
+	return stmt, ok
+}
+
+// deletionRange returns the range which should be deleted to remove the code between two positions. This is the whole
+// of the lines that the code is on if there's nothing else on them, otherwise just the code.
+func deletionRange(file *token.File, start, end token.Position) *protocol.Range {
+	before := file.Line(start.Line)[:start.Column]
+	after := file.Line(end.Line)[end.Column:]
+	if len(bytes.TrimSpace(before)) > 0 || len(bytes.TrimSpace(after)) > 0 || end.Line == file.NumLines() {
+		return newRange(start, end)
+	}
+	return &protocol.Range{
+		Start: &protocol.Position{Line: start.Line - 1, Character: 0},
+		End:   &protocol.Position{Line: end.Line, Character: 0},
+	}
+}
+
+// newWorkspaceEdit returns a [protocol.WorkspaceEdit] which applies the given edits to a document.
+func newWorkspaceEdit(uri string, edits ...*protocol.TextEdit) *protocol.WorkspaceEdit {
+	return &protocol.WorkspaceEdit{Changes: map[string][]*protocol.TextEdit{uri: edits}}
+}
+
+// showReferencesCommand is the client side command which is run when a reference count code lens is clicked. It's
+// called with the same arguments as VS Code's editor.action.showReferences command: the URI of the document, the
+// position of the declaration, and the locations of its references.
+const showReferencesCommand = "loxls.showReferences"
+
+// codeLensData is stored in a code lens between textDocument/codeLens and codeLens/resolve.
+type codeLensData struct {
+	URI      string             `json:"uri"`
+	Position *protocol.Position `json:"position"` // The start of the declaration's name.
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_codeLens
+func (h *Handler) textDocumentCodeLens(params *protocol.CodeLensParams) ([]*protocol.CodeLens, error) {
+	doc, err := h.document(params.TextDocument.Uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.HasErrors {
+		// Identifiers aren't resolved in documents with syntax errors, so references can't be counted.
+		return nil, nil
+	}
+
+	var names []ast.Ident
+	ast.Walk(doc.Program, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case ast.FunDecl:
+			names = append(names, n.Name)
+		case ast.ClassDecl:
+			names = append(names, n.Name)
+		}
+		return true
+	})
+
+	// Lenses are returned unresolved so that references are only counted for the lenses which are shown.
+	var lenses []*protocol.CodeLens
+	for _, name := range names {
+		if name.Token.Lexeme == token.PlaceholderIdent {
+			continue
+		}
+		data, err := toLSPAny(codeLensData{URI: doc.URI, Position: newPosition(name.Start())})
+		if err != nil {
+			return nil, err
+		}
+		lenses = append(lenses, &protocol.CodeLens{
+			Range: newRange(name.Start(), name.End()),
+			Data:  data,
+		})
+	}
+	return lenses, nil
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#codeLens_resolve
+func (h *Handler) codeLensResolve(lens *protocol.CodeLens) (*protocol.CodeLens, error) {
+	var data codeLensData
+	if err := fromLSPAny(lens.Data, &data); err != nil || data.Position == nil {
+		return nil, jsonrpc.NewError(jsonrpc.InvalidParams, "Invalid code lens data", map[string]any{"data": lens.Data})
+	}
+	doc, err := h.document(data.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	pos, err := newTokenPosition(data.Position, doc.File)
+	if err != nil {
+		return nil, jsonrpc.NewError(jsonrpc.InvalidParams, "Invalid position", map[string]any{"error": err.Error()})
+	}
+	decl, ok := doc.Nodes.InnermostNode(pos).(ast.Ident)
+	if !ok || doc.IdentDecls[decl] != decl {
+		// The document has changed since the lens was created so the declaration can't be found. The client will
+		// request new lenses for the changed document.
+		return lens, nil
+	}
+
+	refs := references(doc, decl)
+	locations := make([]*protocol.Location, len(refs))
+	for i, ref := range refs {
+		locations[i] = &protocol.Location{Uri: doc.URI, Range: newRange(ref.Start(), ref.End())}
+	}
+
+	var title string
+	switch len(refs) {
+	case 0:
+		title = "no references"
+	case 1:
+		title = "1 reference"
+	default:
+		title = fmt.Sprintf("%d references", len(refs))
+	}
+	args := make([]protocol.LSPAny, 3)
+	for i, arg := range []any{doc.URI, data.Position, locations} {
+		if args[i], err = toLSPAny(arg); err != nil {
+			return nil, err
+		}
+	}
+	lens.Command = &protocol.Command{
+		Title:     title,
+		Command:   showReferencesCommand,
+		Arguments: args,
+	}
+	return lens, nil
+}
+
+// fromLSPAny converts a [protocol.LSPAny] to the value pointed to by v by round-tripping it through JSON.
+func fromLSPAny(value protocol.LSPAny, v any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// diagnosticCode returns the code of a diagnostic published by the server, or an empty string if it doesn't have one.
+func diagnosticCode(diagnostic *protocol.Diagnostic) lox.ErrorCode {
+	if diagnostic.Code == nil {
+		return ""
+	}
+	code, ok := diagnostic.Code.Value.(protocol.String)
+	if !ok {
+		return ""
+	}
+	return lox.ErrorCode(code)
+}
@@ -0,0 +1,1092 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/marcuscaisey/lox/lox/parser"
+	"github.com/marcuscaisey/lox/lox/token"
+	"github.com/marcuscaisey/lox/loxls/lsp/protocol"
+)
+
+func TestCodeActionAddSemicolon(t *testing.T) {
+	const uri = "file:///test.lox"
+	s := startServer(t)
+	s.Initialize(t, nil)
+
+	s.Notify(t, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": "print 1\nprint 2;\n"},
+	})
+	var diagnosticsParams struct {
+		Diagnostics []json.RawMessage `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(s.WaitForNotification(t, "textDocument/publishDiagnostics"), &diagnosticsParams); err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnosticsParams.Diagnostics) != 1 {
+		t.Fatalf("%d diagnostics published, want 1", len(diagnosticsParams.Diagnostics))
+	}
+
+	resp := s.Request(t, 1, "textDocument/codeAction", map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"range":        map[string]any{"start": map[string]any{"line": 0, "character": 6}, "end": map[string]any{"line": 0, "character": 6}},
+		"context":      map[string]any{"diagnostics": diagnosticsParams.Diagnostics},
+	})
+	if resp.Error != nil {
+		t.Fatalf("textDocument/codeAction returned error: %+v", resp.Error)
+	}
+
+	var actions []*protocol.CodeAction
+	if err := json.Unmarshal(resp.Result, &actions); err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 {
+		t.Fatalf("%d code actions returned, want 1", len(actions))
+	}
+	want := map[string][]*protocol.TextEdit{
+		uri: {
+			{
+				Range: &protocol.Range{
+					Start: &protocol.Position{Line: 0, Character: 7},
+					End:   &protocol.Position{Line: 0, Character: 7},
+				},
+				NewText: ";",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, actions[0].Edit.Changes); diff != "" {
+		t.Errorf("incorrect edit (-want +got):\n%s", diff)
+	}
+	if actions[0].Kind != protocol.CodeActionKindQuickFix {
+		t.Errorf("code action kind = %q, want %q", actions[0].Kind, protocol.CodeActionKindQuickFix)
+	}
+}
+
+func TestCodeActionQuickFixes(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want map[string]string // The result of applying each code action, keyed by title.
+	}{
+		{
+			name: "UnusedVariable",
+			src:  "fun f() {\n    var x = 1;\n}\nf();\n",
+			want: map[string]string{
+				"Rename x to _x":          "fun f() {\n    var _x = 1;\n}\nf();\n",
+				"Remove declaration of x": "fun f() {\n}\nf();\n",
+			},
+		},
+		{
+			name: "UnusedParameter",
+			src:  "fun f(a) {}\nf(1);\n",
+			want: map[string]string{
+				"Rename a to _a": "fun f(_a) {}\nf(1);\n",
+			},
+		},
+		{
+			name: "Undeclared",
+			src:  "fun f() {\n    print 1;\n    print z;\n}\nf();\n",
+			want: map[string]string{
+				"Declare z": "fun f() {\n    print 1;\n    var z;\n    print z;\n}\nf();\n",
+			},
+		},
+		{
+			name: "UndeclaredSimilarName",
+			src:  "var count = 1;\nprint count;\nprint cuont;\n",
+			want: map[string]string{
+				"replace with count": "var count = 1;\nprint count;\nprint count;\n",
+				"Declare cuont":      "var count = 1;\nprint count;\nvar cuont;\nprint cuont;\n",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			const uri = "file:///test.lox"
+			s := startServer(t)
+			s.Initialize(t, nil)
+
+			s.Notify(t, "textDocument/didOpen", map[string]any{
+				"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": test.src},
+			})
+			var diagnosticsParams struct {
+				Diagnostics []json.RawMessage `json:"diagnostics"`
+			}
+			if err := json.Unmarshal(s.WaitForNotification(t, "textDocument/publishDiagnostics"), &diagnosticsParams); err != nil {
+				t.Fatal(err)
+			}
+
+			resp := s.Request(t, 1, "textDocument/codeAction", map[string]any{
+				"textDocument": map[string]any{"uri": uri},
+				"range":        map[string]any{"start": map[string]any{"line": 0, "character": 0}, "end": map[string]any{"line": 0, "character": 0}},
+				"context":      map[string]any{"diagnostics": diagnosticsParams.Diagnostics},
+			})
+			if resp.Error != nil {
+				t.Fatalf("textDocument/codeAction returned error: %+v", resp.Error)
+			}
+			var actions []*protocol.CodeAction
+			if err := json.Unmarshal(resp.Result, &actions); err != nil {
+				t.Fatal(err)
+			}
+
+			got := map[string]string{}
+			for _, action := range actions {
+				if action.Kind != protocol.CodeActionKindQuickFix {
+					t.Errorf("code action %q has kind %q, want %q", action.Title, action.Kind, protocol.CodeActionKindQuickFix)
+				}
+				got[action.Title] = applyTextEdits(t, test.src, action.Edit.Changes[uri])
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("incorrect code action results (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// applyTextEdits applies non-overlapping edits to ASCII source code.
+func applyTextEdits(t *testing.T, src string, edits []*protocol.TextEdit) string {
+	t.Helper()
+	lineOffsets := []int{0}
+	for i, c := range src {
+		if c == '\n' {
+			lineOffsets = append(lineOffsets, i+1)
+		}
+	}
+	offset := func(pos *protocol.Position) int {
+		return lineOffsets[pos.Line] + pos.Character
+	}
+	edits = slices.Clone(edits)
+	slices.SortFunc(edits, func(a, b *protocol.TextEdit) int {
+		return offset(b.Range.Start) - offset(a.Range.Start)
+	})
+	for _, edit := range edits {
+		src = src[:offset(edit.Range.Start)] + edit.NewText + src[offset(edit.Range.End):]
+	}
+	return src
+}
+
+func TestFormattingReplacesWholeDocument(t *testing.T) {
+	const uri = "file:///test.lox"
+	s := startServer(t)
+	s.Initialize(t, nil)
+
+	s.Notify(t, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": "\nprint  \"€\";\n\n"},
+	})
+	s.WaitForNotification(t, "textDocument/publishDiagnostics")
+
+	resp := s.Request(t, 1, "textDocument/formatting", map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"options":      map[string]any{"tabSize": 4, "insertSpaces": true},
+	})
+	if resp.Error != nil {
+		t.Fatalf("textDocument/formatting returned error: %+v", resp.Error)
+	}
+
+	var got []*protocol.TextEdit
+	if err := json.Unmarshal(resp.Result, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []*protocol.TextEdit{
+		{
+			Range: &protocol.Range{
+				Start: &protocol.Position{Line: 0, Character: 0},
+				End:   &protocol.Position{Line: 3, Character: 0},
+			},
+			NewText: "print \"€\";\n",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("incorrect edits (-want +got):\n%s", diff)
+	}
+}
+
+func TestRangeFormatting(t *testing.T) {
+	const uri = "file:///test.lox"
+	const src = `fun f() {
+print   1;
+  var x=2;
+    if (x) {print  x;}
+}
+print   3;
+`
+	testCases := []struct {
+		name  string
+		start map[string]any
+		end   map[string]any
+		want  string
+	}{
+		{
+			name:  "NestedStatements",
+			start: map[string]any{"line": 1, "character": 0},
+			end:   map[string]any{"line": 3, "character": 0},
+			want: `fun f() {
+    print 1;
+    var x = 2;
+    if (x) {print  x;}
+}
+print   3;
+`,
+		},
+		{
+			name:  "PartiallyContainedStatement",
+			start: map[string]any{"line": 3, "character": 12},
+			end:   map[string]any{"line": 5, "character": 4},
+			want: `fun f() {
+print   1;
+  var x=2;
+    if (x) {print x;}
+}
+print   3;
+`,
+		},
+		{
+			name:  "WholeDocument",
+			start: map[string]any{"line": 0, "character": 0},
+			end:   map[string]any{"line": 6, "character": 0},
+			want: `fun f() {
+    print 1;
+    var x = 2;
+    if (x) {
+        print x;
+    }
+}
+print 3;
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := startServer(t)
+			s.Initialize(t, nil)
+
+			s.Notify(t, "textDocument/didOpen", map[string]any{
+				"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": src},
+			})
+			s.WaitForNotification(t, "textDocument/publishDiagnostics")
+
+			resp := s.Request(t, 1, "textDocument/rangeFormatting", map[string]any{
+				"textDocument": map[string]any{"uri": uri},
+				"range":        map[string]any{"start": tc.start, "end": tc.end},
+				"options":      map[string]any{"tabSize": 4, "insertSpaces": true},
+			})
+			if resp.Error != nil {
+				t.Fatalf("textDocument/rangeFormatting returned error: %+v", resp.Error)
+			}
+
+			var edits []*protocol.TextEdit
+			if err := json.Unmarshal(resp.Result, &edits); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, applyTextEdits(t, src, edits)); diff != "" {
+				t.Errorf("incorrect formatted source (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCodeLensReferenceCounts(t *testing.T) {
+	const uri = "file:///test.lox"
+	s := startServer(t)
+	s.Initialize(t, nil)
+
+	s.Notify(t, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": "" +
+			"fun used() {}\n" +
+			"fun unused() {}\n" +
+			"class A {}\n" +
+			"used();\n" +
+			"used();\n" +
+			"print A;\n",
+		},
+	})
+	s.WaitForNotification(t, "textDocument/publishDiagnostics")
+
+	resp := s.Request(t, 1, "textDocument/codeLens", map[string]any{"textDocument": map[string]any{"uri": uri}})
+	if resp.Error != nil {
+		t.Fatalf("textDocument/codeLens returned error: %+v", resp.Error)
+	}
+	var lenses []json.RawMessage
+	if err := json.Unmarshal(resp.Result, &lenses); err != nil {
+		t.Fatal(err)
+	}
+
+	type resolvedLens struct {
+		Line       int
+		Title      string
+		References []int // The line of each reference.
+	}
+	var got []resolvedLens
+	for i, lens := range lenses {
+		resp := s.Request(t, 2+i, "codeLens/resolve", lens)
+		if resp.Error != nil {
+			t.Fatalf("codeLens/resolve returned error: %+v", resp.Error)
+		}
+		var resolved struct {
+			Range   protocol.Range `json:"range"`
+			Command struct {
+				Title     string            `json:"title"`
+				Command   string            `json:"command"`
+				Arguments []json.RawMessage `json:"arguments"`
+			} `json:"command"`
+		}
+		if err := json.Unmarshal(resp.Result, &resolved); err != nil {
+			t.Fatal(err)
+		}
+		if resolved.Command.Command != showReferencesCommand {
+			t.Errorf("command = %q, want %q", resolved.Command.Command, showReferencesCommand)
+		}
+		if len(resolved.Command.Arguments) != 3 {
+			t.Fatalf("command has %d arguments, want 3", len(resolved.Command.Arguments))
+		}
+		var locations []protocol.Location
+		if err := json.Unmarshal(resolved.Command.Arguments[2], &locations); err != nil {
+			t.Fatal(err)
+		}
+		lens := resolvedLens{Line: resolved.Range.Start.Line, Title: resolved.Command.Title}
+		for _, location := range locations {
+			lens.References = append(lens.References, location.Range.Start.Line)
+		}
+		got = append(got, lens)
+	}
+
+	want := []resolvedLens{
+		{Line: 0, Title: "2 references", References: []int{3, 4}},
+		{Line: 1, Title: "no references"},
+		{Line: 2, Title: "1 reference", References: []int{5}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("incorrect code lenses (-want +got):\n%s", diff)
+	}
+}
+
+func TestHover(t *testing.T) {
+	const uri = "file:///test.lox"
+	s := startServer(t)
+	s.Initialize(t, nil)
+
+	s.Notify(t, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": "" +
+			"var x = 1;\n" +
+			"fun add(a, b) {\n" +
+			"    return a + b;\n" +
+			"}\n" +
+			"class A {\n" +
+			"    f(c) { return c; }\n" +
+			"}\n" +
+			"print add(x, clock());\n" +
+			"print A().f(y);\n" +
+			"// Not a doc comment.\n" +
+			"\n" +
+			"// Returns the\n" +
+			"//  sum.\n" +
+			"fun sum(a, b) { return a + b; } // Not a doc comment either.\n" +
+			"print sum(1, 2);\n" +
+			"print math.PI;\n",
+		},
+	})
+	s.WaitForNotification(t, "textDocument/publishDiagnostics")
+
+	tests := []struct {
+		name      string
+		line      int
+		character int
+		want      string // Empty if the result should be null.
+	}{
+		{
+			name:      "Variable",
+			line:      7,
+			character: 10,
+			want:      "```lox\nvar x\n```\n\n---\n\nDeclared on line 1:\n```lox\nvar x = 1;\n```",
+		},
+		{
+			name:      "Function",
+			line:      7,
+			character: 6,
+			want:      "```lox\nfun add(a, b)\n```\n\n---\n\nDeclared on line 2:\n```lox\nfun add(a, b) {\n```",
+		},
+		{
+			name:      "Parameter",
+			line:      2,
+			character: 11,
+			want:      "```lox\n(parameter) a\n```\n\nParameter of `fun add(a, b)`\n\n---\n\nDeclared on line 2:\n```lox\nfun add(a, b) {\n```",
+		},
+		{
+			name:      "MethodParameter",
+			line:      5,
+			character: 18,
+			want:      "```lox\n(parameter) c\n```\n\nParameter of `A.f(c)`\n\n---\n\nDeclared on line 6:\n```lox\nf(c) { return c; }\n```",
+		},
+		{
+			name:      "Class",
+			line:      8,
+			character: 6,
+			want:      "```lox\nclass A\n```\n\n---\n\nDeclared on line 5:\n```lox\nclass A {\n```",
+		},
+		{
+			name:      "BuiltIn",
+			line:      7,
+			character: 13,
+			want:      "```lox\nfun clock\n```\n\nBuilt-in function",
+		},
+		{
+			name:      "BuiltInModule",
+			line:      15,
+			character: 7,
+			want:      "```lox\nmath\n```\n\nBuilt-in module",
+		},
+		{
+			name:      "DocComment",
+			line:      14,
+			character: 6,
+			want:      "```lox\nfun sum(a, b)\n```\n\nReturns the\n sum.\n\n---\n\nDeclared on line 14:\n```lox\nfun sum(a, b) { return a + b; } // Not a doc comment either.\n```",
+		},
+		{name: "Unresolved", line: 8, character: 12},
+		{name: "NotIdentifier", line: 7, character: 0},
+	}
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := s.Request(t, 1+i, "textDocument/hover", map[string]any{
+				"textDocument": map[string]any{"uri": uri},
+				"position":     map[string]any{"line": test.line, "character": test.character},
+			})
+			if resp.Error != nil {
+				t.Fatalf("textDocument/hover returned error: %+v", resp.Error)
+			}
+			var hover *protocol.Hover
+			if err := json.Unmarshal(resp.Result, &hover); err != nil {
+				t.Fatal(err)
+			}
+			if test.want == "" {
+				if hover != nil {
+					t.Errorf("textDocument/hover returned %q, want null", hover.Contents.Value)
+				}
+				return
+			}
+			if hover == nil {
+				t.Fatalf("textDocument/hover returned null, want %q", test.want)
+			}
+			if diff := cmp.Diff(test.want, hover.Contents.Value); diff != "" {
+				t.Errorf("incorrect hover contents (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	const uri = "file:///test.lox"
+	s := startServer(t)
+	s.Initialize(t, nil)
+
+	s.Notify(t, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": "" +
+			"fun f(a) {\n" +
+			"    var b = a;\n" +
+			"    return b + g;\n" +
+			"}\n" +
+			"var g = 1;\n" +
+			"print f(y);\n",
+		},
+	})
+	s.WaitForNotification(t, "textDocument/publishDiagnostics")
+
+	tests := []struct {
+		name      string
+		line      int
+		character int
+		want      *protocol.Range // Nil if the result should be null.
+	}{
+		{
+			name:      "Local",
+			line:      2,
+			character: 11,
+			want:      &protocol.Range{Start: &protocol.Position{Line: 1, Character: 8}, End: &protocol.Position{Line: 1, Character: 9}},
+		},
+		{
+			name:      "Parameter",
+			line:      1,
+			character: 12,
+			want:      &protocol.Range{Start: &protocol.Position{Line: 0, Character: 6}, End: &protocol.Position{Line: 0, Character: 7}},
+		},
+		{
+			name:      "Global",
+			line:      2,
+			character: 15,
+			want:      &protocol.Range{Start: &protocol.Position{Line: 4, Character: 4}, End: &protocol.Position{Line: 4, Character: 5}},
+		},
+		{
+			name:      "Function",
+			line:      5,
+			character: 6,
+			want:      &protocol.Range{Start: &protocol.Position{Line: 0, Character: 4}, End: &protocol.Position{Line: 0, Character: 5}},
+		},
+		{name: "Unresolved", line: 5, character: 8},
+		{name: "NotIdentifier", line: 5, character: 0},
+	}
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := s.Request(t, 1+i, "textDocument/definition", map[string]any{
+				"textDocument": map[string]any{"uri": uri},
+				"position":     map[string]any{"line": test.line, "character": test.character},
+			})
+			if resp.Error != nil {
+				t.Fatalf("textDocument/definition returned error: %+v", resp.Error)
+			}
+			var location *protocol.Location
+			if err := json.Unmarshal(resp.Result, &location); err != nil {
+				t.Fatal(err)
+			}
+			if test.want == nil {
+				if location != nil {
+					t.Errorf("textDocument/definition returned %+v, want null", location)
+				}
+				return
+			}
+			want := &protocol.Location{Uri: uri, Range: test.want}
+			if diff := cmp.Diff(want, location); diff != "" {
+				t.Errorf("incorrect definition (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDocumentSymbolsNested(t *testing.T) {
+	src := "" +
+		"fun outer(a) {\n" +
+		"    var x = a;\n" +
+		"    var _ = x;\n" +
+		"    fun inner() {\n" +
+		"        {\n" +
+		"            var y = x;\n" +
+		"        }\n" +
+		"    }\n" +
+		"}\n"
+	program, err := parser.ParseFile(token.NewFile("test.lox", []byte(src)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newTestRange := func(startLine, startChar, endLine, endChar int) *protocol.Range {
+		return &protocol.Range{
+			Start: &protocol.Position{Line: startLine, Character: startChar},
+			End:   &protocol.Position{Line: endLine, Character: endChar},
+		}
+	}
+	want := protocol.DocumentSymbolSlice{
+		{
+			Name:           "outer",
+			Detail:         "fun(a)",
+			Kind:           protocol.SymbolKindFunction,
+			Range:          newTestRange(0, 0, 8, 1),
+			SelectionRange: newTestRange(0, 4, 0, 9),
+			Children: protocol.DocumentSymbolSlice{
+				{
+					Name:           "x",
+					Kind:           protocol.SymbolKindVariable,
+					Range:          newTestRange(1, 8, 1, 14),
+					SelectionRange: newTestRange(1, 8, 1, 9),
+				},
+				{
+					Name:           "inner",
+					Detail:         "fun()",
+					Kind:           protocol.SymbolKindFunction,
+					Range:          newTestRange(3, 4, 7, 5),
+					SelectionRange: newTestRange(3, 8, 3, 13),
+					Children: protocol.DocumentSymbolSlice{
+						{
+							Name:           "y",
+							Kind:           protocol.SymbolKindVariable,
+							Range:          newTestRange(5, 16, 5, 22),
+							SelectionRange: newTestRange(5, 16, 5, 17),
+						},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, documentSymbols(program)); diff != "" {
+		t.Errorf("incorrect document symbols (-want +got):\n%s", diff)
+	}
+}
+
+func TestFoldingRanges(t *testing.T) {
+	src := "" +
+		"// A point.\n" +
+		"// It has two coordinates.\n" +
+		"class Point < Base {\n" +
+		"    init(x, y) {\n" +
+		"        this.x = x;\n" +
+		"        this.y = y;\n" +
+		"    }\n" +
+		"}\n" +
+		"\n" +
+		"// Not folded.\n" +
+		"fun f() { return 1; }\n" +
+		"while (true) {\n" +
+		"    break;\n" +
+		"}\n"
+	program, err := parser.ParseFile(token.NewFile("test.lox", []byte(src)), parser.WithComments())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*protocol.FoldingRange{
+		{StartLine: 0, EndLine: 1, Kind: protocol.FoldingRangeKindComment},
+		{StartLine: 2, EndLine: 6},
+		{StartLine: 3, EndLine: 5},
+		{StartLine: 11, EndLine: 12},
+	}
+	got := foldingRanges(program)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("incorrect folding ranges (-want +got):\n%s", diff)
+	}
+}
+
+func TestReferencesShadowed(t *testing.T) {
+	const uri = "file:///test.lox"
+	s := startServer(t)
+	s.Initialize(t, nil)
+
+	s.Notify(t, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": "" +
+			"var x = 1;\n" +
+			"{\n" +
+			"    var x = 2;\n" +
+			"    x = x + 1;\n" +
+			"    print x;\n" +
+			"}\n" +
+			"x = 3;\n" +
+			"print x;\n",
+		},
+	})
+	s.WaitForNotification(t, "textDocument/publishDiagnostics")
+
+	tests := []struct {
+		name               string
+		line               int
+		character          int
+		includeDeclaration bool
+		want               []string // The start position of each reference as line:character.
+	}{
+		{
+			name:               "OuterIncludingDeclaration",
+			line:               7,
+			character:          6,
+			includeDeclaration: true,
+			want:               []string{"0:4", "6:0", "7:6"},
+		},
+		{
+			name:      "Outer",
+			line:      0,
+			character: 4,
+			want:      []string{"6:0", "7:6"},
+		},
+		{
+			name:               "InnerIncludingDeclaration",
+			line:               3,
+			character:          8,
+			includeDeclaration: true,
+			want:               []string{"2:8", "3:4", "3:8", "4:10"},
+		},
+		{
+			name:      "Inner",
+			line:      3,
+			character: 4,
+			want:      []string{"3:4", "3:8", "4:10"},
+		},
+		{name: "NotIdentifier", line: 1, character: 0},
+	}
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := s.Request(t, 1+i, "textDocument/references", map[string]any{
+				"textDocument": map[string]any{"uri": uri},
+				"position":     map[string]any{"line": test.line, "character": test.character},
+				"context":      map[string]any{"includeDeclaration": test.includeDeclaration},
+			})
+			if resp.Error != nil {
+				t.Fatalf("textDocument/references returned error: %+v", resp.Error)
+			}
+			var locations []*protocol.Location
+			if err := json.Unmarshal(resp.Result, &locations); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, location := range locations {
+				if location.Uri != uri {
+					t.Errorf("reference has URI %q, want %q", location.Uri, uri)
+				}
+				got = append(got, fmt.Sprintf("%d:%d", location.Range.Start.Line, location.Range.Start.Character))
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("incorrect references (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRename(t *testing.T) {
+	const uri = "file:///test.lox"
+	s := startServer(t)
+	s.Initialize(t, nil)
+
+	s.Notify(t, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": "" +
+			"var x = 1;\n" +
+			"{\n" +
+			"    var x = 2;\n" +
+			"    x = x + 1;\n" +
+			"}\n" +
+			"print x;\n" +
+			"print clock();\n" +
+			"print y;\n" +
+			"x.y = 1;\n" +
+			"import \"lib.lox\";\n" +
+			"print lib;\n",
+		},
+	})
+	s.WaitForNotification(t, "textDocument/publishDiagnostics")
+
+	tests := []struct {
+		name      string
+		line      int
+		character int
+		newName   string
+		want      []string // The start position of each edit as line:character.
+		wantErr   bool
+	}{
+		{
+			name:      "Outer",
+			line:      5,
+			character: 6,
+			newName:   "z",
+			want:      []string{"0:4", "5:6", "8:0"},
+		},
+		{
+			name:      "Inner",
+			line:      2,
+			character: 8,
+			newName:   "z",
+			want:      []string{"2:8", "3:4", "3:8"},
+		},
+		{name: "Builtin", line: 6, character: 6, newName: "z", wantErr: true},
+		{name: "Undeclared", line: 7, character: 6, newName: "z", wantErr: true},
+		{name: "InvalidName", line: 0, character: 4, newName: "class", wantErr: true},
+		{name: "Property", line: 8, character: 2, newName: "z"},
+		{name: "NotIdentifier", line: 1, character: 0, newName: "z"},
+		{name: "ImportedModule", line: 10, character: 6, newName: "z", wantErr: true},
+	}
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := s.Request(t, 1+i, "textDocument/rename", map[string]any{
+				"textDocument": map[string]any{"uri": uri},
+				"position":     map[string]any{"line": test.line, "character": test.character},
+				"newName":      test.newName,
+			})
+			if test.wantErr {
+				if resp.Error == nil {
+					t.Fatalf("textDocument/rename returned no error, want one")
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Fatalf("textDocument/rename returned error: %+v", resp.Error)
+			}
+			var edit *protocol.WorkspaceEdit
+			if err := json.Unmarshal(resp.Result, &edit); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			if edit != nil {
+				for _, textEdit := range edit.Changes[uri] {
+					if textEdit.NewText != test.newName {
+						t.Errorf("edit has new text %q, want %q", textEdit.NewText, test.newName)
+					}
+					got = append(got, fmt.Sprintf("%d:%d", textEdit.Range.Start.Line, textEdit.Range.Start.Character))
+				}
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("incorrect edits (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSemanticTokens(t *testing.T) {
+	const uri = "file:///test.lox"
+	s := startServer(t)
+	s.Initialize(t, nil)
+
+	s.Notify(t, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": "" +
+			"// Comment\n" +
+			"fun add(a, b) {\n" +
+			"    return a + b;\n" +
+			"}\n" +
+			"var x = add(1, clock());\n" +
+			"print \"ab\" + x.y;\n",
+		},
+	})
+	s.WaitForNotification(t, "textDocument/publishDiagnostics")
+
+	// Each token is formatted as line:character:length type [modifiers].
+	decode := func(t *testing.T, data []uint32) []string {
+		t.Helper()
+		var tokens []string
+		line, char := 0, 0
+		for i := 0; i+5 <= len(data); i += 5 {
+			if data[i] > 0 {
+				char = 0
+			}
+			line += int(data[i])
+			char += int(data[i+1])
+			tok := fmt.Sprintf("%d:%d:%d %s", line, char, data[i+2], semanticTokenTypes[data[i+3]])
+			for j, modifier := range semanticTokenModifiers {
+				if data[i+4]&(1<<j) != 0 {
+					tok += " " + string(modifier)
+				}
+			}
+			tokens = append(tokens, tok)
+		}
+		return tokens
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		params map[string]any
+		want   []string
+	}{
+		{
+			name:   "Full",
+			method: "textDocument/semanticTokens/full",
+			params: map[string]any{"textDocument": map[string]any{"uri": uri}},
+			want: []string{
+				"0:0:10 comment",
+				"1:0:3 keyword",
+				"1:4:3 function declaration",
+				"1:8:1 parameter declaration",
+				"1:11:1 parameter declaration",
+				"2:4:6 keyword",
+				"2:11:1 parameter",
+				"2:15:1 parameter",
+				"4:0:3 keyword",
+				"4:4:1 variable declaration",
+				"4:8:3 function",
+				"4:12:1 number",
+				"4:15:5 function defaultLibrary",
+				"5:0:5 keyword",
+				"5:6:4 string",
+				"5:13:1 variable",
+				"5:15:1 property",
+			},
+		},
+		{
+			name:   "Range",
+			method: "textDocument/semanticTokens/range",
+			params: map[string]any{
+				"textDocument": map[string]any{"uri": uri},
+				"range": map[string]any{
+					"start": map[string]any{"line": 2, "character": 11},
+					"end":   map[string]any{"line": 2, "character": 12},
+				},
+			},
+			want: []string{"2:11:1 parameter"},
+		},
+	}
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := s.Request(t, 1+i, test.method, test.params)
+			if resp.Error != nil {
+				t.Fatalf("%s returned error: %+v", test.method, resp.Error)
+			}
+			var tokens *protocol.SemanticTokens
+			if err := json.Unmarshal(resp.Result, &tokens); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, decode(t, tokens.Data)); diff != "" {
+				t.Errorf("incorrect semantic tokens (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	const uri = "file:///test.lox"
+	s := startServer(t)
+	s.Initialize(t, nil)
+
+	s.Notify(t, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": "" +
+			"var a = 1;\n" +
+			"fun f(p) {\n" +
+			"    var b = 2;\n" +
+			"    print b;\n" +
+			"    var c = 3;\n" +
+			"    return c;\n" +
+			"}\n" +
+			"var type = K;\n" +
+			"class K {}\n" +
+			"a.x;\n",
+		},
+	})
+	s.WaitForNotification(t, "textDocument/publishDiagnostics")
+
+	tests := []struct {
+		name      string
+		line      int
+		character int
+		want      []string // Each non-keyword item formatted as label: detail. Nil if the result should be null.
+	}{
+		{
+			name:      "Function",
+			line:      3,
+			character: 4,
+			want: []string{
+				"K: class K",
+				"a: var a",
+				"b: var b",
+				"charAt: Built-in function",
+				"clock: Built-in function",
+				"error: Built-in function",
+				"f: fun f(p)",
+				"indexOf: Built-in function",
+				"len: Built-in function",
+				"math: Built-in module",
+				"p: (parameter) p",
+				"substring: Built-in function",
+				"toLower: Built-in function",
+				"toUpper: Built-in function",
+				"trim: Built-in function",
+				"type: var type",
+			},
+		},
+		{
+			name:      "Global",
+			line:      7,
+			character: 11,
+			want: []string{
+				"a: var a",
+				"charAt: Built-in function",
+				"clock: Built-in function",
+				"error: Built-in function",
+				"f: fun f(p)",
+				"indexOf: Built-in function",
+				"len: Built-in function",
+				"math: Built-in module",
+				"substring: Built-in function",
+				"toLower: Built-in function",
+				"toUpper: Built-in function",
+				"trim: Built-in function",
+				"type: Built-in function",
+			},
+		},
+		{name: "Property", line: 9, character: 2},
+	}
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := s.Request(t, 1+i, "textDocument/completion", map[string]any{
+				"textDocument": map[string]any{"uri": uri},
+				"position":     map[string]any{"line": test.line, "character": test.character},
+			})
+			if resp.Error != nil {
+				t.Fatalf("textDocument/completion returned error: %+v", resp.Error)
+			}
+			var result *protocol.CompletionItemSliceOrCompletionList
+			if err := json.Unmarshal(resp.Result, &result); err != nil {
+				t.Fatal(err)
+			}
+			if test.want == nil {
+				if result != nil && result.Value != nil {
+					t.Errorf("textDocument/completion returned %+v, want null", result.Value)
+				}
+				return
+			}
+			items, ok := result.Value.(protocol.CompletionItemSlice)
+			if !ok {
+				t.Fatalf("textDocument/completion returned %T, want CompletionItemSlice", result.Value)
+			}
+			var got []string
+			keywords := 0
+			for _, item := range items {
+				if item.Kind == protocol.CompletionItemKindKeyword {
+					keywords++
+					continue
+				}
+				got = append(got, fmt.Sprintf("%s: %s", item.Label, item.Detail))
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("incorrect completion items (-want +got):\n%s", diff)
+			}
+			if want := len(token.Keywords()); keywords != want {
+				t.Errorf("textDocument/completion returned %d keywords, want %d", keywords, want)
+			}
+		})
+	}
+}
+
+func TestSignatureHelp(t *testing.T) {
+	const uri = "file:///test.lox"
+	s := startServer(t)
+	s.Initialize(t, nil)
+
+	s.Notify(t, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": "" +
+			"// Adds two numbers.\n" +
+			"fun add(a, b) {\n" +
+			"    return a + b;\n" +
+			"}\n" +
+			"class P {\n" +
+			"    init(x, y) {}\n" +
+			"}\n" +
+			"print add(1, add(2, 3));\n" +
+			"print P(1, ",
+		},
+	})
+	s.WaitForNotification(t, "textDocument/publishDiagnostics")
+
+	tests := []struct {
+		name      string
+		line      int
+		character int
+		want      string // The signature, active parameter, and documentation. Empty if the result should be null.
+	}{
+		{name: "FirstArgument", line: 7, character: 10, want: "add(a, b) 0 Adds two numbers."},
+		{name: "SecondArgument", line: 7, character: 13, want: "add(a, b) 1 Adds two numbers."},
+		{name: "NestedCall", line: 7, character: 20, want: "add(a, b) 1 Adds two numbers."},
+		{name: "UnclosedConstructorCall", line: 8, character: 11, want: "P(x, y) 1"},
+		{name: "OutsideCall", line: 7, character: 23},
+		{name: "Declaration", line: 1, character: 8},
+	}
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := s.Request(t, 1+i, "textDocument/signatureHelp", map[string]any{
+				"textDocument": map[string]any{"uri": uri},
+				"position":     map[string]any{"line": test.line, "character": test.character},
+			})
+			if resp.Error != nil {
+				t.Fatalf("textDocument/signatureHelp returned error: %+v", resp.Error)
+			}
+			var help *protocol.SignatureHelp
+			if err := json.Unmarshal(resp.Result, &help); err != nil {
+				t.Fatal(err)
+			}
+			got := ""
+			if help != nil {
+				if len(help.Signatures) != 1 {
+					t.Fatalf("textDocument/signatureHelp returned %d signatures, want 1", len(help.Signatures))
+				}
+				signature := help.Signatures[0]
+				got = fmt.Sprintf("%s %d", signature.Label, help.ActiveParameter)
+				if signature.Documentation != nil {
+					got += " " + signature.Documentation.Value
+				}
+			}
+			if got != test.want {
+				t.Errorf("textDocument/signatureHelp returned %q, want %q", got, test.want)
+			}
+		})
+	}
+}
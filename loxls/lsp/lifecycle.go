@@ -1,19 +1,44 @@
 package lsp
 
 import (
-	"os"
-
+	"github.com/marcuscaisey/lox/loxls/jsonrpc"
 	"github.com/marcuscaisey/lox/loxls/lsp/protocol"
 )
 
 // https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#initialize
 func (h *Handler) initialize(params *protocol.InitializeParams) (*protocol.InitializeResult, error) {
+	if err := h.settings.Update(params.InitializationOptions); err != nil {
+		return nil, jsonrpc.NewError(jsonrpc.InvalidParams, "Invalid initializationOptions", map[string]any{"error": err.Error()})
+	}
+
 	h.initialized = true
 
+	if params.WorkspaceFoldersInitializeParams != nil && len(params.WorkspaceFolders) > 0 {
+		for _, folder := range params.WorkspaceFolders {
+			h.addWorkspaceRoot(folder.Uri)
+		}
+	} else if params.RootUri != "" {
+		h.addWorkspaceRoot(params.RootUri)
+	}
+
+	if workspace := params.Capabilities.Workspace; workspace != nil {
+		if watchedFiles := workspace.DidChangeWatchedFiles; watchedFiles != nil {
+			h.clientSupportsWatchedFilesRegistration = watchedFiles.DynamicRegistration
+		}
+	}
 	if textDocument := params.Capabilities.TextDocument; textDocument != nil {
 		if documentSymbol := textDocument.DocumentSymbol; documentSymbol != nil {
 			h.clientSupportsHierarchicalDocumentSymbols = documentSymbol.HierarchicalDocumentSymbolSupport
 		}
+		if rename := textDocument.Rename; rename != nil {
+			h.clientSupportsPrepareRename = rename.PrepareSupport
+		}
+	}
+
+	// The server can only say that it supports textDocument/prepareRename if the client does.
+	var renameProvider protocol.BooleanOrRenameOptionsValue = protocol.Boolean(true)
+	if h.clientSupportsPrepareRename {
+		renameProvider = &protocol.RenameOptions{PrepareProvider: true}
 	}
 
 	return &protocol.InitializeResult{
@@ -28,12 +53,54 @@ func (h *Handler) initialize(params *protocol.InitializeParams) (*protocol.Initi
 			DefinitionProvider: &protocol.BooleanOrDefinitionOptions{
 				Value: protocol.Boolean(true),
 			},
+			CompletionProvider: &protocol.CompletionOptions{},
+			SignatureHelpProvider: &protocol.SignatureHelpOptions{
+				TriggerCharacters: []string{"(", ","},
+			},
+			HoverProvider: &protocol.BooleanOrHoverOptions{
+				Value: protocol.Boolean(true),
+			},
+			ReferencesProvider: &protocol.BooleanOrReferenceOptions{
+				Value: protocol.Boolean(true),
+			},
+			RenameProvider: &protocol.BooleanOrRenameOptions{
+				Value: renameProvider,
+			},
 			DocumentSymbolProvider: &protocol.BooleanOrDocumentSymbolOptions{
 				Value: protocol.Boolean(true),
 			},
+			FoldingRangeProvider: &protocol.BooleanOrFoldingRangeOptionsOrFoldingRangeRegistrationOptions{
+				Value: protocol.Boolean(true),
+			},
+			SemanticTokensProvider: &protocol.SemanticTokensOptionsOrSemanticTokensRegistrationOptions{
+				Value: &protocol.SemanticTokensOptions{
+					Legend: &protocol.SemanticTokensLegend{
+						TokenTypes:     stringSlice(semanticTokenTypes),
+						TokenModifiers: stringSlice(semanticTokenModifiers),
+					},
+					Range: &protocol.BooleanOrSemanticTokensOptionsRangeOr2{Value: protocol.Boolean(true)},
+					Full:  &protocol.BooleanOrSemanticTokensOptionsFullOr2{Value: protocol.Boolean(true)},
+				},
+			},
 			DocumentFormattingProvider: &protocol.BooleanOrDocumentFormattingOptions{
 				Value: protocol.Boolean(true),
 			},
+			DocumentRangeFormattingProvider: &protocol.BooleanOrDocumentRangeFormattingOptions{
+				Value: protocol.Boolean(true),
+			},
+			CodeActionProvider: &protocol.BooleanOrCodeActionOptions{
+				Value: &protocol.CodeActionOptions{
+					CodeActionKinds: []protocol.CodeActionKind{protocol.CodeActionKindQuickFix},
+				},
+			},
+			CodeLensProvider: &protocol.CodeLensOptions{
+				ResolveProvider: true,
+			},
+			WorkspaceSymbolProvider: &protocol.BooleanOrWorkspaceSymbolOptions{
+				Value: &protocol.WorkspaceSymbolOptions{
+					WorkDoneProgressOptions: &protocol.WorkDoneProgressOptions{WorkDoneProgress: true},
+				},
+			},
 		},
 		ServerInfo: &protocol.InitializeResultServerInfo{
 			Name:    "loxls",
@@ -43,8 +110,15 @@ func (h *Handler) initialize(params *protocol.InitializeParams) (*protocol.Initi
 }
 
 // https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#shutdown
+//
+// In-flight requests are cancelled on shutdown. This happens in [Handler.Preempt] as soon as the shutdown request is
+// received, so by the time that it's handled, they've already finished.
 func (h *Handler) shutdown() (any, error) {
 	h.shuttingDown = true
+	for uri := range h.pendingChangesByURI {
+		h.discardPendingChange(uri)
+	}
+	h.docsByURI = map[string]*document{}
 	return nil, nil
 }
 
@@ -54,6 +128,24 @@ func (h *Handler) exit() error {
 	if !h.shuttingDown {
 		code = 1
 	}
-	os.Exit(code)
+	h.osExit(code)
 	return nil
 }
+
+func (h *Handler) addWorkspaceRoot(uri string) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		h.log.Errorf("Ignoring workspace root %s: %s", uri, err)
+		return
+	}
+	h.workspaceRoots = append(h.workspaceRoots, path)
+}
+
+// stringSlice converts a slice of values with an underlying string type to a []string.
+func stringSlice[S ~[]E, E ~string](s S) []string {
+	strs := make([]string, len(s))
+	for i, v := range s {
+		strs[i] = string(v)
+	}
+	return strs
+}
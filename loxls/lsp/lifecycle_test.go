@@ -0,0 +1,60 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/marcuscaisey/lox/loxls/jsonrpc"
+	"github.com/marcuscaisey/lox/loxls/lsp/protocol"
+)
+
+func TestShutdownThenExit(t *testing.T) {
+	s := startServer(t)
+
+	s.Initialize(t, nil)
+	if resp := s.Request(t, 1, "shutdown", nil); resp.Error != nil {
+		t.Fatalf("shutdown returned error: %+v", resp.Error)
+	}
+
+	resp := s.Request(t, 2, "textDocument/documentSymbol", map[string]any{"textDocument": map[string]any{"uri": "file:///a.lox"}})
+	if resp.Error == nil || resp.Error.Code != jsonrpc.InvalidRequest {
+		t.Errorf("request after shutdown returned error %+v, want code %d", resp.Error, jsonrpc.InvalidRequest)
+	}
+
+	s.Notify(t, "exit", nil)
+	if code := s.ExitCode(t); code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+}
+
+func TestShutdownCancelsInFlightRequests(t *testing.T) {
+	// There are enough files that the server blocks writing progress reports whilst the shutdown request is being sent,
+	// so the scan can't finish before it's cancelled.
+	s := startWorkspaceServer(t, 500, WithMinFilesForProgress(1))
+	s.write(t, newTestMessage("workspace/symbol", map[string]any{"query": "", "workDoneToken": "scan"}, map[string]any{"id": 1}))
+	s.WaitForNotification(t, "$/progress")
+	s.write(t, newTestMessage("shutdown", nil, map[string]any{"id": 2}))
+
+	resp := s.Response(t, 1)
+	if resp.Error == nil || resp.Error.Code != jsonrpc.ErrorCode(protocol.LSPErrorCodesRequestCancelled) {
+		t.Errorf("in-flight workspace/symbol response error = %+v, want RequestCancelled error", resp.Error)
+	}
+	if resp := s.Response(t, 2); resp.Error != nil {
+		t.Fatalf("shutdown returned error: %+v", resp.Error)
+	}
+
+	s.Notify(t, "exit", nil)
+	if code := s.ExitCode(t); code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	s := startServer(t)
+
+	s.Initialize(t, nil)
+
+	s.Notify(t, "exit", nil)
+	if code := s.ExitCode(t); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+}
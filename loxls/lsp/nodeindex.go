@@ -0,0 +1,67 @@
+package lsp
+
+import (
+	"sort"
+
+	"github.com/marcuscaisey/lox/lox/ast"
+	"github.com/marcuscaisey/lox/lox/token"
+)
+
+// nodeIndex is an index of the nodes in a program which can find the innermost node containing a position without
+// walking the whole tree. It takes O(log n + d) time, where n is the number of nodes and d is the depth of the tree.
+type nodeIndex struct {
+	// nodes is sorted by start position. Since nodes are added in pre-order, a node always comes after its ancestors.
+	nodes []indexedNode
+}
+
+type indexedNode struct {
+	node   ast.Node
+	parent int // Index of the parent node or -1 if this is the root.
+}
+
+// newNodeIndex returns an index of the nodes in a program.
+func newNodeIndex(program ast.Program) *nodeIndex {
+	idx := &nodeIndex{}
+	idx.add(program, -1)
+	return idx
+}
+
+func (idx *nodeIndex) add(node ast.Node, parent int) {
+	i := len(idx.nodes)
+	idx.nodes = append(idx.nodes, indexedNode{node: node, parent: parent})
+	isNode := true
+	ast.Walk(node, func(child ast.Node) bool {
+		if isNode {
+			// The first node visited is node itself.
+			isNode = false
+			return true
+		}
+		idx.add(child, i)
+		return false
+	})
+}
+
+// InnermostNode returns the innermost node which contains the given position, or nil if there isn't one.
+func (idx *nodeIndex) InnermostNode(pos token.Position) ast.Node {
+	node, _ := idx.InnermostNodeAndParent(pos)
+	return node
+}
+
+// InnermostNodeAndParent returns the innermost node which contains the given position and its parent. The node is nil
+// if there isn't one and the parent is nil if the node is the root.
+func (idx *nodeIndex) InnermostNodeAndParent(pos token.Position) (ast.Node, ast.Node) {
+	// Every node containing pos starts at or before it. Of those nodes, the innermost one is the last to start, so it's
+	// either the last node which starts at or before pos or one of its ancestors.
+	i := sort.Search(len(idx.nodes), func(i int) bool {
+		return idx.nodes[i].node.Start().Compare(pos) > 0
+	}) - 1
+	for ; i >= 0; i = idx.nodes[i].parent {
+		if posInRange(pos, idx.nodes[i].node) {
+			if parent := idx.nodes[i].parent; parent >= 0 {
+				return idx.nodes[i].node, idx.nodes[parent].node
+			}
+			return idx.nodes[i].node, nil
+		}
+	}
+	return nil, nil
+}
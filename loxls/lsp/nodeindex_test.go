@@ -0,0 +1,127 @@
+package lsp
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcuscaisey/lox/lox/ast"
+	"github.com/marcuscaisey/lox/lox/parser"
+	"github.com/marcuscaisey/lox/lox/token"
+)
+
+const testdataDir = "../../test/testdata"
+
+// TestInnermostNode checks that the index finds the same node as walking the whole tree at every position in each
+// testdata program.
+func TestInnermostNode(t *testing.T) {
+	err := filepath.WalkDir(testdataDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".lox" {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		file := token.NewFile(path, src)
+		// Programs with syntax errors are included, since the server still indexes them.
+		program, _ := parser.ParseFile(file, parser.WithComments())
+
+		t.Run(strings.TrimPrefix(path, testdataDir+"/"), func(t *testing.T) {
+			idx := newNodeIndex(program)
+			for line := 1; line <= file.NumLines(); line++ {
+				for col := 0; col <= len(file.Line(line)); col++ {
+					pos := token.Position{File: file, Line: line, Column: col}
+					got := describeNode(idx.InnermostNode(pos))
+					want := describeNode(walkInnermostNode(program, pos))
+					if got != want {
+						t.Errorf("InnermostNode(%d:%d) = %s, want %s", line, col, got, want)
+					}
+				}
+			}
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// walkInnermostNode finds the innermost node which contains pos by walking the whole tree.
+func walkInnermostNode(program ast.Program, pos token.Position) ast.Node {
+	var innermost ast.Node
+	ast.Walk(program, func(n ast.Node) bool {
+		if posInRange(pos, n) {
+			innermost = n
+		}
+		return true
+	})
+	return innermost
+}
+
+func describeNode(node ast.Node) string {
+	if node == nil {
+		return "<nil>"
+	}
+	start, end := node.Start(), node.End()
+	return fmt.Sprintf("%T %d:%d-%d:%d", node, start.Line, start.Column, end.Line, end.Column)
+}
+
+const benchmarkSnippet = `class Counter {
+  init(start) {
+    this.count = start;
+  }
+
+  increment() {
+    this.count = this.count + 1;
+    return this.count;
+  }
+}
+
+fun countTo(n) {
+  var counter = Counter(0);
+  while (counter.count < n) {
+    print counter.increment();
+  }
+}
+
+`
+
+func BenchmarkInnermostNode(b *testing.B) {
+	file := token.NewFile("bench.lox", []byte(strings.Repeat(benchmarkSnippet, 1000)))
+	program, err := parser.ParseFile(file)
+	if err != nil {
+		b.Fatal(err)
+	}
+	// Look up the position of the n in "counter.count < n" in every copy of the snippet.
+	var positions []token.Position
+	for line := 1; line <= file.NumLines(); line++ {
+		if col := strings.Index(string(file.Line(line)), "< n"); col != -1 {
+			positions = append(positions, token.Position{File: file, Line: line, Column: col + 2})
+		}
+	}
+
+	b.Run("Index", func(b *testing.B) {
+		idx := newNodeIndex(program)
+		b.ResetTimer()
+		for i := range b.N {
+			idx.InnermostNode(positions[i%len(positions)])
+		}
+	})
+	b.Run("Walk", func(b *testing.B) {
+		for i := range b.N {
+			walkInnermostNode(program, positions[i%len(positions)])
+		}
+	})
+	b.Run("BuildIndex", func(b *testing.B) {
+		for range b.N {
+			newNodeIndex(program)
+		}
+	})
+}
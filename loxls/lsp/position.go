@@ -0,0 +1,58 @@
+package lsp
+
+import (
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/marcuscaisey/lox/lox/token"
+	"github.com/marcuscaisey/lox/loxls/lsp/protocol"
+)
+
+// This file converts between positions in the two coordinate systems which are used by the server:
+//   - [token.Position] has a 1-based line number and a column which is a byte offset into the line.
+//   - [protocol.Position] has a 0-based line number and a character which is an offset into the line in UTF-16 code
+//     units, as the server advertises the UTF-16 position encoding.
+//
+// All positions sent to or received from the client should be converted using these functions.
+
+// newPosition converts a [token.Position] to a [protocol.Position].
+func newPosition(pos token.Position) *protocol.Position {
+	return &protocol.Position{
+		Line:      pos.Line - 1,
+		Character: pos.ColumnUTF16(),
+	}
+}
+
+// newRange creates a [protocol.Range] from a pair of [token.Position].
+func newRange(start, end token.Position) *protocol.Range {
+	return &protocol.Range{
+		Start: newPosition(start),
+		End:   newPosition(end),
+	}
+}
+
+// newTokenPosition converts a [protocol.Position] in the given file to a [token.Position].
+// As required by the specification, a character which is past the end of the line is treated as the end of the line.
+// A character which is in the middle of a UTF-16 surrogate pair is treated as the start of the pair. An error is
+// returned if the line doesn't exist in the file.
+func newTokenPosition(pos *protocol.Position, file *token.File) (token.Position, error) {
+	if pos.Line < 0 || pos.Line >= file.NumLines() {
+		return token.Position{}, fmt.Errorf("line %d is out of range, file has %d lines", pos.Line, file.NumLines())
+	}
+	line := file.Line(pos.Line + 1)
+	col := 0
+	for charsLeft := pos.Character; col < len(line); {
+		r, size := utf8.DecodeRune(line[col:])
+		if charsLeft -= utf16.RuneLen(r); charsLeft < 0 {
+			break
+		}
+		col += size
+	}
+	return token.Position{File: file, Line: pos.Line + 1, Column: col}, nil
+}
+
+// posInRange reports whether a [token.Position] is contained within a [token.Range].
+func posInRange(pos token.Position, rang token.Range) bool {
+	return pos.Compare(rang.Start()) >= 0 && pos.Compare(rang.End()) < 0
+}
@@ -0,0 +1,85 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/marcuscaisey/lox/lox/token"
+	"github.com/marcuscaisey/lox/loxls/lsp/protocol"
+)
+
+// The lines of positionTestFile are:
+//   - ASCII characters only, which are 1 byte and 1 UTF-16 code unit each.
+//   - é (2 bytes) and € (3 bytes), which are 1 UTF-16 code unit each.
+//   - 😀 (4 bytes), which is a surrogate pair of 2 UTF-16 code units.
+const positionTestFile = "var x = 1;\n" +
+	"var é = \"€\";\n" +
+	"var s = \"😀\";\n"
+
+func TestPositionConversion(t *testing.T) {
+	file := token.NewFile("test.lox", []byte(positionTestFile))
+	tests := []struct {
+		name     string
+		tokenPos token.Position
+		lspPos   *protocol.Position
+	}{
+		{name: "StartOfFile", tokenPos: token.Position{Line: 1, Column: 0}, lspPos: &protocol.Position{Line: 0, Character: 0}},
+		{name: "ASCII", tokenPos: token.Position{Line: 1, Column: 4}, lspPos: &protocol.Position{Line: 0, Character: 4}},
+		{name: "EndOfASCIILine", tokenPos: token.Position{Line: 1, Column: 10}, lspPos: &protocol.Position{Line: 0, Character: 10}},
+		{name: "BeforeTwoByteRune", tokenPos: token.Position{Line: 2, Column: 4}, lspPos: &protocol.Position{Line: 1, Character: 4}},
+		{name: "AfterTwoByteRune", tokenPos: token.Position{Line: 2, Column: 6}, lspPos: &protocol.Position{Line: 1, Character: 5}},
+		{name: "AfterThreeByteRune", tokenPos: token.Position{Line: 2, Column: 13}, lspPos: &protocol.Position{Line: 1, Character: 10}},
+		{name: "BeforeSurrogatePair", tokenPos: token.Position{Line: 3, Column: 9}, lspPos: &protocol.Position{Line: 2, Character: 9}},
+		{name: "AfterSurrogatePair", tokenPos: token.Position{Line: 3, Column: 13}, lspPos: &protocol.Position{Line: 2, Character: 11}},
+		{name: "EndOfSurrogatePairLine", tokenPos: token.Position{Line: 3, Column: 15}, lspPos: &protocol.Position{Line: 2, Character: 13}},
+		{name: "EmptyLastLine", tokenPos: token.Position{Line: 4, Column: 0}, lspPos: &protocol.Position{Line: 3, Character: 0}},
+	}
+	for _, test := range tests {
+		test.tokenPos.File = file
+		t.Run(test.name, func(t *testing.T) {
+			if got := newPosition(test.tokenPos); *got != *test.lspPos {
+				t.Errorf("newPosition(%+v) = %+v, want %+v", test.tokenPos, got, test.lspPos)
+			}
+
+			got, err := newTokenPosition(test.lspPos, file)
+			if err != nil {
+				t.Fatalf("newTokenPosition(%+v) returned error: %s", test.lspPos, err)
+			}
+			if got != test.tokenPos {
+				t.Errorf("newTokenPosition(%+v) = %+v, want %+v", test.lspPos, got, test.tokenPos)
+			}
+		})
+	}
+}
+
+func TestNewTokenPositionAdjustsCharacter(t *testing.T) {
+	file := token.NewFile("test.lox", []byte(positionTestFile))
+	tests := []struct {
+		name   string
+		lspPos *protocol.Position
+		want   token.Position
+	}{
+		{name: "PastEndOfLine", lspPos: &protocol.Position{Line: 0, Character: 100}, want: token.Position{Line: 1, Column: 10}},
+		{name: "MiddleOfSurrogatePair", lspPos: &protocol.Position{Line: 2, Character: 10}, want: token.Position{Line: 3, Column: 9}},
+	}
+	for _, test := range tests {
+		test.want.File = file
+		t.Run(test.name, func(t *testing.T) {
+			got, err := newTokenPosition(test.lspPos, file)
+			if err != nil {
+				t.Fatalf("newTokenPosition(%+v) returned error: %s", test.lspPos, err)
+			}
+			if got != test.want {
+				t.Errorf("newTokenPosition(%+v) = %+v, want %+v", test.lspPos, got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewTokenPositionLineOutOfRange(t *testing.T) {
+	file := token.NewFile("test.lox", []byte(positionTestFile))
+	for _, line := range []int{-1, 4} {
+		if got, err := newTokenPosition(&protocol.Position{Line: line}, file); err == nil {
+			t.Errorf("newTokenPosition(line %d) = %+v, want error", line, got)
+		}
+	}
+}
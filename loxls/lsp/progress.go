@@ -0,0 +1,172 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/marcuscaisey/lox/loxls/lsp/protocol"
+)
+
+// workDoneProgressBegin is the value sent in a $/progress notification to start progress reporting.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workDoneProgressBegin
+type workDoneProgressBegin struct {
+	Kind        string `json:"kind"`
+	Title       string `json:"title"`
+	Cancellable bool   `json:"cancellable,omitempty"`
+	Message     string `json:"message,omitempty"`
+	Percentage  int    `json:"percentage"`
+}
+
+// workDoneProgressReport is the value sent in a $/progress notification to report progress.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workDoneProgressReport
+type workDoneProgressReport struct {
+	Kind       string `json:"kind"`
+	Message    string `json:"message,omitempty"`
+	Percentage int    `json:"percentage"`
+}
+
+// workDoneProgressEnd is the value sent in a $/progress notification to end progress reporting.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workDoneProgressEnd
+type workDoneProgressEnd struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message,omitempty"`
+}
+
+// workDoneProgress reports the progress of a long-running request to the client using the work done progress token that
+// was provided with the request.
+// A nil *workDoneProgress is valid and reports nothing, which allows handlers to report progress unconditionally.
+type workDoneProgress struct {
+	client    *client
+	tracker   *progressTracker
+	token     protocol.ProgressToken
+	key       string
+	cancelled atomic.Bool
+}
+
+// Begin starts progress reporting with the given title. The client will be shown a button which can be used to cancel
+// the request.
+func (p *workDoneProgress) Begin(title string) error {
+	if p == nil {
+		return nil
+	}
+	p.tracker.add(p)
+	return p.notify(&workDoneProgressBegin{Kind: "begin", Title: title, Cancellable: true})
+}
+
+// Report reports the percentage of the work which has been completed along with an optional message.
+func (p *workDoneProgress) Report(percentage int, message string) error {
+	if p == nil {
+		return nil
+	}
+	return p.notify(&workDoneProgressReport{Kind: "report", Message: message, Percentage: percentage})
+}
+
+// End ends progress reporting.
+func (p *workDoneProgress) End() error {
+	if p == nil {
+		return nil
+	}
+	p.tracker.remove(p)
+	return p.notify(&workDoneProgressEnd{Kind: "end"})
+}
+
+// Cancelled reports whether the client has cancelled the request.
+func (p *workDoneProgress) Cancelled() bool {
+	return p != nil && p.cancelled.Load()
+}
+
+func (p *workDoneProgress) notify(value any) error {
+	lspAny, err := toLSPAny(value)
+	if err != nil {
+		return fmt.Errorf("reporting progress: %s", err)
+	}
+	return p.client.Progress(&protocol.ProgressParams{Token: p.token, Value: lspAny})
+}
+
+// progressTracker keeps track of the progress which is currently being reported so that it can be cancelled by the
+// client. It's safe for concurrent use.
+type progressTracker struct {
+	client *client
+
+	mu            sync.Mutex
+	progressByKey map[string]*workDoneProgress
+}
+
+func newProgressTracker(client *client) *progressTracker {
+	return &progressTracker{
+		client:        client,
+		progressByKey: map[string]*workDoneProgress{},
+	}
+}
+
+// New returns a *workDoneProgress which reports progress using the given token. If the token is nil, then nil is
+// returned.
+func (t *progressTracker) New(token protocol.ProgressToken) *workDoneProgress {
+	if token == nil || token.Value == nil {
+		return nil
+	}
+	return &workDoneProgress{
+		client:  t.client,
+		tracker: t,
+		token:   token,
+		key:     progressTokenKey(token),
+	}
+}
+
+// Cancel marks the progress with the given token as cancelled.
+func (t *progressTracker) Cancel(token protocol.ProgressToken) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if p, ok := t.progressByKey[progressTokenKey(token)]; ok {
+		p.cancelled.Store(true)
+	}
+}
+
+func (t *progressTracker) add(p *workDoneProgress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.progressByKey[p.key] = p
+}
+
+func (t *progressTracker) remove(p *workDoneProgress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.progressByKey, p.key)
+}
+
+// progressTokenKey returns a key which uniquely identifies a progress token. The JSON encoding is used so that the
+// integer 1 and the string "1" are different keys.
+func progressTokenKey(token protocol.ProgressToken) string {
+	if token == nil {
+		return ""
+	}
+	data, err := json.Marshal(token)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// toLSPAny converts a value to a [protocol.LSPAny] by round-tripping it through JSON.
+func toLSPAny(v any) (protocol.LSPAny, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var lspAny protocol.LSPAny
+	if err := json.Unmarshal(data, &lspAny); err != nil {
+		return nil, err
+	}
+	return lspAny, nil
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#window_workDoneProgress_cancel
+func (h *Handler) windowWorkDoneProgressCancel(params *protocol.WorkDoneProgressCancelParams) error {
+	h.progress.Cancel(params.Token)
+	return nil
+}
@@ -10,7 +10,23 @@ package protocol
 //typegen:method textDocument/didChange
 //typegen:method textDocument/didClose
 //typegen:method textDocument/definition
+//typegen:method textDocument/hover
+//typegen:method textDocument/completion
+//typegen:method textDocument/references
+//typegen:method textDocument/prepareRename
+//typegen:method textDocument/rename
 //typegen:method textDocument/documentSymbol
+//typegen:method textDocument/foldingRange
+//typegen:method textDocument/semanticTokens/full
+//typegen:method textDocument/semanticTokens/range
 //typegen:method textDocument/publishDiagnostics
 //typegen:method textDocument/formatting
+//typegen:method textDocument/rangeFormatting
+//typegen:method textDocument/codeAction
+//typegen:method textDocument/codeLens
+//typegen:method codeLens/resolve
 //typegen:method window/logMessage
+//typegen:method workspace/didChangeConfiguration
+//typegen:method workspace/symbol
+//typegen:method $/progress
+//typegen:method window/workDoneProgress/cancel
@@ -33,6 +33,32 @@ type IntegerOrStringValue interface {
 func (Integer) isIntegerOrStringValue() {}
 func (String) isIntegerOrStringValue()  {}
 
+// AsInteger returns the value of i and true if it's a [Integer], otherwise it returns the
+// zero value and false.
+func (i IntegerOrString) AsInteger() (Integer, bool) {
+	value, ok := i.Value.(Integer)
+	return value, ok
+}
+
+// IsInteger reports whether the value of i is a [Integer].
+func (i IntegerOrString) IsInteger() bool {
+	_, ok := i.Value.(Integer)
+	return ok
+}
+
+// AsString returns the value of i and true if it's a [String], otherwise it returns the
+// zero value and false.
+func (i IntegerOrString) AsString() (String, bool) {
+	value, ok := i.Value.(String)
+	return value, ok
+}
+
+// IsString reports whether the value of i is a [String].
+func (i IntegerOrString) IsString() bool {
+	_, ok := i.Value.(String)
+	return ok
+}
+
 func (i *IntegerOrString) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -85,6 +111,11 @@ const (
 	ResourceOperationKindDelete ResourceOperationKind = "delete"
 )
 
+// String returns the value of r as it appears in the specification.
+func (r ResourceOperationKind) String() string {
+	return string(r)
+}
+
 var validResourceOperationKindValues = map[string]bool{
 	"create": true,
 	"rename": true,
@@ -135,6 +166,11 @@ const (
 	FailureHandlingKindUndo FailureHandlingKind = "undo"
 )
 
+// String returns the value of f as it appears in the specification.
+func (f FailureHandlingKind) String() string {
+	return string(f)
+}
+
 var validFailureHandlingKindValues = map[string]bool{
 	"abort":                 true,
 	"transactional":         true,
@@ -256,6 +292,66 @@ const (
 	SymbolKindTypeParameter SymbolKind = 26
 )
 
+// String returns the name of the constant which s is equal to.
+func (s SymbolKind) String() string {
+	switch s {
+	case SymbolKindFile:
+		return "SymbolKindFile"
+	case SymbolKindModule:
+		return "SymbolKindModule"
+	case SymbolKindNamespace:
+		return "SymbolKindNamespace"
+	case SymbolKindPackage:
+		return "SymbolKindPackage"
+	case SymbolKindClass:
+		return "SymbolKindClass"
+	case SymbolKindMethod:
+		return "SymbolKindMethod"
+	case SymbolKindProperty:
+		return "SymbolKindProperty"
+	case SymbolKindField:
+		return "SymbolKindField"
+	case SymbolKindConstructor:
+		return "SymbolKindConstructor"
+	case SymbolKindEnum:
+		return "SymbolKindEnum"
+	case SymbolKindInterface:
+		return "SymbolKindInterface"
+	case SymbolKindFunction:
+		return "SymbolKindFunction"
+	case SymbolKindVariable:
+		return "SymbolKindVariable"
+	case SymbolKindConstant:
+		return "SymbolKindConstant"
+	case SymbolKindString:
+		return "SymbolKindString"
+	case SymbolKindNumber:
+		return "SymbolKindNumber"
+	case SymbolKindBoolean:
+		return "SymbolKindBoolean"
+	case SymbolKindArray:
+		return "SymbolKindArray"
+	case SymbolKindObject:
+		return "SymbolKindObject"
+	case SymbolKindKey:
+		return "SymbolKindKey"
+	case SymbolKindNull:
+		return "SymbolKindNull"
+	case SymbolKindEnumMember:
+		return "SymbolKindEnumMember"
+	case SymbolKindStruct:
+		return "SymbolKindStruct"
+	case SymbolKindEvent:
+		return "SymbolKindEvent"
+	case SymbolKindOperator:
+		return "SymbolKindOperator"
+	case SymbolKindTypeParameter:
+		return "SymbolKindTypeParameter"
+	default:
+		return fmt.Sprintf("SymbolKind(%d)", uint32(s))
+	}
+}
+
 var validSymbolKindValues = map[uint32]bool{
 	1:  true,
 	2:  true,
@@ -334,6 +430,16 @@ const (
 	SymbolTagDeprecated SymbolTag = 1
 )
 
+// String returns the name of the constant which s is equal to.
+func (s SymbolTag) String() string {
+	switch s {
+	case SymbolTagDeprecated:
+		return "SymbolTagDeprecated"
+	default:
+		return fmt.Sprintf("SymbolTag(%d)", uint32(s))
+	}
+}
+
 var validSymbolTagValues = map[uint32]bool{
 	1: true,
 }
@@ -616,6 +722,11 @@ const (
 	MarkupKindMarkdown MarkupKind = "markdown"
 )
 
+// String returns the value of m as it appears in the specification.
+func (m MarkupKind) String() string {
+	return string(m)
+}
+
 var validMarkupKindValues = map[string]bool{
 	"plaintext": true,
 	"markdown":  true,
@@ -659,6 +770,16 @@ const (
 	CompletionItemTagDeprecated CompletionItemTag = 1
 )
 
+// String returns the name of the constant which c is equal to.
+func (c CompletionItemTag) String() string {
+	switch c {
+	case CompletionItemTagDeprecated:
+		return "CompletionItemTagDeprecated"
+	default:
+		return fmt.Sprintf("CompletionItemTag(%d)", uint32(c))
+	}
+}
+
 var validCompletionItemTagValues = map[uint32]bool{
 	1: true,
 }
@@ -723,6 +844,18 @@ const (
 	InsertTextModeadjustIndentation InsertTextMode = 2
 )
 
+// String returns the name of the constant which i is equal to.
+func (i InsertTextMode) String() string {
+	switch i {
+	case InsertTextModeasIs:
+		return "InsertTextModeasIs"
+	case InsertTextModeadjustIndentation:
+		return "InsertTextModeadjustIndentation"
+	default:
+		return fmt.Sprintf("InsertTextMode(%d)", uint32(i))
+	}
+}
+
 var validInsertTextModeValues = map[uint32]bool{
 	1: true,
 	2: true,
@@ -838,6 +971,64 @@ const (
 	CompletionItemKindTypeParameter CompletionItemKind = 25
 )
 
+// String returns the name of the constant which c is equal to.
+func (c CompletionItemKind) String() string {
+	switch c {
+	case CompletionItemKindText:
+		return "CompletionItemKindText"
+	case CompletionItemKindMethod:
+		return "CompletionItemKindMethod"
+	case CompletionItemKindFunction:
+		return "CompletionItemKindFunction"
+	case CompletionItemKindConstructor:
+		return "CompletionItemKindConstructor"
+	case CompletionItemKindField:
+		return "CompletionItemKindField"
+	case CompletionItemKindVariable:
+		return "CompletionItemKindVariable"
+	case CompletionItemKindClass:
+		return "CompletionItemKindClass"
+	case CompletionItemKindInterface:
+		return "CompletionItemKindInterface"
+	case CompletionItemKindModule:
+		return "CompletionItemKindModule"
+	case CompletionItemKindProperty:
+		return "CompletionItemKindProperty"
+	case CompletionItemKindUnit:
+		return "CompletionItemKindUnit"
+	case CompletionItemKindValue:
+		return "CompletionItemKindValue"
+	case CompletionItemKindEnum:
+		return "CompletionItemKindEnum"
+	case CompletionItemKindKeyword:
+		return "CompletionItemKindKeyword"
+	case CompletionItemKindSnippet:
+		return "CompletionItemKindSnippet"
+	case CompletionItemKindColor:
+		return "CompletionItemKindColor"
+	case CompletionItemKindFile:
+		return "CompletionItemKindFile"
+	case CompletionItemKindReference:
+		return "CompletionItemKindReference"
+	case CompletionItemKindFolder:
+		return "CompletionItemKindFolder"
+	case CompletionItemKindEnumMember:
+		return "CompletionItemKindEnumMember"
+	case CompletionItemKindConstant:
+		return "CompletionItemKindConstant"
+	case CompletionItemKindStruct:
+		return "CompletionItemKindStruct"
+	case CompletionItemKindEvent:
+		return "CompletionItemKindEvent"
+	case CompletionItemKindOperator:
+		return "CompletionItemKindOperator"
+	case CompletionItemKindTypeParameter:
+		return "CompletionItemKindTypeParameter"
+	default:
+		return fmt.Sprintf("CompletionItemKind(%d)", uint32(c))
+	}
+}
+
 var validCompletionItemKindValues = map[uint32]bool{
 	1:  true,
 	2:  true,
@@ -1156,6 +1347,11 @@ const (
 	CodeActionKindSourceFixAll CodeActionKind = "source.fixAll"
 )
 
+// String returns the value of c as it appears in the specification.
+func (c CodeActionKind) String() string {
+	return string(c)
+}
+
 type CodeActionClientCapabilitiesCodeActionLiteralSupportCodeActionKind struct {
 	// The code action kind values the client supports. When this
 	// property exists the client also guarantees that it will
@@ -1282,6 +1478,16 @@ const (
 	PrepareSupportDefaultBehaviorIdentifier PrepareSupportDefaultBehavior = 1
 )
 
+// String returns the name of the constant which p is equal to.
+func (p PrepareSupportDefaultBehavior) String() string {
+	switch p {
+	case PrepareSupportDefaultBehaviorIdentifier:
+		return "PrepareSupportDefaultBehaviorIdentifier"
+	default:
+		return fmt.Sprintf("PrepareSupportDefaultBehavior(%d)", uint32(p))
+	}
+}
+
 var validPrepareSupportDefaultBehaviorValues = map[uint32]bool{
 	1: true,
 }
@@ -1351,6 +1557,11 @@ const (
 	FoldingRangeKindRegion FoldingRangeKind = "region"
 )
 
+// String returns the value of f as it appears in the specification.
+func (f FoldingRangeKind) String() string {
+	return string(f)
+}
+
 type FoldingRangeClientCapabilitiesFoldingRangeKind struct {
 	// The folding range kind values the client supports. When this
 	// property exists the client also guarantees that it will
@@ -1419,6 +1630,18 @@ const (
 	DiagnosticTagDeprecated DiagnosticTag = 2
 )
 
+// String returns the name of the constant which d is equal to.
+func (d DiagnosticTag) String() string {
+	switch d {
+	case DiagnosticTagUnnecessary:
+		return "DiagnosticTagUnnecessary"
+	case DiagnosticTagDeprecated:
+		return "DiagnosticTagDeprecated"
+	default:
+		return fmt.Sprintf("DiagnosticTag(%d)", uint32(d))
+	}
+}
+
 var validDiagnosticTagValues = map[uint32]bool{
 	1: true,
 	2: true,
@@ -1514,6 +1737,19 @@ type BooleanOrSemanticTokensClientCapabilitiesRequestsRangeOr2Value interface {
 }
 
 func (Boolean) isBooleanOrSemanticTokensClientCapabilitiesRequestsRangeOr2Value() {}
+
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrSemanticTokensClientCapabilitiesRequestsRangeOr2) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrSemanticTokensClientCapabilitiesRequestsRangeOr2) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
 func (*SemanticTokensClientCapabilitiesRequestsRangeOr2) isBooleanOrSemanticTokensClientCapabilitiesRequestsRangeOr2Value() {
 }
 
@@ -1564,6 +1800,19 @@ type BooleanOrSemanticTokensClientCapabilitiesRequestsFullOr2Value interface {
 }
 
 func (Boolean) isBooleanOrSemanticTokensClientCapabilitiesRequestsFullOr2Value() {}
+
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrSemanticTokensClientCapabilitiesRequestsFullOr2) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrSemanticTokensClientCapabilitiesRequestsFullOr2) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
 func (*SemanticTokensClientCapabilitiesRequestsFullOr2) isBooleanOrSemanticTokensClientCapabilitiesRequestsFullOr2Value() {
 }
 
@@ -1607,6 +1856,11 @@ const (
 	TokenFormatRelative TokenFormat = "relative"
 )
 
+// String returns the value of t as it appears in the specification.
+func (t TokenFormat) String() string {
+	return string(t)
+}
+
 var validTokenFormatValues = map[string]bool{
 	"relative": true,
 }
@@ -2006,6 +2260,11 @@ const (
 	PositionEncodingKindUTF32 PositionEncodingKind = "utf-32"
 )
 
+// String returns the value of p as it appears in the specification.
+func (p PositionEncodingKind) String() string {
+	return string(p)
+}
+
 // General client capabilities.
 //
 // @since 3.16.0
@@ -2102,6 +2361,97 @@ func (Uinteger) isLSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoole
 func (Decimal) isLSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBooleanValue()   {}
 func (Boolean) isLSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBooleanValue()   {}
 
+// AsLSPObject returns the value of l and true if it's a [LSPObject], otherwise it returns the
+// zero value and false.
+func (l LSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoolean) AsLSPObject() (LSPObject, bool) {
+	value, ok := l.Value.(LSPObject)
+	return value, ok
+}
+
+// IsLSPObject reports whether the value of l is a [LSPObject].
+func (l LSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoolean) IsLSPObject() bool {
+	_, ok := l.Value.(LSPObject)
+	return ok
+}
+
+// AsLSPArray returns the value of l and true if it's a [LSPArray], otherwise it returns the
+// zero value and false.
+func (l LSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoolean) AsLSPArray() (LSPArray, bool) {
+	value, ok := l.Value.(LSPArray)
+	return value, ok
+}
+
+// IsLSPArray reports whether the value of l is a [LSPArray].
+func (l LSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoolean) IsLSPArray() bool {
+	_, ok := l.Value.(LSPArray)
+	return ok
+}
+
+// AsString returns the value of l and true if it's a [String], otherwise it returns the
+// zero value and false.
+func (l LSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoolean) AsString() (String, bool) {
+	value, ok := l.Value.(String)
+	return value, ok
+}
+
+// IsString reports whether the value of l is a [String].
+func (l LSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoolean) IsString() bool {
+	_, ok := l.Value.(String)
+	return ok
+}
+
+// AsInteger returns the value of l and true if it's a [Integer], otherwise it returns the
+// zero value and false.
+func (l LSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoolean) AsInteger() (Integer, bool) {
+	value, ok := l.Value.(Integer)
+	return value, ok
+}
+
+// IsInteger reports whether the value of l is a [Integer].
+func (l LSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoolean) IsInteger() bool {
+	_, ok := l.Value.(Integer)
+	return ok
+}
+
+// AsUinteger returns the value of l and true if it's a [Uinteger], otherwise it returns the
+// zero value and false.
+func (l LSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoolean) AsUinteger() (Uinteger, bool) {
+	value, ok := l.Value.(Uinteger)
+	return value, ok
+}
+
+// IsUinteger reports whether the value of l is a [Uinteger].
+func (l LSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoolean) IsUinteger() bool {
+	_, ok := l.Value.(Uinteger)
+	return ok
+}
+
+// AsDecimal returns the value of l and true if it's a [Decimal], otherwise it returns the
+// zero value and false.
+func (l LSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoolean) AsDecimal() (Decimal, bool) {
+	value, ok := l.Value.(Decimal)
+	return value, ok
+}
+
+// IsDecimal reports whether the value of l is a [Decimal].
+func (l LSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoolean) IsDecimal() bool {
+	_, ok := l.Value.(Decimal)
+	return ok
+}
+
+// AsBoolean returns the value of l and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (l LSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoolean) AsBoolean() (Boolean, bool) {
+	value, ok := l.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of l is a [Boolean].
+func (l LSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoolean) IsBoolean() bool {
+	_, ok := l.Value.(Boolean)
+	return ok
+}
+
 func (l *LSPObjectOrLSPArrayOrStringOrIntegerOrUintegerOrDecimalOrBoolean) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -2195,6 +2545,11 @@ const (
 	TraceValuesVerbose TraceValues = "verbose"
 )
 
+// String returns the value of t as it appears in the specification.
+func (t TraceValues) String() string {
+	return string(t)
+}
+
 var validTraceValuesValues = map[string]bool{
 	"off":      true,
 	"messages": true,
@@ -2316,6 +2671,20 @@ const (
 	TextDocumentSyncKindIncremental TextDocumentSyncKind = 2
 )
 
+// String returns the name of the constant which t is equal to.
+func (t TextDocumentSyncKind) String() string {
+	switch t {
+	case TextDocumentSyncKindNone:
+		return "TextDocumentSyncKindNone"
+	case TextDocumentSyncKindFull:
+		return "TextDocumentSyncKindFull"
+	case TextDocumentSyncKindIncremental:
+		return "TextDocumentSyncKindIncremental"
+	default:
+		return fmt.Sprintf("TextDocumentSyncKind(%d)", uint32(t))
+	}
+}
+
 var validTextDocumentSyncKindValues = map[uint32]bool{
 	0: true,
 	1: true,
@@ -2374,6 +2743,32 @@ type BooleanOrSaveOptionsValue interface {
 func (Boolean) isBooleanOrSaveOptionsValue()      {}
 func (*SaveOptions) isBooleanOrSaveOptionsValue() {}
 
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrSaveOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrSaveOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsSaveOptions returns the value of b and true if it's a [SaveOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrSaveOptions) AsSaveOptions() (*SaveOptions, bool) {
+	value, ok := b.Value.(*SaveOptions)
+	return value, ok
+}
+
+// IsSaveOptions reports whether the value of b is a [SaveOptions].
+func (b BooleanOrSaveOptions) IsSaveOptions() bool {
+	_, ok := b.Value.(*SaveOptions)
+	return ok
+}
+
 func (b *BooleanOrSaveOptions) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -2436,6 +2831,32 @@ type TextDocumentSyncOptionsOrTextDocumentSyncKindValue interface {
 func (*TextDocumentSyncOptions) isTextDocumentSyncOptionsOrTextDocumentSyncKindValue() {}
 func (TextDocumentSyncKind) isTextDocumentSyncOptionsOrTextDocumentSyncKindValue()     {}
 
+// AsTextDocumentSyncOptions returns the value of t and true if it's a [TextDocumentSyncOptions], otherwise it returns the
+// zero value and false.
+func (t TextDocumentSyncOptionsOrTextDocumentSyncKind) AsTextDocumentSyncOptions() (*TextDocumentSyncOptions, bool) {
+	value, ok := t.Value.(*TextDocumentSyncOptions)
+	return value, ok
+}
+
+// IsTextDocumentSyncOptions reports whether the value of t is a [TextDocumentSyncOptions].
+func (t TextDocumentSyncOptionsOrTextDocumentSyncKind) IsTextDocumentSyncOptions() bool {
+	_, ok := t.Value.(*TextDocumentSyncOptions)
+	return ok
+}
+
+// AsTextDocumentSyncKind returns the value of t and true if it's a [TextDocumentSyncKind], otherwise it returns the
+// zero value and false.
+func (t TextDocumentSyncOptionsOrTextDocumentSyncKind) AsTextDocumentSyncKind() (TextDocumentSyncKind, bool) {
+	value, ok := t.Value.(TextDocumentSyncKind)
+	return value, ok
+}
+
+// IsTextDocumentSyncKind reports whether the value of t is a [TextDocumentSyncKind].
+func (t TextDocumentSyncOptionsOrTextDocumentSyncKind) IsTextDocumentSyncKind() bool {
+	_, ok := t.Value.(TextDocumentSyncKind)
+	return ok
+}
+
 func (t *TextDocumentSyncOptionsOrTextDocumentSyncKind) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -2569,6 +2990,32 @@ type StringOrNotebookDocumentFilterValue interface {
 func (String) isStringOrNotebookDocumentFilterValue()                 {}
 func (NotebookDocumentFilter) isStringOrNotebookDocumentFilterValue() {}
 
+// AsString returns the value of s and true if it's a [String], otherwise it returns the
+// zero value and false.
+func (s StringOrNotebookDocumentFilter) AsString() (String, bool) {
+	value, ok := s.Value.(String)
+	return value, ok
+}
+
+// IsString reports whether the value of s is a [String].
+func (s StringOrNotebookDocumentFilter) IsString() bool {
+	_, ok := s.Value.(String)
+	return ok
+}
+
+// AsNotebookDocumentFilter returns the value of s and true if it's a [NotebookDocumentFilter], otherwise it returns the
+// zero value and false.
+func (s StringOrNotebookDocumentFilter) AsNotebookDocumentFilter() (NotebookDocumentFilter, bool) {
+	value, ok := s.Value.(NotebookDocumentFilter)
+	return value, ok
+}
+
+// IsNotebookDocumentFilter reports whether the value of s is a [NotebookDocumentFilter].
+func (s StringOrNotebookDocumentFilter) IsNotebookDocumentFilter() bool {
+	_, ok := s.Value.(NotebookDocumentFilter)
+	return ok
+}
+
 func (s *StringOrNotebookDocumentFilter) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -2825,6 +3272,32 @@ type BooleanOrHoverOptionsValue interface {
 func (Boolean) isBooleanOrHoverOptionsValue()       {}
 func (*HoverOptions) isBooleanOrHoverOptionsValue() {}
 
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrHoverOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrHoverOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsHoverOptions returns the value of b and true if it's a [HoverOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrHoverOptions) AsHoverOptions() (*HoverOptions, bool) {
+	value, ok := b.Value.(*HoverOptions)
+	return value, ok
+}
+
+// IsHoverOptions reports whether the value of b is a [HoverOptions].
+func (b BooleanOrHoverOptions) IsHoverOptions() bool {
+	_, ok := b.Value.(*HoverOptions)
+	return ok
+}
+
 func (b *BooleanOrHoverOptions) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -3009,6 +3482,32 @@ type TextDocumentFilterOrNotebookCellTextDocumentFilterValue interface {
 func (TextDocumentFilter) isTextDocumentFilterOrNotebookCellTextDocumentFilterValue()              {}
 func (*NotebookCellTextDocumentFilter) isTextDocumentFilterOrNotebookCellTextDocumentFilterValue() {}
 
+// AsTextDocumentFilter returns the value of t and true if it's a [TextDocumentFilter], otherwise it returns the
+// zero value and false.
+func (t TextDocumentFilterOrNotebookCellTextDocumentFilter) AsTextDocumentFilter() (TextDocumentFilter, bool) {
+	value, ok := t.Value.(TextDocumentFilter)
+	return value, ok
+}
+
+// IsTextDocumentFilter reports whether the value of t is a [TextDocumentFilter].
+func (t TextDocumentFilterOrNotebookCellTextDocumentFilter) IsTextDocumentFilter() bool {
+	_, ok := t.Value.(TextDocumentFilter)
+	return ok
+}
+
+// AsNotebookCellTextDocumentFilter returns the value of t and true if it's a [NotebookCellTextDocumentFilter], otherwise it returns the
+// zero value and false.
+func (t TextDocumentFilterOrNotebookCellTextDocumentFilter) AsNotebookCellTextDocumentFilter() (*NotebookCellTextDocumentFilter, bool) {
+	value, ok := t.Value.(*NotebookCellTextDocumentFilter)
+	return value, ok
+}
+
+// IsNotebookCellTextDocumentFilter reports whether the value of t is a [NotebookCellTextDocumentFilter].
+func (t TextDocumentFilterOrNotebookCellTextDocumentFilter) IsNotebookCellTextDocumentFilter() bool {
+	_, ok := t.Value.(*NotebookCellTextDocumentFilter)
+	return ok
+}
+
 func (t *TextDocumentFilterOrNotebookCellTextDocumentFilter) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -3086,6 +3585,32 @@ type BooleanOrDeclarationOptionsOrDeclarationRegistrationOptionsValue interface
 
 func (Boolean) isBooleanOrDeclarationOptionsOrDeclarationRegistrationOptionsValue()             {}
 func (*DeclarationOptions) isBooleanOrDeclarationOptionsOrDeclarationRegistrationOptionsValue() {}
+
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrDeclarationOptionsOrDeclarationRegistrationOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrDeclarationOptionsOrDeclarationRegistrationOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsDeclarationOptions returns the value of b and true if it's a [DeclarationOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrDeclarationOptionsOrDeclarationRegistrationOptions) AsDeclarationOptions() (*DeclarationOptions, bool) {
+	value, ok := b.Value.(*DeclarationOptions)
+	return value, ok
+}
+
+// IsDeclarationOptions reports whether the value of b is a [DeclarationOptions].
+func (b BooleanOrDeclarationOptionsOrDeclarationRegistrationOptions) IsDeclarationOptions() bool {
+	_, ok := b.Value.(*DeclarationOptions)
+	return ok
+}
 func (*DeclarationRegistrationOptions) isBooleanOrDeclarationOptionsOrDeclarationRegistrationOptionsValue() {
 }
 
@@ -3144,6 +3669,32 @@ type BooleanOrDefinitionOptionsValue interface {
 func (Boolean) isBooleanOrDefinitionOptionsValue()            {}
 func (*DefinitionOptions) isBooleanOrDefinitionOptionsValue() {}
 
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrDefinitionOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrDefinitionOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsDefinitionOptions returns the value of b and true if it's a [DefinitionOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrDefinitionOptions) AsDefinitionOptions() (*DefinitionOptions, bool) {
+	value, ok := b.Value.(*DefinitionOptions)
+	return value, ok
+}
+
+// IsDefinitionOptions reports whether the value of b is a [DefinitionOptions].
+func (b BooleanOrDefinitionOptions) IsDefinitionOptions() bool {
+	_, ok := b.Value.(*DefinitionOptions)
+	return ok
+}
+
 func (b *BooleanOrDefinitionOptions) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -3199,6 +3750,19 @@ type BooleanOrTypeDefinitionOptionsOrTypeDefinitionRegistrationOptionsValue inte
 }
 
 func (Boolean) isBooleanOrTypeDefinitionOptionsOrTypeDefinitionRegistrationOptionsValue() {}
+
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrTypeDefinitionOptionsOrTypeDefinitionRegistrationOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrTypeDefinitionOptionsOrTypeDefinitionRegistrationOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
 func (*TypeDefinitionOptions) isBooleanOrTypeDefinitionOptionsOrTypeDefinitionRegistrationOptionsValue() {
 }
 func (*TypeDefinitionRegistrationOptions) isBooleanOrTypeDefinitionOptionsOrTypeDefinitionRegistrationOptionsValue() {
@@ -3264,6 +3828,19 @@ type BooleanOrImplementationOptionsOrImplementationRegistrationOptionsValue inte
 }
 
 func (Boolean) isBooleanOrImplementationOptionsOrImplementationRegistrationOptionsValue() {}
+
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrImplementationOptionsOrImplementationRegistrationOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrImplementationOptionsOrImplementationRegistrationOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
 func (*ImplementationOptions) isBooleanOrImplementationOptionsOrImplementationRegistrationOptionsValue() {
 }
 func (*ImplementationRegistrationOptions) isBooleanOrImplementationOptionsOrImplementationRegistrationOptionsValue() {
@@ -3324,6 +3901,32 @@ type BooleanOrReferenceOptionsValue interface {
 func (Boolean) isBooleanOrReferenceOptionsValue()           {}
 func (*ReferenceOptions) isBooleanOrReferenceOptionsValue() {}
 
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrReferenceOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrReferenceOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsReferenceOptions returns the value of b and true if it's a [ReferenceOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrReferenceOptions) AsReferenceOptions() (*ReferenceOptions, bool) {
+	value, ok := b.Value.(*ReferenceOptions)
+	return value, ok
+}
+
+// IsReferenceOptions reports whether the value of b is a [ReferenceOptions].
+func (b BooleanOrReferenceOptions) IsReferenceOptions() bool {
+	_, ok := b.Value.(*ReferenceOptions)
+	return ok
+}
+
 func (b *BooleanOrReferenceOptions) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -3374,6 +3977,32 @@ type BooleanOrDocumentHighlightOptionsValue interface {
 func (Boolean) isBooleanOrDocumentHighlightOptionsValue()                   {}
 func (*DocumentHighlightOptions) isBooleanOrDocumentHighlightOptionsValue() {}
 
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrDocumentHighlightOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrDocumentHighlightOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsDocumentHighlightOptions returns the value of b and true if it's a [DocumentHighlightOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrDocumentHighlightOptions) AsDocumentHighlightOptions() (*DocumentHighlightOptions, bool) {
+	value, ok := b.Value.(*DocumentHighlightOptions)
+	return value, ok
+}
+
+// IsDocumentHighlightOptions reports whether the value of b is a [DocumentHighlightOptions].
+func (b BooleanOrDocumentHighlightOptions) IsDocumentHighlightOptions() bool {
+	_, ok := b.Value.(*DocumentHighlightOptions)
+	return ok
+}
+
 func (b *BooleanOrDocumentHighlightOptions) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -3429,6 +4058,32 @@ type BooleanOrDocumentSymbolOptionsValue interface {
 func (Boolean) isBooleanOrDocumentSymbolOptionsValue()                {}
 func (*DocumentSymbolOptions) isBooleanOrDocumentSymbolOptionsValue() {}
 
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrDocumentSymbolOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrDocumentSymbolOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsDocumentSymbolOptions returns the value of b and true if it's a [DocumentSymbolOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrDocumentSymbolOptions) AsDocumentSymbolOptions() (*DocumentSymbolOptions, bool) {
+	value, ok := b.Value.(*DocumentSymbolOptions)
+	return value, ok
+}
+
+// IsDocumentSymbolOptions reports whether the value of b is a [DocumentSymbolOptions].
+func (b BooleanOrDocumentSymbolOptions) IsDocumentSymbolOptions() bool {
+	_, ok := b.Value.(*DocumentSymbolOptions)
+	return ok
+}
+
 func (b *BooleanOrDocumentSymbolOptions) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -3489,6 +4144,32 @@ type BooleanOrCodeActionOptionsValue interface {
 func (Boolean) isBooleanOrCodeActionOptionsValue()            {}
 func (*CodeActionOptions) isBooleanOrCodeActionOptionsValue() {}
 
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrCodeActionOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrCodeActionOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsCodeActionOptions returns the value of b and true if it's a [CodeActionOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrCodeActionOptions) AsCodeActionOptions() (*CodeActionOptions, bool) {
+	value, ok := b.Value.(*CodeActionOptions)
+	return value, ok
+}
+
+// IsCodeActionOptions reports whether the value of b is a [CodeActionOptions].
+func (b BooleanOrCodeActionOptions) IsCodeActionOptions() bool {
+	_, ok := b.Value.(*CodeActionOptions)
+	return ok
+}
+
 func (b *BooleanOrCodeActionOptions) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -3562,6 +4243,19 @@ type BooleanOrDocumentColorOptionsOrDocumentColorRegistrationOptionsValue interf
 }
 
 func (Boolean) isBooleanOrDocumentColorOptionsOrDocumentColorRegistrationOptionsValue() {}
+
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrDocumentColorOptionsOrDocumentColorRegistrationOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrDocumentColorOptionsOrDocumentColorRegistrationOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
 func (*DocumentColorOptions) isBooleanOrDocumentColorOptionsOrDocumentColorRegistrationOptionsValue() {
 }
 func (*DocumentColorRegistrationOptions) isBooleanOrDocumentColorOptionsOrDocumentColorRegistrationOptionsValue() {
@@ -3627,6 +4321,32 @@ type BooleanOrWorkspaceSymbolOptionsValue interface {
 func (Boolean) isBooleanOrWorkspaceSymbolOptionsValue()                 {}
 func (*WorkspaceSymbolOptions) isBooleanOrWorkspaceSymbolOptionsValue() {}
 
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrWorkspaceSymbolOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrWorkspaceSymbolOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsWorkspaceSymbolOptions returns the value of b and true if it's a [WorkspaceSymbolOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrWorkspaceSymbolOptions) AsWorkspaceSymbolOptions() (*WorkspaceSymbolOptions, bool) {
+	value, ok := b.Value.(*WorkspaceSymbolOptions)
+	return value, ok
+}
+
+// IsWorkspaceSymbolOptions reports whether the value of b is a [WorkspaceSymbolOptions].
+func (b BooleanOrWorkspaceSymbolOptions) IsWorkspaceSymbolOptions() bool {
+	_, ok := b.Value.(*WorkspaceSymbolOptions)
+	return ok
+}
+
 func (b *BooleanOrWorkspaceSymbolOptions) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -3677,6 +4397,32 @@ type BooleanOrDocumentFormattingOptionsValue interface {
 func (Boolean) isBooleanOrDocumentFormattingOptionsValue()                    {}
 func (*DocumentFormattingOptions) isBooleanOrDocumentFormattingOptionsValue() {}
 
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrDocumentFormattingOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrDocumentFormattingOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsDocumentFormattingOptions returns the value of b and true if it's a [DocumentFormattingOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrDocumentFormattingOptions) AsDocumentFormattingOptions() (*DocumentFormattingOptions, bool) {
+	value, ok := b.Value.(*DocumentFormattingOptions)
+	return value, ok
+}
+
+// IsDocumentFormattingOptions reports whether the value of b is a [DocumentFormattingOptions].
+func (b BooleanOrDocumentFormattingOptions) IsDocumentFormattingOptions() bool {
+	_, ok := b.Value.(*DocumentFormattingOptions)
+	return ok
+}
+
 func (b *BooleanOrDocumentFormattingOptions) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -3732,6 +4478,32 @@ type BooleanOrDocumentRangeFormattingOptionsValue interface {
 func (Boolean) isBooleanOrDocumentRangeFormattingOptionsValue()                         {}
 func (*DocumentRangeFormattingOptions) isBooleanOrDocumentRangeFormattingOptionsValue() {}
 
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrDocumentRangeFormattingOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrDocumentRangeFormattingOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsDocumentRangeFormattingOptions returns the value of b and true if it's a [DocumentRangeFormattingOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrDocumentRangeFormattingOptions) AsDocumentRangeFormattingOptions() (*DocumentRangeFormattingOptions, bool) {
+	value, ok := b.Value.(*DocumentRangeFormattingOptions)
+	return value, ok
+}
+
+// IsDocumentRangeFormattingOptions reports whether the value of b is a [DocumentRangeFormattingOptions].
+func (b BooleanOrDocumentRangeFormattingOptions) IsDocumentRangeFormattingOptions() bool {
+	_, ok := b.Value.(*DocumentRangeFormattingOptions)
+	return ok
+}
+
 func (b *BooleanOrDocumentRangeFormattingOptions) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -3796,6 +4568,32 @@ type BooleanOrRenameOptionsValue interface {
 func (Boolean) isBooleanOrRenameOptionsValue()        {}
 func (*RenameOptions) isBooleanOrRenameOptionsValue() {}
 
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrRenameOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrRenameOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsRenameOptions returns the value of b and true if it's a [RenameOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrRenameOptions) AsRenameOptions() (*RenameOptions, bool) {
+	value, ok := b.Value.(*RenameOptions)
+	return value, ok
+}
+
+// IsRenameOptions reports whether the value of b is a [RenameOptions].
+func (b BooleanOrRenameOptions) IsRenameOptions() bool {
+	_, ok := b.Value.(*RenameOptions)
+	return ok
+}
+
 func (b *BooleanOrRenameOptions) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -3852,6 +4650,32 @@ type BooleanOrFoldingRangeOptionsOrFoldingRangeRegistrationOptionsValue interfac
 
 func (Boolean) isBooleanOrFoldingRangeOptionsOrFoldingRangeRegistrationOptionsValue()              {}
 func (*FoldingRangeOptions) isBooleanOrFoldingRangeOptionsOrFoldingRangeRegistrationOptionsValue() {}
+
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrFoldingRangeOptionsOrFoldingRangeRegistrationOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrFoldingRangeOptionsOrFoldingRangeRegistrationOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsFoldingRangeOptions returns the value of b and true if it's a [FoldingRangeOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrFoldingRangeOptionsOrFoldingRangeRegistrationOptions) AsFoldingRangeOptions() (*FoldingRangeOptions, bool) {
+	value, ok := b.Value.(*FoldingRangeOptions)
+	return value, ok
+}
+
+// IsFoldingRangeOptions reports whether the value of b is a [FoldingRangeOptions].
+func (b BooleanOrFoldingRangeOptionsOrFoldingRangeRegistrationOptions) IsFoldingRangeOptions() bool {
+	_, ok := b.Value.(*FoldingRangeOptions)
+	return ok
+}
 func (*FoldingRangeRegistrationOptions) isBooleanOrFoldingRangeOptionsOrFoldingRangeRegistrationOptionsValue() {
 }
 
@@ -3915,6 +4739,19 @@ type BooleanOrSelectionRangeOptionsOrSelectionRangeRegistrationOptionsValue inte
 }
 
 func (Boolean) isBooleanOrSelectionRangeOptionsOrSelectionRangeRegistrationOptionsValue() {}
+
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrSelectionRangeOptionsOrSelectionRangeRegistrationOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrSelectionRangeOptionsOrSelectionRangeRegistrationOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
 func (*SelectionRangeOptions) isBooleanOrSelectionRangeOptionsOrSelectionRangeRegistrationOptionsValue() {
 }
 func (*SelectionRangeRegistrationOptions) isBooleanOrSelectionRangeOptionsOrSelectionRangeRegistrationOptionsValue() {
@@ -3997,6 +4834,19 @@ type BooleanOrCallHierarchyOptionsOrCallHierarchyRegistrationOptionsValue interf
 }
 
 func (Boolean) isBooleanOrCallHierarchyOptionsOrCallHierarchyRegistrationOptionsValue() {}
+
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrCallHierarchyOptionsOrCallHierarchyRegistrationOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrCallHierarchyOptionsOrCallHierarchyRegistrationOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
 func (*CallHierarchyOptions) isBooleanOrCallHierarchyOptionsOrCallHierarchyRegistrationOptionsValue() {
 }
 func (*CallHierarchyRegistrationOptions) isBooleanOrCallHierarchyOptionsOrCallHierarchyRegistrationOptionsValue() {
@@ -4062,6 +4912,19 @@ type BooleanOrLinkedEditingRangeOptionsOrLinkedEditingRangeRegistrationOptionsVa
 }
 
 func (Boolean) isBooleanOrLinkedEditingRangeOptionsOrLinkedEditingRangeRegistrationOptionsValue() {}
+
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrLinkedEditingRangeOptionsOrLinkedEditingRangeRegistrationOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrLinkedEditingRangeOptionsOrLinkedEditingRangeRegistrationOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
 func (*LinkedEditingRangeOptions) isBooleanOrLinkedEditingRangeOptionsOrLinkedEditingRangeRegistrationOptionsValue() {
 }
 func (*LinkedEditingRangeRegistrationOptions) isBooleanOrLinkedEditingRangeOptionsOrLinkedEditingRangeRegistrationOptionsValue() {
@@ -4128,6 +4991,32 @@ type BooleanOrSemanticTokensOptionsRangeOr2Value interface {
 func (Boolean) isBooleanOrSemanticTokensOptionsRangeOr2Value()                        {}
 func (*SemanticTokensOptionsRangeOr2) isBooleanOrSemanticTokensOptionsRangeOr2Value() {}
 
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrSemanticTokensOptionsRangeOr2) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrSemanticTokensOptionsRangeOr2) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsSemanticTokensOptionsRangeOr2 returns the value of b and true if it's a [SemanticTokensOptionsRangeOr2], otherwise it returns the
+// zero value and false.
+func (b BooleanOrSemanticTokensOptionsRangeOr2) AsSemanticTokensOptionsRangeOr2() (*SemanticTokensOptionsRangeOr2, bool) {
+	value, ok := b.Value.(*SemanticTokensOptionsRangeOr2)
+	return value, ok
+}
+
+// IsSemanticTokensOptionsRangeOr2 reports whether the value of b is a [SemanticTokensOptionsRangeOr2].
+func (b BooleanOrSemanticTokensOptionsRangeOr2) IsSemanticTokensOptionsRangeOr2() bool {
+	_, ok := b.Value.(*SemanticTokensOptionsRangeOr2)
+	return ok
+}
+
 func (b *BooleanOrSemanticTokensOptionsRangeOr2) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -4176,6 +5065,32 @@ type BooleanOrSemanticTokensOptionsFullOr2Value interface {
 func (Boolean) isBooleanOrSemanticTokensOptionsFullOr2Value()                       {}
 func (*SemanticTokensOptionsFullOr2) isBooleanOrSemanticTokensOptionsFullOr2Value() {}
 
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrSemanticTokensOptionsFullOr2) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrSemanticTokensOptionsFullOr2) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsSemanticTokensOptionsFullOr2 returns the value of b and true if it's a [SemanticTokensOptionsFullOr2], otherwise it returns the
+// zero value and false.
+func (b BooleanOrSemanticTokensOptionsFullOr2) AsSemanticTokensOptionsFullOr2() (*SemanticTokensOptionsFullOr2, bool) {
+	value, ok := b.Value.(*SemanticTokensOptionsFullOr2)
+	return value, ok
+}
+
+// IsSemanticTokensOptionsFullOr2 reports whether the value of b is a [SemanticTokensOptionsFullOr2].
+func (b BooleanOrSemanticTokensOptionsFullOr2) IsSemanticTokensOptionsFullOr2() bool {
+	_, ok := b.Value.(*SemanticTokensOptionsFullOr2)
+	return ok
+}
+
 func (b *BooleanOrSemanticTokensOptionsFullOr2) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -4240,6 +5155,19 @@ type SemanticTokensOptionsOrSemanticTokensRegistrationOptionsValue interface {
 }
 
 func (*SemanticTokensOptions) isSemanticTokensOptionsOrSemanticTokensRegistrationOptionsValue() {}
+
+// AsSemanticTokensOptions returns the value of s and true if it's a [SemanticTokensOptions], otherwise it returns the
+// zero value and false.
+func (s SemanticTokensOptionsOrSemanticTokensRegistrationOptions) AsSemanticTokensOptions() (*SemanticTokensOptions, bool) {
+	value, ok := s.Value.(*SemanticTokensOptions)
+	return value, ok
+}
+
+// IsSemanticTokensOptions reports whether the value of s is a [SemanticTokensOptions].
+func (s SemanticTokensOptionsOrSemanticTokensRegistrationOptions) IsSemanticTokensOptions() bool {
+	_, ok := s.Value.(*SemanticTokensOptions)
+	return ok
+}
 func (*SemanticTokensRegistrationOptions) isSemanticTokensOptionsOrSemanticTokensRegistrationOptionsValue() {
 }
 
@@ -4300,6 +5228,45 @@ func (Boolean) isBooleanOrMonikerOptionsOrMonikerRegistrationOptionsValue()
 func (*MonikerOptions) isBooleanOrMonikerOptionsOrMonikerRegistrationOptionsValue()             {}
 func (*MonikerRegistrationOptions) isBooleanOrMonikerOptionsOrMonikerRegistrationOptionsValue() {}
 
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrMonikerOptionsOrMonikerRegistrationOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrMonikerOptionsOrMonikerRegistrationOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsMonikerOptions returns the value of b and true if it's a [MonikerOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrMonikerOptionsOrMonikerRegistrationOptions) AsMonikerOptions() (*MonikerOptions, bool) {
+	value, ok := b.Value.(*MonikerOptions)
+	return value, ok
+}
+
+// IsMonikerOptions reports whether the value of b is a [MonikerOptions].
+func (b BooleanOrMonikerOptionsOrMonikerRegistrationOptions) IsMonikerOptions() bool {
+	_, ok := b.Value.(*MonikerOptions)
+	return ok
+}
+
+// AsMonikerRegistrationOptions returns the value of b and true if it's a [MonikerRegistrationOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrMonikerOptionsOrMonikerRegistrationOptions) AsMonikerRegistrationOptions() (*MonikerRegistrationOptions, bool) {
+	value, ok := b.Value.(*MonikerRegistrationOptions)
+	return value, ok
+}
+
+// IsMonikerRegistrationOptions reports whether the value of b is a [MonikerRegistrationOptions].
+func (b BooleanOrMonikerOptionsOrMonikerRegistrationOptions) IsMonikerRegistrationOptions() bool {
+	_, ok := b.Value.(*MonikerRegistrationOptions)
+	return ok
+}
+
 func (b *BooleanOrMonikerOptionsOrMonikerRegistrationOptions) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -4368,6 +5335,19 @@ type BooleanOrTypeHierarchyOptionsOrTypeHierarchyRegistrationOptionsValue interf
 }
 
 func (Boolean) isBooleanOrTypeHierarchyOptionsOrTypeHierarchyRegistrationOptionsValue() {}
+
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrTypeHierarchyOptionsOrTypeHierarchyRegistrationOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrTypeHierarchyOptionsOrTypeHierarchyRegistrationOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
 func (*TypeHierarchyOptions) isBooleanOrTypeHierarchyOptionsOrTypeHierarchyRegistrationOptionsValue() {
 }
 func (*TypeHierarchyRegistrationOptions) isBooleanOrTypeHierarchyOptionsOrTypeHierarchyRegistrationOptionsValue() {
@@ -4442,6 +5422,32 @@ type BooleanOrInlineValueOptionsOrInlineValueRegistrationOptionsValue interface
 
 func (Boolean) isBooleanOrInlineValueOptionsOrInlineValueRegistrationOptionsValue()             {}
 func (*InlineValueOptions) isBooleanOrInlineValueOptionsOrInlineValueRegistrationOptionsValue() {}
+
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrInlineValueOptionsOrInlineValueRegistrationOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrInlineValueOptionsOrInlineValueRegistrationOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsInlineValueOptions returns the value of b and true if it's a [InlineValueOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrInlineValueOptionsOrInlineValueRegistrationOptions) AsInlineValueOptions() (*InlineValueOptions, bool) {
+	value, ok := b.Value.(*InlineValueOptions)
+	return value, ok
+}
+
+// IsInlineValueOptions reports whether the value of b is a [InlineValueOptions].
+func (b BooleanOrInlineValueOptionsOrInlineValueRegistrationOptions) IsInlineValueOptions() bool {
+	_, ok := b.Value.(*InlineValueOptions)
+	return ok
+}
 func (*InlineValueRegistrationOptions) isBooleanOrInlineValueOptionsOrInlineValueRegistrationOptionsValue() {
 }
 
@@ -4517,6 +5523,32 @@ type BooleanOrInlayHintOptionsOrInlayHintRegistrationOptionsValue interface {
 
 func (Boolean) isBooleanOrInlayHintOptionsOrInlayHintRegistrationOptionsValue()           {}
 func (*InlayHintOptions) isBooleanOrInlayHintOptionsOrInlayHintRegistrationOptionsValue() {}
+
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrInlayHintOptionsOrInlayHintRegistrationOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrInlayHintOptionsOrInlayHintRegistrationOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsInlayHintOptions returns the value of b and true if it's a [InlayHintOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrInlayHintOptionsOrInlayHintRegistrationOptions) AsInlayHintOptions() (*InlayHintOptions, bool) {
+	value, ok := b.Value.(*InlayHintOptions)
+	return value, ok
+}
+
+// IsInlayHintOptions reports whether the value of b is a [InlayHintOptions].
+func (b BooleanOrInlayHintOptionsOrInlayHintRegistrationOptions) IsInlayHintOptions() bool {
+	_, ok := b.Value.(*InlayHintOptions)
+	return ok
+}
 func (*InlayHintRegistrationOptions) isBooleanOrInlayHintOptionsOrInlayHintRegistrationOptionsValue() {
 }
 
@@ -4598,6 +5630,32 @@ type DiagnosticOptionsOrDiagnosticRegistrationOptionsValue interface {
 func (*DiagnosticOptions) isDiagnosticOptionsOrDiagnosticRegistrationOptionsValue()             {}
 func (*DiagnosticRegistrationOptions) isDiagnosticOptionsOrDiagnosticRegistrationOptionsValue() {}
 
+// AsDiagnosticOptions returns the value of d and true if it's a [DiagnosticOptions], otherwise it returns the
+// zero value and false.
+func (d DiagnosticOptionsOrDiagnosticRegistrationOptions) AsDiagnosticOptions() (*DiagnosticOptions, bool) {
+	value, ok := d.Value.(*DiagnosticOptions)
+	return value, ok
+}
+
+// IsDiagnosticOptions reports whether the value of d is a [DiagnosticOptions].
+func (d DiagnosticOptionsOrDiagnosticRegistrationOptions) IsDiagnosticOptions() bool {
+	_, ok := d.Value.(*DiagnosticOptions)
+	return ok
+}
+
+// AsDiagnosticRegistrationOptions returns the value of d and true if it's a [DiagnosticRegistrationOptions], otherwise it returns the
+// zero value and false.
+func (d DiagnosticOptionsOrDiagnosticRegistrationOptions) AsDiagnosticRegistrationOptions() (*DiagnosticRegistrationOptions, bool) {
+	value, ok := d.Value.(*DiagnosticRegistrationOptions)
+	return value, ok
+}
+
+// IsDiagnosticRegistrationOptions reports whether the value of d is a [DiagnosticRegistrationOptions].
+func (d DiagnosticOptionsOrDiagnosticRegistrationOptions) IsDiagnosticRegistrationOptions() bool {
+	_, ok := d.Value.(*DiagnosticRegistrationOptions)
+	return ok
+}
+
 func (d *DiagnosticOptionsOrDiagnosticRegistrationOptions) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -4651,6 +5709,32 @@ type BooleanOrInlineCompletionOptionsValue interface {
 func (Boolean) isBooleanOrInlineCompletionOptionsValue()                  {}
 func (*InlineCompletionOptions) isBooleanOrInlineCompletionOptionsValue() {}
 
+// AsBoolean returns the value of b and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (b BooleanOrInlineCompletionOptions) AsBoolean() (Boolean, bool) {
+	value, ok := b.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of b is a [Boolean].
+func (b BooleanOrInlineCompletionOptions) IsBoolean() bool {
+	_, ok := b.Value.(Boolean)
+	return ok
+}
+
+// AsInlineCompletionOptions returns the value of b and true if it's a [InlineCompletionOptions], otherwise it returns the
+// zero value and false.
+func (b BooleanOrInlineCompletionOptions) AsInlineCompletionOptions() (*InlineCompletionOptions, bool) {
+	value, ok := b.Value.(*InlineCompletionOptions)
+	return value, ok
+}
+
+// IsInlineCompletionOptions reports whether the value of b is a [InlineCompletionOptions].
+func (b BooleanOrInlineCompletionOptions) IsInlineCompletionOptions() bool {
+	_, ok := b.Value.(*InlineCompletionOptions)
+	return ok
+}
+
 func (b *BooleanOrInlineCompletionOptions) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -4694,6 +5778,32 @@ type StringOrBooleanValue interface {
 func (String) isStringOrBooleanValue()  {}
 func (Boolean) isStringOrBooleanValue() {}
 
+// AsString returns the value of s and true if it's a [String], otherwise it returns the
+// zero value and false.
+func (s StringOrBoolean) AsString() (String, bool) {
+	value, ok := s.Value.(String)
+	return value, ok
+}
+
+// IsString reports whether the value of s is a [String].
+func (s StringOrBoolean) IsString() bool {
+	_, ok := s.Value.(String)
+	return ok
+}
+
+// AsBoolean returns the value of s and true if it's a [Boolean], otherwise it returns the
+// zero value and false.
+func (s StringOrBoolean) AsBoolean() (Boolean, bool) {
+	value, ok := s.Value.(Boolean)
+	return value, ok
+}
+
+// IsBoolean reports whether the value of s is a [Boolean].
+func (s StringOrBoolean) IsBoolean() bool {
+	_, ok := s.Value.(Boolean)
+	return ok
+}
+
 func (s *StringOrBoolean) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -4747,6 +5857,11 @@ const (
 	FileOperationPatternKindfolder FileOperationPatternKind = "folder"
 )
 
+// String returns the value of f as it appears in the specification.
+func (f FileOperationPatternKind) String() string {
+	return string(f)
+}
+
 var validFileOperationPatternKindValues = map[string]bool{
 	"file":   true,
 	"folder": true,
@@ -5142,6 +6257,32 @@ type LocationOrLocationSliceValue interface {
 func (*Location) isLocationOrLocationSliceValue()     {}
 func (LocationSlice) isLocationOrLocationSliceValue() {}
 
+// AsLocation returns the value of l and true if it's a [Location], otherwise it returns the
+// zero value and false.
+func (l LocationOrLocationSlice) AsLocation() (*Location, bool) {
+	value, ok := l.Value.(*Location)
+	return value, ok
+}
+
+// IsLocation reports whether the value of l is a [Location].
+func (l LocationOrLocationSlice) IsLocation() bool {
+	_, ok := l.Value.(*Location)
+	return ok
+}
+
+// AsLocationSlice returns the value of l and true if it's a [LocationSlice], otherwise it returns the
+// zero value and false.
+func (l LocationOrLocationSlice) AsLocationSlice() (LocationSlice, bool) {
+	value, ok := l.Value.(LocationSlice)
+	return value, ok
+}
+
+// IsLocationSlice reports whether the value of l is a [LocationSlice].
+func (l LocationOrLocationSlice) IsLocationSlice() bool {
+	_, ok := l.Value.(LocationSlice)
+	return ok
+}
+
 func (l *LocationOrLocationSlice) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -5226,6 +6367,32 @@ type DefinitionOrDefinitionLinkSliceValue interface {
 func (Definition) isDefinitionOrDefinitionLinkSliceValue()          {}
 func (DefinitionLinkSlice) isDefinitionOrDefinitionLinkSliceValue() {}
 
+// AsDefinition returns the value of d and true if it's a [Definition], otherwise it returns the
+// zero value and false.
+func (d DefinitionOrDefinitionLinkSlice) AsDefinition() (Definition, bool) {
+	value, ok := d.Value.(Definition)
+	return value, ok
+}
+
+// IsDefinition reports whether the value of d is a [Definition].
+func (d DefinitionOrDefinitionLinkSlice) IsDefinition() bool {
+	_, ok := d.Value.(Definition)
+	return ok
+}
+
+// AsDefinitionLinkSlice returns the value of d and true if it's a [DefinitionLinkSlice], otherwise it returns the
+// zero value and false.
+func (d DefinitionOrDefinitionLinkSlice) AsDefinitionLinkSlice() (DefinitionLinkSlice, bool) {
+	value, ok := d.Value.(DefinitionLinkSlice)
+	return value, ok
+}
+
+// IsDefinitionLinkSlice reports whether the value of d is a [DefinitionLinkSlice].
+func (d DefinitionOrDefinitionLinkSlice) IsDefinitionLinkSlice() bool {
+	_, ok := d.Value.(DefinitionLinkSlice)
+	return ok
+}
+
 func (d *DefinitionOrDefinitionLinkSlice) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -5357,6 +6524,32 @@ type SymbolInformationSliceOrDocumentSymbolSliceValue interface {
 func (SymbolInformationSlice) isSymbolInformationSliceOrDocumentSymbolSliceValue() {}
 func (DocumentSymbolSlice) isSymbolInformationSliceOrDocumentSymbolSliceValue()    {}
 
+// AsSymbolInformationSlice returns the value of s and true if it's a [SymbolInformationSlice], otherwise it returns the
+// zero value and false.
+func (s SymbolInformationSliceOrDocumentSymbolSlice) AsSymbolInformationSlice() (SymbolInformationSlice, bool) {
+	value, ok := s.Value.(SymbolInformationSlice)
+	return value, ok
+}
+
+// IsSymbolInformationSlice reports whether the value of s is a [SymbolInformationSlice].
+func (s SymbolInformationSliceOrDocumentSymbolSlice) IsSymbolInformationSlice() bool {
+	_, ok := s.Value.(SymbolInformationSlice)
+	return ok
+}
+
+// AsDocumentSymbolSlice returns the value of s and true if it's a [DocumentSymbolSlice], otherwise it returns the
+// zero value and false.
+func (s SymbolInformationSliceOrDocumentSymbolSlice) AsDocumentSymbolSlice() (DocumentSymbolSlice, bool) {
+	value, ok := s.Value.(DocumentSymbolSlice)
+	return value, ok
+}
+
+// IsDocumentSymbolSlice reports whether the value of s is a [DocumentSymbolSlice].
+func (s SymbolInformationSliceOrDocumentSymbolSlice) IsDocumentSymbolSlice() bool {
+	_, ok := s.Value.(DocumentSymbolSlice)
+	return ok
+}
+
 func (s *SymbolInformationSliceOrDocumentSymbolSlice) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
@@ -5414,6 +6607,56 @@ type DocumentFormattingParams struct {
 	Options *FormattingOptions `json:"options"`
 }
 
+// The parameters of a {@link DocumentRangeFormattingRequest}.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#documentRangeFormattingParams
+type DocumentRangeFormattingParams struct {
+	*WorkDoneProgressParams
+	// The document to format.
+	TextDocument *TextDocumentIdentifier `json:"textDocument"`
+	// The range to format
+	Range *Range `json:"range"`
+	// The format options
+	Options *FormattingOptions `json:"options"`
+}
+
+// Parameters for a {@link FoldingRangeRequest}.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#foldingRangeParams
+type FoldingRangeParams struct {
+	*WorkDoneProgressParams
+	*PartialResultParams
+	// The text document.
+	TextDocument *TextDocumentIdentifier `json:"textDocument"`
+}
+
+// Represents a folding range. To be valid, start and end line must be bigger than zero and smaller
+// than the number of lines in the document. Clients are free to ignore invalid ranges.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#foldingRange
+type FoldingRange struct {
+	// The zero-based start line of the range to fold. The folded area starts after the line's last character.
+	// To be valid, the end must be zero or larger and smaller than the number of lines in the document.
+	StartLine int `json:"startLine"`
+	// The zero-based character offset from where the folded range starts. If not defined, defaults to the length of the start line.
+	StartCharacter int `json:"startCharacter,omitempty"`
+	// The zero-based end line of the range to fold. The folded area ends with the line's last character.
+	// To be valid, the end must be zero or larger and smaller than the number of lines in the document.
+	EndLine int `json:"endLine"`
+	// The zero-based character offset before the folded range ends. If not defined, defaults to the length of the end line.
+	EndCharacter int `json:"endCharacter,omitempty"`
+	// Describes the kind of the folding range such as 'comment' or 'region'. The kind
+	// is used to categorize folding ranges and used by commands like 'Fold all comments'.
+	// See {@link FoldingRangeKind} for an enumeration of standardized kinds.
+	Kind FoldingRangeKind `json:"kind,omitempty"`
+	// The text that the client should show when the specified range is
+	// collapsed. If not defined or not supported by the client, a default
+	// will be chosen by the client.
+	//
+	// @since 3.17.0
+	CollapsedText string `json:"collapsedText,omitempty"`
+}
+
 // A text edit applicable to a text document.
 //
 // https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textEdit
@@ -5450,6 +6693,24 @@ const (
 	MessageTypeDebug MessageType = 5
 )
 
+// String returns the name of the constant which m is equal to.
+func (m MessageType) String() string {
+	switch m {
+	case MessageTypeError:
+		return "MessageTypeError"
+	case MessageTypeWarning:
+		return "MessageTypeWarning"
+	case MessageTypeInfo:
+		return "MessageTypeInfo"
+	case MessageTypeLog:
+		return "MessageTypeLog"
+	case MessageTypeDebug:
+		return "MessageTypeDebug"
+	default:
+		return fmt.Sprintf("MessageType(%d)", uint32(m))
+	}
+}
+
 var validMessageTypeValues = map[uint32]bool{
 	1: true,
 	2: true,
@@ -5647,6 +6908,22 @@ const (
 	DiagnosticSeverityHint DiagnosticSeverity = 4
 )
 
+// String returns the name of the constant which d is equal to.
+func (d DiagnosticSeverity) String() string {
+	switch d {
+	case DiagnosticSeverityError:
+		return "DiagnosticSeverityError"
+	case DiagnosticSeverityWarning:
+		return "DiagnosticSeverityWarning"
+	case DiagnosticSeverityInformation:
+		return "DiagnosticSeverityInformation"
+	case DiagnosticSeverityHint:
+		return "DiagnosticSeverityHint"
+	default:
+		return fmt.Sprintf("DiagnosticSeverity(%d)", uint32(d))
+	}
+}
+
 var validDiagnosticSeverityValues = map[uint32]bool{
 	1: true,
 	2: true,
@@ -5752,6 +7029,867 @@ type PublishDiagnosticsParams struct {
 	Diagnostics []*Diagnostic `json:"diagnostics"`
 }
 
+// The parameters of a change configuration notification.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#didChangeConfigurationParams
+type DidChangeConfigurationParams struct {
+	// The actual changed settings
+	Settings LSPAny `json:"settings"`
+}
+
+// The parameters of a {@link WorkspaceSymbolRequest}.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspaceSymbolParams
+type WorkspaceSymbolParams struct {
+	*WorkDoneProgressParams
+	*PartialResultParams
+	// A query string to filter symbols by. Clients may send an empty
+	// string here to request all symbols.
+	Query string `json:"query"`
+}
+
+type WorkspaceSymbolLocation struct {
+	Uri string `json:"uri"`
+}
+
+// LocationOrWorkspaceSymbolLocation contains either of the following types:
+//   - [*Location]
+//   - [*WorkspaceSymbolLocation]
+type LocationOrWorkspaceSymbolLocation struct {
+	Value LocationOrWorkspaceSymbolLocationValue
+}
+
+// LocationOrWorkspaceSymbolLocationValue is either of the following types:
+//   - [*Location]
+//   - [*WorkspaceSymbolLocation]
+//
+//gosumtype:decl LocationOrWorkspaceSymbolLocationValue
+type LocationOrWorkspaceSymbolLocationValue interface {
+	isLocationOrWorkspaceSymbolLocationValue()
+}
+
+func (*Location) isLocationOrWorkspaceSymbolLocationValue()                {}
+func (*WorkspaceSymbolLocation) isLocationOrWorkspaceSymbolLocationValue() {}
+
+// AsLocation returns the value of l and true if it's a [Location], otherwise it returns the
+// zero value and false.
+func (l LocationOrWorkspaceSymbolLocation) AsLocation() (*Location, bool) {
+	value, ok := l.Value.(*Location)
+	return value, ok
+}
+
+// IsLocation reports whether the value of l is a [Location].
+func (l LocationOrWorkspaceSymbolLocation) IsLocation() bool {
+	_, ok := l.Value.(*Location)
+	return ok
+}
+
+// AsWorkspaceSymbolLocation returns the value of l and true if it's a [WorkspaceSymbolLocation], otherwise it returns the
+// zero value and false.
+func (l LocationOrWorkspaceSymbolLocation) AsWorkspaceSymbolLocation() (*WorkspaceSymbolLocation, bool) {
+	value, ok := l.Value.(*WorkspaceSymbolLocation)
+	return value, ok
+}
+
+// IsWorkspaceSymbolLocation reports whether the value of l is a [WorkspaceSymbolLocation].
+func (l LocationOrWorkspaceSymbolLocation) IsWorkspaceSymbolLocation() bool {
+	_, ok := l.Value.(*WorkspaceSymbolLocation)
+	return ok
+}
+
+func (l *LocationOrWorkspaceSymbolLocation) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var locationValue *Location
+	if err := json.Unmarshal(data, &locationValue); err == nil {
+		l.Value = locationValue
+		return nil
+	}
+	var workspaceSymbolLocationValue *WorkspaceSymbolLocation
+	if err := json.Unmarshal(data, &workspaceSymbolLocationValue); err == nil {
+		l.Value = workspaceSymbolLocationValue
+		return nil
+	}
+	return &json.UnmarshalTypeError{
+		Value: string(data),
+		Type:  reflect.TypeFor[*LocationOrWorkspaceSymbolLocation](),
+	}
+}
+
+func (l LocationOrWorkspaceSymbolLocation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.Value)
+}
+
+// A special workspace symbol that supports locations without a range.
+//
+// See also SymbolInformation.
+//
+// @since 3.17.0
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspaceSymbol
+type WorkspaceSymbol struct {
+	*BaseSymbolInformation
+	// The location of the symbol. Whether a server is allowed to
+	// return a location without a range depends on the client
+	// capability `workspace.symbol.resolveSupport`.
+	//
+	// See SymbolInformation#location for more details.
+	Location *LocationOrWorkspaceSymbolLocation `json:"location"`
+	// A data entry field that is preserved on a workspace symbol between a
+	// workspace symbol request and a workspace symbol resolve request.
+	Data LSPAny `json:"data,omitempty"`
+}
+
+type WorkspaceSymbolSlice []*WorkspaceSymbol
+
+// SymbolInformationSliceOrWorkspaceSymbolSlice contains either of the following types:
+//   - [SymbolInformationSlice]
+//   - [WorkspaceSymbolSlice]
+type SymbolInformationSliceOrWorkspaceSymbolSlice struct {
+	Value SymbolInformationSliceOrWorkspaceSymbolSliceValue
+}
+
+// SymbolInformationSliceOrWorkspaceSymbolSliceValue is either of the following types:
+//   - [SymbolInformationSlice]
+//   - [WorkspaceSymbolSlice]
+//
+//gosumtype:decl SymbolInformationSliceOrWorkspaceSymbolSliceValue
+type SymbolInformationSliceOrWorkspaceSymbolSliceValue interface {
+	isSymbolInformationSliceOrWorkspaceSymbolSliceValue()
+}
+
+func (SymbolInformationSlice) isSymbolInformationSliceOrWorkspaceSymbolSliceValue() {}
+func (WorkspaceSymbolSlice) isSymbolInformationSliceOrWorkspaceSymbolSliceValue()   {}
+
+// AsSymbolInformationSlice returns the value of s and true if it's a [SymbolInformationSlice], otherwise it returns the
+// zero value and false.
+func (s SymbolInformationSliceOrWorkspaceSymbolSlice) AsSymbolInformationSlice() (SymbolInformationSlice, bool) {
+	value, ok := s.Value.(SymbolInformationSlice)
+	return value, ok
+}
+
+// IsSymbolInformationSlice reports whether the value of s is a [SymbolInformationSlice].
+func (s SymbolInformationSliceOrWorkspaceSymbolSlice) IsSymbolInformationSlice() bool {
+	_, ok := s.Value.(SymbolInformationSlice)
+	return ok
+}
+
+// AsWorkspaceSymbolSlice returns the value of s and true if it's a [WorkspaceSymbolSlice], otherwise it returns the
+// zero value and false.
+func (s SymbolInformationSliceOrWorkspaceSymbolSlice) AsWorkspaceSymbolSlice() (WorkspaceSymbolSlice, bool) {
+	value, ok := s.Value.(WorkspaceSymbolSlice)
+	return value, ok
+}
+
+// IsWorkspaceSymbolSlice reports whether the value of s is a [WorkspaceSymbolSlice].
+func (s SymbolInformationSliceOrWorkspaceSymbolSlice) IsWorkspaceSymbolSlice() bool {
+	_, ok := s.Value.(WorkspaceSymbolSlice)
+	return ok
+}
+
+func (s *SymbolInformationSliceOrWorkspaceSymbolSlice) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var symbolInformationSliceValue SymbolInformationSlice
+	if err := json.Unmarshal(data, &symbolInformationSliceValue); err == nil {
+		s.Value = symbolInformationSliceValue
+		return nil
+	}
+	var workspaceSymbolSliceValue WorkspaceSymbolSlice
+	if err := json.Unmarshal(data, &workspaceSymbolSliceValue); err == nil {
+		s.Value = workspaceSymbolSliceValue
+		return nil
+	}
+	return &json.UnmarshalTypeError{
+		Value: string(data),
+		Type:  reflect.TypeFor[*SymbolInformationSliceOrWorkspaceSymbolSlice](),
+	}
+}
+
+func (s SymbolInformationSliceOrWorkspaceSymbolSlice) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Value)
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#progressParams
+type ProgressParams struct {
+	// The progress token provided by the client or server.
+	Token ProgressToken `json:"token"`
+	// The progress data.
+	Value LSPAny `json:"value"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workDoneProgressCancelParams
+type WorkDoneProgressCancelParams struct {
+	// The token to be used to report progress.
+	Token ProgressToken `json:"token"`
+}
+
+// The parameters of a {@link CodeActionRequest}.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#codeActionParams
+type CodeActionParams struct {
+	*WorkDoneProgressParams
+	*PartialResultParams
+	// The document in which the command was invoked.
+	TextDocument *TextDocumentIdentifier `json:"textDocument"`
+	// The range for which the command was invoked.
+	Range *Range `json:"range"`
+	// Context carrying additional information.
+	Context *CodeActionContext `json:"context"`
+}
+
+// Contains additional diagnostic information about the context in which
+// a {@link CodeActionProvider.provideCodeActions code action} is run.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#codeActionContext
+type CodeActionContext struct {
+	// An array of diagnostics known on the client side overlapping the range provided to the
+	// `textDocument/codeAction` request. They are provided so that the server knows which
+	// errors are currently presented to the user for the given range. There is no guarantee
+	// that these accurately reflect the error state of the resource. The primary parameter
+	// to compute code actions is the provided range.
+	Diagnostics []*Diagnostic `json:"diagnostics"`
+	// Requested kind of actions to return.
+	//
+	// Actions not of this kind are filtered out by the client before being shown. So servers
+	// can omit computing them.
+	Only []CodeActionKind `json:"only,omitempty"`
+	// The reason why code actions were requested.
+	//
+	// @since 3.17.0
+	TriggerKind CodeActionTriggerKind `json:"triggerKind,omitempty"`
+}
+
+// The reason why code actions were requested.
+//
+// @since 3.17.0
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#codeActionTriggerKind
+type CodeActionTriggerKind uint32
+
+const (
+	// Code actions were explicitly requested by the user or by an extension.
+	CodeActionTriggerKindInvoked CodeActionTriggerKind = 1
+	// Code actions were requested automatically.
+	//
+	// This typically happens when current selection in a file changes, but can
+	// also be triggered when file content changes.
+	CodeActionTriggerKindAutomatic CodeActionTriggerKind = 2
+)
+
+// String returns the name of the constant which c is equal to.
+func (c CodeActionTriggerKind) String() string {
+	switch c {
+	case CodeActionTriggerKindInvoked:
+		return "CodeActionTriggerKindInvoked"
+	case CodeActionTriggerKindAutomatic:
+		return "CodeActionTriggerKindAutomatic"
+	default:
+		return fmt.Sprintf("CodeActionTriggerKind(%d)", uint32(c))
+	}
+}
+
+// Represents a reference to a command. Provides a title which
+// will be used to represent a command in the UI and, optionally,
+// an array of arguments which will be passed to the command handler
+// function when invoked.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#command
+type Command struct {
+	// Title of the command, like `save`.
+	Title string `json:"title"`
+	// The identifier of the actual command handler.
+	Command string `json:"command"`
+	// Arguments that the command handler should be
+	// invoked with.
+	Arguments []LSPAny `json:"arguments,omitempty"`
+}
+
+// Captures why the code action is currently disabled.
+type CodeActionDisabled struct {
+	// Human readable description of why the code action is currently disabled.
+	//
+	// This is displayed in the code actions UI.
+	Reason string `json:"reason"`
+}
+
+// A code action represents a change that can be performed in code, e.g. to fix a problem or
+// to refactor code.
+//
+// A CodeAction must set either `edit` and/or a `command`. If both are supplied, the `edit` is applied first, then the `command` is executed.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#codeAction
+type CodeAction struct {
+	// A short, human-readable, title for this code action.
+	Title string `json:"title"`
+	// The kind of the code action.
+	//
+	// Used to filter code actions.
+	Kind CodeActionKind `json:"kind,omitempty"`
+	// The diagnostics that this code action resolves.
+	Diagnostics []*Diagnostic `json:"diagnostics,omitempty"`
+	// Marks this as a preferred action. Preferred actions are used by the `auto fix` command and can be targeted
+	// by keybindings.
+	//
+	// A quick fix should be marked preferred if it properly addresses the underlying error.
+	// A refactoring should be marked preferred if it is the most reasonable choice of actions to take.
+	//
+	// @since 3.15.0
+	IsPreferred bool `json:"isPreferred,omitempty"`
+	// Marks that the code action cannot currently be applied.
+	//
+	// @since 3.16.0
+	Disabled *CodeActionDisabled `json:"disabled,omitempty"`
+	// The workspace edit this code action performs.
+	Edit *WorkspaceEdit `json:"edit,omitempty"`
+	// A command this code action executes. If a code action
+	// provides an edit and a command, first the edit is
+	// executed and then the command.
+	Command *Command `json:"command,omitempty"`
+	// A data entry field that is preserved on a code action between
+	// a `textDocument/codeAction` and a `codeAction/resolve` request.
+	//
+	// @since 3.16.0
+	Data LSPAny `json:"data,omitempty"`
+}
+
+// A workspace edit represents changes to many resources managed in the workspace. The edit
+// should either provide `changes` or `documentChanges`. If documentChanges are present
+// they are preferred over `changes` if the client can handle versioned document edits.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspaceEdit
+type WorkspaceEdit struct {
+	// Holds changes to existing resources.
+	Changes map[string][]*TextEdit `json:"changes,omitempty"`
+}
+
+// CommandOrCodeAction contains either of the following types:
+//   - [*Command]
+//   - [*CodeAction]
+type CommandOrCodeAction struct {
+	Value CommandOrCodeActionValue
+}
+
+// CommandOrCodeActionValue is either of the following types:
+//   - [*Command]
+//   - [*CodeAction]
+//
+//gosumtype:decl CommandOrCodeActionValue
+type CommandOrCodeActionValue interface {
+	isCommandOrCodeActionValue()
+}
+
+func (*Command) isCommandOrCodeActionValue()    {}
+func (*CodeAction) isCommandOrCodeActionValue() {}
+
+// AsCommand returns the value of c and true if it's a [Command], otherwise it returns the
+// zero value and false.
+func (c CommandOrCodeAction) AsCommand() (*Command, bool) {
+	value, ok := c.Value.(*Command)
+	return value, ok
+}
+
+// IsCommand reports whether the value of c is a [Command].
+func (c CommandOrCodeAction) IsCommand() bool {
+	_, ok := c.Value.(*Command)
+	return ok
+}
+
+// AsCodeAction returns the value of c and true if it's a [CodeAction], otherwise it returns the
+// zero value and false.
+func (c CommandOrCodeAction) AsCodeAction() (*CodeAction, bool) {
+	value, ok := c.Value.(*CodeAction)
+	return value, ok
+}
+
+// IsCodeAction reports whether the value of c is a [CodeAction].
+func (c CommandOrCodeAction) IsCodeAction() bool {
+	_, ok := c.Value.(*CodeAction)
+	return ok
+}
+
+func (c *CommandOrCodeAction) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var commandValue *Command
+	if err := json.Unmarshal(data, &commandValue); err == nil {
+		c.Value = commandValue
+		return nil
+	}
+	var codeActionValue *CodeAction
+	if err := json.Unmarshal(data, &codeActionValue); err == nil {
+		c.Value = codeActionValue
+		return nil
+	}
+	return &json.UnmarshalTypeError{
+		Value: string(data),
+		Type:  reflect.TypeFor[*CommandOrCodeAction](),
+	}
+}
+
+func (c CommandOrCodeAction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Value)
+}
+
+// The parameters of a {@link CodeLensRequest}.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#codeLensParams
+type CodeLensParams struct {
+	*WorkDoneProgressParams
+	*PartialResultParams
+	// The document to request code lens for.
+	TextDocument *TextDocumentIdentifier `json:"textDocument"`
+}
+
+// A code lens represents a {@link Command command} that should be shown along with
+// source text, like the number of references, a way to run tests, etc.
+//
+// A code lens is _unresolved_ when no command is associated to it. For performance
+// reasons the creation of a code lens and resolving should be done in two stages.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#codeLens
+type CodeLens struct {
+	// The range in which this code lens is valid. Should only span a single line.
+	Range *Range `json:"range"`
+	// The command this code lens represents.
+	Command *Command `json:"command,omitempty"`
+	// A data entry field that is preserved on a code lens item between
+	// a {@link CodeLensRequest} and a {@link CodeLensResolveRequest}
+	Data LSPAny `json:"data,omitempty"`
+}
+
+// Parameters for a {@link HoverRequest}.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#hoverParams
+type HoverParams struct {
+	*TextDocumentPositionParams
+	*WorkDoneProgressParams
+}
+
+// The result of a hover request.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#hover
+type Hover struct {
+	// The hover's content
+	Contents *MarkupContent `json:"contents"`
+	// An optional range inside the text document that is used to
+	// visualize the hover, e.g. by changing the background color.
+	Range *Range `json:"range,omitempty"`
+}
+
+// A `MarkupContent` literal represents a string value which content is interpreted base on its
+// kind flag. Currently the protocol supports `plaintext` and `markdown` as markup kinds.
+//
+// If the kind is `markdown` then the value can contain fenced code blocks like in GitHub issues.
+// See https://help.github.com/articles/creating-and-highlighting-code-blocks/#syntax-highlighting
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#markupContent
+type MarkupContent struct {
+	// The type of the Markup
+	Kind MarkupKind `json:"kind"`
+	// The content itself
+	Value string `json:"value"`
+}
+
+// Parameters for a {@link ReferencesRequest}.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#referenceParams
+type ReferenceParams struct {
+	Context *ReferenceContext `json:"context"`
+	*TextDocumentPositionParams
+	*WorkDoneProgressParams
+	*PartialResultParams
+}
+
+// Value-object that contains additional information when
+// requesting references.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#referenceContext
+type ReferenceContext struct {
+	// Include the declaration of the current symbol.
+	IncludeDeclaration bool `json:"includeDeclaration"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#prepareRenameParams
+type PrepareRenameParams struct {
+	*TextDocumentPositionParams
+	*WorkDoneProgressParams
+}
+
+// The result of a {@link PrepareRenameRequest} which contains the range of the string to rename and the text of the
+// string which the client should use as the placeholder of the new name.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#prepareRenameResult
+type PrepareRenamePlaceholder struct {
+	Range       *Range `json:"range"`
+	Placeholder string `json:"placeholder"`
+}
+
+// The parameters of a {@link RenameRequest}.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#renameParams
+type RenameParams struct {
+	// The document to rename.
+	TextDocument *TextDocumentIdentifier `json:"textDocument"`
+	// The position at which this request was sent.
+	Position *Position `json:"position"`
+	// The new name of the symbol. If the given name is not valid the
+	// request must return a {@link ResponseError} with an
+	// appropriate message set.
+	NewName string `json:"newName"`
+	*WorkDoneProgressParams
+}
+
+// A set of predefined token types. This set is not fixed
+// an clients can specify additional token types via the
+// corresponding client capabilities.
+//
+// @since 3.16.0
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#semanticTokenTypes
+type SemanticTokenTypes string
+
+const (
+	SemanticTokenTypesNamespace SemanticTokenTypes = "namespace"
+	// Represents a generic type. Acts as a fallback for types which can't be mapped to
+	// a specific type like class or enum.
+	SemanticTokenTypesType          SemanticTokenTypes = "type"
+	SemanticTokenTypesClass         SemanticTokenTypes = "class"
+	SemanticTokenTypesEnum          SemanticTokenTypes = "enum"
+	SemanticTokenTypesInterface     SemanticTokenTypes = "interface"
+	SemanticTokenTypesStruct        SemanticTokenTypes = "struct"
+	SemanticTokenTypesTypeParameter SemanticTokenTypes = "typeParameter"
+	SemanticTokenTypesParameter     SemanticTokenTypes = "parameter"
+	SemanticTokenTypesVariable      SemanticTokenTypes = "variable"
+	SemanticTokenTypesProperty      SemanticTokenTypes = "property"
+	SemanticTokenTypesEnumMember    SemanticTokenTypes = "enumMember"
+	SemanticTokenTypesEvent         SemanticTokenTypes = "event"
+	SemanticTokenTypesFunction      SemanticTokenTypes = "function"
+	SemanticTokenTypesMethod        SemanticTokenTypes = "method"
+	SemanticTokenTypesMacro         SemanticTokenTypes = "macro"
+	SemanticTokenTypesKeyword       SemanticTokenTypes = "keyword"
+	SemanticTokenTypesModifier      SemanticTokenTypes = "modifier"
+	SemanticTokenTypesComment       SemanticTokenTypes = "comment"
+	SemanticTokenTypesString        SemanticTokenTypes = "string"
+	SemanticTokenTypesNumber        SemanticTokenTypes = "number"
+	SemanticTokenTypesRegexp        SemanticTokenTypes = "regexp"
+	SemanticTokenTypesOperator      SemanticTokenTypes = "operator"
+	// @since 3.17.0
+	SemanticTokenTypesDecorator SemanticTokenTypes = "decorator"
+)
+
+// String returns the value of s as it appears in the specification.
+func (s SemanticTokenTypes) String() string {
+	return string(s)
+}
+
+// A set of predefined token modifiers. This set is not fixed
+// an clients can specify additional token types via the
+// corresponding client capabilities.
+//
+// @since 3.16.0
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#semanticTokenModifiers
+type SemanticTokenModifiers string
+
+const (
+	SemanticTokenModifiersDeclaration    SemanticTokenModifiers = "declaration"
+	SemanticTokenModifiersDefinition     SemanticTokenModifiers = "definition"
+	SemanticTokenModifiersReadonly       SemanticTokenModifiers = "readonly"
+	SemanticTokenModifiersStatic         SemanticTokenModifiers = "static"
+	SemanticTokenModifiersDeprecated     SemanticTokenModifiers = "deprecated"
+	SemanticTokenModifiersAbstract       SemanticTokenModifiers = "abstract"
+	SemanticTokenModifiersAsync          SemanticTokenModifiers = "async"
+	SemanticTokenModifiersModification   SemanticTokenModifiers = "modification"
+	SemanticTokenModifiersDocumentation  SemanticTokenModifiers = "documentation"
+	SemanticTokenModifiersDefaultLibrary SemanticTokenModifiers = "defaultLibrary"
+)
+
+// String returns the value of s as it appears in the specification.
+func (s SemanticTokenModifiers) String() string {
+	return string(s)
+}
+
+// @since 3.16.0
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#semanticTokensParams
+type SemanticTokensParams struct {
+	// The text document.
+	TextDocument *TextDocumentIdentifier `json:"textDocument"`
+	*WorkDoneProgressParams
+	*PartialResultParams
+}
+
+// @since 3.16.0
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#semanticTokensRangeParams
+type SemanticTokensRangeParams struct {
+	// The text document.
+	TextDocument *TextDocumentIdentifier `json:"textDocument"`
+	// The range the semantic tokens are requested for.
+	Range *Range `json:"range"`
+	*WorkDoneProgressParams
+	*PartialResultParams
+}
+
+// @since 3.16.0
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#semanticTokens
+type SemanticTokens struct {
+	// An optional result id. If provided and clients support delta updating
+	// the client will include the result id in the next semantic token request.
+	// A server can then instead of computing all semantic tokens again simply
+	// send a delta.
+	ResultId string `json:"resultId,omitempty"`
+	// The actual tokens.
+	Data []uint32 `json:"data"`
+}
+
+// How a completion was triggered
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#completionTriggerKind
+type CompletionTriggerKind uint32
+
+const (
+	// Completion was triggered by typing an identifier (24x7 code
+	// complete), manual invocation (e.g Ctrl+Space) or via API.
+	CompletionTriggerKindInvoked CompletionTriggerKind = 1
+	// Completion was triggered by a trigger character specified by
+	// the `triggerCharacters` properties of the `CompletionRegistrationOptions`.
+	CompletionTriggerKindTriggerCharacter CompletionTriggerKind = 2
+	// Completion was re-triggered as current completion list is incomplete
+	CompletionTriggerKindTriggerForIncompleteCompletions CompletionTriggerKind = 3
+)
+
+// String returns the name of the constant which c is equal to.
+func (c CompletionTriggerKind) String() string {
+	switch c {
+	case CompletionTriggerKindInvoked:
+		return "CompletionTriggerKindInvoked"
+	case CompletionTriggerKindTriggerCharacter:
+		return "CompletionTriggerKindTriggerCharacter"
+	case CompletionTriggerKindTriggerForIncompleteCompletions:
+		return "CompletionTriggerKindTriggerForIncompleteCompletions"
+	default:
+		return fmt.Sprintf("CompletionTriggerKind(%d)", uint32(c))
+	}
+}
+
+var validCompletionTriggerKindValues = map[uint32]bool{
+	1: true,
+	2: true,
+	3: true,
+}
+
+func (c *CompletionTriggerKind) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var uint32Value uint32
+	if err := json.Unmarshal(data, &uint32Value); err != nil {
+		return err
+	}
+	if !validCompletionTriggerKindValues[uint32Value] {
+		return fmt.Errorf("cannot unmarshal %v into CompletionTriggerKind: custom values are not supported", uint32Value)
+	}
+	*c = CompletionTriggerKind(uint32Value)
+
+	return nil
+}
+
+func (c CompletionTriggerKind) MarshalJSON() ([]byte, error) {
+	var uint32Value = uint32(c)
+	if !validCompletionTriggerKindValues[uint32Value] {
+		return nil, fmt.Errorf("cannot marshal %v into CompletionTriggerKind: custom values are not supported", uint32Value)
+	}
+	return json.Marshal(uint32Value)
+
+}
+
+// Contains additional information about the context in which a completion request is triggered.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#completionContext
+type CompletionContext struct {
+	// How the completion was triggered.
+	TriggerKind CompletionTriggerKind `json:"triggerKind"`
+	// The trigger character (a single character) that has trigger code complete.
+	// Is undefined if `triggerKind !== CompletionTriggerKind.TriggerCharacter`
+	TriggerCharacter string `json:"triggerCharacter,omitempty"`
+}
+
+// Completion parameters
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#completionParams
+type CompletionParams struct {
+	// The completion context. This is only available it the client specifies
+	// to send this using the client capability `textDocument.completion.contextSupport === true`
+	Context *CompletionContext `json:"context,omitempty"`
+	*TextDocumentPositionParams
+	*WorkDoneProgressParams
+	*PartialResultParams
+}
+
+// Additional details for a completion item label.
+//
+// @since 3.17.0
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#completionItemLabelDetails
+type CompletionItemLabelDetails struct {
+	// An optional string which is rendered less prominently directly after {@link CompletionItem.label label},
+	// without any spacing. Should be used for function signatures and type annotations.
+	Detail string `json:"detail,omitempty"`
+	// An optional string which is rendered less prominently after {@link CompletionItem.detail}. Should be used
+	// for fully qualified names and file paths.
+	Description string `json:"description,omitempty"`
+}
+
+// A completion item represents a text snippet that is
+// proposed to complete text that is being typed.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#completionItem
+type CompletionItem struct {
+	// The label of this completion item.
+	//
+	// The label property is also by default the text that
+	// is inserted when selecting this completion.
+	//
+	// If label details are provided the label itself should
+	// be an unqualified name of the completion item.
+	Label string `json:"label"`
+	// Additional details for the label
+	//
+	// @since 3.17.0
+	LabelDetails *CompletionItemLabelDetails `json:"labelDetails,omitempty"`
+	// The kind of this completion item. Based of the kind
+	// an icon is chosen by the editor.
+	Kind CompletionItemKind `json:"kind,omitempty"`
+	// Tags for this completion item.
+	//
+	// @since 3.15.0
+	Tags []CompletionItemTag `json:"tags,omitempty"`
+	// A human-readable string with additional information
+	// about this item, like type or symbol information.
+	Detail string `json:"detail,omitempty"`
+	// Select this item when showing.
+	//
+	// *Note* that only one completion item can be selected and that the
+	// tool / client decides which item that is. The rule is that the *first*
+	// item of those that match best is selected.
+	Preselect bool `json:"preselect,omitempty"`
+	// A string that should be used when comparing this item
+	// with other items. When `falsy` the {@link CompletionItem.label label}
+	// is used.
+	SortText string `json:"sortText,omitempty"`
+	// A string that should be used when filtering a set of
+	// completion items. When `falsy` the {@link CompletionItem.label label}
+	// is used.
+	FilterText string `json:"filterText,omitempty"`
+	// A string that should be inserted into a document when selecting
+	// this completion. When `falsy` the {@link CompletionItem.label label}
+	// is used.
+	InsertText string `json:"insertText,omitempty"`
+	// An optional array of additional {@link TextEdit text edits} that are applied when
+	// selecting this completion. Edits must not overlap (including the same insert position)
+	// with the main {@link CompletionItem.textEdit edit} nor with themselves.
+	AdditionalTextEdits []*TextEdit `json:"additionalTextEdits,omitempty"`
+	// An optional set of characters that when pressed while this completion is active will accept it first and
+	// then type that character.
+	CommitCharacters []string `json:"commitCharacters,omitempty"`
+	// An optional {@link Command command} that is executed *after* inserting this completion.
+	Command *Command `json:"command,omitempty"`
+	// A data entry field that is preserved on a completion item between a
+	// {@link CompletionRequest} and a {@link CompletionResolveRequest}.
+	Data LSPAny `json:"data,omitempty"`
+}
+
+type CompletionItemSlice []*CompletionItem
+
+// Represents a collection of {@link CompletionItem completion items} to be presented
+// in the editor.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#completionList
+type CompletionList struct {
+	// This list it not complete. Further typing results in recomputing this list.
+	//
+	// Recomputed lists have all their items replaced (not appended) in the
+	// incomplete completion sessions.
+	IsIncomplete bool `json:"isIncomplete"`
+	// The completion items.
+	Items []*CompletionItem `json:"items"`
+}
+
+// CompletionItemSliceOrCompletionList contains either of the following types:
+//   - [CompletionItemSlice]
+//   - [*CompletionList]
+type CompletionItemSliceOrCompletionList struct {
+	Value CompletionItemSliceOrCompletionListValue
+}
+
+// CompletionItemSliceOrCompletionListValue is either of the following types:
+//   - [CompletionItemSlice]
+//   - [*CompletionList]
+//
+//gosumtype:decl CompletionItemSliceOrCompletionListValue
+type CompletionItemSliceOrCompletionListValue interface {
+	isCompletionItemSliceOrCompletionListValue()
+}
+
+func (CompletionItemSlice) isCompletionItemSliceOrCompletionListValue() {}
+func (*CompletionList) isCompletionItemSliceOrCompletionListValue()     {}
+
+// AsCompletionItemSlice returns the value of c and true if it's a [CompletionItemSlice], otherwise it returns the
+// zero value and false.
+func (c CompletionItemSliceOrCompletionList) AsCompletionItemSlice() (CompletionItemSlice, bool) {
+	value, ok := c.Value.(CompletionItemSlice)
+	return value, ok
+}
+
+// IsCompletionItemSlice reports whether the value of c is a [CompletionItemSlice].
+func (c CompletionItemSliceOrCompletionList) IsCompletionItemSlice() bool {
+	_, ok := c.Value.(CompletionItemSlice)
+	return ok
+}
+
+// AsCompletionList returns the value of c and true if it's a [CompletionList], otherwise it returns the
+// zero value and false.
+func (c CompletionItemSliceOrCompletionList) AsCompletionList() (*CompletionList, bool) {
+	value, ok := c.Value.(*CompletionList)
+	return value, ok
+}
+
+// IsCompletionList reports whether the value of c is a [CompletionList].
+func (c CompletionItemSliceOrCompletionList) IsCompletionList() bool {
+	_, ok := c.Value.(*CompletionList)
+	return ok
+}
+
+func (c *CompletionItemSliceOrCompletionList) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var completionItemSliceValue CompletionItemSlice
+	if err := json.Unmarshal(data, &completionItemSliceValue); err == nil {
+		c.Value = completionItemSliceValue
+		return nil
+	}
+	var completionListValue *CompletionList
+	if err := json.Unmarshal(data, &completionListValue); err == nil {
+		c.Value = completionListValue
+		return nil
+	}
+	return &json.UnmarshalTypeError{
+		Value: string(data),
+		Type:  reflect.TypeFor[*CompletionItemSliceOrCompletionList](),
+	}
+}
+
+func (c CompletionItemSliceOrCompletionList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Value)
+}
+
 // Predefined error codes.
 //
 // https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#errorCodes
@@ -5768,3 +7906,72 @@ const (
 	ErrorCodesServerNotInitialized ErrorCodes = -32002
 	ErrorCodesUnknownErrorCode     ErrorCodes = -32001
 )
+
+// String returns the name of the constant which e is equal to.
+func (e ErrorCodes) String() string {
+	switch e {
+	case ErrorCodesParseError:
+		return "ErrorCodesParseError"
+	case ErrorCodesInvalidRequest:
+		return "ErrorCodesInvalidRequest"
+	case ErrorCodesMethodNotFound:
+		return "ErrorCodesMethodNotFound"
+	case ErrorCodesInvalidParams:
+		return "ErrorCodesInvalidParams"
+	case ErrorCodesInternalError:
+		return "ErrorCodesInternalError"
+	case ErrorCodesServerNotInitialized:
+		return "ErrorCodesServerNotInitialized"
+	case ErrorCodesUnknownErrorCode:
+		return "ErrorCodesUnknownErrorCode"
+	default:
+		return fmt.Sprintf("ErrorCodes(%d)", int32(e))
+	}
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#lSPErrorCodes
+type LSPErrorCodes int32
+
+const (
+	// A request failed but it was syntactically correct, e.g the
+	// method name was known and the parameters were valid. The error
+	// message should contain human readable information about why
+	// the request failed.
+	//
+	// @since 3.17.0
+	LSPErrorCodesRequestFailed LSPErrorCodes = -32803
+	// The server cancelled the request. This error code should
+	// only be used for requests that explicitly support being
+	// server cancellable.
+	//
+	// @since 3.17.0
+	LSPErrorCodesServerCancelled LSPErrorCodes = -32802
+	// The server detected that the content of a document got
+	// modified outside normal conditions. A server should
+	// NOT send this error code if it detects a content change
+	// in it unprocessed messages. The result even computed
+	// on an older state might still be useful for the client.
+	//
+	// If a client decides that a result is not of any use anymore
+	// the client should cancel the request.
+	LSPErrorCodesContentModified LSPErrorCodes = -32801
+	// The client has canceled a request and a server has detected
+	// the cancel.
+	LSPErrorCodesRequestCancelled LSPErrorCodes = -32800
+)
+
+// String returns the name of the constant which l is equal to.
+func (l LSPErrorCodes) String() string {
+	switch l {
+	case LSPErrorCodesRequestFailed:
+		return "LSPErrorCodesRequestFailed"
+	case LSPErrorCodesServerCancelled:
+		return "LSPErrorCodesServerCancelled"
+	case LSPErrorCodesContentModified:
+		return "LSPErrorCodesContentModified"
+	case LSPErrorCodesRequestCancelled:
+		return "LSPErrorCodesRequestCancelled"
+	default:
+		return fmt.Sprintf("LSPErrorCodes(%d)", int32(l))
+	}
+}
@@ -0,0 +1,159 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// The types in this file are used by the textDocument/signatureHelp request. They're written by hand since typegen
+// doesn't support the tuple type which can be used as the label of a ParameterInformation. Only the string form of the
+// label is supported.
+
+// How a signature help was triggered.
+//
+// @since 3.15.0
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#signatureHelpTriggerKind
+type SignatureHelpTriggerKind uint32
+
+const (
+	// Signature help was invoked manually by the user or by a command.
+	SignatureHelpTriggerKindInvoked SignatureHelpTriggerKind = 1
+	// Signature help was triggered by a trigger character.
+	SignatureHelpTriggerKindTriggerCharacter SignatureHelpTriggerKind = 2
+	// Signature help was triggered by the cursor moving or by the document content changing.
+	SignatureHelpTriggerKindContentChange SignatureHelpTriggerKind = 3
+)
+
+// String returns the name of the constant which s is equal to.
+func (s SignatureHelpTriggerKind) String() string {
+	switch s {
+	case SignatureHelpTriggerKindInvoked:
+		return "SignatureHelpTriggerKindInvoked"
+	case SignatureHelpTriggerKindTriggerCharacter:
+		return "SignatureHelpTriggerKindTriggerCharacter"
+	case SignatureHelpTriggerKindContentChange:
+		return "SignatureHelpTriggerKindContentChange"
+	default:
+		return fmt.Sprintf("SignatureHelpTriggerKind(%d)", uint32(s))
+	}
+}
+
+var validSignatureHelpTriggerKindValues = map[uint32]bool{
+	1: true,
+	2: true,
+	3: true,
+}
+
+func (s *SignatureHelpTriggerKind) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var uint32Value uint32
+	if err := json.Unmarshal(data, &uint32Value); err != nil {
+		return err
+	}
+	if !validSignatureHelpTriggerKindValues[uint32Value] {
+		return fmt.Errorf("cannot unmarshal %v into SignatureHelpTriggerKind: custom values are not supported", uint32Value)
+	}
+	*s = SignatureHelpTriggerKind(uint32Value)
+
+	return nil
+}
+
+func (s SignatureHelpTriggerKind) MarshalJSON() ([]byte, error) {
+	var uint32Value = uint32(s)
+	if !validSignatureHelpTriggerKindValues[uint32Value] {
+		return nil, fmt.Errorf("cannot marshal %v into SignatureHelpTriggerKind: custom values are not supported", uint32Value)
+	}
+	return json.Marshal(uint32Value)
+
+}
+
+// Additional information about the context in which a signature help request was triggered.
+//
+// @since 3.15.0
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#signatureHelpContext
+type SignatureHelpContext struct {
+	// Action that caused signature help to be triggered.
+	TriggerKind SignatureHelpTriggerKind `json:"triggerKind"`
+	// Character that caused signature help to be triggered.
+	//
+	// This is undefined when `triggerKind !== SignatureHelpTriggerKind.TriggerCharacter`
+	TriggerCharacter string `json:"triggerCharacter,omitempty"`
+	// `true` if signature help was already showing when it was triggered.
+	//
+	// Retriggers occurs when the signature help is already active and can be caused by actions such as
+	// typing a trigger character, a cursor move, or document content changes.
+	IsRetrigger bool `json:"isRetrigger"`
+	// The currently active `SignatureHelp`.
+	//
+	// The `activeSignatureHelp` has its `SignatureHelp.activeSignature` field updated based on
+	// the user navigating through available signatures.
+	ActiveSignatureHelp *SignatureHelp `json:"activeSignatureHelp,omitempty"`
+}
+
+// Parameters for a {@link SignatureHelpRequest}.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#signatureHelpParams
+type SignatureHelpParams struct {
+	// The signature help context. This is only available if the client specifies
+	// to send this using the client capability `textDocument.signatureHelp.contextSupport === true`
+	//
+	// @since 3.15.0
+	Context *SignatureHelpContext `json:"context,omitempty"`
+	*TextDocumentPositionParams
+	*WorkDoneProgressParams
+}
+
+// Signature help represents the signature of something
+// callable. There can be multiple signature but only one
+// active and only one active parameter.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#signatureHelp
+type SignatureHelp struct {
+	// One or more signatures.
+	Signatures []*SignatureInformation `json:"signatures"`
+	// The active signature. If omitted or the value lies outside the
+	// range of `signatures` the value defaults to zero or is ignored if
+	// the `SignatureHelp` has no signatures.
+	ActiveSignature uint32 `json:"activeSignature,omitempty"`
+	// The active parameter of the active signature. If omitted or the value
+	// lies outside the range of `signatures[activeSignature].parameters`
+	// defaults to 0 if the active signature has parameters. If
+	// the active signature has no parameters it is ignored.
+	ActiveParameter uint32 `json:"activeParameter,omitempty"`
+}
+
+// Represents the signature of something callable. A signature
+// can have a label, like a function-name, a doc-comment, and
+// a set of parameters.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#signatureInformation
+type SignatureInformation struct {
+	// The label of this signature. Will be shown in
+	// the UI.
+	Label string `json:"label"`
+	// The human-readable doc-comment of this signature. Will be shown
+	// in the UI but can be omitted.
+	Documentation *MarkupContent `json:"documentation,omitempty"`
+	// The parameters of this signature.
+	Parameters []*ParameterInformation `json:"parameters,omitempty"`
+}
+
+// Represents a parameter of a callable-signature. A parameter can
+// have a label and a doc-comment.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#parameterInformation
+type ParameterInformation struct {
+	// The label of this parameter information.
+	//
+	// The string is a substring of its containing signature label and it's used to find the range of the
+	// parameter in the signature label.
+	Label string `json:"label"`
+	// The human-readable doc-comment of this parameter. Will be shown
+	// in the UI but can be omitted.
+	Documentation *MarkupContent `json:"documentation,omitempty"`
+}
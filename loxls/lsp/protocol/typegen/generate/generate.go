@@ -70,7 +70,7 @@ import (
 	data := map[string]any{
 		"args":             strings.Join(os.Args[1:], " "),
 		"package":          g.pkg,
-		"importedPackages": slices.Collect(maps.Keys(g.importedPkgs)),
+		"importedPackages": slices.Sorted(maps.Keys(g.importedPkgs)),
 		"typeDeclarations": g.typeDecls,
 	}
 	return mustExecuteTemplate(text, data)
@@ -253,6 +253,27 @@ const (
 	{{- end}}
 )
 
+{{with $receiver := slice $.name 0 1 | lowerFirstLetter}}
+{{- if eq $.type "string"}}
+// String returns the value of {{$receiver}} as it appears in the specification.
+func ({{$receiver}} {{$.name}}) String() string {
+	return string({{$receiver}})
+}
+{{- else}}
+// String returns the name of the constant which {{$receiver}} is equal to.
+func ({{$receiver}} {{$.name}}) String() string {
+	switch {{$receiver}} {
+	{{- range $.members}}
+	case {{.Name}}:
+		return "{{.Name}}"
+	{{- end}}
+	default:
+		return fmt.Sprintf("{{$.name}}(%d)", {{$.type}}({{$receiver}}))
+	}
+}
+{{- end}}
+{{end}}
+
 {{if not .supportsCustomValues}}
 {{with $validValuesVar := printf "valid%sValues" .name}}
 var {{$validValuesVar}} = map[{{$.type}}]bool{
@@ -374,6 +395,23 @@ func ({{.}}) {{$interfaceMethod}}() {}
 {{- end}}
 
 {{with $receiver := slice .name 0 1 | lowerFirstLetter}}
+{{- range $variant := $.variants}}
+{{with $variantName := trimStarPrefix $variant}}
+// As{{$variantName}} returns the value of {{$receiver}} and true if it's a [{{$variantName}}], otherwise it returns the
+// zero value and false.
+func ({{$receiver}} {{$.name}}) As{{$variantName}}() ({{$variant}}, bool) {
+	value, ok := {{$receiver}}.Value.({{$variant}})
+	return value, ok
+}
+
+// Is{{$variantName}} reports whether the value of {{$receiver}} is a [{{$variantName}}].
+func ({{$receiver}} {{$.name}}) Is{{$variantName}}() bool {
+	_, ok := {{$receiver}}.Value.({{$variant}})
+	return ok
+}
+{{- end}}
+{{- end}}
+
 func ({{$receiver}} *{{$.name}}) UnmarshalJSON(data []byte) error {
 	if bytes.Equal(data, []byte("null")) {
 		return nil
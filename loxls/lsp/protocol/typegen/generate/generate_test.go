@@ -0,0 +1,140 @@
+package generate
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/marcuscaisey/lox/loxls/lsp/protocol/typegen/metamodel"
+)
+
+func ref(name string) *metamodel.Type {
+	return &metamodel.Type{Value: metamodel.ReferenceType{Kind: "reference", Name: name}}
+}
+
+func base(name metamodel.BaseTypes) *metamodel.Type {
+	return &metamodel.Type{Value: metamodel.BaseType{Kind: "base", Name: name}}
+}
+
+func or(items ...*metamodel.Type) *metamodel.Type {
+	return &metamodel.Type{Value: metamodel.OrType{Kind: "or", Items: items}}
+}
+
+func TestSumTypeAccessors(t *testing.T) {
+	model := &metamodel.MetaModel{
+		MetaData: metamodel.MetaData{Version: "3.17.0"},
+		Structures: []*metamodel.Structure{
+			{
+				Name:       "Location",
+				Properties: []*metamodel.Property{{Name: "uri", Type: base(metamodel.BaseTypesString)}},
+			},
+			{
+				Name: "Result",
+				Properties: []*metamodel.Property{
+					{Name: "value", Type: or(ref("Location"), base(metamodel.BaseTypesBoolean))},
+					{
+						Name: "nullable",
+						Type: or(
+							ref("Location"),
+							&metamodel.Type{Value: metamodel.ArrayType{Kind: "array", Element: ref("Location")}},
+							base(metamodel.BaseTypesNull),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	src := Source([]*metamodel.Type{ref("Result")}, model, "protocol")
+
+	for _, want := range []string{
+		"func (l LocationOrBoolean) AsLocation() (*Location, bool) {",
+		"func (l LocationOrBoolean) IsLocation() bool {",
+		"func (l LocationOrBoolean) AsBoolean() (Boolean, bool) {",
+		"func (l LocationOrBoolean) IsBoolean() bool {",
+		"func (l LocationOrLocationSlice) AsLocation() (*Location, bool) {",
+		"func (l LocationOrLocationSlice) AsLocationSlice() (LocationSlice, bool) {",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated source does not contain %q:\n%s", want, src)
+		}
+	}
+
+	// Check that the accessors can be used through the pointers which are generated for nullable unions.
+	usage := `
+func useAccessors(r *Result) bool {
+	_, ok := r.Nullable.AsLocationSlice()
+	return ok && r.Value.IsBoolean()
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "protocol.go", src+usage, parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatalf("parsing generated source: %s", err)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	if _, err := conf.Check("protocol", fset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("type checking generated source: %s\n%s", err, src)
+	}
+}
+
+func TestImportsSorted(t *testing.T) {
+	model := &metamodel.MetaModel{
+		MetaData: metamodel.MetaData{Version: "3.17.0"},
+		Structures: []*metamodel.Structure{
+			{
+				Name:       "Result",
+				Properties: []*metamodel.Property{{Name: "value", Type: or(base(metamodel.BaseTypesString), base(metamodel.BaseTypesBoolean))}},
+			},
+		},
+	}
+	want := "import (\n\n\t\"bytes\"\n\t\"encoding/json\"\n\t\"reflect\"\n)"
+
+	// Map iteration order is random, so generate a few times to make it unlikely that the imports are sorted by chance.
+	for range 10 {
+		src := Source([]*metamodel.Type{ref("Result")}, model, "protocol")
+		if !strings.Contains(src, want) {
+			t.Fatalf("generated source does not contain imports %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestOptionalSliceAndMapFields(t *testing.T) {
+	array := &metamodel.Type{Value: metamodel.ArrayType{Kind: "array", Element: base(metamodel.BaseTypesString)}}
+	mapType := &metamodel.Type{Value: metamodel.MapType{
+		Kind:  "map",
+		Key:   metamodel.MapKeyType{Value: metamodel.BaseMapKeyType{Kind: "base", Name: metamodel.BaseMapKeyTypeNameString}},
+		Value: base(metamodel.BaseTypesInteger),
+	}}
+	model := &metamodel.MetaModel{
+		MetaData: metamodel.MetaData{Version: "3.17.0"},
+		Structures: []*metamodel.Structure{
+			{
+				Name: "Result",
+				Properties: []*metamodel.Property{
+					{Name: "optionalArray", Type: array, Optional: true},
+					{Name: "optionalMap", Type: mapType, Optional: true},
+					{Name: "requiredArray", Type: array},
+					{Name: "requiredMap", Type: mapType},
+				},
+			},
+		},
+	}
+
+	src := Source([]*metamodel.Type{ref("Result")}, model, "protocol")
+
+	for _, want := range []string{
+		"OptionalArray []string `json:\"optionalArray,omitempty\"`",
+		"OptionalMap map[string]int `json:\"optionalMap,omitempty\"`",
+		"RequiredArray []string `json:\"requiredArray\"`",
+		"RequiredMap map[string]int `json:\"requiredMap\"`",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated source does not contain %q:\n%s", want, src)
+		}
+	}
+}
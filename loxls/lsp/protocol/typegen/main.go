@@ -76,26 +76,39 @@ func typeGen() error {
 		return err
 	}
 
-	types, err := metaModel.MethodTypes(methods)
+	src, err := generateSource(methods, metaModel, *pkg)
 	if err != nil {
 		return err
 	}
 
-	types = append(types, &metamodel.Type{
-		Value: metamodel.ReferenceType{
-			Kind: "",
-			Name: "ErrorCodes",
-		},
-	})
+	return os.WriteFile(*output, src, 0644)
+}
 
-	src := generate.Source(types, metaModel, *pkg)
+// generateSource generates the formatted source of a Go file which belongs to the given package and contains the types
+// required to implement handlers for the given methods.
+func generateSource(methods []string, metaModel *metamodel.MetaModel, pkg string) ([]byte, error) {
+	types, err := metaModel.MethodTypes(methods)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{"ErrorCodes", "LSPErrorCodes"} {
+		types = append(types, &metamodel.Type{
+			Value: metamodel.ReferenceType{
+				Kind: "",
+				Name: name,
+			},
+		})
+	}
+
+	src := generate.Source(types, metaModel, pkg)
 
 	formattedSrc, err := format.Source([]byte(src))
 	if err != nil {
-		return fmt.Errorf("formatting generated file: %s\ncontents: %s", err, src)
+		return nil, fmt.Errorf("formatting generated file: %s\ncontents: %s", err, src)
 	}
 
-	return os.WriteFile(*output, formattedSrc, 0644)
+	return formattedSrc, nil
 }
 
 func parseMethodComments() ([]string, error) {
@@ -103,7 +116,11 @@ func parseMethodComments() ([]string, error) {
 	if filename == "" {
 		return nil, nil
 	}
+	return readMethodComments(filename)
+}
 
+// readMethodComments returns the methods specified by the method comments in the given file.
+func readMethodComments(filename string) ([]string, error) {
 	f, err := os.Open(filename)
 	if err != nil {
 		return nil, fmt.Errorf("parsing %s comments: %s", methodCommentDirective, err)
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/marcuscaisey/lox/loxls/lsp/protocol/typegen/metamodel"
+)
+
+// TestProtocolUpToDate checks that protocol.go is the output of running typegen with the methods listed in generate.go,
+// so that it's never edited by hand. It's skipped if the meta model hasn't been downloaded, which running go generate in
+// loxls/lsp/protocol does.
+func TestProtocolUpToDate(t *testing.T) {
+	metaModel, err := metamodel.LoadCached(*lspVersion)
+	if errors.Is(err, metamodel.ErrNotCached) {
+		t.Skipf("LSP %s meta model not cached, run go generate in loxls/lsp/protocol to download it", *lspVersion)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	methods, err := readMethodComments("../generate.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := generateSource(methods, metaModel, "protocol")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := os.ReadFile("../protocol.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("protocol.go is out of date, run go generate in loxls/lsp/protocol to regenerate it")
+	}
+}
@@ -3,6 +3,7 @@ package metamodel
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -18,7 +19,29 @@ func Load(version string) (*MetaModel, error) {
 	if err != nil {
 		return nil, fmt.Errorf("loading meta model: %s", err)
 	}
+	return parse(data)
+}
+
+// ErrNotCached is returned by [LoadCached] if the meta model hasn't been downloaded yet.
+var ErrNotCached = errors.New("meta model not cached")
+
+// LoadCached is like [Load] but only loads the meta model from the cache. [ErrNotCached] is returned if it hasn't been
+// downloaded yet.
+func LoadCached(version string) (*MetaModel, error) {
+	path, err := cachePath(version)
+	if err != nil {
+		return nil, fmt.Errorf("loading meta model: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotCached
+	} else if err != nil {
+		return nil, fmt.Errorf("loading meta model: reading from cache: %w", err)
+	}
+	return parse(data)
+}
 
+func parse(data []byte) (*MetaModel, error) {
 	var model *MetaModel
 	decoder := json.NewDecoder(bytes.NewReader(data))
 	decoder.DisallowUnknownFields() // This should catch any updates to the model that we're not aware of.
@@ -29,12 +52,20 @@ func Load(version string) (*MetaModel, error) {
 	return model, nil
 }
 
-func readOrDownload(version string) ([]byte, error) {
+// cachePath returns the path that the meta model for the given LSP version is cached at.
+func cachePath(version string) (string, error) {
 	cacheDir, err := os.UserCacheDir()
 	if err != nil {
-		return nil, fmt.Errorf("checking cache directory: %w", err)
+		return "", fmt.Errorf("checking cache directory: %w", err)
+	}
+	return fmt.Sprintf("%s/loxls/typegen/metamodels/%s.json", cacheDir, version), nil
+}
+
+func readOrDownload(version string) ([]byte, error) {
+	cachePath, err := cachePath(version)
+	if err != nil {
+		return nil, err
 	}
-	cachePath := fmt.Sprintf("%s/loxls/typegen/metamodels/%s.json", cacheDir, version)
 	if data, err := os.ReadFile(cachePath); err == nil {
 		return data, nil
 	} else if !os.IsNotExist(err) {
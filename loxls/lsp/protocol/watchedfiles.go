@@ -0,0 +1,151 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// The types in this file are used to register for and receive workspace/didChangeWatchedFiles notifications. They're
+// written by hand so that the glob pattern of a FileSystemWatcher can be a plain string, instead of the union of a
+// pattern and a relative pattern, and so that registration options can be any value. Only the string form of the glob
+// pattern is supported.
+
+// General parameters to register for a notification or to register a provider.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#registration
+type Registration struct {
+	// The id used to register the request. The id can be used to deregister
+	// the request again.
+	Id string `json:"id"`
+	// The method / capability to register for.
+	Method string `json:"method"`
+	// Options necessary for the registration.
+	RegisterOptions any `json:"registerOptions,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#registrationParams
+type RegistrationParams struct {
+	Registrations []*Registration `json:"registrations"`
+}
+
+// Describe options to be used when registered for text document change events.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#didChangeWatchedFilesRegistrationOptions
+type DidChangeWatchedFilesRegistrationOptions struct {
+	// The watchers to register.
+	Watchers []*FileSystemWatcher `json:"watchers"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#fileSystemWatcher
+type FileSystemWatcher struct {
+	// The glob pattern to watch. See {@link GlobPattern glob pattern} for more detail.
+	GlobPattern string `json:"globPattern"`
+	// The kind of events of interest. If omitted it defaults
+	// to WatchKind.Create | WatchKind.Change | WatchKind.Delete
+	// which is 7.
+	Kind WatchKind `json:"kind,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#watchKind
+type WatchKind uint32
+
+const (
+	// Interested in create events.
+	WatchKindCreate WatchKind = 1
+	// Interested in change events
+	WatchKindChange WatchKind = 2
+	// Interested in delete events
+	WatchKindDelete WatchKind = 4
+)
+
+// String returns the name of the constant which w is equal to.
+func (w WatchKind) String() string {
+	switch w {
+	case WatchKindCreate:
+		return "WatchKindCreate"
+	case WatchKindChange:
+		return "WatchKindChange"
+	case WatchKindDelete:
+		return "WatchKindDelete"
+	default:
+		return fmt.Sprintf("WatchKind(%d)", uint32(w))
+	}
+}
+
+// The watched files change notification's parameters.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#didChangeWatchedFilesParams
+type DidChangeWatchedFilesParams struct {
+	// The actual file events.
+	Changes []*FileEvent `json:"changes"`
+}
+
+// An event describing a file change.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#fileEvent
+type FileEvent struct {
+	// The file's uri.
+	Uri string `json:"uri"`
+	// The change type.
+	Type FileChangeType `json:"type"`
+}
+
+// The file event type
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#fileChangeType
+type FileChangeType uint32
+
+const (
+	// The file got created.
+	FileChangeTypeCreated FileChangeType = 1
+	// The file got changed.
+	FileChangeTypeChanged FileChangeType = 2
+	// The file got deleted.
+	FileChangeTypeDeleted FileChangeType = 3
+)
+
+// String returns the name of the constant which f is equal to.
+func (f FileChangeType) String() string {
+	switch f {
+	case FileChangeTypeCreated:
+		return "FileChangeTypeCreated"
+	case FileChangeTypeChanged:
+		return "FileChangeTypeChanged"
+	case FileChangeTypeDeleted:
+		return "FileChangeTypeDeleted"
+	default:
+		return fmt.Sprintf("FileChangeType(%d)", uint32(f))
+	}
+}
+
+var validFileChangeTypeValues = map[uint32]bool{
+	1: true,
+	2: true,
+	3: true,
+}
+
+func (f *FileChangeType) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var uint32Value uint32
+	if err := json.Unmarshal(data, &uint32Value); err != nil {
+		return err
+	}
+	if !validFileChangeTypeValues[uint32Value] {
+		return fmt.Errorf("cannot unmarshal %v into FileChangeType: custom values are not supported", uint32Value)
+	}
+	*f = FileChangeType(uint32Value)
+
+	return nil
+}
+
+func (f FileChangeType) MarshalJSON() ([]byte, error) {
+	var uint32Value = uint32(f)
+	if !validFileChangeTypeValues[uint32Value] {
+		return nil, fmt.Errorf("cannot marshal %v into FileChangeType: custom values are not supported", uint32Value)
+	}
+	return json.Marshal(uint32Value)
+
+}
@@ -0,0 +1,110 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/marcuscaisey/lox/loxls/lsp/protocol"
+)
+
+// settingsSection is the section of the client's configuration which contains the server's settings.
+const settingsSection = "loxls"
+
+// settings are the user configurable settings of the server.
+type settings struct {
+	// Strict enables checks which aren't required for a program to be valid, such as reporting identifiers which are
+	// declared and never used.
+	Strict bool `json:"strict"`
+	// ReportShadowedBuiltins enables warnings for declarations which shadow a built-in function.
+	ReportShadowedBuiltins bool `json:"reportShadowedBuiltins"`
+	// ReportShadowedDeclarations enables warnings for local declarations which shadow one in an enclosing scope.
+	ReportShadowedDeclarations bool `json:"reportShadowedDeclarations"`
+	// IndentSize is the number of spaces used for each level of indentation when formatting.
+	IndentSize int `json:"indentSize"`
+}
+
+func defaultSettings() settings {
+	return settings{
+		Strict:                     true,
+		ReportShadowedDeclarations: true,
+		IndentSize:                 4,
+	}
+}
+
+// settingsStore holds the current settings of the server. It's safe for concurrent use.
+// Settings are provided by the client in the initializationOptions of the initialize request and are updated by the
+// workspace/didChangeConfiguration notification.
+type settingsStore struct {
+	mu       sync.RWMutex
+	settings settings
+}
+
+func newSettingsStore() *settingsStore {
+	return &settingsStore{settings: defaultSettings()}
+}
+
+// Get returns the current settings.
+func (s *settingsStore) Get() settings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings
+}
+
+// Update replaces the current settings with those in the given value. Settings which aren't present are reset to their
+// default value. The settings can either be provided at the top level of the value or nested under a "loxls" key.
+func (s *settingsStore) Update(value protocol.LSPAny) error {
+	newSettings, err := parseSettings(value)
+	if err != nil {
+		return fmt.Errorf("updating settings: %s", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings = newSettings
+	return nil
+}
+
+func parseSettings(value protocol.LSPAny) (settings, error) {
+	settings := defaultSettings()
+	if value == nil || value.Value == nil {
+		return settings, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return settings, err
+	}
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return settings, errors.New("settings must be an object")
+	}
+	if section, ok := sections[settingsSection]; ok {
+		data = section
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, err
+	}
+
+	if settings.IndentSize <= 0 {
+		return settings, fmt.Errorf("indentSize must be positive, got %d", settings.IndentSize)
+	}
+	return settings, nil
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspace_didChangeConfiguration
+func (h *Handler) workspaceDidChangeConfiguration(params *protocol.DidChangeConfigurationParams) error {
+	if err := h.settings.Update(params.Settings); err != nil {
+		return fmt.Errorf("workspace/didChangeConfiguration: %s", err)
+	}
+	for _, doc := range h.docsByURI {
+		if _, ok := h.pendingChangesByURI[doc.URI]; ok {
+			// The document will be analysed with the new settings when its pending change is applied.
+			continue
+		}
+		if err := h.updateDoc(doc.URI, doc.Version, doc.Text); err != nil {
+			return fmt.Errorf("workspace/didChangeConfiguration: %s", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package lsp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/marcuscaisey/lox/loxls/lsp/protocol"
+)
+
+func TestParseSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    settings
+		wantErr string
+	}{
+		{
+			name:  "Null",
+			value: `null`,
+			want:  defaultSettings(),
+		},
+		{
+			name:  "TopLevel",
+			value: `{"strict": false, "indentSize": 2}`,
+			want:  settings{Strict: false, ReportShadowedDeclarations: true, IndentSize: 2},
+		},
+		{
+			name:  "NestedUnderLoxls",
+			value: `{"loxls": {"reportShadowedBuiltins": true, "reportShadowedDeclarations": false}}`,
+			want:  settings{Strict: true, ReportShadowedBuiltins: true, IndentSize: 4},
+		},
+		{
+			name:  "NestedSectionTakesPrecedence",
+			value: `{"strict": false, "loxls": {"indentSize": 8}}`,
+			want:  settings{Strict: true, ReportShadowedDeclarations: true, IndentSize: 8},
+		},
+		{
+			name:  "UnknownSettingsIgnored",
+			value: `{"colour": "blue"}`,
+			want:  defaultSettings(),
+		},
+		{
+			name:    "NotObject",
+			value:   `"strict"`,
+			wantErr: "settings must be an object",
+		},
+		{
+			name:    "InvalidType",
+			value:   `{"strict": "yes"}`,
+			wantErr: "cannot unmarshal string",
+		},
+		{
+			name:    "InvalidNestedType",
+			value:   `{"loxls": {"indentSize": "4"}}`,
+			wantErr: "cannot unmarshal string",
+		},
+		{
+			name:    "NonPositiveIndentSize",
+			value:   `{"indentSize": 0}`,
+			wantErr: "indentSize must be positive, got 0",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var value protocol.LSPAny
+			if err := json.Unmarshal([]byte(test.value), &value); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := parseSettings(value)
+
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("parseSettings(%s) returned error %v, want error containing %q", test.value, err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSettings(%s) returned error: %s", test.value, err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("parseSettings(%s) returned incorrect settings (-want +got):\n%s", test.value, diff)
+			}
+		})
+	}
+}
+
+func TestDidChangeConfigurationRepublishesDiagnostics(t *testing.T) {
+	const uri = "file:///test.lox"
+	s := startServer(t)
+	s.Initialize(t, nil)
+
+	diagnosticMessages := func() []string {
+		t.Helper()
+		var params protocol.PublishDiagnosticsParams
+		if err := json.Unmarshal(s.WaitForNotification(t, "textDocument/publishDiagnostics"), &params); err != nil {
+			t.Fatal(err)
+		}
+		msgs := []string{}
+		for _, diagnostic := range params.Diagnostics {
+			msgs = append(msgs, diagnostic.Message)
+		}
+		return msgs
+	}
+	assertDiagnostics := func(want ...string) {
+		t.Helper()
+		if want == nil {
+			want = []string{}
+		}
+		if diff := cmp.Diff(want, diagnosticMessages()); diff != "" {
+			t.Errorf("incorrect diagnostics (-want +got):\n%s", diff)
+		}
+	}
+
+	const src = "fun f() {\n    var x = 1;\n}\nf();\n"
+	s.Notify(t, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": src},
+	})
+	assertDiagnostics("x has been declared but is never used")
+
+	s.Notify(t, "workspace/didChangeConfiguration", map[string]any{"settings": map[string]any{"loxls": map[string]any{"strict": false}}})
+	assertDiagnostics()
+
+	// Invalid settings are reported and the current settings are kept.
+	s.Notify(t, "workspace/didChangeConfiguration", map[string]any{"settings": map[string]any{"strict": "no"}})
+	var logParams protocol.LogMessageParams
+	if err := json.Unmarshal(s.WaitForNotification(t, "window/logMessage"), &logParams); err != nil {
+		t.Fatal(err)
+	}
+	if logParams.Type != protocol.MessageTypeError || !strings.Contains(logParams.Message, "workspace/didChangeConfiguration") {
+		t.Errorf("window/logMessage params = %+v, want error from workspace/didChangeConfiguration", logParams)
+	}
+	s.Notify(t, "textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": uri, "version": 2},
+		"contentChanges": []map[string]any{{"text": src}},
+	})
+	assertDiagnostics()
+
+	// Settings which aren't provided are reset to their default value.
+	s.Notify(t, "workspace/didChangeConfiguration", map[string]any{"settings": map[string]any{}})
+	assertDiagnostics("x has been declared but is never used")
+}
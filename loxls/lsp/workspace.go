@@ -0,0 +1,206 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/marcuscaisey/lox/lox/ast"
+	"github.com/marcuscaisey/lox/lox/parser"
+	"github.com/marcuscaisey/lox/lox/token"
+	"github.com/marcuscaisey/lox/loxls/jsonrpc"
+	"github.com/marcuscaisey/lox/loxls/lsp/protocol"
+)
+
+const loxFileExt = ".lox"
+
+// workspaceIndex caches the paths of the Lox files in the workspace and the programs that they contain, so that they
+// don't have to be found and parsed again for every request which looks at the whole workspace. It's kept up to date
+// with workspace/didChangeWatchedFiles notifications.
+type workspaceIndex struct {
+	paths         []string // nil if the workspace hasn't been scanned since the last file was created or deleted
+	programsByURI map[string]ast.Program
+}
+
+// watchWorkspaceFiles asks the client to notify the server of changes to Lox files so that the workspace can be
+// indexed. The workspace isn't indexed if the client doesn't support this.
+func (h *Handler) watchWorkspaceFiles() error {
+	if !h.clientSupportsWatchedFilesRegistration {
+		return nil
+	}
+	err := h.client.ClientRegisterCapability(&protocol.RegistrationParams{
+		Registrations: []*protocol.Registration{
+			{
+				Id:     "workspace/didChangeWatchedFiles",
+				Method: "workspace/didChangeWatchedFiles",
+				RegisterOptions: &protocol.DidChangeWatchedFilesRegistrationOptions{
+					Watchers: []*protocol.FileSystemWatcher{{GlobPattern: "**/*" + loxFileExt}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("watching workspace files: %s", err)
+	}
+	h.workspaceIndex = &workspaceIndex{programsByURI: map[string]ast.Program{}}
+	return nil
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspace_didChangeWatchedFiles
+func (h *Handler) workspaceDidChangeWatchedFiles(params *protocol.DidChangeWatchedFilesParams) error {
+	if h.workspaceIndex == nil {
+		return nil
+	}
+	for _, change := range params.Changes {
+		delete(h.workspaceIndex.programsByURI, change.Uri)
+		if change.Type != protocol.FileChangeTypeChanged {
+			h.workspaceIndex.paths = nil
+		}
+	}
+	return nil
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspace_symbol
+func (h *Handler) workspaceSymbol(ctx context.Context, params *protocol.WorkspaceSymbolParams) (*protocol.SymbolInformationSliceOrWorkspaceSymbolSlice, error) {
+	paths, err := h.workspaceFiles()
+	if err != nil {
+		return nil, fmt.Errorf("workspace/symbol: %s", err)
+	}
+
+	var progress *workDoneProgress
+	if params.WorkDoneProgressParams != nil && len(paths) >= h.minFilesForProgress {
+		progress = h.progress.New(params.WorkDoneToken)
+	}
+	if err := progress.Begin("Searching workspace symbols"); err != nil {
+		h.log.Error(err)
+	}
+	defer func() {
+		if err := progress.End(); err != nil {
+			h.log.Error(err)
+		}
+	}()
+
+	symbols := protocol.SymbolInformationSlice{}
+	for i, path := range paths {
+		if progress.Cancelled() || ctx.Err() != nil {
+			return nil, jsonrpc.NewError(jsonrpc.ErrorCode(protocol.LSPErrorCodesRequestCancelled), "Request cancelled", nil)
+		}
+		if err := progress.Report(i*100/len(paths), filepath.Base(path)); err != nil {
+			h.log.Error(err)
+		}
+
+		uri := pathToURI(path)
+		program, err := h.workspaceProgram(uri, path)
+		if err != nil {
+			h.log.Errorf("workspace/symbol: %s", err)
+			continue
+		}
+		for _, symbol := range toSymbolInformations(documentSymbols(program), uri) {
+			if matchesQuery(symbol.Name, params.Query) {
+				symbols = append(symbols, symbol)
+			}
+		}
+	}
+
+	return &protocol.SymbolInformationSliceOrWorkspaceSymbolSlice{Value: symbols}, nil
+}
+
+// workspaceFiles returns the paths of all Lox files under the workspace roots.
+func (h *Handler) workspaceFiles() ([]string, error) {
+	if h.workspaceIndex != nil && h.workspaceIndex.paths != nil {
+		return h.workspaceIndex.paths, nil
+	}
+	paths := []string{}
+	for _, root := range h.workspaceRoots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if !d.IsDir() && filepath.Ext(path) == loxFileExt {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("finding Lox files in %s: %s", root, err)
+		}
+	}
+	if h.workspaceIndex != nil {
+		h.workspaceIndex.paths = paths
+	}
+	return paths, nil
+}
+
+// workspaceProgram returns the program contained in a file in the workspace. The contents of an open document are used
+// in preference to the contents of the file on disk, which are cached in the workspace index if there is one.
+func (h *Handler) workspaceProgram(uri string, path string) (ast.Program, error) {
+	if err := h.applyPendingChange(uri); err != nil {
+		return ast.Program{}, err
+	}
+	if doc, ok := h.docsByURI[uri]; ok {
+		return doc.Program, nil
+	}
+	if program, ok := h.workspaceIndex.program(uri); ok {
+		return program, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ast.Program{}, err
+	}
+	// Syntax errors are ignored as we can still find the symbols in the incomplete program.
+	program, _ := parser.ParseFile(token.NewFile(uri, data))
+	if h.workspaceIndex != nil {
+		h.workspaceIndex.programsByURI[uri] = program
+	}
+	return program, nil
+}
+
+// program returns the cached program contained in the file with the given URI. A nil *workspaceIndex contains no
+// programs.
+func (i *workspaceIndex) program(uri string) (ast.Program, bool) {
+	if i == nil {
+		return ast.Program{}, false
+	}
+	program, ok := i.programsByURI[uri]
+	return program, ok
+}
+
+// matchesQuery reports whether all characters of the query appear in the name in the same order, ignoring case.
+func matchesQuery(name string, query string) bool {
+	queryRunes := []rune(query)
+	for _, r := range name {
+		if len(queryRunes) == 0 {
+			break
+		}
+		if unicode.ToLower(r) == unicode.ToLower(queryRunes[0]) {
+			queryRunes = queryRunes[1:]
+		}
+	}
+	return len(queryRunes) == 0
+}
+
+// uriToPath converts a file URI to a path. An error is returned if the URI doesn't use the file scheme.
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI scheme %q", u.Scheme)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+// pathToURI converts a path to a file URI.
+func pathToURI(path string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return u.String()
+}
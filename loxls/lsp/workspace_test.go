@@ -0,0 +1,205 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/marcuscaisey/lox/loxls/jsonrpc"
+	"github.com/marcuscaisey/lox/loxls/lsp/protocol"
+)
+
+func TestWorkspaceIndex(t *testing.T) {
+	root := t.TempDir()
+	writeFile := func(name, contents string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("a.lox", "fun foo() {}\n")
+
+	s := startServer(t)
+	resp := s.Request(t, 0, "initialize", map[string]any{
+		"processId": nil,
+		"rootUri":   pathToURI(root),
+		"capabilities": map[string]any{
+			"workspace": map[string]any{"didChangeWatchedFiles": map[string]any{"dynamicRegistration": true}},
+		},
+	})
+	if resp.Error != nil {
+		t.Fatalf("initialize returned error: %+v", resp.Error)
+	}
+	s.Notify(t, "initialized", map[string]any{})
+
+	var registration struct {
+		Registrations []struct {
+			Method          string `json:"method"`
+			RegisterOptions struct {
+				Watchers []struct {
+					GlobPattern string `json:"globPattern"`
+				} `json:"watchers"`
+			} `json:"registerOptions"`
+		} `json:"registrations"`
+	}
+	// The params of a request from the server are read in the same way as those of a notification.
+	if err := json.Unmarshal(s.WaitForNotification(t, "client/registerCapability"), &registration); err != nil {
+		t.Fatal(err)
+	}
+	if len(registration.Registrations) != 1 || registration.Registrations[0].Method != "workspace/didChangeWatchedFiles" {
+		t.Fatalf("client/registerCapability registrations = %+v, want workspace/didChangeWatchedFiles", registration.Registrations)
+	}
+	if watchers := registration.Registrations[0].RegisterOptions.Watchers; len(watchers) != 1 || watchers[0].GlobPattern != "**/*.lox" {
+		t.Fatalf("watchers = %+v, want **/*.lox", watchers)
+	}
+
+	id := 0
+	assertSymbols := func(want ...string) {
+		t.Helper()
+		id++
+		resp := s.Request(t, id, "workspace/symbol", map[string]any{"query": ""})
+		if resp.Error != nil {
+			t.Fatalf("workspace/symbol returned error: %+v", resp.Error)
+		}
+		var symbols []struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(resp.Result, &symbols); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, symbol := range symbols {
+			got = append(got, symbol.Name)
+		}
+		slices.Sort(got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("incorrect workspace symbols (-want +got):\n%s", diff)
+		}
+	}
+
+	assertSymbols("foo")
+
+	// The index isn't updated until the client notifies the server of the change.
+	writeFile("a.lox", "fun bar() {}\n")
+	assertSymbols("foo")
+	s.Notify(t, "workspace/didChangeWatchedFiles", map[string]any{
+		"changes": []map[string]any{{"uri": pathToURI(filepath.Join(root, "a.lox")), "type": 2}},
+	})
+	assertSymbols("bar")
+
+	writeFile("b.lox", "fun baz() {}\n")
+	s.Notify(t, "workspace/didChangeWatchedFiles", map[string]any{
+		"changes": []map[string]any{{"uri": pathToURI(filepath.Join(root, "b.lox")), "type": 1}},
+	})
+	assertSymbols("bar", "baz")
+
+	if err := os.Remove(filepath.Join(root, "a.lox")); err != nil {
+		t.Fatal(err)
+	}
+	s.Notify(t, "workspace/didChangeWatchedFiles", map[string]any{
+		"changes": []map[string]any{{"uri": pathToURI(filepath.Join(root, "a.lox")), "type": 3}},
+	})
+	assertSymbols("baz")
+}
+
+// startWorkspaceServer starts a server whose workspace contains n Lox files and performs the initialize handshake.
+func startWorkspaceServer(t *testing.T, n int, opts ...HandlerOption) *testServer {
+	t.Helper()
+	root := t.TempDir()
+	for i := range n {
+		if err := os.WriteFile(filepath.Join(root, fmt.Sprintf("%d.lox", i)), []byte("var x = 1;\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := startServer(t, opts...)
+	resp := s.Request(t, 0, "initialize", map[string]any{"processId": nil, "rootUri": pathToURI(root), "capabilities": map[string]any{}})
+	if resp.Error != nil {
+		t.Fatalf("initialize returned error: %+v", resp.Error)
+	}
+	s.Notify(t, "initialized", map[string]any{})
+	return s
+}
+
+// workspaceSymbolProgress sends a workspace/symbol request with a work done token and returns the kinds of the
+// $/progress notifications sent for the token before the response.
+func workspaceSymbolProgress(t *testing.T, s *testServer, id int) []string {
+	t.Helper()
+	s.write(t, newTestMessage("workspace/symbol", map[string]any{"query": "", "workDoneToken": "scan"}, map[string]any{"id": id}))
+	var kinds []string
+	for {
+		method, data := s.next(t)
+		switch method {
+		case "":
+			return kinds
+		case "$/progress":
+			var notif struct {
+				Params struct {
+					Token string `json:"token"`
+					Value struct {
+						Kind string `json:"kind"`
+					} `json:"value"`
+				} `json:"params"`
+			}
+			if err := json.Unmarshal(data, &notif); err != nil {
+				t.Fatal(err)
+			}
+			if notif.Params.Token != "scan" {
+				t.Fatalf("$/progress token = %q, want %q", notif.Params.Token, "scan")
+			}
+			kinds = append(kinds, notif.Params.Value.Kind)
+		}
+	}
+}
+
+func TestWorkspaceSymbolProgress(t *testing.T) {
+	tests := []struct {
+		name  string
+		files int
+		want  []string
+	}{
+		{name: "BelowMinFiles", files: 2, want: nil},
+		{name: "MinFiles", files: 3, want: []string{"begin", "report", "report", "report", "end"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := startWorkspaceServer(t, test.files, WithMinFilesForProgress(3))
+			got := workspaceSymbolProgress(t, s, 1)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("incorrect $/progress kinds (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWorkspaceSymbolProgressCancellation(t *testing.T) {
+	// There are enough files that the server blocks writing progress reports whilst the cancellation is being sent, so
+	// the scan can't finish before it's cancelled.
+	s := startWorkspaceServer(t, 500, WithMinFilesForProgress(1))
+	s.write(t, newTestMessage("workspace/symbol", map[string]any{"query": "", "workDoneToken": "scan"}, map[string]any{"id": 1}))
+	s.WaitForNotification(t, "$/progress")
+	s.Notify(t, "window/workDoneProgress/cancel", map[string]any{"token": "scan"})
+
+	resp := s.Response(t, 1)
+	if resp.Error == nil || resp.Error.Code != jsonrpc.ErrorCode(protocol.LSPErrorCodesRequestCancelled) {
+		t.Fatalf("workspace/symbol response error = %+v, want RequestCancelled error", resp.Error)
+	}
+}
+
+func TestWorkspaceSymbolCancelRequest(t *testing.T) {
+	// There are enough files that the server blocks writing progress reports whilst the cancellation is being sent, so
+	// the scan can't finish before it's cancelled.
+	s := startWorkspaceServer(t, 500, WithMinFilesForProgress(1))
+	s.write(t, newTestMessage("workspace/symbol", map[string]any{"query": "", "workDoneToken": "scan"}, map[string]any{"id": 1}))
+	s.WaitForNotification(t, "$/progress")
+	s.Notify(t, "$/cancelRequest", map[string]any{"id": 1})
+
+	resp := s.Response(t, 1)
+	if resp.Error == nil || resp.Error.Code != jsonrpc.ErrorCode(protocol.LSPErrorCodesRequestCancelled) {
+		t.Fatalf("workspace/symbol response error = %+v, want RequestCancelled error", resp.Error)
+	}
+}
@@ -2,6 +2,9 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
 
@@ -9,12 +12,58 @@ import (
 	"github.com/marcuscaisey/lox/loxls/lsp"
 )
 
+var (
+	logFile          = flag.String("log-file", "", "Write logs to this file instead of stderr")
+	logLevel         = flag.String("log-level", "info", "Minimum level of logs to write, one of debug, info, warn, or error. Logs at this level are also sent to the client.")
+	progressMinFiles = flag.Int("progress-min-files", 50, "Minimum number of files that a workspace scan must cover for its progress to be reported")
+)
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: loxls [options]\n")
+	fmt.Fprintf(flag.CommandLine.Output(), "\n")
+	fmt.Fprintf(flag.CommandLine.Output(), "Options:\n")
+	flag.PrintDefaults()
+}
+
+func exitWithUsageErr(msg string) {
+	fmt.Fprintf(flag.CommandLine.Output(), "error: %s\n\n", msg)
+	flag.Usage()
+	os.Exit(2)
+}
+
 func main() {
-	handler := slog.NewTextHandler(os.Stderr, nil)
+	flag.Usage = usage
+	flag.Parse()
+
+	if len(flag.Args()) > 0 {
+		exitWithUsageErr("no arguments expected")
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*logLevel)); err != nil {
+		exitWithUsageErr(fmt.Sprintf("invalid -log-level: %s", err))
+	}
+
+	if *progressMinFiles < 0 {
+		exitWithUsageErr("-progress-min-files must not be negative")
+	}
+
+	var logOut io.Writer = os.Stderr
+	if *logFile != "" {
+		f, err := os.OpenFile(*logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		logOut = f
+	}
+
+	handler := slog.NewTextHandler(logOut, &slog.HandlerOptions{Level: level})
 	logger := slog.New(handler)
 	slog.SetDefault(logger)
 
-	if err := jsonrpc.Serve(os.Stdin, os.Stdout, lsp.NewHandler()); err != nil {
+	if err := jsonrpc.Serve(os.Stdin, os.Stdout, lsp.NewHandler(lsp.WithLogLevel(level), lsp.WithMinFilesForProgress(*progressMinFiles))); err != nil {
 		slog.Error("Something went wrong", "error", err.Error())
 		os.Exit(1)
 	}
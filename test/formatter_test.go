@@ -83,3 +83,50 @@ func (r formatterRunner) Update(t *testing.T, path string) {
 	}
 	r.runFormatter(t, path, "-w")
 }
+
+func TestFormatterDiff(t *testing.T) {
+	if *formatter == "" {
+		t.Skip("-formatter flag not provided")
+	}
+	r := newFormatterRunner(*pwd, *formatter)
+
+	tests := []struct {
+		name   string
+		source string
+		want   func(path string) string
+	}{
+		{
+			name:   "Unformatted",
+			source: "var a   = 1;\nprint a;\nprint a+1 ;\n",
+			want: func(path string) string {
+				return "--- " + path + ".orig\n" +
+					"+++ " + path + "\n" +
+					"@@ -1,3 +1,3 @@\n" +
+					"-var a   = 1;\n" +
+					"+var a = 1;\n" +
+					" print a;\n" +
+					"-print a+1 ;\n" +
+					"+print a + 1;\n"
+			},
+		},
+		{
+			name:   "Formatted",
+			source: "var a = 1;\nprint a;\n",
+			want:   func(string) string { return "" },
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.lox")
+			if err := os.WriteFile(path, []byte(test.source), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got := r.runFormatter(t, path, "-d")
+
+			if want := test.want(path); string(got) != want {
+				t.Errorf("incorrect diff printed to stdout:\n%s", computeTextDiff(want, string(got)))
+			}
+		})
+	}
+}
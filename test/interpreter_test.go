@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -14,7 +15,8 @@ import (
 
 var (
 	printsRe = regexp.MustCompile(`// prints: (.+)`)
-	errorRe  = regexp.MustCompile(`// error: (.+)`)
+	// errorRe matches error and warning comments. The matched group includes the severity.
+	errorRe = regexp.MustCompile(`// ((?:error|warning): .+)`)
 )
 
 func newInterpreterRunner(pwd string, interpreter string) interpreterRunner {
@@ -58,7 +60,8 @@ type interpreterResult struct {
 }
 
 func (r interpreterRunner) runInterpreter(t *testing.T, path string) interpreterResult {
-	cmd := exec.Command(r.interpreter, path)
+	args := append(strings.Fields(*interpreterArgs), path)
+	cmd := exec.Command(r.interpreter, args...)
 	relInterpeter, err := filepath.Rel(r.pwd, r.interpreter)
 	if err != nil {
 		t.Fatal(err)
@@ -71,8 +74,10 @@ func (r interpreterRunner) runInterpreter(t *testing.T, path string) interpreter
 	if err != nil {
 		t.Fatal(err)
 	}
-	t.Logf("%s %s", relInterpeter, relPath)
+	t.Logf("%s %s", relInterpeter, strings.Join(append(strings.Fields(*interpreterArgs), relPath), " "))
 
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
 	stdout, err := cmd.Output()
 
 	exitErr := &exec.ExitError{}
@@ -80,14 +85,14 @@ func (r interpreterRunner) runInterpreter(t *testing.T, path string) interpreter
 		t.Fatal(err)
 	}
 	var errors [][]byte
-	errorRe := regexp.MustCompile(`(?m)^.+:\d+:\d+: error: (.+)$`)
-	for _, match := range errorRe.FindAllSubmatch(exitErr.Stderr, -1) {
+	errorRe := regexp.MustCompile(`(?m)^.+:\d+:\d+: ((?:error|warning): .+)$`)
+	for _, match := range errorRe.FindAllSubmatch(stderr.Bytes(), -1) {
 		errors = append(errors, match[1])
 	}
 
 	return interpreterResult{
 		Stdout:   stdout,
-		Stderr:   exitErr.Stderr,
+		Stderr:   stderr.Bytes(),
 		Errors:   errors,
 		ExitCode: cmd.ProcessState.ExitCode(),
 	}
@@ -105,8 +110,10 @@ func (r interpreterRunner) parseExpectedResult(t *testing.T, path string) interp
 		Stdout: r.parseExpectedStdout(data),
 		Errors: errors,
 	}
-	if len(result.Errors) > 0 {
-		result.ExitCode = 1
+	for _, err := range result.Errors {
+		if bytes.HasPrefix(err, []byte("error: ")) {
+			result.ExitCode = 1
+		}
 	}
 
 	return result
@@ -200,7 +207,7 @@ func (r interpreterRunner) updateExpectedStdout(t *testing.T, path string, data
 func (r interpreterRunner) updateExpectedErrors(t *testing.T, path string, data []byte, errors [][]byte) []byte {
 	matches := errorRe.FindAllSubmatchIndex(data, -1)
 	if len(errors) != len(matches) {
-		t.Fatalf(`%d "// error:" %s found in %s but %d %s printed to stderr, these should be equal`,
+		t.Fatalf(`%d "// error:" or "// warning:" %s found in %s but %d %s printed to stderr, these should be equal`,
 			len(matches), pluralise("comment", len(matches)), path, len(errors), pluralise("error", len(errors)))
 	}
 	if len(errors) == 0 {
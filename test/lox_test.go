@@ -22,10 +22,11 @@ func init() {
 }
 
 var (
-	pwd         = flag.String("pwd", "", "directory that the test was invoked from")
-	interpreter = flag.String("interpreter", "", "path to the interpreter to test")
-	formatter   = flag.String("formatter", "", "path to the formatter to test")
-	update      = flag.Bool("update", false, "updates the expected output of each test")
+	pwd             = flag.String("pwd", "", "directory that the test was invoked from")
+	interpreter     = flag.String("interpreter", "", "path to the interpreter to test")
+	interpreterArgs = flag.String("interpreter-args", "", "space separated arguments to pass to the interpreter before the test file")
+	formatter       = flag.String("formatter", "", "path to the formatter to test")
+	update          = flag.Bool("update", false, "updates the expected output of each test")
 )
 
 type testRunner interface {
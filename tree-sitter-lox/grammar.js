@@ -31,11 +31,14 @@ module.exports = grammar({
     // Declarations
     _declaration: ($) =>
       choice(
+        $.import_declaration,
         $.variable_declaration,
         $.function_declaration,
         $.class_declaration,
       ),
 
+    import_declaration: ($) => seq("import", field("path", $.string), ";"),
+
     variable_declaration: ($) =>
       seq(
         "var",
@@ -47,7 +50,12 @@ module.exports = grammar({
     function_declaration: ($) => seq("fun", $._function),
 
     class_declaration: ($) =>
-      seq("class", field("name", $.identifier), field("body", $.class_body)),
+      seq(
+        "class",
+        field("name", $.identifier),
+        optional(seq("<", field("superclass", $.identifier))),
+        field("body", $.class_body),
+      ),
 
     class_body: ($) => seq("{", repeat($.method_declaration), "}"),
 
@@ -77,9 +85,12 @@ module.exports = grammar({
         $.if_statement,
         $.while_statement,
         $.for_statement,
+        $.for_in_statement,
         $.break_statement,
         $.continue_statement,
         $.return_statement,
+        $.try_statement,
+        $.throw_statement,
       ),
 
     // Statements
@@ -126,12 +137,46 @@ module.exports = grammar({
         field("body", $._statement),
       ),
 
+    for_in_statement: ($) =>
+      seq(
+        "for",
+        "(",
+        field("name", $.identifier),
+        "in",
+        field("iterable", $._expression),
+        ")",
+        field("body", $._statement),
+      ),
+
     break_statement: () => seq("break", ";"),
 
     continue_statement: () => seq("continue", ";"),
 
     return_statement: ($) => seq("return", $._expression, ";"),
 
+    try_statement: ($) =>
+      seq(
+        "try",
+        field("body", $.block_statement),
+        choice(
+          seq($.catch_clause, optional($.finally_clause)),
+          $.finally_clause,
+        ),
+      ),
+
+    catch_clause: ($) =>
+      seq(
+        "catch",
+        "(",
+        field("name", $.identifier),
+        ")",
+        field("body", $.block_statement),
+      ),
+
+    finally_clause: ($) => seq("finally", field("body", $.block_statement)),
+
+    throw_statement: ($) => seq("throw", $._expression, ";"),
+
     // Expressions
     _expression: ($) =>
       choice(
@@ -140,9 +185,11 @@ module.exports = grammar({
         $.group_expression,
         $.identifier,
         $.this_expression,
+        $.super_expression,
         $.call_expression,
         $.get_expression,
         $.unary_expression,
+        $.update_expression,
         $.binary_expression,
         $.ternary_expression,
         $.assignment_expression,
@@ -152,17 +199,42 @@ module.exports = grammar({
 
     number: (_) => /\d+(\.\d+)?/,
 
-    string: (_) => /"[^"\r\n]*"/,
+    // A $ only starts an interpolated expression when it's followed by {, so string contents are made up of characters
+    // other than $ and runs of $ which aren't followed by {.
+    string: ($) =>
+      choice(
+        /"([^"\r\n$]|\$+[^"\r\n{$])*\$*"/,
+        seq(
+          $._string_head,
+          $.interpolation,
+          repeat(seq($._string_middle, $.interpolation)),
+          $._string_tail,
+        ),
+      ),
+
+    _string_head: (_) => /"([^"\r\n$]|\$+[^"\r\n{$])*\$*\$\{/,
+
+    _string_middle: (_) => /\}([^"\r\n$]|\$+[^"\r\n{$])*\$*\$\{/,
+
+    _string_tail: (_) => /\}([^"\r\n$]|\$+[^"\r\n{$])*\$*"/,
+
+    interpolation: ($) => $._expression,
 
     boolean: (_) => choice("true", "false"),
 
     nil: (_) => "nil",
 
     function_expression: ($) =>
-      seq(
-        "fun",
-        field("parameters", $.parameters),
-        field("body", $.block_statement),
+      prec.right(
+        "assignment",
+        seq(
+          "fun",
+          field("parameters", $.parameters),
+          choice(
+            field("body", $.block_statement),
+            seq("=>", field("body", $._expression)),
+          ),
+        ),
       ),
 
     group_expression: ($) => seq("(", field("expression", $._expression), ")"),
@@ -171,6 +243,8 @@ module.exports = grammar({
 
     this_expression: (_) => "this",
 
+    super_expression: ($) => seq("super", ".", field("method", $.identifier)),
+
     call_expression: ($) =>
       prec(
         "postfix",
@@ -198,6 +272,24 @@ module.exports = grammar({
     unary_expression: ($) =>
       prec.right("unary", seq(choice("!", "-"), field("right", $._expression))),
 
+    update_expression: ($) =>
+      choice(
+        prec.right(
+          "unary",
+          seq(
+            choice("++", "--"),
+            field("operand", choice($.identifier, $.get_expression)),
+          ),
+        ),
+        prec(
+          "postfix",
+          seq(
+            field("operand", choice($.identifier, $.get_expression)),
+            choice("++", "--"),
+          ),
+        ),
+      ),
+
     binary_expression: ($) =>
       choice(
         prec.left(
@@ -248,7 +340,7 @@ module.exports = grammar({
           "multiplicative",
           seq(
             field("left", $._expression),
-            choice("*", "/", "%"),
+            choice("*", "/", "~/", "%"),
             field("right", $._expression),
           ),
         ),
@@ -2,6 +2,12 @@
 
 (this_expression) @variable.builtin
 
+(super_expression
+  "super" @variable.builtin)
+
+(super_expression
+  method: (identifier) @function.method)
+
 (parameters
   (identifier) @variable.parameter)
 
@@ -25,13 +31,20 @@
 (class_declaration
   name: (identifier) @type)
 
+(class_declaration
+  superclass: (identifier) @type)
+
 (function_declaration
   name: (identifier) @function)
 
 (call_expression
   callee: (identifier) @function.call)
 
-((identifier) @function.builtin (#any-of? @function.builtin "clock" "type" "error"))
+((identifier) @function.builtin
+  (#any-of? @function.builtin
+    "clock" "type" "error" "len" "substring" "indexOf" "charAt" "toUpper" "toLower" "trim"))
+
+((identifier) @module.builtin (#eq? @module.builtin "math"))
 
 (method_declaration
   name: (identifier) @function.method)
@@ -62,8 +75,12 @@
   "-"
   "*"
   "/"
+  "~/"
   "%"
+  "++"
+  "--"
   "="
+  "=>"
 ] @operator
 
 [
@@ -76,6 +93,8 @@
 
 "fun" @keyword.function
 
+"import" @keyword.import
+
 "class" @keyword.type
 
 (modifiers [
@@ -92,6 +111,7 @@
 [
   "while"
   "for"
+  "in"
 ] @keyword.repeat
 
 [
@@ -104,6 +124,13 @@
   ":"
 ] @keyword.conditional.ternary
 
+[
+  "try"
+  "catch"
+  "finally"
+  "throw"
+] @keyword.exception
+
 [
   ","
   "."